@@ -2,6 +2,7 @@ package gocosmos
 
 import (
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
@@ -16,6 +17,8 @@ var (
 type Conn struct {
 	restClient *RestClient // Azure Cosmos DB REST API client.
 	defaultDb  string      // default database used in Cosmos DB operations.
+	tx         *Tx         // transaction in progress, if any.
+	lastResult Result      // result of the last statement executed with ExecContext, see LastResult.
 }
 
 // String implements fmt.Stringer/String.
@@ -37,6 +40,45 @@ func (c *Conn) PrepareContext(_ context.Context, query string) (driver.Stmt, err
 	return ParseQueryWithDefaultDb(c, c.defaultDb, query)
 }
 
+// ExecContext implements driver.ExecerContext/ExecContext.
+//
+// The result of the statement is kept by the connection and can be retrieved with LastResult.
+//
+// @Available since v1.2.0
+func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.lastResult = nil
+	stmt, err := c.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stmt.Close() }()
+	// database/sql does not check the number of arguments if the connection executes the statement by itself
+	if numInputs := stmt.NumInput(); numInputs >= 0 && numInputs != len(args) {
+		return nil, fmt.Errorf("sql: expected %d arguments, got %d", numInputs, len(args))
+	}
+	var result driver.Result
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		values := make([]driver.Value, len(args))
+		for i, arg := range args {
+			values[i] = arg.Value
+		}
+		result, err = stmt.Exec(values)
+	}
+	c.lastResult, _ = result.(Result)
+	return result, err
+}
+
+// LastResult returns the result of the last statement executed by the connection with ExecContext (i.e. sql.DB.Exec,
+// sql.Conn.ExecContext or sql.Tx.Exec, but not a prepared statement), nil if it failed before reaching the server.
+// Use sql.Conn.Raw to access the driver's connection, see Result.
+//
+// @Available since v1.2.0
+func (c *Conn) LastResult() Result {
+	return c.lastResult
+}
+
 // Close implements driver.Conn/Close.
 func (c *Conn) Close() error {
 	return nil
@@ -49,9 +91,19 @@ func (c *Conn) Begin() (driver.Tx, error) {
 
 // BeginTx implements driver.ConnBeginTx/BeginTx.
 //
+// (since v1.2.0) Transactions are supported for INSERT, UPSERT, UPDATE and DELETE statements operating on the same
+// logical partition, see Tx. Only the default isolation level is supported.
+//
 // @Available since v0.2.1
-func (c *Conn) BeginTx(_ context.Context, _ driver.TxOptions) (driver.Tx, error) {
-	return nil, errors.New("transaction is not supported")
+func (c *Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, fmt.Errorf("isolation level %d is not supported", opts.Isolation)
+	}
+	if c.tx != nil {
+		return nil, errors.New("a transaction is already in progress")
+	}
+	c.tx = &Tx{conn: c, ctx: ctx}
+	return c.tx, nil
 }
 
 // CheckNamedValue implements driver.NamedValueChecker/CheckNamedValue.
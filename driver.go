@@ -8,7 +8,6 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"strconv"
 	"strings"
 	"time"
 
@@ -49,7 +48,7 @@ func _myMacAddr(ip string) (net.HardwareAddr, error) {
 func _valuesToNamedValues(values []driver.Value) []driver.NamedValue {
 	result := make([]driver.NamedValue, len(values))
 	for i, v := range values {
-		result[i] = driver.NamedValue{Name: "$" + strconv.Itoa(i+1), Ordinal: i, Value: v}
+		result[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
 	}
 	return result
 }
@@ -97,6 +96,24 @@ var (
 	//
 	// @Available since v0.2.1
 	ErrQueryNotSupported = errors.New("this operation is not supported, please use Exec")
+
+	// ErrTxNotCommitted is returned by the driver.Result of a statement executed inside a transaction when the
+	// transaction has not been committed (yet).
+	//
+	// @Available since v1.2.0
+	ErrTxNotCommitted = errors.New("transaction has not been committed")
+
+	// ErrTxCrossPartition is returned when a statement executed inside a transaction does not operate on the same
+	// collection and logical partition as the previous statements of the transaction.
+	//
+	// @Available since v1.2.0
+	ErrTxCrossPartition = errors.New("all statements in a transaction must operate on the same collection and partition key value")
+
+	// ErrTooManyRows is returned by a set-based UPDATE or DELETE statement when more documents than allowed by
+	// WITH MAX_ROWS match the WHERE clause. No document is modified in that case.
+	//
+	// @Available since v1.2.0
+	ErrTooManyRows = errors.New("too many documents match the WHERE clause")
 )
 
 /*----------------------------------------------------------------------*/
@@ -109,13 +126,17 @@ type Driver struct {
 //
 // connStr is expected in the following format:
 //
-//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;DefaultDb=<db-name>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>]
+//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>|AadToken=<access-token>|TokenProvider=<name>|ResourceToken=<url-encoded-resource-token>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;DefaultDb=<db-name>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>][;MaxRetries=<max-retries>][;MaxRetryWaitMs=<max-retry-wait-in-ms>][;MaxDegreeOfParallelism=<n>]
 //
-// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, and InsecureSkipVerify is false
+// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, InsecureSkipVerify is false,
+// MaxRetries is DefaultMaxRetries, MaxRetryWaitMs is DefaultMaxRetryWaitMs and MaxDegreeOfParallelism is 1.
 //
 // - DefaultDb is added since v0.1.1
 // - AutoId is added since v0.1.2
 // - InsecureSkipVerify is added since v0.1.4
+// - MaxRetries and MaxRetryWaitMs are added since v1.2.0
+// - MaxDegreeOfParallelism is added since v1.2.0: default max number of partition key ranges queried concurrently by SELECT queries, see NewRestClient
+// - AadToken, TokenProvider and ResourceToken are added since v1.2.0, see NewRestClient
 func (d *Driver) Open(connStr string) (driver.Conn, error) {
 	restClient, err := NewRestClient(nil, connStr)
 	if err != nil {
@@ -0,0 +1,1631 @@
+package gocosmostest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// undefinedValue is the value of a property that does not exist, it is distinct from JSON null.
+type undefinedValue struct{}
+
+var undefined = undefinedValue{}
+
+/*----------------------------------------------------------------------*/
+
+const (
+	tokEOF = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokParam
+	tokOp
+)
+
+type token struct {
+	kind int
+	text string // identifier, operator or parameter name; unescaped content of string literals
+	num  float64
+	pos  int
+}
+
+type lexer struct {
+	input  string
+	pos    int
+	tokens []token
+}
+
+func (l *lexer) errorf(pos int, format string, a ...interface{}) error {
+	return fmt.Errorf("syntax error at position %d: %s", pos+1, fmt.Sprintf(format, a...))
+}
+
+func tokenize(input string) ([]token, error) {
+	l := &lexer{input: input}
+	for {
+		for l.pos < len(l.input) && unicode.IsSpace(rune(l.input[l.pos])) {
+			l.pos++
+		}
+		if l.pos >= len(l.input) {
+			l.tokens = append(l.tokens, token{kind: tokEOF, pos: l.pos})
+			return l.tokens, nil
+		}
+		start, ch := l.pos, l.input[l.pos]
+		switch {
+		case ch == '_' || ch == '$' || unicode.IsLetter(rune(ch)):
+			for l.pos < len(l.input) && (l.input[l.pos] == '_' || l.input[l.pos] == '$' || unicode.IsLetter(rune(l.input[l.pos])) || unicode.IsDigit(rune(l.input[l.pos]))) {
+				l.pos++
+			}
+			l.tokens = append(l.tokens, token{kind: tokIdent, text: l.input[start:l.pos], pos: start})
+		case ch == '@':
+			l.pos++
+			for l.pos < len(l.input) && (l.input[l.pos] == '_' || unicode.IsLetter(rune(l.input[l.pos])) || unicode.IsDigit(rune(l.input[l.pos]))) {
+				l.pos++
+			}
+			if l.pos == start+1 {
+				return nil, l.errorf(start, "invalid parameter name")
+			}
+			l.tokens = append(l.tokens, token{kind: tokParam, text: l.input[start:l.pos], pos: start})
+		case unicode.IsDigit(rune(ch)) || (ch == '.' && l.pos+1 < len(l.input) && unicode.IsDigit(rune(l.input[l.pos+1]))):
+			for l.pos < len(l.input) && (unicode.IsDigit(rune(l.input[l.pos])) || l.input[l.pos] == '.') {
+				l.pos++
+			}
+			if l.pos < len(l.input) && (l.input[l.pos] == 'e' || l.input[l.pos] == 'E') {
+				l.pos++
+				if l.pos < len(l.input) && (l.input[l.pos] == '+' || l.input[l.pos] == '-') {
+					l.pos++
+				}
+				for l.pos < len(l.input) && unicode.IsDigit(rune(l.input[l.pos])) {
+					l.pos++
+				}
+			}
+			num, err := strconv.ParseFloat(l.input[start:l.pos], 64)
+			if err != nil {
+				return nil, l.errorf(start, "invalid number %q", l.input[start:l.pos])
+			}
+			l.tokens = append(l.tokens, token{kind: tokNumber, num: num, pos: start})
+		case ch == '\'' || ch == '"':
+			str, err := l.scanString(ch)
+			if err != nil {
+				return nil, err
+			}
+			l.tokens = append(l.tokens, token{kind: tokString, text: str, pos: start})
+		default:
+			op := ""
+			for _, candidate := range []string{"!=", "<>", "<=", ">=", "||", "??", "=", "<", ">", "+", "-", "*", "/", "%", "(", ")", "[", "]", "{", "}", ",", ".", ":", "?"} {
+				if strings.HasPrefix(l.input[l.pos:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, l.errorf(start, "unexpected character %q", ch)
+			}
+			l.pos += len(op)
+			l.tokens = append(l.tokens, token{kind: tokOp, text: op, pos: start})
+		}
+	}
+}
+
+func (l *lexer) scanString(quote byte) (string, error) {
+	start := l.pos
+	l.pos++
+	var sb strings.Builder
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+		switch {
+		case ch == quote:
+			l.pos++
+			return sb.String(), nil
+		case ch == '\\' && l.pos+1 < len(l.input):
+			l.pos++
+			switch esc := l.input[l.pos]; esc {
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if l.pos+4 >= len(l.input) {
+					return "", l.errorf(l.pos, "invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(l.input[l.pos+1:l.pos+5], 16, 32)
+				if err != nil {
+					return "", l.errorf(l.pos, "invalid unicode escape")
+				}
+				sb.WriteRune(rune(code))
+				l.pos += 4
+			default:
+				sb.WriteByte(esc)
+			}
+			l.pos++
+		default:
+			sb.WriteByte(ch)
+			l.pos++
+		}
+	}
+	return "", l.errorf(start, "unterminated string literal")
+}
+
+/*----------------------------------------------------------------------*/
+
+// evalCtx is the context an expression is evaluated in.
+type evalCtx struct {
+	alias  string
+	doc    interface{}
+	params map[string]interface{}
+	group  []interface{} // documents of the current group, used by aggregate functions
+}
+
+type expr interface {
+	eval(ctx *evalCtx) interface{}
+}
+
+type exprLiteral struct{ value interface{} }
+
+func (e *exprLiteral) eval(*evalCtx) interface{} { return e.value }
+
+type exprParam struct{ name string }
+
+func (e *exprParam) eval(ctx *evalCtx) interface{} {
+	if v, ok := ctx.params[e.name]; ok {
+		return v
+	}
+	return undefined
+}
+
+type exprRef struct{ name string }
+
+func (e *exprRef) eval(ctx *evalCtx) interface{} {
+	if e.name == ctx.alias {
+		return ctx.doc
+	}
+	return undefined
+}
+
+type exprMember struct {
+	obj  expr
+	prop expr
+}
+
+func (e *exprMember) eval(ctx *evalCtx) interface{} {
+	obj, prop := e.obj.eval(ctx), e.prop.eval(ctx)
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		if name, ok := prop.(string); ok {
+			if v, ok := o[name]; ok {
+				return v
+			}
+		}
+	case []interface{}:
+		if f, ok := prop.(float64); ok && f >= 0 && f == math.Trunc(f) && int(f) < len(o) {
+			return o[int(f)]
+		}
+	}
+	return undefined
+}
+
+type exprUnary struct {
+	op string
+	x  expr
+}
+
+func (e *exprUnary) eval(ctx *evalCtx) interface{} {
+	v := e.x.eval(ctx)
+	switch e.op {
+	case "NOT":
+		if b, ok := v.(bool); ok {
+			return !b
+		}
+	case "-":
+		if f, ok := v.(float64); ok {
+			return -f
+		}
+	case "+":
+		if f, ok := v.(float64); ok {
+			return f
+		}
+	}
+	return undefined
+}
+
+type exprBinary struct {
+	op   string
+	l, r expr
+}
+
+func (e *exprBinary) eval(ctx *evalCtx) interface{} {
+	switch e.op {
+	case "AND":
+		l, r := e.l.eval(ctx), e.r.eval(ctx)
+		if l == false || r == false {
+			return false
+		}
+		if l == true && r == true {
+			return true
+		}
+		return undefined
+	case "OR":
+		l, r := e.l.eval(ctx), e.r.eval(ctx)
+		if l == true || r == true {
+			return true
+		}
+		if l == false && r == false {
+			return false
+		}
+		return undefined
+	case "??":
+		if l := e.l.eval(ctx); l != undefined {
+			return l
+		}
+		return e.r.eval(ctx)
+	}
+	l, r := e.l.eval(ctx), e.r.eval(ctx)
+	switch e.op {
+	case "=", "!=", "<>":
+		eq, ok := equals(l, r)
+		if !ok {
+			return undefined
+		}
+		return eq == (e.op == "=")
+	case "<", "<=", ">", ">=":
+		c, ok := compare(l, r)
+		if !ok {
+			return undefined
+		}
+		switch e.op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		}
+		return c >= 0
+	case "||":
+		ls, lok := l.(string)
+		rs, rok := r.(string)
+		if lok && rok {
+			return ls + rs
+		}
+		return undefined
+	}
+	lf, lok := l.(float64)
+	rf, rok := r.(float64)
+	if !lok || !rok {
+		return undefined
+	}
+	switch e.op {
+	case "+":
+		return lf + rf
+	case "-":
+		return lf - rf
+	case "*":
+		return lf * rf
+	case "/":
+		if rf == 0 {
+			return undefined
+		}
+		return lf / rf
+	case "%":
+		if rf == 0 {
+			return undefined
+		}
+		return math.Mod(lf, rf)
+	}
+	return undefined
+}
+
+type exprIn struct {
+	x    expr
+	list []expr
+	not  bool
+}
+
+func (e *exprIn) eval(ctx *evalCtx) interface{} {
+	v := e.x.eval(ctx)
+	if v == undefined {
+		return undefined
+	}
+	for _, item := range e.list {
+		if eq, ok := equals(v, item.eval(ctx)); ok && eq {
+			return !e.not
+		}
+	}
+	return e.not
+}
+
+type exprBetween struct {
+	x, lo, hi expr
+	not       bool
+}
+
+func (e *exprBetween) eval(ctx *evalCtx) interface{} {
+	v := e.x.eval(ctx)
+	c1, ok1 := compare(v, e.lo.eval(ctx))
+	c2, ok2 := compare(v, e.hi.eval(ctx))
+	if !ok1 || !ok2 {
+		return undefined
+	}
+	return (c1 >= 0 && c2 <= 0) != e.not
+}
+
+type exprLike struct {
+	x, pattern expr
+	not        bool
+}
+
+func (e *exprLike) eval(ctx *evalCtx) interface{} {
+	s, ok1 := e.x.eval(ctx).(string)
+	p, ok2 := e.pattern.eval(ctx).(string)
+	if !ok1 || !ok2 {
+		return undefined
+	}
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	for _, ch := range p {
+		switch ch {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String()).MatchString(s) != e.not
+}
+
+type exprTernary struct{ cond, yes, no expr }
+
+func (e *exprTernary) eval(ctx *evalCtx) interface{} {
+	if e.cond.eval(ctx) == true {
+		return e.yes.eval(ctx)
+	}
+	return e.no.eval(ctx)
+}
+
+type exprArray struct{ items []expr }
+
+func (e *exprArray) eval(ctx *evalCtx) interface{} {
+	result := make([]interface{}, 0, len(e.items))
+	for _, item := range e.items {
+		if v := item.eval(ctx); v != undefined {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+type exprObject struct {
+	keys  []string
+	items []expr
+}
+
+func (e *exprObject) eval(ctx *evalCtx) interface{} {
+	result := make(map[string]interface{}, len(e.items))
+	for i, item := range e.items {
+		if v := item.eval(ctx); v != undefined {
+			result[e.keys[i]] = v
+		}
+	}
+	return result
+}
+
+type exprCall struct {
+	name string // upper-cased function name
+	args []expr
+}
+
+var aggregateFuncs = map[string]bool{"COUNT": true, "SUM": true, "MIN": true, "MAX": true, "AVG": true}
+
+// builtinFuncs maps the supported built-in functions to their number of arguments (min, max).
+var builtinFuncs = map[string][2]int{
+	"IS_DEFINED": {1, 1}, "IS_NULL": {1, 1}, "IS_BOOL": {1, 1}, "IS_NUMBER": {1, 1}, "IS_STRING": {1, 1},
+	"IS_ARRAY": {1, 1}, "IS_OBJECT": {1, 1}, "IS_PRIMITIVE": {1, 1},
+	"CONTAINS": {2, 3}, "STARTSWITH": {2, 3}, "ENDSWITH": {2, 3}, "LOWER": {1, 1}, "UPPER": {1, 1},
+	"LENGTH": {1, 1}, "CONCAT": {2, 100}, "SUBSTRING": {3, 3}, "INDEX_OF": {2, 3}, "TRIM": {1, 1},
+	"LTRIM": {1, 1}, "RTRIM": {1, 1}, "REPLACE": {3, 3}, "TOSTRING": {1, 1}, "STRINGEQUALS": {2, 3},
+	"ARRAY_CONTAINS": {2, 3}, "ARRAY_LENGTH": {1, 1}, "ARRAY_CONCAT": {2, 100}, "ARRAY_SLICE": {2, 3},
+	"ABS": {1, 1}, "CEILING": {1, 1}, "FLOOR": {1, 1}, "ROUND": {1, 1}, "TRUNC": {1, 1}, "SQRT": {1, 1},
+	"POWER": {2, 2},
+	"COUNT": {1, 1}, "SUM": {1, 1}, "MIN": {1, 1}, "MAX": {1, 1}, "AVG": {1, 1},
+}
+
+func (e *exprCall) eval(ctx *evalCtx) interface{} {
+	if aggregateFuncs[e.name] {
+		return e.aggregate(ctx)
+	}
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		args[i] = arg.eval(ctx)
+	}
+	typeOf := func(v interface{}) string {
+		switch v.(type) {
+		case nil:
+			return "null"
+		case bool:
+			return "bool"
+		case float64:
+			return "number"
+		case string:
+			return "string"
+		case []interface{}:
+			return "array"
+		case map[string]interface{}:
+			return "object"
+		}
+		return "undefined"
+	}
+	ignoreCase := len(args) > 2 && args[len(args)-1] == true
+	strArgs := func(n int) ([]string, bool) {
+		result := make([]string, n)
+		for i := 0; i < n; i++ {
+			s, ok := args[i].(string)
+			if !ok {
+				return nil, false
+			}
+			if ignoreCase {
+				s = strings.ToLower(s)
+			}
+			result[i] = s
+		}
+		return result, true
+	}
+	numArg := func(i int) (float64, bool) {
+		f, ok := args[i].(float64)
+		return f, ok
+	}
+	switch e.name {
+	case "IS_DEFINED":
+		return args[0] != undefined
+	case "IS_NULL":
+		return args[0] == nil
+	case "IS_BOOL":
+		return typeOf(args[0]) == "bool"
+	case "IS_NUMBER":
+		return typeOf(args[0]) == "number"
+	case "IS_STRING":
+		return typeOf(args[0]) == "string"
+	case "IS_ARRAY":
+		return typeOf(args[0]) == "array"
+	case "IS_OBJECT":
+		return typeOf(args[0]) == "object"
+	case "IS_PRIMITIVE":
+		t := typeOf(args[0])
+		return t == "null" || t == "bool" || t == "number" || t == "string"
+	case "CONTAINS", "STARTSWITH", "ENDSWITH", "STRINGEQUALS":
+		s, ok := strArgs(2)
+		if !ok {
+			return undefined
+		}
+		switch e.name {
+		case "CONTAINS":
+			return strings.Contains(s[0], s[1])
+		case "STARTSWITH":
+			return strings.HasPrefix(s[0], s[1])
+		case "ENDSWITH":
+			return strings.HasSuffix(s[0], s[1])
+		}
+		return s[0] == s[1]
+	case "INDEX_OF":
+		if s, ok := strArgs(2); ok {
+			return float64(strings.Index(s[0], s[1]))
+		}
+	case "LOWER", "UPPER", "LENGTH", "TRIM", "LTRIM", "RTRIM":
+		s, ok := args[0].(string)
+		if !ok {
+			return undefined
+		}
+		switch e.name {
+		case "LOWER":
+			return strings.ToLower(s)
+		case "UPPER":
+			return strings.ToUpper(s)
+		case "LENGTH":
+			return float64(len([]rune(s)))
+		case "TRIM":
+			return strings.TrimSpace(s)
+		case "LTRIM":
+			return strings.TrimLeftFunc(s, unicode.IsSpace)
+		}
+		return strings.TrimRightFunc(s, unicode.IsSpace)
+	case "CONCAT":
+		var sb strings.Builder
+		for _, arg := range args {
+			s, ok := arg.(string)
+			if !ok {
+				return undefined
+			}
+			sb.WriteString(s)
+		}
+		return sb.String()
+	case "SUBSTRING":
+		s, ok := args[0].(string)
+		start, ok1 := numArg(1)
+		length, ok2 := numArg(2)
+		if !ok || !ok1 || !ok2 {
+			return undefined
+		}
+		runes := []rune(s)
+		from, to := int(math.Max(0, start)), int(math.Max(0, start+length))
+		if from > len(runes) {
+			from = len(runes)
+		}
+		if to > len(runes) {
+			to = len(runes)
+		}
+		return string(runes[from:to])
+	case "REPLACE":
+		if s, ok := strArgs(3); ok {
+			return strings.ReplaceAll(s[0], s[1], s[2])
+		}
+	case "TOSTRING":
+		switch v := args[0].(type) {
+		case undefinedValue:
+			return undefined
+		case string:
+			return v
+		default:
+			js, _ := json.Marshal(v)
+			return string(js)
+		}
+	case "ARRAY_CONTAINS":
+		arr, ok := args[0].([]interface{})
+		if !ok {
+			return undefined
+		}
+		for _, item := range arr {
+			if partialMatch(item, args[1], ignoreCase) {
+				return true
+			}
+		}
+		return false
+	case "ARRAY_LENGTH":
+		if arr, ok := args[0].([]interface{}); ok {
+			return float64(len(arr))
+		}
+	case "ARRAY_CONCAT":
+		result := make([]interface{}, 0)
+		for _, arg := range args {
+			arr, ok := arg.([]interface{})
+			if !ok {
+				return undefined
+			}
+			result = append(result, arr...)
+		}
+		return result
+	case "ARRAY_SLICE":
+		arr, ok := args[0].([]interface{})
+		start, ok1 := numArg(1)
+		if !ok || !ok1 {
+			return undefined
+		}
+		from := int(start)
+		if from < 0 {
+			from += len(arr)
+		}
+		from = int(math.Max(0, math.Min(float64(from), float64(len(arr)))))
+		to := len(arr)
+		if len(args) > 2 {
+			length, ok := numArg(2)
+			if !ok {
+				return undefined
+			}
+			to = int(math.Max(float64(from), math.Min(float64(from)+length, float64(len(arr)))))
+		}
+		return append([]interface{}{}, arr[from:to]...)
+	case "ABS", "CEILING", "FLOOR", "ROUND", "TRUNC", "SQRT":
+		f, ok := numArg(0)
+		if !ok {
+			return undefined
+		}
+		switch e.name {
+		case "ABS":
+			return math.Abs(f)
+		case "CEILING":
+			return math.Ceil(f)
+		case "FLOOR":
+			return math.Floor(f)
+		case "ROUND":
+			return math.Round(f)
+		case "TRUNC":
+			return math.Trunc(f)
+		}
+		return math.Sqrt(f)
+	case "POWER":
+		base, ok1 := numArg(0)
+		exp, ok2 := numArg(1)
+		if ok1 && ok2 {
+			return math.Pow(base, exp)
+		}
+	}
+	return undefined
+}
+
+// partialMatch implements the matching rule of ARRAY_CONTAINS: with partial matching, an object matches if it contains
+// all properties of the searched object.
+func partialMatch(item, search interface{}, partial bool) bool {
+	if partial {
+		if s, ok := search.(map[string]interface{}); ok {
+			o, ok := item.(map[string]interface{})
+			if !ok {
+				return false
+			}
+			for k, v := range s {
+				if eq, ok := equals(o[k], v); !ok || !eq {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	eq, ok := equals(item, search)
+	return ok && eq
+}
+
+func (e *exprCall) aggregate(ctx *evalCtx) interface{} {
+	var count, sum float64
+	var best interface{} = undefined
+	allNumbers := true
+	for _, doc := range ctx.group {
+		v := e.args[0].eval(&evalCtx{alias: ctx.alias, doc: doc, params: ctx.params})
+		if v == undefined {
+			continue
+		}
+		count++
+		if f, ok := v.(float64); ok {
+			sum += f
+		} else {
+			allNumbers = false
+		}
+		if best == undefined {
+			best = v
+		} else if c := orderCompare(v, best); (e.name == "MIN" && c < 0) || (e.name == "MAX" && c > 0) {
+			best = v
+		}
+	}
+	switch e.name {
+	case "COUNT":
+		return count
+	case "SUM":
+		if !allNumbers {
+			return undefined
+		}
+		return sum
+	case "AVG":
+		if !allNumbers || count == 0 {
+			return undefined
+		}
+		return sum / count
+	}
+	return best
+}
+
+// containsAggregate returns true if the expression calls an aggregate function.
+func containsAggregate(e expr) bool {
+	switch x := e.(type) {
+	case *exprCall:
+		if aggregateFuncs[x.name] {
+			return true
+		}
+		for _, arg := range x.args {
+			if containsAggregate(arg) {
+				return true
+			}
+		}
+	case *exprMember:
+		return containsAggregate(x.obj) || containsAggregate(x.prop)
+	case *exprUnary:
+		return containsAggregate(x.x)
+	case *exprBinary:
+		return containsAggregate(x.l) || containsAggregate(x.r)
+	case *exprTernary:
+		return containsAggregate(x.cond) || containsAggregate(x.yes) || containsAggregate(x.no)
+	case *exprArray:
+		for _, item := range x.items {
+			if containsAggregate(item) {
+				return true
+			}
+		}
+	case *exprObject:
+		for _, item := range x.items {
+			if containsAggregate(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*----------------------------------------------------------------------*/
+
+// equals compares two values for equality, returns ok=false if they are not comparable (the result is undefined).
+func equals(a, b interface{}) (bool, bool) {
+	if a == undefined || b == undefined {
+		return false, false
+	}
+	switch a.(type) {
+	case nil:
+		return b == nil, true
+	case bool, float64, string:
+		return a == b, true
+	}
+	if _, isArr := a.([]interface{}); isArr {
+		if _, ok := b.([]interface{}); !ok {
+			return false, true
+		}
+	} else if _, ok := b.(map[string]interface{}); !ok {
+		return false, true
+	}
+	js1, _ := json.Marshal(a)
+	js2, _ := json.Marshal(b)
+	return string(js1) == string(js2), true
+}
+
+// compare compares two values of the same primitive type, returns ok=false if they are not comparable.
+func compare(a, b interface{}) (int, bool) {
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmpFloat(x, y), true
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmpBool(x, y), true
+		}
+	}
+	return 0, false
+}
+
+func cmpFloat(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func cmpBool(a, b bool) int {
+	if a == b {
+		return 0
+	}
+	if !a {
+		return -1
+	}
+	return 1
+}
+
+// typeRank returns the rank of the value's type in the ORDER BY ordering: undefined < null < boolean < number < string < array < object.
+func typeRank(v interface{}) int {
+	switch v.(type) {
+	case nil:
+		return 1
+	case bool:
+		return 2
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	case map[string]interface{}:
+		return 6
+	}
+	return 0
+}
+
+// orderCompare compares two values of any type, following the ORDER BY ordering.
+func orderCompare(a, b interface{}) int {
+	ra, rb := typeRank(a), typeRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	if c, ok := compare(a, b); ok {
+		return c
+	}
+	return 0
+}
+
+/*----------------------------------------------------------------------*/
+
+type selectItem struct {
+	expr  expr
+	alias string
+}
+
+type orderItem struct {
+	expr expr
+	desc bool
+}
+
+// sqlQuery is a parsed "SELECT" query.
+type sqlQuery struct {
+	distinct  bool
+	top       int // -1 if not specified
+	star      bool
+	value     expr // the projection of "SELECT VALUE"
+	items     []selectItem
+	alias     string
+	where     expr
+	groupBy   []expr
+	orderBy   []orderItem
+	offset    int // -1 if not specified
+	limit     int // -1 if not specified
+	params    map[string]bool
+	aggregate bool // true if the projection calls aggregate functions
+
+	pendingLimits [3]expr // TOP, OFFSET and LIMIT expressions, resolved when the query is executed
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	query  *sqlQuery
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("syntax error at position %d: %s", p.peek().pos+1, fmt.Sprintf(format, a...))
+}
+
+// isKeyword returns true if the next token is one of the specified keywords.
+func (p *parser) isKeyword(keywords ...string) bool {
+	t := p.peek()
+	if t.kind != tokIdent {
+		return false
+	}
+	for _, kw := range keywords {
+		if strings.EqualFold(t.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.errorf("expected %s", keyword)
+	}
+	return nil
+}
+
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == op
+}
+
+func (p *parser) acceptOp(op string) bool {
+	if p.isOp(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectOp(op string) error {
+	if !p.acceptOp(op) {
+		return p.errorf("expected %q", op)
+	}
+	return nil
+}
+
+var reservedWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "ORDER": true, "GROUP": true, "BY": true, "OFFSET": true, "LIMIT": true,
+	"AND": true, "OR": true, "NOT": true, "IN": true, "BETWEEN": true, "LIKE": true, "AS": true, "ASC": true, "DESC": true,
+	"VALUE": true, "DISTINCT": true, "TOP": true, "JOIN": true, "TRUE": true, "FALSE": true, "NULL": true, "UNDEFINED": true,
+}
+
+// parseQuery parses a Cosmos DB SQL "SELECT" query.
+func parseQuery(query string) (*sqlQuery, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, query: &sqlQuery{top: -1, offset: -1, limit: -1, params: make(map[string]bool)}}
+	if err := p.parseSelect(); err != nil {
+		return nil, err
+	}
+	return p.query, nil
+}
+
+// parseCondition parses the filter predicate of a patch request, e.g. "FROM c WHERE c.status = 'active'".
+func parseCondition(condition string) (*sqlQuery, error) {
+	return parseQuery("SELECT * " + condition)
+}
+
+func (p *parser) parseInt(what string) (int, error) {
+	t := p.next()
+	if t.kind != tokNumber || t.num < 0 || t.num != math.Trunc(t.num) {
+		p.pos--
+		return 0, p.errorf("expected non-negative integer for %s", what)
+	}
+	return int(t.num), nil
+}
+
+func (p *parser) parseIntOrParam(what string) (expr, error) {
+	if t := p.peek(); t.kind == tokParam {
+		p.pos++
+		p.query.params[t.text] = true
+		return &exprParam{name: t.text}, nil
+	}
+	n, err := p.parseInt(what)
+	return &exprLiteral{value: float64(n)}, err
+}
+
+func (p *parser) parseSelect() error {
+	q := p.query
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return err
+	}
+	q.distinct = p.acceptKeyword("DISTINCT")
+	var topExpr expr
+	if p.acceptKeyword("TOP") {
+		var err error
+		if topExpr, err = p.parseIntOrParam("TOP"); err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.acceptOp("*"):
+		q.star = true
+	case p.acceptKeyword("VALUE"):
+		e, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		q.value = e
+	default:
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			item := selectItem{expr: e}
+			if p.acceptKeyword("AS") || (p.peek().kind == tokIdent && !reservedWords[strings.ToUpper(p.peek().text)]) {
+				t := p.next()
+				if t.kind != tokIdent && t.kind != tokString {
+					p.pos--
+					return p.errorf("expected alias")
+				}
+				item.alias = t.text
+			}
+			q.items = append(q.items, item)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	if err := p.expectKeyword("FROM"); err != nil {
+		return err
+	}
+	t := p.next()
+	if t.kind != tokIdent || reservedWords[strings.ToUpper(t.text)] {
+		p.pos--
+		return p.errorf("expected collection name")
+	}
+	q.alias = t.text
+	if p.acceptKeyword("AS") || (p.peek().kind == tokIdent && !reservedWords[strings.ToUpper(p.peek().text)]) {
+		t := p.next()
+		if t.kind != tokIdent {
+			p.pos--
+			return p.errorf("expected alias")
+		}
+		q.alias = t.text
+	}
+	if p.isKeyword("JOIN") {
+		return p.errorf("JOIN is not supported")
+	}
+	if p.acceptKeyword("WHERE") {
+		e, err := p.parseExpr()
+		if err != nil {
+			return err
+		}
+		if containsAggregate(e) {
+			return p.errorf("aggregate functions are not allowed in WHERE clause")
+		}
+		q.where = e
+	}
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			q.groupBy = append(q.groupBy, e)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return err
+			}
+			item := orderItem{expr: e}
+			if p.acceptKeyword("DESC") {
+				item.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			q.orderBy = append(q.orderBy, item)
+			if !p.acceptOp(",") {
+				break
+			}
+		}
+	}
+	var offsetExpr, limitExpr expr
+	if p.acceptKeyword("OFFSET") {
+		var err error
+		if offsetExpr, err = p.parseIntOrParam("OFFSET"); err != nil {
+			return err
+		}
+		if err = p.expectKeyword("LIMIT"); err != nil {
+			return err
+		}
+		if limitExpr, err = p.parseIntOrParam("LIMIT"); err != nil {
+			return err
+		}
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return p.errorf("unexpected %q", p.tokenText(t))
+	}
+
+	// TOP, OFFSET and LIMIT can be parameters, hence they are resolved when the query is executed
+	q.pendingLimits = [3]expr{topExpr, offsetExpr, limitExpr}
+	for _, item := range q.items {
+		q.aggregate = q.aggregate || containsAggregate(item.expr)
+	}
+	q.aggregate = q.aggregate || (q.value != nil && containsAggregate(q.value))
+	if q.star && len(q.groupBy) > 0 {
+		return p.errorf("SELECT * is not allowed with GROUP BY")
+	}
+	for _, item := range q.orderBy {
+		if containsAggregate(item.expr) {
+			return p.errorf("aggregate functions are not allowed in ORDER BY clause")
+		}
+	}
+	return nil
+}
+
+func (p *parser) tokenText(t token) string {
+	switch t.kind {
+	case tokNumber:
+		return strconv.FormatFloat(t.num, 'f', -1, 64)
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return t.text
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	cond, err := p.parseCoalesce()
+	if err != nil || !p.acceptOp("?") {
+		return cond, err
+	}
+	yes, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOp(":"); err != nil {
+		return nil, err
+	}
+	no, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &exprTernary{cond: cond, yes: yes, no: no}, nil
+}
+
+func (p *parser) parseCoalesce() (expr, error) {
+	l, err := p.parseOr()
+	for err == nil && p.acceptOp("??") {
+		var r expr
+		if r, err = p.parseOr(); err == nil {
+			l = &exprBinary{op: "??", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseOr() (expr, error) {
+	l, err := p.parseAnd()
+	for err == nil && p.acceptKeyword("OR") {
+		var r expr
+		if r, err = p.parseAnd(); err == nil {
+			l = &exprBinary{op: "OR", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	l, err := p.parseNot()
+	for err == nil && p.acceptKeyword("AND") {
+		var r expr
+		if r, err = p.parseNot(); err == nil {
+			l = &exprBinary{op: "AND", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &exprUnary{op: "NOT", x: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	l, err := p.parseConcat()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		not := false
+		if p.isKeyword("NOT") {
+			if next := p.tokens[p.pos+1]; next.kind == tokIdent && (strings.EqualFold(next.text, "IN") || strings.EqualFold(next.text, "BETWEEN") || strings.EqualFold(next.text, "LIKE")) {
+				p.pos++
+				not = true
+			}
+		}
+		switch {
+		case p.acceptKeyword("IN"):
+			if err := p.expectOp("("); err != nil {
+				return nil, err
+			}
+			in := &exprIn{x: l, not: not}
+			for {
+				item, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				in.list = append(in.list, item)
+				if !p.acceptOp(",") {
+					break
+				}
+			}
+			if err := p.expectOp(")"); err != nil {
+				return nil, err
+			}
+			l = in
+		case p.acceptKeyword("BETWEEN"):
+			lo, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("AND"); err != nil {
+				return nil, err
+			}
+			hi, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			l = &exprBetween{x: l, lo: lo, hi: hi, not: not}
+		case p.acceptKeyword("LIKE"):
+			pattern, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			l = &exprLike{x: l, pattern: pattern, not: not}
+		default:
+			t := p.peek()
+			if t.kind != tokOp || !(t.text == "=" || t.text == "!=" || t.text == "<>" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">=") {
+				return l, nil
+			}
+			p.pos++
+			r, err := p.parseConcat()
+			if err != nil {
+				return nil, err
+			}
+			l = &exprBinary{op: t.text, l: l, r: r}
+		}
+	}
+}
+
+func (p *parser) parseConcat() (expr, error) {
+	l, err := p.parseAdditive()
+	for err == nil && p.acceptOp("||") {
+		var r expr
+		if r, err = p.parseAdditive(); err == nil {
+			l = &exprBinary{op: "||", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	l, err := p.parseMultiplicative()
+	for err == nil && (p.isOp("+") || p.isOp("-")) {
+		op := p.next().text
+		var r expr
+		if r, err = p.parseMultiplicative(); err == nil {
+			l = &exprBinary{op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	l, err := p.parseUnary()
+	for err == nil && (p.isOp("*") || p.isOp("/") || p.isOp("%")) {
+		op := p.next().text
+		var r expr
+		if r, err = p.parseUnary(); err == nil {
+			l = &exprBinary{op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.isOp("-") || p.isOp("+") {
+		op := p.next().text
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprUnary{op: op, x: x}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (expr, error) {
+	e, err := p.parsePrimary()
+	for err == nil {
+		switch {
+		case p.acceptOp("."):
+			t := p.next()
+			if t.kind != tokIdent {
+				p.pos--
+				return nil, p.errorf("expected property name")
+			}
+			e = &exprMember{obj: e, prop: &exprLiteral{value: t.text}}
+		case p.acceptOp("["):
+			var prop expr
+			if prop, err = p.parseExpr(); err == nil {
+				err = p.expectOp("]")
+			}
+			e = &exprMember{obj: e, prop: prop}
+		default:
+			return e, nil
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &exprLiteral{value: t.num}, nil
+	case tokString:
+		return &exprLiteral{value: t.text}, nil
+	case tokParam:
+		p.query.params[t.text] = true
+		return &exprParam{name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expectOp(")")
+		case "[":
+			arr := &exprArray{}
+			for !p.acceptOp("]") {
+				if len(arr.items) > 0 {
+					if err := p.expectOp(","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				arr.items = append(arr.items, item)
+			}
+			return arr, nil
+		case "{":
+			obj := &exprObject{}
+			for !p.acceptOp("}") {
+				if len(obj.items) > 0 {
+					if err := p.expectOp(","); err != nil {
+						return nil, err
+					}
+				}
+				key := p.next()
+				if key.kind != tokIdent && key.kind != tokString {
+					p.pos--
+					return nil, p.errorf("expected property name")
+				}
+				if err := p.expectOp(":"); err != nil {
+					return nil, err
+				}
+				item, err := p.parseExpr()
+				if err != nil {
+					return nil, err
+				}
+				obj.keys = append(obj.keys, key.text)
+				obj.items = append(obj.items, item)
+			}
+			return obj, nil
+		}
+	case tokIdent:
+		upper := strings.ToUpper(t.text)
+		switch upper {
+		case "TRUE":
+			return &exprLiteral{value: true}, nil
+		case "FALSE":
+			return &exprLiteral{value: false}, nil
+		case "NULL":
+			return &exprLiteral{value: nil}, nil
+		case "UNDEFINED":
+			return &exprLiteral{value: undefined}, nil
+		}
+		if p.isOp("(") {
+			return p.parseCall(t)
+		}
+		if upper == "UDF" && p.isOp(".") {
+			p.pos--
+			return nil, p.errorf("user-defined functions are not supported")
+		}
+		if reservedWords[upper] {
+			p.pos--
+			return nil, p.errorf("unexpected %s", t.text)
+		}
+		return &exprRef{name: t.text}, nil
+	case tokEOF:
+		p.pos--
+		return nil, p.errorf("unexpected end of query")
+	}
+	p.pos--
+	return nil, p.errorf("unexpected %q", p.tokenText(t))
+}
+
+func (p *parser) parseCall(name token) (expr, error) {
+	fname := strings.ToUpper(name.text)
+	arity, ok := builtinFuncs[fname]
+	if !ok {
+		p.pos--
+		return nil, p.errorf("function %s is not supported", name.text)
+	}
+	p.pos++ // "("
+	call := &exprCall{name: fname}
+	for !p.acceptOp(")") {
+		if len(call.args) > 0 {
+			if err := p.expectOp(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	if len(call.args) < arity[0] || len(call.args) > arity[1] {
+		return nil, fmt.Errorf("syntax error at position %d: wrong number of arguments for function %s", name.pos+1, name.text)
+	}
+	if aggregateFuncs[fname] && containsAggregate(call.args[0]) {
+		return nil, fmt.Errorf("syntax error at position %d: nested aggregate functions are not allowed", name.pos+1)
+	}
+	return call, nil
+}
+
+/*----------------------------------------------------------------------*/
+
+// execute runs the query against the documents, in the supplied order.
+func (q *sqlQuery) execute(docs []interface{}, params map[string]interface{}) ([]interface{}, error) {
+	for name := range q.params {
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("parameter %s is not specified", name)
+		}
+	}
+	top, offset, limit := q.top, q.offset, q.limit
+	for i, target := range []*int{&top, &offset, &limit} {
+		if e := q.pendingLimits[i]; e != nil {
+			f, ok := e.eval(&evalCtx{params: params}).(float64)
+			if !ok || f < 0 || f != math.Trunc(f) {
+				return nil, fmt.Errorf("TOP, OFFSET and LIMIT must be non-negative integers")
+			}
+			*target = int(f)
+		}
+	}
+
+	ctx := &evalCtx{alias: q.alias, params: params}
+	rows := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		ctx.doc = doc
+		if q.where == nil || q.where.eval(ctx) == true {
+			rows = append(rows, doc)
+		}
+	}
+
+	var result []interface{}
+	if len(q.groupBy) > 0 || q.aggregate {
+		var groups [][]interface{}
+		if len(q.groupBy) == 0 {
+			groups = [][]interface{}{rows}
+		} else {
+			index := make(map[string]int)
+			for _, doc := range rows {
+				ctx.doc = doc
+				keys := make([]interface{}, len(q.groupBy))
+				for i, e := range q.groupBy {
+					if keys[i] = e.eval(ctx); keys[i] == undefined {
+						keys[i] = map[string]interface{}{"$undefined": true}
+					}
+				}
+				js, _ := json.Marshal(keys)
+				if i, ok := index[string(js)]; ok {
+					groups[i] = append(groups[i], doc)
+				} else {
+					index[string(js)] = len(groups)
+					groups = append(groups, []interface{}{doc})
+				}
+			}
+		}
+		for _, group := range groups {
+			gctx := &evalCtx{alias: q.alias, params: params, group: group, doc: undefined}
+			if len(group) > 0 {
+				gctx.doc = group[0]
+			}
+			if v := q.project(gctx); v != undefined {
+				result = append(result, v)
+			}
+		}
+	} else {
+		if len(q.orderBy) > 0 {
+			keys := make(map[int][]interface{}, len(rows))
+			indexes := make([]int, len(rows))
+			for i, doc := range rows {
+				ctx.doc = doc
+				indexes[i] = i
+				keys[i] = make([]interface{}, len(q.orderBy))
+				for j, item := range q.orderBy {
+					keys[i][j] = item.expr.eval(ctx)
+				}
+			}
+			sort.SliceStable(indexes, func(a, b int) bool {
+				for j, item := range q.orderBy {
+					c := orderCompare(keys[indexes[a]][j], keys[indexes[b]][j])
+					if item.desc {
+						c = -c
+					}
+					if c != 0 {
+						return c < 0
+					}
+				}
+				return false
+			})
+			sorted := make([]interface{}, len(rows))
+			for i, idx := range indexes {
+				sorted[i] = rows[idx]
+			}
+			rows = sorted
+		}
+		for _, doc := range rows {
+			ctx.doc = doc
+			if v := q.project(ctx); v != undefined {
+				result = append(result, v)
+			}
+		}
+	}
+
+	if q.distinct {
+		seen := make(map[string]bool)
+		distinct := result[:0]
+		for _, v := range result {
+			js, _ := json.Marshal(v)
+			if !seen[string(js)] {
+				seen[string(js)] = true
+				distinct = append(distinct, v)
+			}
+		}
+		result = distinct
+	}
+	if offset > 0 {
+		if offset > len(result) {
+			offset = len(result)
+		}
+		result = result[offset:]
+	}
+	if limit >= 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	if top >= 0 && top < len(result) {
+		result = result[:top]
+	}
+	if result == nil {
+		result = []interface{}{}
+	}
+	return result, nil
+}
+
+// project computes the projection of the current document (or group).
+func (q *sqlQuery) project(ctx *evalCtx) interface{} {
+	if q.star {
+		return ctx.doc
+	}
+	if q.value != nil {
+		return q.value.eval(ctx)
+	}
+	result := make(map[string]interface{}, len(q.items))
+	unnamed := 0
+	for _, item := range q.items {
+		name := item.alias
+		if name == "" {
+			switch e := item.expr.(type) {
+			case *exprMember:
+				if lit, ok := e.prop.(*exprLiteral); ok {
+					name, _ = lit.value.(string)
+				}
+			case *exprRef:
+				name = e.name
+			}
+		}
+		if name == "" {
+			unnamed++
+			name = "$" + strconv.Itoa(unnamed)
+		}
+		if v := item.expr.eval(ctx); v != undefined {
+			result[name] = v
+		}
+	}
+	return result
+}
+
+// matches returns true if the document satisfies the WHERE clause of the query.
+func (q *sqlQuery) matches(doc interface{}) bool {
+	return q.where == nil || q.where.eval(&evalCtx{alias: q.alias, doc: doc}) == true
+}
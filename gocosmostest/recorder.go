@@ -0,0 +1,298 @@
+package gocosmostest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrUnexpectedRequest is returned by a replaying Recorder when a request does not match any of the remaining recorded
+// interactions.
+//
+// @Available since v1.2.0
+var ErrUnexpectedRequest = errors.New("unexpected request")
+
+// scrubbedRequestHeaders are not recorded, as they change from run to run (or must not be leaked to fixture files).
+var scrubbedRequestHeaders = map[string]bool{
+	"Authorization":   true,
+	"X-Ms-Date":       true,
+	"Date":            true,
+	"User-Agent":      true,
+	"Content-Length":  true,
+	"Accept-Encoding": true,
+}
+
+// scrubbedResponseHeaders are not recorded, as they change from run to run or are recomputed on replay.
+var scrubbedResponseHeaders = map[string]bool{
+	"Date":           true,
+	"Content-Length": true,
+}
+
+// fixtureBody is a request or response body. A JSON object or array is stored as-is in fixture files so that they are
+// easy to read and edit, other bodies are stored as strings.
+type fixtureBody []byte
+
+// MarshalJSON implements json.Marshaler/MarshalJSON.
+func (b fixtureBody) MarshalJSON() ([]byte, error) {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed) {
+		return trimmed, nil
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler/UnmarshalJSON.
+func (b *fixtureBody) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = fixtureBody(text)
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return err
+	}
+	*b = buf.Bytes()
+	return nil
+}
+
+// equals compares two bodies, semantically if both are JSON.
+func (b fixtureBody) equals(other fixtureBody) bool {
+	var v1, v2 interface{}
+	if json.Unmarshal(b, &v1) == nil && json.Unmarshal(other, &v2) == nil {
+		return reflect.DeepEqual(v1, v2)
+	}
+	return bytes.Equal(b, other)
+}
+
+type recordedRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"` // path and query string, without scheme and host
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    fixtureBody       `json:"body,omitempty"`
+}
+
+func (r recordedRequest) matches(other recordedRequest) bool {
+	return r.Method == other.Method && r.Path == other.Path && reflect.DeepEqual(r.Headers, other.Headers) && r.Body.equals(other.Body)
+}
+
+type recordedResponse struct {
+	StatusCode int               `json:"status"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       fixtureBody       `json:"body,omitempty"`
+}
+
+type interaction struct {
+	Request  recordedRequest  `json:"request"`
+	Response recordedResponse `json:"response"`
+}
+
+type fixture struct {
+	Interactions []*interaction `json:"interactions"`
+}
+
+func recordHeaders(header http.Header, scrubbed map[string]bool) map[string]string {
+	result := make(map[string]string)
+	for k, v := range header {
+		if k = http.CanonicalHeaderKey(k); !scrubbed[k] && len(v) > 0 {
+			result[k] = v[0]
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func recordRequest(req *http.Request) (recordedRequest, error) {
+	result := recordedRequest{Method: req.Method, Path: req.URL.EscapedPath(), Headers: recordHeaders(req.Header, scrubbedRequestHeaders)}
+	if req.URL.RawQuery != "" {
+		result.Path += "?" + req.URL.RawQuery
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return result, err
+		}
+		// the request may still be sent by the underlying transport
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		result.Body = body
+	}
+	return result, nil
+}
+
+// Recorder is an http.RoundTripper that either records request/response pairs into a fixture file (see NewRecorder),
+// or replays them from a fixture file (see NewReplayer). It is meant to be used with gocosmos.NewRestClient, so that
+// code talking to Cosmos DB can be regression-tested offline and deterministically:
+//
+//	rec := gocosmostest.NewRecorder("testdata/myquery.json", nil)
+//	client, err := gocosmos.NewRestClient(rec.HttpClient(), connStr)
+//	... // use the client
+//	err = rec.Save()
+//
+// and later:
+//
+//	rec, err := gocosmostest.NewReplayer("testdata/myquery.json")
+//	client, err := gocosmos.NewRestClient(rec.HttpClient(), "AccountEndpoint=https://localhost/;AccountKey="+gocosmostest.DefaultAccountKey)
+//	... // use the client, the same way as while recording
+//	err = rec.Verify()
+//
+// The Authorization, x-ms-date and a few other volatile request headers are scrubbed before recording, and the scheme
+// and host of URLs are not recorded. Hence, a fixture can be replayed with any endpoint and account key.
+//
+// While replaying, a request is answered with the first not-yet-replayed interaction that has the same method, path,
+// headers (scrubbed ones excepted) and body (compared as JSON if possible). Requests are not required to come in the
+// recorded order, so that concurrent requests (e.g. to several partition key ranges) can be replayed. A request that
+// matches no interaction fails with ErrUnexpectedRequest.
+//
+// @Available since v1.2.0
+type Recorder struct {
+	fixtureFile string
+	transport   http.RoundTripper // nil if replaying
+	lock        sync.Mutex
+	fixture     fixture
+	replayed    []bool
+	unexpected  []string
+}
+
+// NewRecorder creates a Recorder that sends requests with the supplied transport (http.DefaultTransport if nil) and
+// records them, together with their responses. Call Save to write the recorded interactions to fixtureFile.
+//
+// @Available since v1.2.0
+func NewRecorder(fixtureFile string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{fixtureFile: fixtureFile, transport: transport}
+}
+
+// NewReplayer creates a Recorder that replays the interactions loaded from fixtureFile, without sending any request.
+//
+// @Available since v1.2.0
+func NewReplayer(fixtureFile string) (*Recorder, error) {
+	data, err := os.ReadFile(fixtureFile)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{fixtureFile: fixtureFile}
+	if err := json.Unmarshal(data, &r.fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %s", fixtureFile, err)
+	}
+	r.replayed = make([]bool, len(r.fixture.Interactions))
+	return r, nil
+}
+
+// IsReplaying returns true if the recorder replays interactions from a fixture file, false if it records them.
+func (r *Recorder) IsReplaying() bool {
+	return r.transport == nil
+}
+
+// HttpClient returns a http.Client that uses the recorder as its transport, to be passed to gocosmos.NewRestClient.
+func (r *Recorder) HttpClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper/RoundTrip.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recReq, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.IsReplaying() {
+		return r.replay(req, recReq)
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.fixture.Interactions = append(r.fixture.Interactions, &interaction{
+		Request:  recReq,
+		Response: recordedResponse{StatusCode: resp.StatusCode, Headers: recordHeaders(resp.Header, scrubbedResponseHeaders), Body: body},
+	})
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recReq recordedRequest) (*http.Response, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i, item := range r.fixture.Interactions {
+		if r.replayed[i] || !item.Request.matches(recReq) {
+			continue
+		}
+		r.replayed[i] = true
+		resp := &http.Response{
+			Status:        fmt.Sprintf("%d %s", item.Response.StatusCode, http.StatusText(item.Response.StatusCode)),
+			StatusCode:    item.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(bytes.NewReader(item.Response.Body)),
+			ContentLength: int64(len(item.Response.Body)),
+			Request:       req,
+		}
+		for k, v := range item.Response.Headers {
+			resp.Header.Set(k, v)
+		}
+		return resp, nil
+	}
+	r.unexpected = append(r.unexpected, recReq.Method+" "+recReq.Path)
+	return nil, fmt.Errorf("%w: %s %s does not match any recorded interaction of %s", ErrUnexpectedRequest, recReq.Method, recReq.Path, r.fixtureFile)
+}
+
+// Save writes the recorded interactions to the fixture file. It does nothing if the recorder is replaying.
+func (r *Recorder) Save() error {
+	if r.IsReplaying() {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	data, err := json.MarshalIndent(r.fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.fixtureFile, append(data, '\n'), 0644)
+}
+
+// Verify returns an error if, while replaying, some requests did not match any recorded interaction or some recorded
+// interactions have not been replayed. It always returns nil if the recorder is recording.
+func (r *Recorder) Verify() error {
+	if !r.IsReplaying() {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var errs []string
+	if len(r.unexpected) > 0 {
+		errs = append(errs, fmt.Sprintf("%d unexpected request(s): %s", len(r.unexpected), strings.Join(r.unexpected, ", ")))
+	}
+	var unused []string
+	for i, item := range r.fixture.Interactions {
+		if !r.replayed[i] {
+			unused = append(unused, item.Request.Method+" "+item.Request.Path)
+		}
+	}
+	if len(unused) > 0 {
+		errs = append(errs, fmt.Sprintf("%d interaction(s) not replayed: %s", len(unused), strings.Join(unused, ", ")))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("replaying %s: %s", r.fixtureFile, strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+// Package gocosmostest provides an in-memory fake of the Azure Cosmos DB REST API, to test code that uses gocosmos
+// (RestClient or the database/sql driver) without a real Cosmos DB account or emulator.
+//
+// The fake speaks the subset of the REST API used by RestClient: databases, collections, documents (CRUD, upsert,
+// patch and transactional batch, with etags and partition keys), partition key ranges, offers, query plans and queries,
+// and the change feed (incremental and full-fidelity). Requests are authenticated with the master key, exactly as a
+// real server would do.
+//
+// Sample usage:
+//
+//	server := gocosmostest.NewServer()
+//	defer server.Close()
+//	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+//
+// Limitations:
+//   - Each collection has a single partition key range, with id "0".
+//   - Queries are evaluated entirely by the fake, hence the returned query plans never ask the client to post-process
+//     results. JOIN, sub-queries, spatial functions and user-defined functions are not supported.
+//   - Stored procedures, triggers, user-defined functions, users and permissions are not supported.
+//
+// @Available since v1.2.0
+package gocosmostest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAccountKey is the account key accepted by a server created with NewServer. It is the well-known key of the
+// Azure Cosmos DB emulator.
+//
+// @Available since v1.2.0
+const DefaultAccountKey = "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
+
+// Server is an in-memory fake Cosmos DB server, listening on a local address.
+//
+// @Available since v1.2.0
+type Server struct {
+	httpServer *httptest.Server
+	accountKey string
+	key        []byte
+
+	lock     sync.Mutex
+	dbs      map[string]*database
+	offers   []*offer
+	ridSeq   uint32
+	offerSeq int
+	activity uint64
+}
+
+// NewServer starts a new fake server that accepts DefaultAccountKey. The caller should call Close when finished, to
+// shut it down.
+//
+// @Available since v1.2.0
+func NewServer() *Server {
+	server, err := NewServerWithKey(DefaultAccountKey)
+	if err != nil {
+		panic(err)
+	}
+	return server
+}
+
+// NewServerWithKey starts a new fake server that accepts the supplied (base64-encoded) account key. The caller should
+// call Close when finished, to shut it down.
+//
+// @Available since v1.2.0
+func NewServerWithKey(accountKey string) (*Server, error) {
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot base64 decode account key: %s", err)
+	}
+	s := &Server{accountKey: accountKey, key: key, dbs: make(map[string]*database)}
+	s.httpServer = httptest.NewServer(s)
+	return s, nil
+}
+
+// URL returns the endpoint of the server, e.g. "http://127.0.0.1:12345".
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// AccountKey returns the account key accepted by the server.
+func (s *Server) AccountKey() string {
+	return s.accountKey
+}
+
+// ConnectionString returns a connection string to connect to the server, suitable for gocosmos.NewRestClient and
+// sql.Open("gocosmos", ...). If defaultDb is not empty, it is set as the default database of the connection.
+func (s *Server) ConnectionString(defaultDb string) string {
+	connStr := "AccountEndpoint=" + s.URL() + ";AccountKey=" + s.accountKey
+	if defaultDb != "" {
+		connStr += ";DefaultDb=" + defaultDb
+	}
+	return connStr
+}
+
+// Close shuts down the server and blocks until all outstanding requests on this server have completed.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Reset removes all databases (and their collections and documents) and offers from the server.
+func (s *Server) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.dbs = make(map[string]*database)
+	s.offers = nil
+}
+
+/*----------------------------------------------------------------------*/
+
+// apiError is an error response of the REST API.
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func newApiError(status int, format string, a ...interface{}) *apiError {
+	code := http.StatusText(status)
+	code = strings.ReplaceAll(code, " ", "")
+	return &apiError{status: status, code: code, message: fmt.Sprintf(format, a...)}
+}
+
+// newNotFoundError mimics the "not found" message of Cosmos DB, which mentions the resource type (the driver relies on
+// it to tell a missing document from a missing collection).
+func newNotFoundError(resType, id string) *apiError {
+	return newApiError(http.StatusNotFound, "Entity with the specified id does not exist in the system, ResourceType: %s, Id: %s", resType, id)
+}
+
+// response is a successful response of the REST API.
+type response struct {
+	status  int
+	headers map[string]string
+	body    interface{} // marshalled to JSON, no body if nil
+}
+
+// requestCtx holds the parsed request being served.
+type requestCtx struct {
+	r      *http.Request
+	method string
+	path   []string // segments of the resource path
+	body   []byte
+}
+
+func (rc *requestCtx) header(name string) string {
+	return rc.r.Header.Get(name)
+}
+
+func (rc *requestCtx) decodeBody(v interface{}) *apiError {
+	if err := json.Unmarshal(rc.body, v); err != nil {
+		return newApiError(http.StatusBadRequest, "invalid request body: %s", err)
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler/ServeHTTP.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := &requestCtx{r: r, method: r.Method, path: strings.Split(strings.Trim(r.URL.Path, "/"), "/")}
+	if len(rc.path) == 1 && rc.path[0] == "" {
+		rc.path = []string{}
+	}
+	var err error
+	if r.Body != nil {
+		rc.body, err = io.ReadAll(r.Body)
+	}
+
+	var resp *response
+	var apiErr *apiError
+	if err != nil {
+		apiErr = newApiError(http.StatusBadRequest, "cannot read request body: %s", err)
+	} else if apiErr = s.authenticate(rc); apiErr == nil {
+		s.lock.Lock()
+		resp, apiErr = s.route(rc)
+		s.lock.Unlock()
+	}
+
+	s.lock.Lock()
+	s.activity++
+	activityId := fmt.Sprintf("00000000-0000-0000-0000-%012x", s.activity)
+	s.lock.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-ms-activity-id", activityId)
+	w.Header().Set("x-ms-request-charge", "1")
+	if apiErr != nil {
+		js, _ := json.Marshal(map[string]interface{}{"code": apiErr.code, "message": apiErr.message})
+		w.WriteHeader(apiErr.status)
+		_, _ = w.Write(js)
+		return
+	}
+	for k, v := range resp.headers {
+		w.Header().Set(k, v)
+	}
+	if resp.body == nil || resp.status == http.StatusNoContent || resp.status == http.StatusNotModified {
+		w.WriteHeader(resp.status)
+		return
+	}
+	js, _ := json.Marshal(resp.body)
+	w.WriteHeader(resp.status)
+	_, _ = w.Write(js)
+}
+
+// authenticate validates the "type=master" authorization header of the request.
+func (s *Server) authenticate(rc *requestCtx) *apiError {
+	auth, err := url.QueryUnescape(rc.header("Authorization"))
+	if err != nil || auth == "" {
+		return newApiError(http.StatusUnauthorized, "required Authorization header is missing or invalid")
+	}
+	values := make(map[string]string)
+	for _, kv := range strings.Split(auth, "&") {
+		// the signature is base64-encoded, it must not be decoded as a query string
+		if tokens := strings.SplitN(kv, "=", 2); len(tokens) == 2 {
+			values[tokens[0]] = tokens[1]
+		}
+	}
+	if values["type"] != "master" || values["ver"] != "1.0" || values["sig"] == "" {
+		return newApiError(http.StatusUnauthorized, "invalid Authorization header, only master key authorization is supported")
+	}
+	date := rc.header("x-ms-date")
+	if t, err := time.Parse(time.RFC1123, date); err != nil {
+		return newApiError(http.StatusUnauthorized, "required x-ms-date header is missing or invalid")
+	} else if d := time.Since(t); d > 15*time.Minute || d < -15*time.Minute {
+		return newApiError(http.StatusForbidden, "the x-ms-date header is too far from the current time")
+	}
+	resType, resId := resourceOf(rc.path)
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", strings.ToLower(rc.method), strings.ToLower(resType), resId, strings.ToLower(date), "")
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(stringToSign))
+	if expected := base64.StdEncoding.EncodeToString(h.Sum(nil)); !hmac.Equal([]byte(expected), []byte(values["sig"])) {
+		return newApiError(http.StatusUnauthorized, "the input authorization token can't serve the request, the expected payload is '%s'", strings.ReplaceAll(stringToSign, "\n", "\\n"))
+	}
+	return nil
+}
+
+// resourceOf returns the resource type and resource id (link) of a resource path, as used to sign requests.
+func resourceOf(path []string) (string, string) {
+	switch n := len(path); {
+	case n == 0:
+		return "", ""
+	case n%2 == 1:
+		return path[n-1], strings.Join(path[:n-1], "/")
+	default:
+		if path[n-2] == "offers" {
+			// offers are signed with their lower-cased rid only
+			return path[n-2], strings.ToLower(path[n-1])
+		}
+		return path[n-2], strings.Join(path, "/")
+	}
+}
+
+func (s *Server) route(rc *requestCtx) (*response, *apiError) {
+	path := rc.path
+	switch {
+	case len(path) >= 1 && path[0] == "offers":
+		return s.routeOffers(rc)
+	case len(path) == 0 || path[0] != "dbs":
+	case len(path) == 1:
+		switch rc.method {
+		case http.MethodGet:
+			return s.listDatabases()
+		case http.MethodPost:
+			return s.createDatabase(rc)
+		}
+	case len(path) == 2:
+		switch rc.method {
+		case http.MethodGet:
+			return s.getDatabase(path[1])
+		case http.MethodDelete:
+			return s.deleteDatabase(path[1])
+		}
+	case len(path) >= 3 && path[2] == "colls":
+		db, ok := s.dbs[path[1]]
+		if !ok {
+			return nil, newNotFoundError("Database", path[1])
+		}
+		return s.routeCollections(rc, db)
+	}
+	return nil, newApiError(http.StatusNotImplemented, "%s /%s is not supported by gocosmostest", rc.method, strings.Join(path, "/"))
+}
+
+func (s *Server) routeCollections(rc *requestCtx, db *database) (*response, *apiError) {
+	path := rc.path
+	if len(path) == 3 {
+		switch rc.method {
+		case http.MethodGet:
+			return db.listCollections()
+		case http.MethodPost:
+			return s.createCollection(rc, db)
+		}
+	} else if len(path) == 4 {
+		switch rc.method {
+		case http.MethodGet:
+			return db.getCollection(path[3])
+		case http.MethodPut:
+			return s.replaceCollection(rc, db, path[3])
+		case http.MethodDelete:
+			return s.deleteCollection(db, path[3])
+		}
+	} else {
+		coll, ok := db.colls[path[3]]
+		if !ok {
+			return nil, newNotFoundError("Collection", path[3])
+		}
+		switch {
+		case len(path) == 5 && path[4] == "pkranges" && rc.method == http.MethodGet:
+			return coll.getPkranges()
+		case len(path) == 5 && path[4] == "docs":
+			switch rc.method {
+			case http.MethodGet:
+				if rc.header("A-IM") != "" {
+					return coll.changeFeed(rc)
+				}
+				return coll.listDocuments(rc)
+			case http.MethodPost:
+				switch {
+				case strings.EqualFold(rc.header("x-ms-cosmos-is-batch-request"), "true"):
+					return coll.executeBatch(rc)
+				case strings.EqualFold(rc.header("x-ms-cosmos-is-query-plan-request"), "true"):
+					return coll.queryPlan(rc)
+				case strings.EqualFold(rc.header("x-ms-documentdb-isquery"), "true") || strings.HasPrefix(rc.header("Content-Type"), "application/query+json"):
+					return coll.queryDocuments(rc)
+				}
+				return coll.createDocument(rc)
+			}
+		case len(path) == 6 && path[4] == "docs":
+			switch rc.method {
+			case http.MethodGet:
+				return coll.getDocument(rc, path[5])
+			case http.MethodPut:
+				return coll.replaceDocument(rc, path[5])
+			case http.MethodPatch:
+				return coll.patchDocument(rc, path[5])
+			case http.MethodDelete:
+				return coll.deleteDocument(rc, path[5])
+			}
+		}
+	}
+	return nil, newApiError(http.StatusNotImplemented, "%s /%s is not supported by gocosmostest", rc.method, strings.Join(path, "/"))
+}
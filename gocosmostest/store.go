@@ -0,0 +1,1310 @@
+package gocosmostest
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var etagSeq uint64
+
+// newEtag generates a new unique etag.
+func newEtag() string {
+	return fmt.Sprintf(`"%08x-0000-0000-0000-%012x"`, time.Now().Unix(), atomic.AddUint64(&etagSeq, 1))
+}
+
+// encodeRid encodes a resource id the way Cosmos DB does (base64 with '-' instead of '/').
+func encodeRid(ridBytes []byte) string {
+	return strings.ReplaceAll(base64.StdEncoding.EncodeToString(ridBytes), "/", "-")
+}
+
+// deepCopy returns a deep copy of a JSON document.
+func deepCopy(doc map[string]interface{}) map[string]interface{} {
+	js, _ := json.Marshal(doc)
+	var result map[string]interface{}
+	_ = json.Unmarshal(js, &result)
+	return result
+}
+
+// toJsonValue converts a Go value to its JSON representation (map[string]interface{}, []interface{}, float64, etc).
+func toJsonValue(v interface{}) interface{} {
+	js, _ := json.Marshal(v)
+	var result interface{}
+	_ = json.Unmarshal(js, &result)
+	return result
+}
+
+// pageSize returns the page size requested via the "x-ms-max-item-count" header, 100 by default; -1 means no limit.
+func pageSize(rc *requestCtx) int {
+	if n, err := strconv.Atoi(rc.header("x-ms-max-item-count")); err == nil && n != 0 {
+		return n
+	}
+	return 100
+}
+
+// page returns the page of items that starts at the position specified by the "x-ms-continuation" header, and the
+// continuation token of the next page (empty if there is no more page).
+func page(rc *requestCtx, items []interface{}) ([]interface{}, string, *apiError) {
+	start := 0
+	if token := rc.header("x-ms-continuation"); token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 0 {
+			return nil, "", newApiError(http.StatusBadRequest, "invalid continuation token %q", token)
+		}
+		start = n
+	}
+	if start > len(items) {
+		start = len(items)
+	}
+	end := len(items)
+	if size := pageSize(rc); size > 0 && start+size < end {
+		end = start + size
+	}
+	if end < len(items) {
+		return items[start:end], strconv.Itoa(end), nil
+	}
+	return items[start:end], "", nil
+}
+
+/*----------------------------------------------------------------------*/
+
+type database struct {
+	id, rid, etag string
+	ridBytes      []byte
+	seq           uint32
+	ts            int64
+	colls         map[string]*collection
+	collSeq       uint32
+}
+
+func (db *database) info() map[string]interface{} {
+	return map[string]interface{}{
+		"id": db.id, "_rid": db.rid, "_ts": db.ts, "_self": "dbs/" + db.rid + "/", "_etag": db.etag,
+		"_colls": "colls/", "_users": "users/",
+	}
+}
+
+func (s *Server) listDatabases() (*response, *apiError) {
+	dbs := make([]*database, 0, len(s.dbs))
+	for _, db := range s.dbs {
+		dbs = append(dbs, db)
+	}
+	sort.Slice(dbs, func(i, j int) bool { return dbs[i].seq < dbs[j].seq })
+	list := make([]interface{}, len(dbs))
+	for i, db := range dbs {
+		list[i] = db.info()
+	}
+	return &response{status: http.StatusOK, body: map[string]interface{}{"_rid": "", "Databases": list, "_count": len(list)}}, nil
+}
+
+func (s *Server) createDatabase(rc *requestCtx) (*response, *apiError) {
+	var body struct {
+		Id string `json:"id"`
+	}
+	if err := rc.decodeBody(&body); err != nil {
+		return nil, err
+	}
+	if err := validateId(body.Id); err != nil {
+		return nil, err
+	}
+	if _, ok := s.dbs[body.Id]; ok {
+		return nil, newApiError(http.StatusConflict, "database %q already exists", body.Id)
+	}
+	s.ridSeq++
+	db := &database{id: body.Id, seq: s.ridSeq, ts: time.Now().Unix(), etag: newEtag(), colls: make(map[string]*collection)}
+	db.ridBytes = make([]byte, 4)
+	binary.BigEndian.PutUint32(db.ridBytes, s.ridSeq)
+	db.rid = encodeRid(db.ridBytes)
+	if err := s.createOffer(rc, "dbs/"+db.rid+"/", db.rid); err != nil {
+		return nil, err
+	}
+	s.dbs[db.id] = db
+	return &response{status: http.StatusCreated, body: db.info()}, nil
+}
+
+func (s *Server) getDatabase(dbName string) (*response, *apiError) {
+	db, ok := s.dbs[dbName]
+	if !ok {
+		return nil, newNotFoundError("Database", dbName)
+	}
+	return &response{status: http.StatusOK, body: db.info()}, nil
+}
+
+func (s *Server) deleteDatabase(dbName string) (*response, *apiError) {
+	db, ok := s.dbs[dbName]
+	if !ok {
+		return nil, newNotFoundError("Database", dbName)
+	}
+	for _, coll := range db.colls {
+		s.deleteOffer(coll.rid)
+	}
+	s.deleteOffer(db.rid)
+	delete(s.dbs, dbName)
+	return &response{status: http.StatusNoContent}, nil
+}
+
+// validateId validates the id of a resource.
+func validateId(id string) *apiError {
+	if strings.TrimSpace(id) == "" {
+		return newApiError(http.StatusBadRequest, "the id of the resource is missing")
+	}
+	if strings.ContainsAny(id, `/\?#`) {
+		return newApiError(http.StatusBadRequest, "the id of the resource contains invalid character(s): %q", id)
+	}
+	return nil
+}
+
+/*----------------------------------------------------------------------*/
+
+type collection struct {
+	db              *database
+	id, rid, etag   string
+	ridBytes        []byte
+	seq             uint32
+	ts              int64
+	partitionKey    map[string]interface{}
+	pkPaths         []string
+	indexingPolicy  map[string]interface{}
+	uniqueKeyPolicy map[string]interface{}
+
+	docs   map[string]*document // indexed by documentKey
+	docSeq uint64
+	lsn    int64          // logical sequence number of the last change
+	log    []changeRecord // all changes, used by the full-fidelity change feed
+}
+
+// document is an immutable version of a document, changes are made by replacing it with a new version.
+type document struct {
+	id, pk string // pk is the JSON-encoded partition key values
+	seq    uint64
+	lsn    int64
+	data   map[string]interface{}
+}
+
+type changeRecord struct {
+	lsn, prevLsn      int64
+	op                string
+	ts                int64
+	current, previous map[string]interface{}
+}
+
+func (c *collection) self() string {
+	return "dbs/" + c.db.rid + "/colls/" + c.rid + "/"
+}
+
+func (c *collection) info() map[string]interface{} {
+	result := map[string]interface{}{
+		"id": c.id, "_rid": c.rid, "_ts": c.ts, "_self": c.self(), "_etag": c.etag,
+		"_docs": "docs/", "_sprocs": "sprocs/", "_triggers": "triggers/", "_udfs": "udfs/", "_conflicts": "conflicts/",
+		"indexingPolicy":           c.indexingPolicy,
+		"conflictResolutionPolicy": map[string]interface{}{"mode": "LastWriterWins", "conflictResolutionPath": "/_ts", "conflictResolutionProcedure": ""},
+		"geospatialConfig":         map[string]interface{}{"type": "Geography"},
+	}
+	if c.partitionKey != nil {
+		result["partitionKey"] = c.partitionKey
+	}
+	if c.uniqueKeyPolicy != nil {
+		result["uniqueKeyPolicy"] = c.uniqueKeyPolicy
+	}
+	return result
+}
+
+func (db *database) listCollections() (*response, *apiError) {
+	colls := make([]*collection, 0, len(db.colls))
+	for _, coll := range db.colls {
+		colls = append(colls, coll)
+	}
+	sort.Slice(colls, func(i, j int) bool { return colls[i].seq < colls[j].seq })
+	list := make([]interface{}, len(colls))
+	for i, coll := range colls {
+		list[i] = coll.info()
+	}
+	return &response{status: http.StatusOK, body: map[string]interface{}{"_rid": db.rid, "DocumentCollections": list, "_count": len(list)}}, nil
+}
+
+func (db *database) getCollection(collName string) (*response, *apiError) {
+	coll, ok := db.colls[collName]
+	if !ok {
+		return nil, newNotFoundError("Collection", collName)
+	}
+	return &response{status: http.StatusOK, body: coll.info()}, nil
+}
+
+type collectionBody struct {
+	Id              string                 `json:"id"`
+	PartitionKey    map[string]interface{} `json:"partitionKey"`
+	IndexingPolicy  map[string]interface{} `json:"indexingPolicy"`
+	UniqueKeyPolicy map[string]interface{} `json:"uniqueKeyPolicy"`
+}
+
+// pkPathsOf returns the paths of a partition key definition.
+func pkPathsOf(pk map[string]interface{}) ([]string, *apiError) {
+	if pk == nil {
+		return nil, nil
+	}
+	paths, _ := pk["paths"].([]interface{})
+	result := make([]string, 0, len(paths))
+	for _, p := range paths {
+		path, ok := p.(string)
+		if !ok || !strings.HasPrefix(path, "/") {
+			return nil, newApiError(http.StatusBadRequest, "invalid partition key path %#v", p)
+		}
+		result = append(result, path)
+	}
+	if len(result) == 0 {
+		return nil, newApiError(http.StatusBadRequest, "partition key paths are missing")
+	}
+	return result, nil
+}
+
+func (s *Server) createCollection(rc *requestCtx, db *database) (*response, *apiError) {
+	var body collectionBody
+	if err := rc.decodeBody(&body); err != nil {
+		return nil, err
+	}
+	if err := validateId(body.Id); err != nil {
+		return nil, err
+	}
+	if _, ok := db.colls[body.Id]; ok {
+		return nil, newApiError(http.StatusConflict, "collection %q already exists", body.Id)
+	}
+	pkPaths, err := pkPathsOf(body.PartitionKey)
+	if err != nil {
+		return nil, err
+	}
+	db.collSeq++
+	coll := &collection{
+		db: db, id: body.Id, seq: db.collSeq, ts: time.Now().Unix(), etag: newEtag(),
+		partitionKey: body.PartitionKey, pkPaths: pkPaths, indexingPolicy: body.IndexingPolicy, uniqueKeyPolicy: body.UniqueKeyPolicy,
+		docs: make(map[string]*document),
+	}
+	if coll.indexingPolicy == nil {
+		coll.indexingPolicy = map[string]interface{}{
+			"indexingMode": "consistent", "automatic": true,
+			"includedPaths": []interface{}{map[string]interface{}{"path": "/*"}},
+			"excludedPaths": []interface{}{map[string]interface{}{"path": `/"_etag"/?`}},
+		}
+	}
+	coll.ridBytes = make([]byte, 8)
+	copy(coll.ridBytes, db.ridBytes)
+	binary.BigEndian.PutUint32(coll.ridBytes[4:], db.collSeq)
+	coll.rid = encodeRid(coll.ridBytes)
+	if err := s.createOffer(rc, coll.self(), coll.rid); err != nil {
+		return nil, err
+	}
+	db.colls[coll.id] = coll
+	return &response{status: http.StatusCreated, body: coll.info()}, nil
+}
+
+func (s *Server) replaceCollection(rc *requestCtx, db *database, collName string) (*response, *apiError) {
+	coll, ok := db.colls[collName]
+	if !ok {
+		return nil, newNotFoundError("Collection", collName)
+	}
+	var body collectionBody
+	if err := rc.decodeBody(&body); err != nil {
+		return nil, err
+	}
+	if body.PartitionKey != nil {
+		pkPaths, err := pkPathsOf(body.PartitionKey)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Join(pkPaths, ",") != strings.Join(coll.pkPaths, ",") {
+			return nil, newApiError(http.StatusBadRequest, "partition key of a collection cannot be changed")
+		}
+	}
+	if body.IndexingPolicy != nil {
+		coll.indexingPolicy = body.IndexingPolicy
+	}
+	if rc.header("x-ms-offer-throughput") != "" || rc.header("x-ms-cosmos-offer-autopilot-settings") != "" {
+		s.deleteOffer(coll.rid)
+		if err := s.createOffer(rc, coll.self(), coll.rid); err != nil {
+			return nil, err
+		}
+	}
+	coll.ts, coll.etag = time.Now().Unix(), newEtag()
+	return &response{status: http.StatusOK, body: coll.info()}, nil
+}
+
+func (s *Server) deleteCollection(db *database, collName string) (*response, *apiError) {
+	coll, ok := db.colls[collName]
+	if !ok {
+		return nil, newNotFoundError("Collection", collName)
+	}
+	s.deleteOffer(coll.rid)
+	delete(db.colls, collName)
+	return &response{status: http.StatusNoContent}, nil
+}
+
+func (c *collection) getPkranges() (*response, *apiError) {
+	pkrange := map[string]interface{}{
+		"id": "0", "_rid": c.rid, "_self": c.self() + "pkranges/0/", "_etag": c.etag, "_ts": c.ts,
+		"minInclusive": "", "maxExclusive": "FF", "ridPrefix": 0, "throughputFraction": 1, "status": "online",
+		"parents": []interface{}{},
+	}
+	return &response{status: http.StatusOK, body: map[string]interface{}{"_rid": c.rid, "PartitionKeyRanges": []interface{}{pkrange}, "_count": 1}}, nil
+}
+
+/*----------------------------------------------------------------------*/
+
+// valueAtPath returns the value at a path such as "/address/city", undefined if it does not exist.
+func valueAtPath(doc map[string]interface{}, path string) interface{} {
+	var v interface{} = doc
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return undefined
+		}
+		if v, ok = m[name]; !ok {
+			return undefined
+		}
+	}
+	return v
+}
+
+// pkOf returns the JSON-encoded partition key values of a document.
+func (c *collection) pkOf(doc map[string]interface{}) string {
+	values := make([]interface{}, len(c.pkPaths))
+	for i, path := range c.pkPaths {
+		if values[i] = valueAtPath(doc, path); values[i] == undefined {
+			// missing partition key value is represented as an empty object
+			values[i] = map[string]interface{}{}
+		}
+	}
+	js, _ := json.Marshal(values)
+	return string(js)
+}
+
+// pkFromHeader returns the JSON-encoded partition key values specified by the "x-ms-documentdb-partitionkey" header,
+// empty string if the header is not specified.
+func (c *collection) pkFromHeader(rc *requestCtx) (string, *apiError) {
+	header := rc.header("x-ms-documentdb-partitionkey")
+	if header == "" || header == "null" {
+		return "", nil
+	}
+	var values []interface{}
+	if err := json.Unmarshal([]byte(header), &values); err != nil {
+		return "", newApiError(http.StatusBadRequest, "invalid partition key header %q", header)
+	}
+	if len(values) != len(c.pkPaths) {
+		return "", newApiError(http.StatusBadRequest, "partition key %s does not match the definition of the collection", header)
+	}
+	js, _ := json.Marshal(values)
+	return string(js), nil
+}
+
+// requiredPk returns the partition key values specified by the request, which is required by point operations.
+func (c *collection) requiredPk(rc *requestCtx) (string, *apiError) {
+	pk, err := c.pkFromHeader(rc)
+	if err == nil && pk == "" {
+		if len(c.pkPaths) > 0 {
+			return "", newApiError(http.StatusBadRequest, "partition key must be specified for this operation")
+		}
+		pk = "[]"
+	}
+	return pk, err
+}
+
+func documentKey(pk, id string) string {
+	return pk + "\x00" + id
+}
+
+// sortedDocs returns the documents of the collection, in the order they were created. If pk is not empty, only
+// documents of the partition are returned.
+func (c *collection) sortedDocs(pk string) []*document {
+	docs := make([]*document, 0, len(c.docs))
+	for _, doc := range c.docs {
+		if pk == "" || doc.pk == pk {
+			docs = append(docs, doc)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].seq < docs[j].seq })
+	return docs
+}
+
+func (c *collection) docResponse(status int, doc *document) *response {
+	headers := map[string]string{"etag": doc.data["_etag"].(string), "x-ms-session-token": fmt.Sprintf("0:-1#%d", c.lsn)}
+	return &response{status: status, headers: headers, body: doc.data}
+}
+
+// checkUniqueKeys verifies that the document does not violate the unique key policy of the collection.
+func (c *collection) checkUniqueKeys(pk, id string, data map[string]interface{}) *apiError {
+	uniqueKeys, _ := c.uniqueKeyPolicy["uniqueKeys"].([]interface{})
+	for _, uk := range uniqueKeys {
+		ukMap, _ := uk.(map[string]interface{})
+		paths, _ := ukMap["paths"].([]interface{})
+		if len(paths) == 0 {
+			continue
+		}
+		keyOf := func(d map[string]interface{}) string {
+			values := make([]interface{}, len(paths))
+			for i, p := range paths {
+				path, _ := p.(string)
+				if values[i] = valueAtPath(d, path); values[i] == undefined {
+					values[i] = nil
+				}
+			}
+			js, _ := json.Marshal(values)
+			return string(js)
+		}
+		key := keyOf(data)
+		for _, doc := range c.docs {
+			if doc.pk == pk && doc.id != id && keyOf(doc.data) == key {
+				return newApiError(http.StatusConflict, "unique index constraint violation on %v", paths)
+			}
+		}
+	}
+	return nil
+}
+
+// write stores a new version of a document, previous is the current version (nil if the document is being created).
+func (c *collection) write(pk, id string, data map[string]interface{}, previous *document) (*document, *apiError) {
+	if err := c.checkUniqueKeys(pk, id, data); err != nil {
+		return nil, err
+	}
+	c.lsn++
+	doc := &document{id: id, pk: pk, lsn: c.lsn, data: data}
+	if previous != nil {
+		doc.seq = previous.seq
+		data["_rid"] = previous.data["_rid"]
+	} else {
+		c.docSeq++
+		doc.seq = c.docSeq
+		ridBytes := make([]byte, 16)
+		copy(ridBytes, c.ridBytes)
+		binary.BigEndian.PutUint64(ridBytes[8:], c.docSeq)
+		data["_rid"] = encodeRid(ridBytes)
+	}
+	now := time.Now().Unix()
+	data["_self"] = c.self() + "docs/" + data["_rid"].(string) + "/"
+	data["_etag"] = newEtag()
+	data["_attachments"] = "attachments/"
+	data["_ts"] = float64(now) // numbers of JSON documents are float64, as queries expect
+	delete(data, "_lsn")
+	c.docs[documentKey(pk, id)] = doc
+
+	record := changeRecord{lsn: c.lsn, op: "create", ts: now, current: data}
+	if previous != nil {
+		record.op, record.previous, record.prevLsn = "replace", previous.data, previous.lsn
+	}
+	c.log = append(c.log, record)
+	return doc, nil
+}
+
+// remove deletes a document.
+func (c *collection) remove(doc *document) {
+	c.lsn++
+	delete(c.docs, documentKey(doc.pk, doc.id))
+	c.log = append(c.log, changeRecord{lsn: c.lsn, op: "delete", ts: time.Now().Unix(), previous: doc.data, prevLsn: doc.lsn})
+}
+
+// find returns the document with the specified partition key and id.
+func (c *collection) find(pk, id string) (*document, *apiError) {
+	doc, ok := c.docs[documentKey(pk, id)]
+	if !ok {
+		return nil, newNotFoundError("Document", id)
+	}
+	return doc, nil
+}
+
+func checkIfMatch(doc *document, ifMatch string) *apiError {
+	if ifMatch != "" && ifMatch != "*" && ifMatch != doc.data["_etag"] {
+		return newApiError(http.StatusPreconditionFailed, "the etag of document %q does not match", doc.id)
+	}
+	return nil
+}
+
+/*----------------------------------------------------------------------*/
+
+// Document operations, shared by single requests and batches. pk is the partition key specified by the request.
+
+func (c *collection) opCreate(pk string, data map[string]interface{}, upsert bool) (*document, int, *apiError) {
+	id, _ := data["id"].(string)
+	if err := validateId(id); err != nil {
+		return nil, 0, err
+	}
+	docPk := c.pkOf(data)
+	if pk != "" && pk != docPk {
+		return nil, 0, newApiError(http.StatusBadRequest, "partition key %s extracted from document does not match the one specified in the request %s", docPk, pk)
+	}
+	existing := c.docs[documentKey(docPk, id)]
+	if existing != nil && !upsert {
+		return nil, 0, newApiError(http.StatusConflict, "document %q already exists", id)
+	}
+	doc, err := c.write(docPk, id, data, existing)
+	if err != nil {
+		return nil, 0, err
+	}
+	if existing != nil {
+		return doc, http.StatusOK, nil
+	}
+	return doc, http.StatusCreated, nil
+}
+
+func (c *collection) opReplace(pk, id string, data map[string]interface{}, ifMatch string) (*document, *apiError) {
+	if bodyId, _ := data["id"].(string); bodyId != id {
+		return nil, newApiError(http.StatusBadRequest, "the id of the document %q does not match the requested one %q", bodyId, id)
+	}
+	if docPk := c.pkOf(data); docPk != pk {
+		return nil, newApiError(http.StatusBadRequest, "partition key %s extracted from document does not match the one specified in the request %s", docPk, pk)
+	}
+	existing, err := c.find(pk, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(existing, ifMatch); err != nil {
+		return nil, err
+	}
+	return c.write(pk, id, data, existing)
+}
+
+func (c *collection) opDelete(pk, id, ifMatch string) *apiError {
+	existing, err := c.find(pk, id)
+	if err != nil {
+		return err
+	}
+	if err := checkIfMatch(existing, ifMatch); err != nil {
+		return err
+	}
+	c.remove(existing)
+	return nil
+}
+
+type patchBody struct {
+	Operations []map[string]interface{} `json:"operations"`
+	Condition  string                   `json:"condition"`
+}
+
+func (c *collection) opPatch(pk, id string, body patchBody, ifMatch string) (*document, *apiError) {
+	existing, err := c.find(pk, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIfMatch(existing, ifMatch); err != nil {
+		return nil, err
+	}
+	if len(body.Operations) == 0 || len(body.Operations) > 10 {
+		return nil, newApiError(http.StatusBadRequest, "number of patch operations must be between 1 and 10, got %d", len(body.Operations))
+	}
+	if body.Condition != "" {
+		cond, err := parseCondition(body.Condition)
+		if err != nil {
+			return nil, newApiError(http.StatusBadRequest, "invalid patch condition: %s", err)
+		}
+		if !cond.matches(existing.data) {
+			return nil, newApiError(http.StatusPreconditionFailed, "document %q does not match the patch condition", id)
+		}
+	}
+	data := deepCopy(existing.data)
+	for _, op := range body.Operations {
+		if err := applyPatchOp(data, op); err != nil {
+			return nil, err
+		}
+	}
+	if newId, _ := data["id"].(string); newId != id {
+		return nil, newApiError(http.StatusBadRequest, "the id of a document cannot be patched")
+	}
+	if c.pkOf(data) != pk {
+		return nil, newApiError(http.StatusBadRequest, "the partition key of a document cannot be patched")
+	}
+	return c.write(pk, id, data, existing)
+}
+
+// applyPatchOp applies a patch operation to a document.
+func applyPatchOp(doc map[string]interface{}, op map[string]interface{}) *apiError {
+	opType, _ := op["op"].(string)
+	path, _ := op["path"].(string)
+	value := op["value"]
+	if opType == "move" {
+		from, _ := op["from"].(string)
+		v, err := patchPath(doc, from, "remove", nil)
+		if err != nil {
+			return err
+		}
+		opType, value = "add", v
+	}
+	_, err := patchPath(doc, path, opType, value)
+	return err
+}
+
+// patchPath applies an operation to the value at the path, returns the removed value for "remove" operations.
+func patchPath(doc map[string]interface{}, path, opType string, value interface{}) (interface{}, *apiError) {
+	if !strings.HasPrefix(path, "/") || path == "/" {
+		return nil, newApiError(http.StatusBadRequest, "invalid patch path %q", path)
+	}
+	names := strings.Split(path[1:], "/")
+	for i, name := range names {
+		names[i] = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+	}
+	// navigate to the parent of the target
+	var parent interface{} = doc
+	var setParent func(v interface{})
+	for _, name := range names[:len(names)-1] {
+		var child interface{}
+		var ok bool
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			child, ok = p[name]
+			name := name
+			setParent = func(v interface{}) { p[name] = v }
+		case []interface{}:
+			idx, err := strconv.Atoi(name)
+			if ok = err == nil && idx >= 0 && idx < len(p); ok {
+				child = p[idx]
+				setParent = func(v interface{}) { p[idx] = v }
+			}
+		}
+		if !ok {
+			return nil, newApiError(http.StatusBadRequest, "the parent of patch path %q does not exist", path)
+		}
+		parent = child
+	}
+	name := names[len(names)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		current, exists := p[name]
+		switch opType {
+		case "add", "set":
+			p[name] = value
+		case "replace":
+			if !exists {
+				return nil, newApiError(http.StatusBadRequest, "patch path %q does not exist", path)
+			}
+			p[name] = value
+		case "remove":
+			if !exists {
+				return nil, newApiError(http.StatusBadRequest, "patch path %q does not exist", path)
+			}
+			delete(p, name)
+			return current, nil
+		case "incr":
+			delta, ok := value.(float64)
+			if !ok {
+				return nil, newApiError(http.StatusBadRequest, "value of incr operation must be a number")
+			}
+			if !exists {
+				p[name] = delta
+			} else if f, ok := current.(float64); ok {
+				p[name] = f + delta
+			} else {
+				return nil, newApiError(http.StatusBadRequest, "patch path %q is not a number", path)
+			}
+		default:
+			return nil, newApiError(http.StatusBadRequest, "invalid patch operation %q", opType)
+		}
+	case []interface{}:
+		idx, err := strconv.Atoi(name)
+		if name == "-" && (opType == "add" || opType == "set") {
+			idx, err = len(p), nil
+		}
+		if err != nil || idx < 0 || idx > len(p) || (idx == len(p) && opType != "add" && opType != "set") {
+			return nil, newApiError(http.StatusBadRequest, "invalid array index in patch path %q", path)
+		}
+		switch opType {
+		case "add":
+			p = append(p[:idx], append([]interface{}{value}, p[idx:]...)...)
+		case "set", "replace":
+			if idx == len(p) {
+				p = append(p, value)
+			} else {
+				p[idx] = value
+			}
+		case "remove":
+			removed := p[idx]
+			p = append(p[:idx], p[idx+1:]...)
+			setParent(p)
+			return removed, nil
+		case "incr":
+			f, ok1 := p[idx].(float64)
+			delta, ok2 := value.(float64)
+			if !ok1 || !ok2 {
+				return nil, newApiError(http.StatusBadRequest, "patch path %q is not a number", path)
+			}
+			p[idx] = f + delta
+		default:
+			return nil, newApiError(http.StatusBadRequest, "invalid patch operation %q", opType)
+		}
+		setParent(p)
+	default:
+		return nil, newApiError(http.StatusBadRequest, "the parent of patch path %q is not an object or array", path)
+	}
+	return nil, nil
+}
+
+/*----------------------------------------------------------------------*/
+
+func (c *collection) createDocument(rc *requestCtx) (*response, *apiError) {
+	var data map[string]interface{}
+	if err := rc.decodeBody(&data); err != nil {
+		return nil, err
+	}
+	pk, err := c.pkFromHeader(rc)
+	if err != nil {
+		return nil, err
+	}
+	doc, status, err := c.opCreate(pk, data, strings.EqualFold(rc.header("x-ms-documentdb-is-upsert"), "true"))
+	if err != nil {
+		return nil, err
+	}
+	return c.docResponse(status, doc), nil
+}
+
+func (c *collection) getDocument(rc *requestCtx, id string) (*response, *apiError) {
+	pk, err := c.requiredPk(rc)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.find(pk, id)
+	if err != nil {
+		return nil, err
+	}
+	if etag := rc.header("If-None-Match"); etag != "" && etag == doc.data["_etag"] {
+		return &response{status: http.StatusNotModified, headers: map[string]string{"etag": etag}}, nil
+	}
+	return c.docResponse(http.StatusOK, doc), nil
+}
+
+func (c *collection) replaceDocument(rc *requestCtx, id string) (*response, *apiError) {
+	var data map[string]interface{}
+	if err := rc.decodeBody(&data); err != nil {
+		return nil, err
+	}
+	pk, err := c.requiredPk(rc)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.opReplace(pk, id, data, rc.header("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+	return c.docResponse(http.StatusOK, doc), nil
+}
+
+func (c *collection) patchDocument(rc *requestCtx, id string) (*response, *apiError) {
+	var body patchBody
+	if err := rc.decodeBody(&body); err != nil {
+		return nil, err
+	}
+	pk, err := c.requiredPk(rc)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.opPatch(pk, id, body, rc.header("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+	return c.docResponse(http.StatusOK, doc), nil
+}
+
+func (c *collection) deleteDocument(rc *requestCtx, id string) (*response, *apiError) {
+	pk, err := c.requiredPk(rc)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.opDelete(pk, id, rc.header("If-Match")); err != nil {
+		return nil, err
+	}
+	return &response{status: http.StatusNoContent}, nil
+}
+
+func (c *collection) listDocuments(rc *requestCtx) (*response, *apiError) {
+	docs := c.sortedDocs("")
+	items := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		items[i] = doc.data
+	}
+	return c.pagedResponse(rc, items)
+}
+
+// pagedResponse returns a page of documents, as responded by list and query requests.
+func (c *collection) pagedResponse(rc *requestCtx, items []interface{}) (*response, *apiError) {
+	items, continuation, err := page(rc, items)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{"x-ms-session-token": fmt.Sprintf("0:-1#%d", c.lsn), "x-ms-item-count": strconv.Itoa(len(items))}
+	if continuation != "" {
+		headers["x-ms-continuation"] = continuation
+	}
+	body := map[string]interface{}{"_rid": c.rid, "Documents": items, "_count": len(items)}
+	return &response{status: http.StatusOK, headers: headers, body: body}, nil
+}
+
+type queryBody struct {
+	Query      string `json:"query"`
+	Parameters []struct {
+		Name  string      `json:"name"`
+		Value interface{} `json:"value"`
+	} `json:"parameters"`
+}
+
+// parseQueryRequest parses the query specified by the request body.
+func parseQueryRequest(rc *requestCtx) (*sqlQuery, map[string]interface{}, *apiError) {
+	var body queryBody
+	if err := rc.decodeBody(&body); err != nil {
+		return nil, nil, err
+	}
+	query, err := parseQuery(body.Query)
+	if err != nil {
+		return nil, nil, newApiError(http.StatusBadRequest, "%s", err)
+	}
+	params := make(map[string]interface{}, len(body.Parameters))
+	for _, p := range body.Parameters {
+		params[p.Name] = p.Value
+	}
+	return query, params, nil
+}
+
+func (c *collection) queryPlan(rc *requestCtx) (*response, *apiError) {
+	if _, _, err := parseQueryRequest(rc); err != nil {
+		return nil, err
+	}
+	// queries are evaluated entirely server-side, hence the plan does not ask the client to post-process the results
+	plan := map[string]interface{}{
+		"partitionedQueryExecutionInfoVersion": 2,
+		"queryInfo": map[string]interface{}{
+			"distinctType": "None", "top": nil, "offset": nil, "limit": nil,
+			"orderBy": []interface{}{}, "orderByExpressions": []interface{}{},
+			"groupByExpressions": []interface{}{}, "groupByAliases": []interface{}{},
+			"aggregates": []interface{}{}, "groupByAliasToAggregateType": map[string]interface{}{},
+			"rewrittenQuery": "", "hasSelectValue": false, "dCountInfo": nil,
+		},
+		"queryRanges": []interface{}{map[string]interface{}{"min": "", "max": "FF", "isMinInclusive": true, "isMaxInclusive": false}},
+	}
+	return &response{status: http.StatusOK, body: plan}, nil
+}
+
+func (c *collection) queryDocuments(rc *requestCtx) (*response, *apiError) {
+	query, params, err := parseQueryRequest(rc)
+	if err != nil {
+		return nil, err
+	}
+	if pkRangeId := rc.header("x-ms-documentdb-partitionkeyrangeid"); pkRangeId != "" && pkRangeId != "0" {
+		return nil, newApiError(http.StatusGone, "partition key range %q does not exist", pkRangeId)
+	}
+	pk, err := c.pkFromHeader(rc)
+	if err != nil {
+		return nil, err
+	}
+	docs := c.sortedDocs(pk)
+	items := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		items[i] = doc.data
+	}
+	result, qerr := query.execute(items, params)
+	if qerr != nil {
+		return nil, newApiError(http.StatusBadRequest, "%s", qerr)
+	}
+	return c.pagedResponse(rc, result)
+}
+
+/*----------------------------------------------------------------------*/
+
+// changeFeedStart returns the LSN after which changes are returned by a change feed request.
+func (c *collection) changeFeedStart(rc *requestCtx, fullFidelity bool) (int64, *apiError) {
+	token := rc.header("x-ms-continuation")
+	if token == "" {
+		token = rc.header("If-None-Match")
+	}
+	switch {
+	case token == "*":
+		return c.lsn, nil
+	case token != "":
+		lsn, err := strconv.ParseInt(strings.Trim(token, `"`), 10, 64)
+		if err != nil || lsn < 0 {
+			return 0, newApiError(http.StatusBadRequest, "invalid change feed continuation %q", token)
+		}
+		return lsn, nil
+	case fullFidelity:
+		return 0, newApiError(http.StatusBadRequest, "full-fidelity change feed can only start from now or from a continuation")
+	}
+	if since := rc.header("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		if err != nil {
+			return 0, newApiError(http.StatusBadRequest, "invalid If-Modified-Since header %q", since)
+		}
+		lsn := int64(0)
+		for _, record := range c.log {
+			if record.ts >= t.Unix() {
+				break
+			}
+			lsn = record.lsn
+		}
+		return lsn, nil
+	}
+	return 0, nil
+}
+
+func (c *collection) changeFeed(rc *requestCtx) (*response, *apiError) {
+	fullFidelity := strings.EqualFold(rc.header("A-IM"), "Full-Fidelity Feed")
+	if !fullFidelity && !strings.EqualFold(rc.header("A-IM"), "Incremental feed") {
+		return nil, newApiError(http.StatusBadRequest, "invalid A-IM header %q", rc.header("A-IM"))
+	}
+	start, err := c.changeFeedStart(rc, fullFidelity)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	var lsns []int64
+	if fullFidelity {
+		for _, record := range c.log {
+			if record.lsn <= start {
+				continue
+			}
+			metadata := map[string]interface{}{"operationType": record.op, "lsn": record.lsn, "crts": record.ts}
+			item := map[string]interface{}{"metadata": metadata}
+			if record.current != nil {
+				item["current"] = record.current
+			} else {
+				item["current"] = map[string]interface{}{}
+			}
+			if record.previous != nil {
+				item["previous"] = record.previous
+				metadata["previousImageLSN"] = record.prevLsn
+			}
+			items, lsns = append(items, item), append(lsns, record.lsn)
+		}
+	} else {
+		docs := make([]*document, 0)
+		for _, doc := range c.docs {
+			if doc.lsn > start {
+				docs = append(docs, doc)
+			}
+		}
+		sort.Slice(docs, func(i, j int) bool { return docs[i].lsn < docs[j].lsn })
+		for _, doc := range docs {
+			data := make(map[string]interface{}, len(doc.data)+1)
+			for k, v := range doc.data {
+				data[k] = v
+			}
+			data["_lsn"] = doc.lsn
+			items, lsns = append(items, data), append(lsns, doc.lsn)
+		}
+	}
+
+	if len(items) == 0 {
+		return &response{status: http.StatusNotModified, headers: map[string]string{"etag": fmt.Sprintf(`"%d"`, start)}}, nil
+	}
+	headers := make(map[string]string)
+	if size := pageSize(rc); size > 0 && size < len(items) {
+		items, lsns = items[:size], lsns[:size]
+		headers["x-ms-continuation"] = fmt.Sprintf(`"%d"`, lsns[len(lsns)-1])
+	}
+	headers["etag"] = fmt.Sprintf(`"%d"`, lsns[len(lsns)-1])
+	headers["x-ms-session-token"] = fmt.Sprintf("0:-1#%d", c.lsn)
+	body := map[string]interface{}{"_rid": c.rid, "Documents": items, "_count": len(items)}
+	return &response{status: http.StatusOK, headers: headers, body: body}, nil
+}
+
+/*----------------------------------------------------------------------*/
+
+type batchOp struct {
+	OperationType string                 `json:"operationType"`
+	Id            string                 `json:"id"`
+	ResourceBody  map[string]interface{} `json:"resourceBody"`
+	IfMatch       string                 `json:"ifMatch"`
+}
+
+// executeBatchOp executes an operation of a batch, returns its result.
+func (c *collection) executeBatchOp(pk string, op batchOp) (map[string]interface{}, *apiError) {
+	var doc *document
+	status := http.StatusOK
+	var err *apiError
+	id := op.Id
+	if id == "" && op.ResourceBody != nil {
+		id, _ = op.ResourceBody["id"].(string)
+	}
+	switch op.OperationType {
+	case "Create", "Upsert":
+		if op.ResourceBody == nil {
+			return nil, newApiError(http.StatusBadRequest, "resource body is missing")
+		}
+		doc, status, err = c.opCreate(pk, op.ResourceBody, op.OperationType == "Upsert")
+	case "Replace":
+		if op.ResourceBody == nil {
+			return nil, newApiError(http.StatusBadRequest, "resource body is missing")
+		}
+		doc, err = c.opReplace(pk, id, op.ResourceBody, op.IfMatch)
+	case "Delete":
+		status, err = http.StatusNoContent, c.opDelete(pk, id, op.IfMatch)
+	case "Read":
+		if doc, err = c.find(pk, id); err == nil {
+			err = checkIfMatch(doc, op.IfMatch)
+		}
+	case "Patch":
+		var body patchBody
+		js, _ := json.Marshal(op.ResourceBody)
+		_ = json.Unmarshal(js, &body)
+		doc, err = c.opPatch(pk, id, body, op.IfMatch)
+	default:
+		err = newApiError(http.StatusBadRequest, "invalid batch operation type %q", op.OperationType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{"statusCode": status, "requestCharge": 1}
+	if doc != nil {
+		result["eTag"] = doc.data["_etag"]
+		result["resourceBody"] = doc.data
+	}
+	return result, nil
+}
+
+func (c *collection) executeBatch(rc *requestCtx) (*response, *apiError) {
+	var ops []batchOp
+	if err := rc.decodeBody(&ops); err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 || len(ops) > 100 {
+		return nil, newApiError(http.StatusBadRequest, "number of batch operations must be between 1 and 100, got %d", len(ops))
+	}
+	pk, err := c.requiredPk(rc)
+	if err != nil {
+		return nil, err
+	}
+	isAtomic := !strings.EqualFold(rc.header("x-ms-cosmos-batch-atomic"), "false")
+
+	// snapshot of the collection, to roll back an atomic batch
+	docs := make(map[string]*document, len(c.docs))
+	for k, v := range c.docs {
+		docs[k] = v
+	}
+	lsn, docSeq, logLen := c.lsn, c.docSeq, len(c.log)
+
+	results := make([]interface{}, len(ops))
+	failed := -1
+	status := http.StatusOK
+	for i, op := range ops {
+		result, err := c.executeBatchOp(pk, op)
+		if err != nil {
+			result = map[string]interface{}{"statusCode": err.status, "requestCharge": 1}
+			if failed < 0 {
+				failed = i
+			}
+			if isAtomic {
+				results[i] = result
+				for j := range results {
+					if j != i {
+						results[j] = map[string]interface{}{"statusCode": http.StatusFailedDependency, "requestCharge": 0}
+					}
+				}
+				c.docs, c.lsn, c.docSeq, c.log = docs, lsn, docSeq, c.log[:logLen]
+				status = err.status
+				break
+			}
+			status = http.StatusMultiStatus
+		}
+		results[i] = result
+	}
+	headers := map[string]string{"x-ms-session-token": fmt.Sprintf("0:-1#%d", c.lsn)}
+	return &response{status: status, headers: headers, body: results}, nil
+}
+
+/*----------------------------------------------------------------------*/
+
+type offer struct {
+	rid             string
+	resource        string
+	offerResourceId string
+	ts              int64
+	etag            string
+	content         map[string]interface{}
+}
+
+func (o *offer) info() map[string]interface{} {
+	return map[string]interface{}{
+		"id": o.rid, "_rid": o.rid, "_self": "offers/" + o.rid + "/", "_etag": o.etag, "_ts": o.ts,
+		"offerVersion": "V2", "offerType": "Invalid", "resource": o.resource, "offerResourceId": o.offerResourceId,
+		"content": toJsonValue(o.content),
+	}
+}
+
+func manualThroughputContent(ru, maxEver int) map[string]interface{} {
+	return map[string]interface{}{
+		"offerThroughput": ru, "offerIsRUPerMinuteThroughputEnabled": false,
+		"offerMinimumThroughputParameters": map[string]interface{}{"maxThroughputEverProvisioned": maxEver, "maxConsumedStorageEverInKB": 0},
+	}
+}
+
+func autopilotThroughputContent(maxru, maxEver int) map[string]interface{} {
+	return map[string]interface{}{
+		"offerThroughput": maxru / 10, "offerAutopilotSettings": map[string]interface{}{"maxThroughput": maxru},
+		"offerMinimumThroughputParameters": map[string]interface{}{"maxThroughputEverProvisioned": maxEver, "maxConsumedStorageEverInKB": 0},
+	}
+}
+
+func validateRu(ru int) *apiError {
+	if ru < 400 {
+		return newApiError(http.StatusBadRequest, "throughput must be at least 400, got %d", ru)
+	}
+	return nil
+}
+
+func validateMaxRu(maxru int) *apiError {
+	if maxru < 1000 || maxru%1000 != 0 {
+		return newApiError(http.StatusBadRequest, "autoscale max throughput must be a multiple of 1000, got %d", maxru)
+	}
+	return nil
+}
+
+// createOffer creates the offer of a database or collection, if the request specifies its throughput.
+func (s *Server) createOffer(rc *requestCtx, resource, resourceRid string) *apiError {
+	var content map[string]interface{}
+	if header := rc.header("x-ms-offer-throughput"); header != "" {
+		ru, err := strconv.Atoi(header)
+		if err != nil {
+			return newApiError(http.StatusBadRequest, "invalid x-ms-offer-throughput header %q", header)
+		}
+		if err := validateRu(ru); err != nil {
+			return err
+		}
+		content = manualThroughputContent(ru, ru)
+	} else if header := rc.header("x-ms-cosmos-offer-autopilot-settings"); header != "" {
+		var settings struct {
+			MaxThroughput int `json:"maxThroughput"`
+		}
+		if err := json.Unmarshal([]byte(header), &settings); err != nil || settings.MaxThroughput <= 0 {
+			return newApiError(http.StatusBadRequest, "invalid x-ms-cosmos-offer-autopilot-settings header %q", header)
+		}
+		if err := validateMaxRu(settings.MaxThroughput); err != nil {
+			return err
+		}
+		content = autopilotThroughputContent(settings.MaxThroughput, settings.MaxThroughput)
+	} else {
+		return nil
+	}
+	s.offerSeq++
+	rid := encodeRid([]byte{byte(s.offerSeq >> 16), byte(s.offerSeq >> 8), byte(s.offerSeq)})
+	s.offers = append(s.offers, &offer{rid: rid, resource: resource, offerResourceId: resourceRid, ts: time.Now().Unix(), etag: newEtag(), content: content})
+	return nil
+}
+
+func (s *Server) deleteOffer(resourceRid string) {
+	for i, o := range s.offers {
+		if o.offerResourceId == resourceRid {
+			s.offers = append(s.offers[:i], s.offers[i+1:]...)
+			return
+		}
+	}
+}
+
+// findOffer finds an offer by its id, case-insensitively (clients address offers by lower-cased ids).
+func (s *Server) findOffer(rid string) (*offer, *apiError) {
+	for _, o := range s.offers {
+		if strings.EqualFold(o.rid, rid) {
+			return o, nil
+		}
+	}
+	return nil, newNotFoundError("Offer", rid)
+}
+
+func (s *Server) routeOffers(rc *requestCtx) (*response, *apiError) {
+	path := rc.path
+	switch {
+	case len(path) == 1 && (rc.method == http.MethodGet || rc.method == http.MethodPost):
+		items := make([]interface{}, 0, len(s.offers))
+		for _, o := range s.offers {
+			items = append(items, o.info())
+		}
+		if rc.method == http.MethodPost {
+			query, params, err := parseQueryRequest(rc)
+			if err != nil {
+				return nil, err
+			}
+			result, qerr := query.execute(items, params)
+			if qerr != nil {
+				return nil, newApiError(http.StatusBadRequest, "%s", qerr)
+			}
+			items = result
+		}
+		return &response{status: http.StatusOK, body: map[string]interface{}{"_rid": "", "Offers": items, "_count": len(items)}}, nil
+	case len(path) == 2 && rc.method == http.MethodGet:
+		o, err := s.findOffer(path[1])
+		if err != nil {
+			return nil, err
+		}
+		return &response{status: http.StatusOK, body: o.info()}, nil
+	case len(path) == 2 && rc.method == http.MethodPut:
+		o, err := s.findOffer(path[1])
+		if err != nil {
+			return nil, err
+		}
+		return s.replaceOffer(rc, o)
+	}
+	return nil, newApiError(http.StatusNotImplemented, "%s /%s is not supported by gocosmostest", rc.method, strings.Join(path, "/"))
+}
+
+func (s *Server) replaceOffer(rc *requestCtx, o *offer) (*response, *apiError) {
+	var body struct {
+		Content struct {
+			OfferThroughput        *int `json:"offerThroughput"`
+			OfferAutopilotSettings *struct {
+				MaxThroughput int `json:"maxThroughput"`
+			} `json:"offerAutopilotSettings"`
+		} `json:"content"`
+	}
+	if err := rc.decodeBody(&body); err != nil {
+		return nil, err
+	}
+	current := o.info()["content"].(map[string]interface{})
+	_, isAutopilot := current["offerAutopilotSettings"]
+	ru, _ := current["offerThroughput"].(float64)
+	maxEver := 0
+	if m, ok := current["offerMinimumThroughputParameters"].(map[string]interface{}); ok {
+		f, _ := m["maxThroughputEverProvisioned"].(float64)
+		maxEver = int(f)
+	}
+	content := body.Content
+	switch {
+	case strings.EqualFold(rc.header("x-ms-cosmos-migrate-offer-to-autopilot"), "true"):
+		if isAutopilot {
+			return nil, newApiError(http.StatusBadRequest, "offer is already autoscale")
+		}
+		maxru := int(ru) * 10
+		if maxru < 4000 {
+			maxru = 4000
+		}
+		o.content = autopilotThroughputContent(maxru, maxEver)
+	case strings.EqualFold(rc.header("x-ms-cosmos-migrate-offer-to-manual-throughput"), "true"):
+		if !isAutopilot {
+			return nil, newApiError(http.StatusBadRequest, "offer is already manual")
+		}
+		o.content = manualThroughputContent(int(ru), maxEver)
+	case content.OfferAutopilotSettings != nil:
+		if !isAutopilot {
+			return nil, newApiError(http.StatusBadRequest, "offer must be migrated to autoscale first")
+		}
+		maxru := content.OfferAutopilotSettings.MaxThroughput
+		if err := validateMaxRu(maxru); err != nil {
+			return nil, err
+		}
+		if maxru > maxEver {
+			maxEver = maxru
+		}
+		o.content = autopilotThroughputContent(maxru, maxEver)
+	case content.OfferThroughput != nil:
+		if isAutopilot {
+			return nil, newApiError(http.StatusBadRequest, "offer must be migrated to manual throughput first")
+		}
+		if err := validateRu(*content.OfferThroughput); err != nil {
+			return nil, err
+		}
+		if *content.OfferThroughput > maxEver {
+			maxEver = *content.OfferThroughput
+		}
+		o.content = manualThroughputContent(*content.OfferThroughput, maxEver)
+	default:
+		return nil, newApiError(http.StatusBadRequest, "offer content is missing")
+	}
+	o.ts, o.etag = time.Now().Unix(), newEtag()
+	return &response{status: http.StatusOK, body: o.info()}, nil
+}
@@ -3,8 +3,15 @@ package gocosmos_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/microsoft/gocosmos/gocosmostest"
 )
 
 func TestDriver_invalidConnectionString(t *testing.T) {
@@ -68,3 +75,31 @@ func TestDriver_Close(t *testing.T) {
 		t.Fatalf("%s failed: %s", testName, err)
 	}
 }
+
+func TestDriver_QueryContextDeadline(t *testing.T) {
+	testName := "TestDriver_QueryContextDeadline"
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		// never respond, wait until the client gives up
+		// (request body must be consumed so that the server can detect the closed connection)
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	db, err := sql.Open("gocosmos", "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+";DefaultDb=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := db.QueryContext(ctx, "SELECT * FROM c WITH collection=mytable"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%s failed: expected error %#v but received %#v", testName, context.DeadlineExceeded, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := db.ExecContext(ctx, "DELETE FROM mytable WHERE id=1 AND pk=1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("%s failed: expected error %#v but received %#v", testName, context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,137 @@
+package gocosmos_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+)
+
+const replayConnStr = "AccountEndpoint=https://replay.invalid:8081/;AccountKey=" + gocosmostest.DefaultAccountKey
+
+func _runRecordedScenario(t *testing.T, testName string, client *gocosmos.RestClient) []interface{} {
+	if result := client.CreateDatabase(gocosmos.DatabaseSpec{Id: "mydb"}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+	if result := client.CreateCollection(gocosmos.CollectionSpec{DbName: "mydb", CollName: "mytable", PartitionKeyInfo: map[string]interface{}{"paths": []string{"/pk"}, "kind": "Hash"}}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		doc := gocosmos.DocInfo{"id": id, "pk": "p" + id, "value": "value" + id}
+		if result := client.CreateDocument(gocosmos.DocumentSpec{DbName: "mydb", CollName: "mytable", PartitionKeyValues: []interface{}{doc["pk"]}, DocumentData: doc}); result.Error() != nil {
+			t.Fatalf("%s failed: %s", testName, result.Error())
+		}
+	}
+	result := client.QueryDocuments(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT VALUE c.value FROM c WHERE c.id != @id ORDER BY c.id DESC",
+		Params: []interface{}{map[string]interface{}{"name": "@id", "value": "2"}}})
+	if result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+	return result.Documents
+}
+
+func TestRecorder_RecordReplay(t *testing.T) {
+	testName := "TestRecorder_RecordReplay"
+	fixtureFile := filepath.Join(t.TempDir(), "fixture.json")
+	server := gocosmostest.NewServer()
+	defer server.Close()
+
+	rec := gocosmostest.NewRecorder(fixtureFile, nil)
+	client, err := gocosmos.NewRestClient(rec.HttpClient(), server.ConnectionString(""))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	recorded := _runRecordedScenario(t, testName, client)
+	if !reflect.DeepEqual(recorded, []interface{}{"value3", "value1"}) {
+		t.Fatalf("%s failed: unexpected result %#v", testName, recorded)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	data, _ := os.ReadFile(fixtureFile)
+	for _, scrubbed := range []string{"Authorization", "X-Ms-Date", "sig%3D", server.URL()} {
+		if strings.Contains(string(data), scrubbed) {
+			t.Fatalf("%s failed: fixture file must not contain %q", testName, scrubbed)
+		}
+	}
+
+	replayer, err := gocosmostest.NewReplayer(fixtureFile)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	client, _ = gocosmos.NewRestClient(replayer.HttpClient(), replayConnStr)
+	if replayed := _runRecordedScenario(t, testName, client); !reflect.DeepEqual(replayed, recorded) {
+		t.Fatalf("%s failed: expected %#v but received %#v", testName, recorded, replayed)
+	}
+	if err := replayer.Verify(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+}
+
+func TestRecorder_ReplayUnexpectedRequest(t *testing.T) {
+	testName := "TestRecorder_ReplayUnexpectedRequest"
+	replayer, err := gocosmostest.NewReplayer("testdata/replay_query_2pkranges.json")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	client, _ := gocosmos.NewRestClient(replayer.HttpClient(), replayConnStr)
+	result := client.GetDatabase("mydb")
+	if !errors.Is(result.Error(), gocosmostest.ErrUnexpectedRequest) {
+		t.Fatalf("%s failed: expected ErrUnexpectedRequest but received %#v", testName, result.Error())
+	}
+	// same path but different query
+	queryResult := client.QueryDocuments(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c"})
+	if !errors.Is(queryResult.Error(), gocosmostest.ErrUnexpectedRequest) {
+		t.Fatalf("%s failed: expected ErrUnexpectedRequest but received %#v", testName, queryResult.Error())
+	}
+	err = replayer.Verify()
+	if err == nil || !strings.Contains(err.Error(), "2 unexpected request(s)") || !strings.Contains(err.Error(), "9 interaction(s) not replayed") {
+		t.Fatalf("%s failed: unexpected verification result %#v", testName, err)
+	}
+}
+
+// TestRecorder_ReplayQueryAndMerge replays queries against a collection with two partition key ranges, where the client
+// must merge results of both ranges.
+func TestRecorder_ReplayQueryAndMerge(t *testing.T) {
+	testName := "TestRecorder_ReplayQueryAndMerge"
+	replayer, err := gocosmostest.NewReplayer("testdata/replay_query_2pkranges.json")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	client, _ := gocosmos.NewRestClient(replayer.HttpClient(), replayConnStr)
+
+	// ORDER BY: results of both ranges are merged and sorted, the first range is returned in two pages
+	result := client.QueryDocuments(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c ORDER BY c.grade DESC"})
+	if result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+	var ids []string
+	for _, doc := range result.Documents.AsDocInfoSlice() {
+		ids = append(ids, doc.Id())
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "2", "3", "4", "5"}) {
+		t.Fatalf("%s failed: unexpected result %#v", testName, ids)
+	}
+	if result.RequestCharge != 2.5*3 {
+		// 3 query pages (query plan and pkranges are not counted)
+		t.Fatalf("%s failed: expected request charge %f but received %f", testName, 2.5*3, result.RequestCharge)
+	}
+
+	// DISTINCT: duplicates across ranges are removed
+	result = client.QueryDocuments(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT DISTINCT VALUE c.category FROM c"})
+	if result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+	if len(result.Documents) != 3 {
+		t.Fatalf("%s failed: expected 3 distinct values but received %#v", testName, result.Documents)
+	}
+
+	if err := replayer.Verify(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+}
@@ -0,0 +1,893 @@
+package gocosmos_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+)
+
+func _newFakeClient(t *testing.T, testName string) (*gocosmostest.Server, *gocosmos.RestClient) {
+	server := gocosmostest.NewServer()
+	client, err := gocosmos.NewRestClient(nil, server.ConnectionString(""))
+	if err != nil {
+		server.Close()
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	return server, client
+}
+
+func TestGocosmostest_Auth(t *testing.T) {
+	testName := "TestGocosmostest_Auth"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	badKey := "AAAAyDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
+	client, _ := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL()+";AccountKey="+badKey)
+	if result := client.ListDatabases(); result.StatusCode != 401 {
+		t.Fatalf("%s failed: expected status 401 but received %d", testName, result.StatusCode)
+	}
+	client, _ = gocosmos.NewRestClient(nil, server.ConnectionString(""))
+	if result := client.ListDatabases(); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+}
+
+func TestGocosmostest_DatabaseCollection(t *testing.T) {
+	testName := "TestGocosmostest_DatabaseCollection"
+	server, client := _newFakeClient(t, testName)
+	defer server.Close()
+
+	if result := client.CreateDatabase(gocosmos.DatabaseSpec{Id: "mydb", Ru: 400}); result.Error() != nil || result.Rid == "" {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.CreateDatabase(gocosmos.DatabaseSpec{Id: "mydb"}); result.StatusCode != 409 {
+		t.Fatalf("%s failed: expected status 409 but received %d", testName, result.StatusCode)
+	}
+	spec := gocosmos.CollectionSpec{DbName: "mydb", CollName: "mytable", PartitionKeyInfo: map[string]interface{}{"paths": []string{"/pk"}, "kind": "Hash"}}
+	collResult := client.CreateCollection(spec)
+	if collResult.Error() != nil || collResult.PartitionKey.Kind() != "Hash" {
+		t.Fatalf("%s failed: %#v", testName, collResult)
+	}
+	if result := client.ListCollections("mydb"); result.Error() != nil || result.Count != 1 || result.Collections[0].Id != "mytable" {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.GetPkranges("mydb", "mytable"); result.Error() != nil || len(result.Pkranges) != 1 || result.Pkranges[0].Id != "0" {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.GetCollection("mydb", "notfound"); result.StatusCode != 404 {
+		t.Fatalf("%s failed: expected status 404 but received %d", testName, result.StatusCode)
+	}
+
+	// offers
+	dbResult := client.GetDatabase("mydb")
+	if result := client.GetOfferForResource(dbResult.Rid); result.Error() != nil || result.OfferThroughput() != 400 || result.IsAutopilot() {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.ReplaceOfferForResource(dbResult.Rid, 0, 6000); result.Error() != nil || !result.IsAutopilot() {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.ReplaceOfferForResource(dbResult.Rid, 800, 0); result.Error() != nil || result.OfferThroughput() != 800 || result.IsAutopilot() {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.GetOfferForResource(collResult.Rid); result.StatusCode != 404 {
+		t.Fatalf("%s failed: expected status 404 but received %d", testName, result.StatusCode)
+	}
+
+	if result := client.DeleteDatabase("mydb"); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+	if result := client.ListDatabases(); result.Error() != nil || result.Count != 0 {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+}
+
+func _newFakeCollection(t *testing.T, testName string, uniqueKeyPolicy map[string]interface{}) (*gocosmostest.Server, *gocosmos.RestClient) {
+	server, client := _newFakeClient(t, testName)
+	client.CreateDatabase(gocosmos.DatabaseSpec{Id: "mydb"})
+	result := client.CreateCollection(gocosmos.CollectionSpec{DbName: "mydb", CollName: "mytable",
+		PartitionKeyInfo: map[string]interface{}{"paths": []string{"/pk"}, "kind": "Hash"},
+		UniqueKeyPolicy:  uniqueKeyPolicy,
+	})
+	if result.Error() != nil {
+		server.Close()
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+	return server, client
+}
+
+func TestGocosmostest_DocumentCrud(t *testing.T) {
+	testName := "TestGocosmostest_DocumentCrud"
+	server, client := _newFakeCollection(t, testName, map[string]interface{}{"uniqueKeys": []interface{}{map[string]interface{}{"paths": []string{"/email"}}}})
+	defer server.Close()
+
+	spec := gocosmos.DocumentSpec{DbName: "mydb", CollName: "mytable", PartitionKeyValues: []interface{}{"a"},
+		DocumentData: gocosmos.DocInfo{"id": "1", "pk": "a", "email": "1@domain.com", "count": 1}}
+	created := client.CreateDocument(spec)
+	if created.Error() != nil || created.StatusCode != 201 || created.Etag() == "" {
+		t.Fatalf("%s failed: %#v", testName, created)
+	}
+	if result := client.CreateDocument(spec); result.StatusCode != 409 {
+		t.Fatalf("%s failed: expected status 409 but received %d", testName, result.StatusCode)
+	}
+	wrongPk := spec
+	wrongPk.PartitionKeyValues = []interface{}{"b"}
+	if result := client.CreateDocument(wrongPk); result.StatusCode != 400 {
+		t.Fatalf("%s failed: expected status 400 but received %d", testName, result.StatusCode)
+	}
+	dupEmail := gocosmos.DocumentSpec{DbName: "mydb", CollName: "mytable", PartitionKeyValues: []interface{}{"a"},
+		DocumentData: gocosmos.DocInfo{"id": "2", "pk": "a", "email": "1@domain.com"}}
+	if result := client.CreateDocument(dupEmail); result.StatusCode != 409 {
+		t.Fatalf("%s failed: expected status 409 (unique key) but received %d", testName, result.StatusCode)
+	}
+
+	docReq := gocosmos.DocReq{DbName: "mydb", CollName: "mytable", DocId: "1", PartitionKeyValues: []interface{}{"a"}}
+	if result := client.GetDocument(docReq); result.Error() != nil || result.DocInfo["email"] != "1@domain.com" {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.GetDocument(gocosmos.DocReq{DbName: "mydb", CollName: "mytable", DocId: "1", PartitionKeyValues: []interface{}{"b"}}); result.StatusCode != 404 {
+		t.Fatalf("%s failed: expected status 404 but received %d", testName, result.StatusCode)
+	}
+	notMatch := docReq
+	notMatch.NotMatchEtag = created.Etag()
+	if result := client.GetDocument(notMatch); result.StatusCode != 304 {
+		t.Fatalf("%s failed: expected status 304 but received %d", testName, result.StatusCode)
+	}
+
+	spec.DocumentData = gocosmos.DocInfo{"id": "1", "pk": "a", "email": "1@domain.com", "count": 2}
+	if result := client.ReplaceDocument(`"wrong"`, spec); result.StatusCode != 412 {
+		t.Fatalf("%s failed: expected status 412 but received %d", testName, result.StatusCode)
+	}
+	replaced := client.ReplaceDocument(created.Etag(), spec)
+	if replaced.Error() != nil || replaced.DocInfo.Rid() != created.DocInfo.Rid() || replaced.Etag() == created.Etag() {
+		t.Fatalf("%s failed: %#v", testName, replaced)
+	}
+
+	patchReq := gocosmos.PatchDocReq{DbName: "mydb", CollName: "mytable", DocId: "1", PartitionKeyValues: []interface{}{"a"},
+		Condition: "FROM c WHERE c.count = 2", Operations: []gocosmos.PatchOp{{Op: gocosmos.PatchOpIncrement, Path: "/count", Value: 10}, {Op: gocosmos.PatchOpAdd, Path: "/tags", Value: []string{"x"}}}}
+	if result := client.PatchDocument(patchReq); result.Error() != nil || result.DocInfo["count"] != 12.0 || !reflect.DeepEqual(result.DocInfo["tags"], []interface{}{"x"}) {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.PatchDocument(patchReq); result.StatusCode != 412 {
+		t.Fatalf("%s failed: expected status 412 but received %d", testName, result.StatusCode)
+	}
+
+	spec.IsUpsert = true
+	spec.DocumentData = gocosmos.DocInfo{"id": "3", "pk": "a"}
+	if result := client.CreateDocument(spec); result.Error() != nil || result.StatusCode != 201 {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	spec.DocumentData = gocosmos.DocInfo{"id": "3", "pk": "a", "value": "upserted"}
+	if result := client.CreateDocument(spec); result.Error() != nil || result.StatusCode != 200 || result.DocInfo["value"] != "upserted" {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+
+	if result := client.DeleteDocument(docReq); result.Error() != nil || result.StatusCode != 204 {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	if result := client.DeleteDocument(docReq); result.StatusCode != 404 {
+		t.Fatalf("%s failed: expected status 404 but received %d", testName, result.StatusCode)
+	}
+}
+
+func TestGocosmostest_Batch(t *testing.T) {
+	testName := "TestGocosmostest_Batch"
+	server, client := _newFakeCollection(t, testName, nil)
+	defer server.Close()
+
+	req := gocosmos.BatchReq{DbName: "mydb", CollName: "mytable", PartitionKeyValues: []interface{}{"a"}, Operations: []gocosmos.BatchOp{
+		{Op: gocosmos.BatchOpCreate, Document: gocosmos.DocInfo{"id": "1", "pk": "a"}},
+		{Op: gocosmos.BatchOpCreate, Document: gocosmos.DocInfo{"id": "2", "pk": "a"}},
+	}}
+	if result := client.ExecuteBatch(req); result.Error() != nil || len(result.Results) != 2 || result.Results[1].StatusCode != 201 {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	req.Operations = []gocosmos.BatchOp{
+		{Op: gocosmos.BatchOpDelete, Id: "1"},
+		{Op: gocosmos.BatchOpCreate, Document: gocosmos.DocInfo{"id": "2", "pk": "a"}},
+	}
+	result := client.ExecuteBatch(req)
+	if result.Error() == nil || result.FailedOperation() != 1 || result.Results[0].StatusCode != 424 || result.Results[1].StatusCode != 409 {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	// the failed batch is rolled back
+	if result := client.GetDocument(gocosmos.DocReq{DbName: "mydb", CollName: "mytable", DocId: "1", PartitionKeyValues: []interface{}{"a"}}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName, result.Error())
+	}
+}
+
+func TestGocosmostest_Query(t *testing.T) {
+	testName := "TestGocosmostest_Query"
+	server, client := _newFakeCollection(t, testName, nil)
+	defer server.Close()
+	for i := 0; i < 10; i++ {
+		doc := gocosmos.DocInfo{"id": fmt.Sprintf("%02d", i), "pk": fmt.Sprintf("p%d", i%2), "grade": i, "category": i % 3}
+		if result := client.CreateDocument(gocosmos.DocumentSpec{DbName: "mydb", CollName: "mytable", PartitionKeyValues: []interface{}{doc["pk"]}, DocumentData: doc}); result.Error() != nil {
+			t.Fatalf("%s failed: %s", testName, result.Error())
+		}
+	}
+
+	testData := []struct {
+		name     string
+		query    string
+		params   []interface{}
+		pkValue  string
+		expected []interface{}
+	}{
+		{name: "value_where", query: "SELECT VALUE c.id FROM c WHERE c.grade >= @min AND c.category != 0", params: []interface{}{map[string]interface{}{"name": "@min", "value": 5}},
+			expected: []interface{}{"05", "07", "08"}},
+		{name: "order_by_top", query: "SELECT TOP 3 c.id, c.grade AS g FROM c ORDER BY c.category DESC, c.grade",
+			expected: []interface{}{map[string]interface{}{"id": "02", "g": 2.0}, map[string]interface{}{"id": "05", "g": 5.0}, map[string]interface{}{"id": "08", "g": 8.0}}},
+		{name: "distinct_offset_limit", query: "SELECT DISTINCT VALUE c.category FROM c ORDER BY c.category OFFSET 1 LIMIT 1", expected: []interface{}{1.0}},
+		{name: "count", query: "SELECT VALUE COUNT(1) FROM c WHERE c.grade > 6", expected: []interface{}{3.0}},
+		{name: "group_by", query: "SELECT c.category, SUM(c.grade) AS total FROM c GROUP BY c.category",
+			expected: []interface{}{map[string]interface{}{"category": 0.0, "total": 18.0}, map[string]interface{}{"category": 1.0, "total": 12.0}, map[string]interface{}{"category": 2.0, "total": 15.0}}},
+		{name: "functions", query: "SELECT VALUE c.id FROM c WHERE STARTSWITH(c.id, '0') AND c.id IN ('01', '02', '99') AND IS_DEFINED(c.grade)", expected: []interface{}{"01", "02"}},
+		{name: "partition", query: "SELECT VALUE c.grade FROM c WHERE c.grade < 5", pkValue: "p1", expected: []interface{}{1.0, 3.0}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := client.QueryDocuments(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: testCase.query, Params: testCase.params, PkValue: testCase.pkValue})
+			if result.Error() != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, result.Error())
+			}
+			if !reflect.DeepEqual([]interface{}(result.Documents), testCase.expected) {
+				t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, testCase.expected, result.Documents)
+			}
+		})
+	}
+
+	// paging
+	result := client.QueryDocuments(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c", MaxItemCount: 4})
+	if result.Error() != nil || result.Count != 4 || result.ContinuationToken == "" {
+		t.Fatalf("%s failed: %#v", testName+"/paging", result)
+	}
+	it := client.QueryDocumentsIterator(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c ORDER BY c.grade DESC", MaxItemCount: 3})
+	defer it.Close()
+	count := 0
+	for ; ; count++ {
+		doc, err := it.Next()
+		if err != nil {
+			break
+		}
+		if id := doc.(map[string]interface{})["id"]; id != fmt.Sprintf("%02d", 9-count) {
+			t.Fatalf("%s failed: unexpected document %#v at position %d", testName+"/iterator", id, count)
+		}
+	}
+	if count != 10 {
+		t.Fatalf("%s failed: expected 10 documents but received %d", testName+"/iterator", count)
+	}
+
+	if result := client.QueryDocuments(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c WHERE"}); result.StatusCode != 400 {
+		t.Fatalf("%s failed: expected status 400 but received %d", testName+"/syntax_error", result.StatusCode)
+	}
+}
+
+func TestGocosmostest_ChangeFeed(t *testing.T) {
+	testName := "TestGocosmostest_ChangeFeed"
+	server, client := _newFakeCollection(t, testName, nil)
+	defer server.Close()
+	spec := gocosmos.DocumentSpec{DbName: "mydb", CollName: "mytable", PartitionKeyValues: []interface{}{"a"}, DocumentData: gocosmos.DocInfo{"id": "1", "pk": "a"}}
+	client.CreateDocument(spec)
+
+	feedReq := gocosmos.ListDocsReq{DbName: "mydb", CollName: "mytable", IsIncrementalFeed: true, ChangeFeedStart: gocosmos.ChangeFeedStartFromNow()}
+	result := client.ListDocuments(feedReq)
+	if result.Error() != nil || result.StatusCode != 304 || result.Etag == "" {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	etag := result.Etag
+
+	spec.DocumentData = gocosmos.DocInfo{"id": "2", "pk": "a"}
+	client.CreateDocument(spec)
+	spec.DocumentData = gocosmos.DocInfo{"id": "1", "pk": "a", "value": "updated"}
+	client.ReplaceDocument("", spec)
+	client.DeleteDocument(gocosmos.DocReq{DbName: "mydb", CollName: "mytable", DocId: "2", PartitionKeyValues: []interface{}{"a"}})
+
+	feedReq = gocosmos.ListDocsReq{DbName: "mydb", CollName: "mytable", IsIncrementalFeed: true, NotMatchEtag: etag}
+	if result := client.ListDocuments(feedReq); result.Error() != nil || result.Count != 1 || result.Documents[0]["value"] != "updated" {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	feedReq = gocosmos.ListDocsReq{DbName: "mydb", CollName: "mytable", IsFullFidelityFeed: true, NotMatchEtag: etag}
+	result = client.ListDocuments(feedReq)
+	if result.Error() != nil || len(result.Changes) != 3 {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+	ops := []string{result.Changes[0].OperationType, result.Changes[1].OperationType, result.Changes[2].OperationType}
+	if !reflect.DeepEqual(ops, []string{gocosmos.ChangeFeedOpCreate, gocosmos.ChangeFeedOpReplace, gocosmos.ChangeFeedOpDelete}) || result.Changes[2].Id() != "2" {
+		t.Fatalf("%s failed: unexpected changes %#v", testName, result.Changes)
+	}
+	feedReq.NotMatchEtag = result.Etag
+	if result := client.ListDocuments(feedReq); result.Error() != nil || result.StatusCode != 304 {
+		t.Fatalf("%s failed: %#v", testName, result)
+	}
+}
+
+func TestGocosmostest_Driver(t *testing.T) {
+	testName := "TestGocosmostest_Driver"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	testData := []struct {
+		stm  string
+		args []interface{}
+	}{
+		{stm: "CREATE DATABASE mydb WITH ru=400"},
+		{stm: "CREATE COLLECTION mytable WITH pk=/username"},
+		{stm: `INSERT INTO mytable (id, username, grade) VALUES ("\"1\"", "\"user1\"", 7) WITH pk=/username`},
+		{stm: "INSERT INTO mytable (id, username, grade) VALUES (:1, :2, :3) WITH pk=/username", args: []interface{}{"2", "user2", 8}},
+		{stm: "UPDATE mytable SET grade=9 WHERE id=:1 AND username=:2", args: []interface{}{"1", "user1"}},
+	}
+	for _, testCase := range testData {
+		if _, err := db.Exec(testCase.stm, testCase.args...); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, testCase.stm)
+		}
+	}
+
+	rows, err := db.Query("SELECT c.grade, c.id FROM c WHERE c.grade > :1 ORDER BY c.id WITH collection=mytable WITH cross_partition=true", 5)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	var result []string
+	for rows.Next() {
+		var id string
+		var grade int
+		if err := rows.Scan(&grade, &id); err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+		result = append(result, fmt.Sprintf("%s:%d", id, grade))
+	}
+	if !reflect.DeepEqual(result, []string{"1:9", "2:8"}) {
+		t.Fatalf("%s failed: unexpected result %#v", testName, result)
+	}
+
+	for _, expected := range []int64{1, 0} {
+		dbResult, err := db.Exec("DELETE FROM mytable WHERE id=:1 AND username=:2", "2", "user2")
+		if err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+		if numRows, _ := dbResult.RowsAffected(); numRows != expected {
+			t.Fatalf("%s failed: expected %d row(s) affected but received %d", testName, expected, numRows)
+		}
+	}
+}
+
+func TestGocosmostest_QuotedIdentifiers(t *testing.T) {
+	testName := "TestGocosmostest_QuotedIdentifiers"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("my db"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	testData := []struct {
+		stm  string
+		args []interface{}
+	}{
+		{stm: "CREATE DATABASE `my db`"},
+		{stm: "CREATE COLLECTION [my db].[my coll.1] WITH pk=/username"},
+		{stm: "INSERT INTO `my coll.1` (id, username, `full name`) VALUES (:1, :2, :3) WITH pk=/username", args: []interface{}{"1 a", "user 1", "First Last"}},
+		{stm: "UPDATE `my coll.1` SET `full name`=:1, [a/b]=:2 WHERE id=:3 AND username=:4", args: []interface{}{"Last First", true, "1 a", "user 1"}},
+	}
+	for _, testCase := range testData {
+		if _, err := db.Exec(testCase.stm, testCase.args...); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, testCase.stm)
+		}
+	}
+
+	var fullName string
+	var ab bool
+	row := db.QueryRow(`SELECT c["a/b"] AS ab, c["full name"] AS fullName FROM [my coll.1] c WHERE c.id=:1`, "1 a")
+	if err := row.Scan(&ab, &fullName); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if fullName != "Last First" || !ab {
+		t.Fatalf("%s failed: unexpected result %q, %v", testName, fullName, ab)
+	}
+
+	if dbResult, err := db.Exec("DELETE FROM `my coll.1` WHERE id=:1 AND username=:2", "1 a", "user 1"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	} else if numRows, _ := dbResult.RowsAffected(); numRows != 1 {
+		t.Fatalf("%s failed: expected 1 row affected but received %d", testName, numRows)
+	}
+	if _, err := db.Exec("DROP DATABASE `my db`"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+}
+
+func TestGocosmostest_NamedParameters(t *testing.T) {
+	testName := "TestGocosmostest_NamedParameters"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	testData := []struct {
+		stm  string
+		args []interface{}
+	}{
+		{stm: "CREATE DATABASE mydb"},
+		{stm: "CREATE COLLECTION mytable WITH pk=/username"},
+		{stm: "INSERT INTO mytable (id, username, grade) VALUES (@id, @username, @grade) WITH pk=/username",
+			args: []interface{}{sql.Named("grade", 7), sql.Named("id", "1"), sql.Named("username", "user1")}},
+		{stm: "UPSERT INTO mytable (id, username, grade) VALUES (@id, @username, @grade) WITH pk=/username",
+			args: []interface{}{sql.Named("id", "2"), sql.Named("username", "user2"), sql.Named("grade", 8)}},
+		{stm: "UPDATE mytable SET grade=@grade WHERE id=@id AND username=@username",
+			args: []interface{}{sql.Named("username", "user1"), sql.Named("id", "1"), sql.Named("grade", 9)}},
+	}
+	for _, testCase := range testData {
+		if _, err := db.Exec(testCase.stm, testCase.args...); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, testCase.stm)
+		}
+	}
+
+	var grade int
+	row := db.QueryRow("SELECT VALUE c.grade FROM c WHERE c.id=@id AND c.username=@username WITH collection=mytable",
+		sql.Named("id", "1"), sql.Named("username", "user1"))
+	if err := row.Scan(&grade); err != nil || grade != 9 {
+		t.Fatalf("%s failed: %d / %s", testName, grade, err)
+	}
+
+	// positional placeholders need not start from 1, arguments not referenced by the query are ignored
+	row = db.QueryRow("SELECT VALUE c.grade FROM c WHERE c.id=$2 WITH collection=mytable WITH cross_partition=true", "ignored", "1")
+	if err := row.Scan(&grade); err != nil || grade != 9 {
+		t.Fatalf("%s failed: %d / %s", testName, grade, err)
+	}
+
+	if _, err := db.Query("SELECT * FROM c WHERE c.id=@id WITH collection=mytable", "1"); err == nil {
+		t.Fatalf("%s failed: expected error for positional argument", testName)
+	}
+	if _, err := db.Query("SELECT * FROM c WHERE c.id=@id WITH collection=mytable", sql.Named("name", "1")); err == nil {
+		t.Fatalf("%s failed: expected error for unknown named argument", testName)
+	}
+	if _, err := db.Exec("DELETE FROM mytable WHERE id=@id AND username=@username", sql.Named("id", "2")); err == nil {
+		t.Fatalf("%s failed: expected error for missing named argument", testName)
+	}
+
+	if dbResult, err := db.Exec("DELETE FROM mytable WHERE id=@id AND username=@username", sql.Named("username", "user2"), sql.Named("id", "2")); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	} else if numRows, _ := dbResult.RowsAffected(); numRows != 1 {
+		t.Fatalf("%s failed: expected 1 row affected but received %d", testName, numRows)
+	}
+}
+
+func TestGocosmostest_MultiRowInsert(t *testing.T) {
+	testName := "TestGocosmostest_MultiRowInsert"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	for _, stm := range []string{"CREATE DATABASE mydb", "CREATE COLLECTION mytable WITH pk=/username"} {
+		if _, err := db.Exec(stm); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, stm)
+		}
+	}
+
+	dbResult, err := db.Exec(`INSERT INTO mytable (id, username, grade) VALUES (:1, :2, 1), (:3, :2, 2), ("\"3\"", "\"user2\"", :4) WITH pk=/username`,
+		"1", "user1", "2", 3)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows, _ := dbResult.RowsAffected(); numRows != 3 {
+		t.Fatalf("%s failed: expected 3 rows affected but received %d", testName, numRows)
+	}
+
+	// rows #1 and #3 conflict with existing documents, row #2 is written
+	_, err = db.Exec(`INSERT INTO mytable (id, username, grade) VALUES ("\"1\"", "\"user1\"", 1), ("\"4\"", "\"user2\"", 4), ("\"3\"", "\"user2\"", 3)`)
+	var rowsErr *gocosmos.RowsError
+	if !errors.As(err, &rowsErr) {
+		t.Fatalf("%s failed: expected RowsError but received %#v", testName, err)
+	}
+	if rowsErr.RowsAffected != 1 || len(rowsErr.Rows) != 2 || rowsErr.Rows[0].Row != 1 || rowsErr.Rows[1].Row != 3 || !errors.Is(err, gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: unexpected error %s", testName, err)
+	}
+
+	// inside a transaction, rows are buffered and committed as a single batch
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	txResult, err := tx.Exec(`UPSERT INTO mytable (id, username, grade) VALUES ("\"5\"", "\"user3\"", 5), ("\"6\"", "\"user3\"", 6) WITH pk=/username`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows, _ := txResult.RowsAffected(); numRows != 2 {
+		t.Fatalf("%s failed: expected 2 rows affected but received %d", testName, numRows)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT VALUE COUNT(1) FROM c WITH collection=mytable WITH cross_partition=true").Scan(&count); err != nil || count != 6 {
+		t.Fatalf("%s failed: expected 6 documents but received %d / %s", testName, count, err)
+	}
+}
+
+func TestGocosmostest_InsertSelect(t *testing.T) {
+	testName := "TestGocosmostest_InsertSelect"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	testData := []struct {
+		stm  string
+		args []interface{}
+	}{
+		{stm: "CREATE DATABASE mydb"},
+		{stm: "CREATE COLLECTION source WITH pk=/username"},
+		{stm: "CREATE COLLECTION target WITH pk=/grade"},
+		{stm: "INSERT INTO source (id, username, grade) VALUES (:1, :2, 1), (:3, :2, 2), (:4, :5, 2), (:6, :5, 3) WITH pk=/username",
+			args: []interface{}{"1", "user1", "2", "3", "user2", "4"}},
+		{stm: `INSERT INTO target (id, grade) VALUES ("\"3\"", 2)`},
+	}
+	for _, testCase := range testData {
+		if _, err := db.Exec(testCase.stm, testCase.args...); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, testCase.stm)
+		}
+	}
+
+	// document "3" already exists in the target collection
+	_, err = db.Exec("INSERT INTO target SELECT c.id, c.grade, c.username AS owner FROM c WHERE c.grade>=:1 ORDER BY c.id WITH collection=source WITH cross_partition=true", 2)
+	var rowsErr *gocosmos.RowsError
+	if !errors.As(err, &rowsErr) {
+		t.Fatalf("%s failed: expected RowsError but received %#v", testName, err)
+	}
+	if rowsErr.RowsAffected != 2 || len(rowsErr.Rows) != 1 || rowsErr.Rows[0].Row != 2 || !errors.Is(rowsErr.Rows[0].Err, gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: unexpected error %s", testName, err)
+	}
+
+	dbResult, err := db.Exec("UPSERT INTO mydb.target SELECT c.id, c.grade, c.username AS owner FROM c WITH db=mydb WITH table=source WITH cross_partition=true WITH pk=/grade")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows, _ := dbResult.RowsAffected(); numRows != 4 {
+		t.Fatalf("%s failed: expected 4 rows affected but received %d", testName, numRows)
+	}
+	var owner string
+	if err := db.QueryRow(`SELECT VALUE c.owner FROM c WHERE c.id="3" AND c.grade=2 WITH collection=target`).Scan(&owner); err != nil || owner != "user2" {
+		t.Fatalf("%s failed: %s / %s", testName, owner, err)
+	}
+
+	if _, err := db.Exec("INSERT INTO target SELECT VALUE c.id FROM c WITH collection=source WITH cross_partition=true"); !errors.As(err, &rowsErr) || rowsErr.Rows[0].Row != 1 {
+		t.Fatalf("%s failed: expected RowsError but received %#v", testName, err)
+	}
+
+	// documents without PK value are reported, the following ones are still written
+	for _, stm := range []string{`CREATE COLLECTION copy WITH pk=/grade`, `INSERT INTO copy (id, grade) VALUES ("\"4\"", 3)`} {
+		if _, err := db.Exec(stm); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, stm)
+		}
+	}
+	_, err = db.Exec(`INSERT INTO copy SELECT c.id, (c.id = "1" OR c.id = "3" ? undefined : c.grade) AS grade FROM c ORDER BY c.id WITH collection=source WITH cross_partition=true`)
+	if !errors.As(err, &rowsErr) {
+		t.Fatalf("%s failed: expected RowsError but received %#v", testName, err)
+	}
+	// rows 1 and 3 have no PK value, document "4" already exists
+	if rowsErr.RowsAffected != 1 || len(rowsErr.Rows) != 3 || rowsErr.Rows[0].Row != 1 || rowsErr.Rows[1].Row != 3 ||
+		rowsErr.Rows[2].Row != 4 || !errors.Is(rowsErr.Rows[2].Err, gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: unexpected error %s", testName, err)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT VALUE COUNT(1) FROM c WITH collection=copy WITH cross_partition=true`).Scan(&count); err != nil || count != 2 {
+		t.Fatalf("%s failed: expected 2 documents but received %d / %s", testName, count, err)
+	}
+	if _, err := db.Exec("INSERT INTO target SELECT * FROM c WITH collection=notfound"); !errors.Is(err, gocosmos.ErrNotFound) {
+		t.Fatalf("%s failed: expected ErrNotFound but received %#v", testName, err)
+	}
+}
+
+func TestGocosmostest_Returning(t *testing.T) {
+	testName := "TestGocosmostest_Returning"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	for _, stm := range []string{"CREATE DATABASE mydb", "CREATE COLLECTION mytable WITH pk=/username"} {
+		if _, err := db.Exec(stm); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, stm)
+		}
+	}
+
+	var id, etag string
+	var grade float64
+	err = db.QueryRow(`INSERT INTO mytable (id, username, grade) VALUES (:1, :2, :3) WITH pk=/username RETURNING id, _etag, grade`, "1", "user1", 1).Scan(&id, &etag, &grade)
+	if err != nil || id != "1" || etag == "" || grade != 1 {
+		t.Fatalf("%s failed: unexpected result %q/%q/%v / %s", testName, id, etag, grade, err)
+	}
+
+	rows, err := db.Query(`UPDATE mytable SET grade=2 WHERE id=:1 AND username=:2 RETURNING *`, "1", "user1")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	cols, _ := rows.Columns()
+	if !reflect.DeepEqual(cols, []string{"_attachments", "_etag", "_rid", "_self", "_ts", "grade", "id", "username"}) {
+		t.Fatalf("%s failed: unexpected columns %v", testName, cols)
+	}
+	numRows := 0
+	for rows.Next() {
+		numRows++
+	}
+	_ = rows.Close()
+	if numRows != 1 {
+		t.Fatalf("%s failed: expected 1 row but received %d", testName, numRows)
+	}
+
+	if err := db.QueryRow(`DELETE FROM mytable WHERE id=:1 AND username=:2 RETURNING grade`, "1", "user1").Scan(&grade); err != nil || grade != 2 {
+		t.Fatalf("%s failed: unexpected result %v / %s", testName, grade, err)
+	}
+	// the document no longer exists: no row is returned
+	if err := db.QueryRow(`DELETE FROM mytable WHERE id=:1 AND username=:2 RETURNING grade`, "1", "user1").Scan(&grade); err != sql.ErrNoRows {
+		t.Fatalf("%s failed: expected ErrNoRows but received %v", testName, err)
+	}
+
+	rows, err = db.Query(`UPSERT INTO mytable (id, username) VALUES ("\"2\"", "\"user2\""), ("\"3\"", "\"user3\"") RETURNING _rid`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	numRows = 0
+	for rows.Next() {
+		var rid string
+		if err := rows.Scan(&rid); err != nil || rid == "" {
+			t.Fatalf("%s failed: unexpected _rid %q / %s", testName, rid, err)
+		}
+		numRows++
+	}
+	_ = rows.Close()
+	if numRows != 2 {
+		t.Fatalf("%s failed: expected 2 rows but received %d", testName, numRows)
+	}
+
+	// statements without RETURNING clause can not be queried
+	if _, err := db.Query(`DELETE FROM mytable WHERE id=:1 AND username=:2`, "1", "user1"); err != gocosmos.ErrQueryNotSupported {
+		t.Fatalf("%s failed: expected ErrQueryNotSupported but received %v", testName, err)
+	}
+}
+
+func TestGocosmostest_Result(t *testing.T) {
+	testName := "TestGocosmostest_Result"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer conn.Close()
+	lastResult := func() gocosmos.Result {
+		var result gocosmos.Result
+		_ = conn.Raw(func(driverConn any) error {
+			result = driverConn.(*gocosmos.Conn).LastResult()
+			return nil
+		})
+		return result
+	}
+
+	for _, stm := range []string{"CREATE DATABASE mydb", "CREATE COLLECTION mytable WITH pk=/username"} {
+		if _, err := conn.ExecContext(context.Background(), stm); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, stm)
+		}
+	}
+
+	if _, err := conn.ExecContext(context.Background(), `INSERT INTO mytable (id, username) VALUES (:1, :2) WITH pk=/username`, "1", "user1"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	result := lastResult()
+	if result == nil || result.DocId() != "1" || result.Rid() == "" || result.Etag() == "" || result.RequestCharge() <= 0 ||
+		result.SessionToken() == "" || result.ActivityId() == "" {
+		t.Fatalf("%s failed: unexpected result %#v", testName, result)
+	}
+	etag := result.Etag()
+
+	if _, err := conn.ExecContext(context.Background(), `UPDATE mytable SET grade=1 WHERE id=:1 AND username=:2`, "1", "user1"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if result := lastResult(); result.DocId() != "1" || result.Etag() == "" || result.Etag() == etag {
+		t.Fatalf("%s failed: unexpected result %#v", testName, result)
+	}
+
+	if _, err := conn.ExecContext(context.Background(), `DELETE FROM mytable WHERE id=:1 AND username=:2`, "1", "user1"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if result := lastResult(); result.DocId() != "1" || result.ActivityId() == "" {
+		t.Fatalf("%s failed: unexpected result %#v", testName, result)
+	}
+
+	// the number of arguments is checked as database/sql does for prepared statements
+	if _, err := conn.ExecContext(context.Background(), `SELECT * FROM c WHERE c.id=:1 WITH collection=mytable`); err == nil {
+		t.Fatalf("%s failed: expected error but received nil", testName)
+	}
+	if result := lastResult(); result != nil {
+		t.Fatalf("%s failed: expected nil result but received %#v", testName, result)
+	}
+}
+
+func TestGocosmostest_SetBasedUpdateDelete(t *testing.T) {
+	testName := "TestGocosmostest_SetBasedUpdateDelete"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	for _, stm := range []string{"CREATE DATABASE mydb", "CREATE COLLECTION mytable WITH pk=/username"} {
+		if _, err := db.Exec(stm); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, stm)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := db.Exec(`INSERT INTO mytable (id, username, grade) VALUES (:1, :2, :3) WITH pk=/username`, fmt.Sprintf("%d", i), fmt.Sprintf("user%d", i%3), i); err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+	}
+	countRows := func(query string, args ...interface{}) int {
+		var count int
+		if err := db.QueryRow(query, args...).Scan(&count); err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+		return count
+	}
+
+	// safety limit: nothing is updated if more documents match
+	if _, err := db.Exec(`UPDATE mytable SET active=true WHERE c.grade >= :1 WITH max_rows=3`, 5); !errors.Is(err, gocosmos.ErrTooManyRows) {
+		t.Fatalf("%s failed: expected ErrTooManyRows but received %v", testName, err)
+	}
+	if count := countRows(`SELECT VALUE COUNT(1) FROM c WHERE c.active=true WITH collection=mytable WITH cross_partition=true`); count != 0 {
+		t.Fatalf("%s failed: expected 0 updated documents but received %d", testName, count)
+	}
+
+	// dry run: matching documents are counted but not updated
+	result, err := db.Exec(`UPDATE mytable SET active=true WHERE c.grade >= @min WITH dry_run`, sql.Named("min", 5))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows, _ := result.RowsAffected(); numRows != 5 {
+		t.Fatalf("%s failed: expected 5 rows affected but received %d", testName, numRows)
+	}
+	if count := countRows(`SELECT VALUE COUNT(1) FROM c WHERE c.active=true WITH collection=mytable WITH cross_partition=true`); count != 0 {
+		t.Fatalf("%s failed: expected 0 updated documents but received %d", testName, count)
+	}
+
+	result, err = db.Exec(`UPDATE mytable SET active=true, level=:2 WHERE c.grade >= :1 WITH max_rows=5`, 5, "high")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows, _ := result.RowsAffected(); numRows != 5 {
+		t.Fatalf("%s failed: expected 5 rows affected but received %d", testName, numRows)
+	}
+	if count := countRows(`SELECT VALUE COUNT(1) FROM c WHERE c.active=true AND c.level="high" WITH collection=mytable WITH cross_partition=true`); count != 5 {
+		t.Fatalf("%s failed: expected 5 updated documents but received %d", testName, count)
+	}
+
+	// no document matched
+	result, err = db.Exec(`DELETE FROM mytable WHERE c.grade > :1`, 100)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows, _ := result.RowsAffected(); numRows != 0 {
+		t.Fatalf("%s failed: expected 0 rows affected but received %d", testName, numRows)
+	}
+
+	rows, err := db.Query(`DELETE FROM mytable WHERE c.username = :1 AND NOT IS_DEFINED(c.active) RETURNING id`, "user0")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"0", "3"}) {
+		t.Fatalf("%s failed: unexpected deleted ids %v", testName, ids)
+	}
+	if count := countRows(`SELECT VALUE COUNT(1) FROM c WITH collection=mytable WITH cross_partition=true`); count != 8 {
+		t.Fatalf("%s failed: expected 8 remaining documents but received %d", testName, count)
+	}
+
+	// set-based statements are not supported inside a transaction
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM mytable WHERE c.grade > :1`, 0); err == nil {
+		t.Fatalf("%s failed: expected error but received nil", testName)
+	}
+	_ = tx.Rollback()
+}
+
+func TestGocosmostest_SelectColumns(t *testing.T) {
+	testName := "TestGocosmostest_SelectColumns"
+	server := gocosmostest.NewServer()
+	defer server.Close()
+	db, err := sql.Open("gocosmos", server.ConnectionString("mydb"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	for _, stm := range []string{"CREATE DATABASE mydb", "CREATE COLLECTION mytable WITH pk=/username"} {
+		if _, err := db.Exec(stm); err != nil {
+			t.Fatalf("%s failed: %s (%s)", testName, err, stm)
+		}
+	}
+	for i := 0; i < 150; i++ {
+		if _, err := db.Exec(`INSERT INTO mytable (id, username, grade) VALUES (:1, "\"user\"", :2) WITH pk=/username`, fmt.Sprintf("%03d", i), i); err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+	}
+	// a field that appears only in the last document, i.e. in the second page of the query's result
+	if _, err := db.Exec(`UPDATE mytable SET email="\"149@domain.com\"" WHERE id="\"149\"" AND username="\"user\""`); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	// columns determined from the first page: the field is not returned, but iterating the result does not fail
+	rows, err := db.Query(`SELECT * FROM c ORDER BY c.id WITH collection=mytable`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if cols, _ := rows.Columns(); !reflect.DeepEqual(cols, []string{"grade", "id", "username"}) {
+		t.Fatalf("%s failed: unexpected columns %v", testName, cols)
+	}
+	numRows := 0
+	for rows.Next() {
+		numRows++
+	}
+	if err := rows.Err(); err != nil || numRows != 150 {
+		t.Fatalf("%s failed: expected 150 rows but received %d / %s", testName, numRows, err)
+	}
+	_ = rows.Close()
+
+	// columns determined from enough prefetched rows
+	rows, err = db.Query(`SELECT * FROM c ORDER BY c.id WITH collection=mytable WITH prefetch=150`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if cols, _ := rows.Columns(); !reflect.DeepEqual(cols, []string{"email", "grade", "id", "username"}) {
+		t.Fatalf("%s failed: unexpected columns %v", testName, cols)
+	}
+	numRows = 0
+	for rows.Next() {
+		numRows++
+	}
+	if err := rows.Err(); err != nil || numRows != 150 {
+		t.Fatalf("%s failed: expected 150 rows but received %d / %s", testName, numRows, err)
+	}
+	_ = rows.Close()
+}
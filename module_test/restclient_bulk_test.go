@@ -0,0 +1,196 @@
+package gocosmos_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/microsoft/gocosmos"
+)
+
+// fakeBulkServer simulates the batch endpoint: the first attempt of documents whose id is listed in throttle is
+// rejected with status 429, documents whose id is listed in conflict are rejected with status 409.
+type fakeBulkServer struct {
+	lock               sync.Mutex
+	throttle, conflict map[string]bool
+	numRequests        int
+	pkPerRequest       []string
+	inFlight           int
+	maxFlight          int
+	delay              time.Duration
+}
+
+func (s *fakeBulkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var ops []map[string]interface{}
+	_ = json.Unmarshal(body, &ops)
+	s.lock.Lock()
+	s.numRequests++
+	s.pkPerRequest = append(s.pkPerRequest, r.Header.Get("x-ms-documentdb-partitionkey"))
+	s.inFlight++
+	if s.inFlight > s.maxFlight {
+		s.maxFlight = s.inFlight
+	}
+	s.lock.Unlock()
+	time.Sleep(s.delay)
+
+	results := make([]map[string]interface{}, len(ops))
+	s.lock.Lock()
+	for i, op := range ops {
+		id, _ := op["id"].(string)
+		switch {
+		case s.throttle[id]:
+			delete(s.throttle, id)
+			results[i] = map[string]interface{}{"statusCode": 429, "retryAfterMilliseconds": 1}
+		case s.conflict[id]:
+			results[i] = map[string]interface{}{"statusCode": 409, "requestCharge": 1}
+		case op["operationType"] == "Delete":
+			results[i] = map[string]interface{}{"statusCode": 204, "requestCharge": 1}
+		default:
+			results[i] = map[string]interface{}{"statusCode": 201, "requestCharge": 1, "eTag": "etag-" + id, "resourceBody": op["resourceBody"]}
+		}
+	}
+	s.inFlight--
+	s.lock.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("x-ms-request-charge", "0.5")
+	w.WriteHeader(200)
+	js, _ := json.Marshal(results)
+	_, _ = w.Write(js)
+}
+
+func TestRestClient_ExecuteBulk(t *testing.T) {
+	name := "TestRestClient_ExecuteBulk"
+	fake := &fakeBulkServer{throttle: map[string]bool{"7": true, "150": true}, conflict: map[string]bool{"42": true}, delay: 5 * time.Millisecond}
+	client, server := _newFakeRestClient(t, name, fake, "")
+	defer server.Close()
+
+	numItems, numPartitions := 1000, 4
+	items := make([]gocosmos.BulkItem, numItems)
+	for i := range items {
+		pk := fmt.Sprintf("pk%d", i%numPartitions)
+		items[i] = gocosmos.BulkItem{DocumentSpec: gocosmos.DocumentSpec{DbName: testDb, CollName: testTable,
+			PartitionKeyValues: []interface{}{pk}, DocumentData: gocosmos.DocInfo{"id": fmt.Sprintf("%d", i), "pk": pk}}}
+	}
+	items[999].Op = gocosmos.BatchOpDelete
+	result := client.ExecuteBulk(gocosmos.BulkReq{Items: items, MaxConcurrency: 3})
+	if result.CallErr != nil || result.ApiErr == nil {
+		t.Fatalf("%s failed: expected ApiErr only but received %#v/%#v", name, result.CallErr, result.ApiErr)
+	}
+	if result.NumItems != numItems || result.NumFailed != 1 || len(result.Results) != numItems {
+		t.Fatalf("%s failed: expected %d items/1 failed but received %d/%d/%d", name, numItems, result.NumItems, result.NumFailed, len(result.Results))
+	}
+	for i, r := range result.Results {
+		expectedStatus, expectedRetries := 201, 0
+		switch i {
+		case 7, 150:
+			expectedRetries = 1
+		case 42:
+			expectedStatus = 409
+		case 999:
+			expectedStatus = 204
+		}
+		if r.Index != i || r.StatusCode != expectedStatus || r.RetryCount != expectedRetries || (r.Err != nil) != (expectedStatus >= 400) {
+			t.Fatalf("%s failed: unexpected result at %d: %#v", name, i, r)
+		}
+		if expectedStatus == 201 && (r.Etag != "etag-"+fmt.Sprintf("%d", i) || r.DocInfo.Id() != fmt.Sprintf("%d", i)) {
+			t.Fatalf("%s failed: unexpected result at %d: %#v", name, i, r)
+		}
+	}
+	// 1000 items in 4 logical partitions => 3 chunks (100, 100 and 50 items) per partition, plus 2 retries
+	if fake.numRequests != 14 {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, 14, fake.numRequests)
+	}
+	if fake.maxFlight > 3 || fake.maxFlight < 2 {
+		t.Fatalf("%s failed: expected 2 to 3 concurrent requests but received %d", name, fake.maxFlight)
+	}
+	if expected := float64(14) * 0.5; result.RequestCharge != expected {
+		t.Fatalf("%s failed: unexpected request charge %#v", name, result.RequestCharge)
+	}
+}
+
+func TestRestClient_ExecuteBulk_UniquePartitionKeys(t *testing.T) {
+	name := "TestRestClient_ExecuteBulk_UniquePartitionKeys"
+	fake := &fakeBulkServer{}
+	client, server := _newFakeRestClient(t, name, fake, "")
+	defer server.Close()
+
+	numItems := 250
+	items := make([]gocosmos.BulkItem, numItems)
+	for i := range items {
+		id := fmt.Sprintf("%d", i)
+		items[i] = gocosmos.BulkItem{DocumentSpec: gocosmos.DocumentSpec{DbName: testDb, CollName: testTable,
+			PartitionKeyValues: []interface{}{id}, DocumentData: gocosmos.DocInfo{"id": id}}}
+	}
+	result := client.ExecuteBulk(gocosmos.BulkReq{Items: items})
+	if err := result.Error(); err != nil || result.NumItems != numItems || result.NumFailed != 0 {
+		t.Fatalf("%s failed: expected %d items/0 failed but received %d/%d (%s)", name, numItems, result.NumItems, result.NumFailed, err)
+	}
+	// items are grouped by logical partition: with partition key /id, each item is sent in its own request
+	if fake.numRequests != numItems {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, numItems, fake.numRequests)
+	}
+}
+
+func TestRestClient_ExecuteBulk_MaxRetryWait(t *testing.T) {
+	name := "TestRestClient_ExecuteBulk_MaxRetryWait"
+	fake := &fakeBulkServer{throttle: map[string]bool{"1": true}}
+	client, server := _newFakeRestClient(t, name, fake, "")
+	defer server.Close()
+	// the server suggests to retry after 1ms, which exceeds the max total wait time
+	client.SetRetryPolicy(gocosmos.RetryPolicy{MaxRetries: 10, MaxRetryWait: 500 * time.Microsecond})
+
+	items := make([]gocosmos.BulkItem, 3)
+	for i := range items {
+		items[i] = gocosmos.BulkItem{DocumentSpec: gocosmos.DocumentSpec{DbName: testDb, CollName: testTable,
+			PartitionKeyValues: []interface{}{"pk"}, DocumentData: gocosmos.DocInfo{"id": fmt.Sprintf("%d", i), "pk": "pk"}}}
+	}
+	result := client.ExecuteBulk(gocosmos.BulkReq{Items: items})
+	if result.NumFailed != 1 || fake.numRequests != 1 {
+		t.Fatalf("%s failed: expected 1 failed item and 1 request but received %d/%d", name, result.NumFailed, fake.numRequests)
+	}
+	if r := result.Results[1]; r.StatusCode != 429 || r.RetryCount != 0 || r.Err == nil {
+		t.Fatalf("%s failed: unexpected result %#v", name, r)
+	}
+}
+
+func TestRestClient_ExecuteBulk_Source(t *testing.T) {
+	name := "TestRestClient_ExecuteBulk_Source"
+	fake := &fakeBulkServer{}
+	client, server := _newFakeRestClient(t, name, fake, "")
+	defer server.Close()
+
+	source := make(chan gocosmos.BulkItem)
+	go func() {
+		defer close(source)
+		for i := 0; i < 250; i++ {
+			source <- gocosmos.BulkItem{DocumentSpec: gocosmos.DocumentSpec{DbName: testDb, CollName: testTable, IsUpsert: true,
+				PartitionKeyValues: []interface{}{"mypk"}, DocumentData: gocosmos.DocInfo{"id": fmt.Sprintf("%d", i)}}}
+		}
+	}()
+	numResults := 0
+	result := client.ExecuteBulkContext(context.Background(), gocosmos.BulkReq{Source: source, OnResult: func(r gocosmos.BulkItemResult) {
+		if r.Err == nil {
+			numResults++
+		}
+	}})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if numResults != 250 || result.NumItems != 250 || len(result.Results) != 0 {
+		t.Fatalf("%s failed: expected 250 results via callback but received %d/%d/%d", name, numResults, result.NumItems, len(result.Results))
+	}
+	if fake.numRequests != 3 {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, 3, fake.numRequests)
+	}
+	for _, pk := range fake.pkPerRequest {
+		if pk != `["mypk"]` {
+			t.Fatalf("%s failed: unexpected partition key %#v", name, pk)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package gocosmos_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+)
+
+// fakeChangeFeedServer simulates the change feed of the monitored collection "coll" and the lease collection "leases"
+// of database "db".
+type fakeChangeFeedServer struct {
+	lock     sync.Mutex
+	pkranges []gocosmos.PkrangeInfo
+	gone     map[string]bool                     // pkranges that have been split
+	changes  map[string][]map[string]interface{} // changed documents per pkrange, each with a "_lsn"
+	lsn      int
+	leases   map[string]map[string]interface{}
+	etag     int
+}
+
+func newFakeChangeFeedServer(pkRangeIds ...string) *fakeChangeFeedServer {
+	s := &fakeChangeFeedServer{gone: map[string]bool{}, changes: map[string][]map[string]interface{}{}, leases: map[string]map[string]interface{}{}}
+	for _, id := range pkRangeIds {
+		s.pkranges = append(s.pkranges, gocosmos.PkrangeInfo{Id: id})
+	}
+	return s
+}
+
+func (s *fakeChangeFeedServer) addChanges(pkRangeId string, ids ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, id := range ids {
+		s.lsn++
+		s.changes[pkRangeId] = append(s.changes[pkRangeId], map[string]interface{}{"id": id, "_lsn": s.lsn})
+	}
+}
+
+func (s *fakeChangeFeedServer) split(parent string, children ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.gone[parent] = true
+	pkranges := make([]gocosmos.PkrangeInfo, 0)
+	for _, pkrange := range s.pkranges {
+		if pkrange.Id != parent {
+			pkranges = append(pkranges, pkrange)
+		}
+	}
+	for _, child := range children {
+		pkranges = append(pkranges, gocosmos.PkrangeInfo{Id: child, Parents: []string{parent}})
+	}
+	s.pkranges = pkranges
+}
+
+func (s *fakeChangeFeedServer) getLeases() map[string]gocosmos.ChangeFeedLease {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result := make(map[string]gocosmos.ChangeFeedLease)
+	for _, doc := range s.leases {
+		var lease gocosmos.ChangeFeedLease
+		js, _ := json.Marshal(doc)
+		_ = json.Unmarshal(js, &lease)
+		result[lease.PkRangeId] = lease
+	}
+	return result
+}
+
+func (s *fakeChangeFeedServer) write(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		js, _ := json.Marshal(data)
+		_, _ = w.Write(js)
+	}
+}
+
+func (s *fakeChangeFeedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	path := r.URL.Path
+	switch {
+	case path == "/dbs/db/colls/coll/pkranges":
+		s.write(w, 200, map[string]interface{}{"PartitionKeyRanges": s.pkranges, "_count": len(s.pkranges)})
+	case path == "/dbs/db/colls/coll/docs" && r.Header.Get("A-IM") != "":
+		pkRangeId := r.Header.Get("x-ms-documentdb-partitionkeyrangeid")
+		if s.gone[pkRangeId] {
+			s.write(w, 410, map[string]interface{}{"message": "partition key range is gone"})
+			return
+		}
+		from, _ := strconv.Atoi(strings.Trim(r.Header.Get("If-None-Match"), `"`))
+		if r.Header.Get("If-None-Match") == "*" {
+			from = s.lsn
+		}
+		maxItems, _ := strconv.Atoi(r.Header.Get("x-ms-max-item-count"))
+		docs := make([]map[string]interface{}, 0)
+		for _, doc := range s.changes[pkRangeId] {
+			if doc["_lsn"].(int) > from && len(docs) < maxItems {
+				docs = append(docs, doc)
+			}
+		}
+		if len(docs) == 0 {
+			w.Header().Set("etag", fmt.Sprintf(`"%d"`, from))
+			w.WriteHeader(304)
+			return
+		}
+		w.Header().Set("etag", fmt.Sprintf(`"%d"`, docs[len(docs)-1]["_lsn"]))
+		s.write(w, 200, map[string]interface{}{"Documents": docs, "_count": len(docs)})
+	case path == "/dbs/db/colls/leases/docs" && r.Method == "GET":
+		docs := make([]map[string]interface{}, 0, len(s.leases))
+		for _, doc := range s.leases {
+			docs = append(docs, doc)
+		}
+		s.write(w, 200, map[string]interface{}{"Documents": docs, "_count": len(docs)})
+	case path == "/dbs/db/colls/leases/docs" && r.Method == "POST":
+		var doc map[string]interface{}
+		_ = json.Unmarshal(body, &doc)
+		id := doc["id"].(string)
+		if _, ok := s.leases[id]; ok {
+			s.write(w, 409, map[string]interface{}{"message": "conflict"})
+			return
+		}
+		s.etag++
+		doc["_etag"] = strconv.Itoa(s.etag)
+		s.leases[id] = doc
+		s.write(w, 201, doc)
+	case strings.HasPrefix(path, "/dbs/db/colls/leases/docs/"):
+		id := strings.TrimPrefix(path, "/dbs/db/colls/leases/docs/")
+		existing, ok := s.leases[id]
+		if !ok {
+			s.write(w, 404, map[string]interface{}{"message": "ResourceType: Document not found"})
+			return
+		}
+		switch r.Method {
+		case "GET":
+			s.write(w, 200, existing)
+		case "DELETE":
+			delete(s.leases, id)
+			w.WriteHeader(204)
+		case "PUT":
+			if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != existing["_etag"] {
+				s.write(w, 412, map[string]interface{}{"message": "precondition failed"})
+				return
+			}
+			var doc map[string]interface{}
+			_ = json.Unmarshal(body, &doc)
+			s.etag++
+			doc["_etag"] = strconv.Itoa(s.etag)
+			s.leases[id] = doc
+			s.write(w, 200, doc)
+		}
+	default:
+		s.write(w, 404, map[string]interface{}{"message": "not found: " + path})
+	}
+}
+
+// changeFeedCollector records the ids of documents delivered to a change feed handler.
+type changeFeedCollector struct {
+	lock sync.Mutex
+	ids  map[string]int
+}
+
+func (c *changeFeedCollector) handle(_ context.Context, docs []gocosmos.DocInfo) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, doc := range docs {
+		c.ids[doc.Id()]++
+	}
+	return nil
+}
+
+func (c *changeFeedCollector) waitFor(numDocs int, timeout time.Duration) bool {
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		c.lock.Lock()
+		n := len(c.ids)
+		c.lock.Unlock()
+		if n >= numDocs {
+			return true
+		}
+	}
+	return false
+}
+
+func _newChangeFeedProcessor(t *testing.T, testName string, client *gocosmos.RestClient, instance string, handler gocosmos.ChangeFeedHandler) *gocosmos.ChangeFeedProcessor {
+	return _newChangeFeedProcessorWithStart(t, testName, client, instance, handler, gocosmos.ChangeFeedStart{})
+}
+
+func _newChangeFeedProcessorWithStart(t *testing.T, testName string, client *gocosmos.RestClient, instance string, handler gocosmos.ChangeFeedHandler, start gocosmos.ChangeFeedStart) *gocosmos.ChangeFeedProcessor {
+	p, err := client.NewChangeFeedProcessor(gocosmos.ChangeFeedProcessorOptions{
+		DbName: "db", CollName: "coll", LeaseCollName: "leases", ProcessorName: "test", InstanceName: instance,
+		Handler: handler, MaxItemCount: 2, StartFrom: start,
+		PollInterval: 5 * time.Millisecond, LeaseExpiration: 200 * time.Millisecond,
+		LeaseRenewInterval: 20 * time.Millisecond, LeaseAcquireInterval: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	return p
+}
+
+func TestNewChangeFeedProcessor_Invalid(t *testing.T) {
+	testName := "TestNewChangeFeedProcessor_Invalid"
+	client, _ := gocosmos.NewRestClient(nil, "AccountEndpoint=https://localhost:8081/;AccountKey="+gocosmostest.DefaultAccountKey)
+	handler := func(context.Context, []gocosmos.DocInfo) error { return nil }
+	for i, opts := range []gocosmos.ChangeFeedProcessorOptions{
+		{CollName: "coll", LeaseCollName: "leases", Handler: handler},
+		{DbName: "db", CollName: "coll", Handler: handler},
+		{DbName: "db", CollName: "coll", LeaseCollName: "leases"},
+		{DbName: "db", CollName: "coll", LeaseCollName: "leases", Handler: handler, StartFrom: gocosmos.ChangeFeedStart{Mode: "invalid"}},
+	} {
+		if _, err := client.NewChangeFeedProcessor(opts); err == nil {
+			t.Fatalf("%s failed: expected error for options #%d", testName, i)
+		}
+	}
+}
+
+func TestChangeFeedProcessor_Run(t *testing.T) {
+	testName := "TestChangeFeedProcessor_Run"
+	fake := newFakeChangeFeedServer("0", "1")
+	fake.addChanges("0", "a1", "a2", "a3")
+	fake.addChanges("1", "b1", "b2", "b3", "b4")
+	client, server := _newFakeRestClient(t, testName, fake, ";MaxRetries=0")
+	defer server.Close()
+
+	collector := &changeFeedCollector{ids: map[string]int{}}
+	p := _newChangeFeedProcessor(t, testName, client, "instance1", collector.handle)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+	if !collector.waitFor(7, 3*time.Second) {
+		t.Fatalf("%s failed: expected 7 documents but received %#v", testName, collector.ids)
+	}
+	fake.addChanges("0", "a4")
+	if !collector.waitFor(8, 3*time.Second) {
+		t.Fatalf("%s failed: expected 8 documents but received %#v", testName, collector.ids)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	leases := fake.getLeases()
+	if len(leases) != 2 {
+		t.Fatalf("%s failed: expected 2 leases but received %#v", testName, leases)
+	}
+	if leases["0"].ContinuationToken != `"8"` || leases["1"].ContinuationToken != `"7"` {
+		t.Fatalf("%s failed: unexpected checkpoints %#v", testName, leases)
+	}
+	for _, lease := range leases {
+		if lease.Owner != "" {
+			t.Fatalf("%s failed: lease should have been released %#v", testName, lease)
+		}
+	}
+
+	// a new run resumes from the checkpoints
+	fake.addChanges("1", "b5")
+	collector = &changeFeedCollector{ids: map[string]int{}}
+	p = _newChangeFeedProcessor(t, testName, client, "instance2", collector.handle)
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() { done <- p.Run(ctx) }()
+	if !collector.waitFor(1, 3*time.Second) {
+		t.Fatalf("%s failed: expected 1 document but received %#v", testName, collector.ids)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+	if len(collector.ids) != 1 || collector.ids["b5"] != 1 {
+		t.Fatalf("%s failed: expected only new changes but received %#v", testName, collector.ids)
+	}
+}
+
+func TestChangeFeedProcessor_Balance(t *testing.T) {
+	testName := "TestChangeFeedProcessor_Balance"
+	fake := newFakeChangeFeedServer("0", "1", "2", "3")
+	client, server := _newFakeRestClient(t, testName, fake, ";MaxRetries=0")
+	defer server.Close()
+
+	collector := &changeFeedCollector{ids: map[string]int{}}
+	p1 := _newChangeFeedProcessor(t, testName, client, "instance1", collector.handle)
+	p2 := _newChangeFeedProcessor(t, testName, client, "instance2", collector.handle)
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	done1, done2 := make(chan error), make(chan error)
+	go func() { done1 <- p1.Run(ctx1) }()
+	for deadline := time.Now().Add(3 * time.Second); len(p1.OwnedLeases()) != 4; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s failed: expected instance1 to own 4 leases but it owns %#v", testName, p1.OwnedLeases())
+		}
+	}
+	go func() { done2 <- p2.Run(ctx2) }()
+	for deadline := time.Now().Add(3 * time.Second); len(p1.OwnedLeases()) != 2 || len(p2.OwnedLeases()) != 2; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s failed: expected leases to be balanced but received %#v/%#v", testName, p1.OwnedLeases(), p2.OwnedLeases())
+		}
+	}
+	for i := 0; i < 4; i++ {
+		fake.addChanges(strconv.Itoa(i), fmt.Sprintf("doc%d", i))
+	}
+	if !collector.waitFor(4, 3*time.Second) {
+		t.Fatalf("%s failed: expected 4 documents but received %#v", testName, collector.ids)
+	}
+
+	// when an instance stops, the other one takes over its leases
+	cancel1()
+	<-done1
+	for deadline := time.Now().Add(3 * time.Second); len(p2.OwnedLeases()) != 4; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s failed: expected instance2 to own 4 leases but it owns %#v", testName, p2.OwnedLeases())
+		}
+	}
+	cancel2()
+	<-done2
+	for id, n := range collector.ids {
+		if n != 1 {
+			t.Fatalf("%s failed: document %s delivered %d times", testName, id, n)
+		}
+	}
+}
+
+func TestChangeFeedProcessor_Split(t *testing.T) {
+	testName := "TestChangeFeedProcessor_Split"
+	fake := newFakeChangeFeedServer("0")
+	fake.addChanges("0", "a1", "a2", "a3")
+	client, server := _newFakeRestClient(t, testName, fake, ";MaxRetries=0")
+	defer server.Close()
+
+	collector := &changeFeedCollector{ids: map[string]int{}}
+	p := _newChangeFeedProcessor(t, testName, client, "instance1", collector.handle)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+	if !collector.waitFor(3, 3*time.Second) {
+		t.Fatalf("%s failed: expected 3 documents but received %#v", testName, collector.ids)
+	}
+	time.Sleep(20 * time.Millisecond)
+	fake.split("0", "1", "2")
+	fake.addChanges("1", "b1")
+	fake.addChanges("2", "c1", "c2")
+	if !collector.waitFor(6, 3*time.Second) {
+		t.Fatalf("%s failed: expected 6 documents but received %#v", testName, collector.ids)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	leases := fake.getLeases()
+	pkRangeIds := make([]string, 0)
+	for id := range leases {
+		pkRangeIds = append(pkRangeIds, id)
+	}
+	sort.Strings(pkRangeIds)
+	if strings.Join(pkRangeIds, ",") != "1,2" {
+		t.Fatalf("%s failed: expected leases of child ranges only but received %#v", testName, leases)
+	}
+	if leases["1"].ContinuationToken != `"4"` || leases["2"].ContinuationToken != `"6"` {
+		t.Fatalf("%s failed: unexpected checkpoints %#v", testName, leases)
+	}
+	for id, n := range collector.ids {
+		if n != 1 {
+			t.Fatalf("%s failed: document %s delivered %d times", testName, id, n)
+		}
+	}
+}
+
+func TestChangeFeedProcessor_StartFromNow(t *testing.T) {
+	testName := "TestChangeFeedProcessor_StartFromNow"
+	fake := newFakeChangeFeedServer("0", "1")
+	fake.addChanges("0", "old1", "old2")
+	fake.addChanges("1", "old3")
+	client, server := _newFakeRestClient(t, testName, fake, ";MaxRetries=0")
+	defer server.Close()
+
+	collector := &changeFeedCollector{ids: map[string]int{}}
+	p := _newChangeFeedProcessorWithStart(t, testName, client, "instance1", collector.handle, gocosmos.ChangeFeedStartFromNow())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- p.Run(ctx) }()
+
+	// wait until the current position of both ranges has been checkpointed
+	for deadline := time.Now().Add(3 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		leases := fake.getLeases()
+		if leases["0"].ContinuationToken != "" && leases["1"].ContinuationToken != "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s failed: current position not checkpointed %#v", testName, leases)
+		}
+	}
+	fake.addChanges("0", "new1")
+	fake.addChanges("1", "new2")
+	if !collector.waitFor(2, 3*time.Second) {
+		t.Fatalf("%s failed: expected 2 documents but received %#v", testName, collector.ids)
+	}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+	if len(collector.ids) != 2 || collector.ids["new1"] != 1 || collector.ids["new2"] != 1 {
+		t.Fatalf("%s failed: expected only new changes but received %#v", testName, collector.ids)
+	}
+}
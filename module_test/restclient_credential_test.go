@@ -0,0 +1,169 @@
+package gocosmos_test
+
+import (
+	"context"
+	"errors"
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func _authHeader(t *testing.T, testName string, requests []recordedRequest) string {
+	if len(requests) == 0 {
+		t.Fatalf("%s failed: no request received", testName)
+	}
+	v, err := url.QueryUnescape(requests[len(requests)-1].Header.Get("Authorization"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	return v
+}
+
+func TestNewRestClient_Credentials(t *testing.T) {
+	name := "TestNewRestClient_Credentials"
+	var requests []recordedRequest
+	server := _newRecordingServer(200, `{"id":"mydb"}`, &requests)
+	defer server.Close()
+
+	invalidConnStrs := []string{
+		"AccountEndpoint=" + server.URL,
+		"AccountEndpoint=" + server.URL + ";AccountKey=" + gocosmostest.DefaultAccountKey + ";AadToken=token",
+		"AccountEndpoint=" + server.URL + ";AadToken=token;TokenProvider=test",
+		"AccountEndpoint=" + server.URL + ";TokenProvider=not-registered",
+	}
+	for _, connStr := range invalidConnStrs {
+		if _, err := gocosmos.NewRestClient(nil, connStr); err == nil {
+			t.Fatalf("%s failed: connection string %q should be invalid", name, connStr)
+		}
+	}
+
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	client.GetDatabase("mydb")
+	if v := _authHeader(t, name, requests); !strings.HasPrefix(v, "type=master&ver=1.0&sig=") {
+		t.Fatalf("%s failed: unexpected authorization header %#v", name, v)
+	}
+
+	client, err = gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AadToken=my-token")
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	client.GetDatabase("mydb")
+	if v := _authHeader(t, name, requests); v != "type=aad&ver=1.0&sig=my-token" {
+		t.Fatalf("%s failed: unexpected authorization header %#v", name, v)
+	}
+	if requests[len(requests)-1].Header.Get("x-ms-date") == "" {
+		t.Fatalf("%s failed: x-ms-date header not set", name)
+	}
+
+	gocosmos.RegisterTokenProvider("test", func(context.Context) (gocosmos.AccessToken, error) {
+		return gocosmos.AccessToken{Token: "provided-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+	})
+	defer gocosmos.RegisterTokenProvider("test", nil)
+	client, err = gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";TokenProvider=test")
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	client.GetDatabase("mydb")
+	if v := _authHeader(t, name, requests); v != "type=aad&ver=1.0&sig=provided-token" {
+		t.Fatalf("%s failed: unexpected authorization header %#v", name, v)
+	}
+
+	client, err = gocosmos.NewRestClientWithCredential(nil, "AccountEndpoint="+server.URL, gocosmos.NewStaticTokenCredential("explicit-token"))
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	client.GetDatabase("mydb")
+	if v := _authHeader(t, name, requests); v != "type=aad&ver=1.0&sig=explicit-token" {
+		t.Fatalf("%s failed: unexpected authorization header %#v", name, v)
+	}
+}
+
+func TestTokenCredential_Refresh(t *testing.T) {
+	name := "TestTokenCredential_Refresh"
+	var lock sync.Mutex
+	numCalls, fail := 0, false
+	fake := gocosmos.NewFakeTokenProvider(400 * time.Millisecond)
+	cred := gocosmos.NewTokenCredential(func(ctx context.Context) (gocosmos.AccessToken, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		numCalls++
+		if fail {
+			return gocosmos.AccessToken{}, errors.New("identity provider is unavailable")
+		}
+		return fake(ctx)
+	})
+
+	token1, err := cred.Token(context.Background())
+	if err != nil || numCalls != 1 || strings.Count(token1.Token, ".") != 2 {
+		t.Fatalf("%s failed: %#v / %d / %s", name, token1, numCalls, err)
+	}
+	if token, _ := cred.Token(context.Background()); token != token1 || numCalls != 1 {
+		t.Fatalf("%s failed: expected cached token but received %#v (%d calls)", name, token, numCalls)
+	}
+
+	// short-lived tokens are refreshed after half of their lifetime
+	time.Sleep(240 * time.Millisecond)
+	token2, err := cred.Token(context.Background())
+	if err != nil || numCalls != 2 || token2 == token1 {
+		t.Fatalf("%s failed: expected refreshed token but received %#v (%d calls) / %s", name, token2, numCalls, err)
+	}
+
+	// the cached token is used while refreshing fails, until it expires
+	fail = true
+	time.Sleep(240 * time.Millisecond)
+	if token, err := cred.Token(context.Background()); err != nil || token != token2 || numCalls != 3 {
+		t.Fatalf("%s failed: expected cached token but received %#v (%d calls) / %s", name, token, numCalls, err)
+	}
+	time.Sleep(240 * time.Millisecond)
+	if _, err := cred.Token(context.Background()); err == nil {
+		t.Fatalf("%s failed: expected error after token expired", name)
+	}
+
+	fail = false
+	cred.Invalidate()
+	if token, err := cred.Token(context.Background()); err != nil || token == token2 {
+		t.Fatalf("%s failed: expected new token but received %#v / %s", name, token, err)
+	}
+}
+
+func TestTokenCredential_RestClient(t *testing.T) {
+	name := "TestTokenCredential_RestClient"
+	var requests []recordedRequest
+	server := _newRecordingServer(401, `{"code":"Unauthorized","message":"token revoked"}`, &requests)
+	defer server.Close()
+
+	numCalls := 0
+	providerErr := errors.New("identity provider is unavailable")
+	var tokenErr error
+	cred := gocosmos.NewTokenCredential(func(context.Context) (gocosmos.AccessToken, error) {
+		numCalls++
+		return gocosmos.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, tokenErr
+	})
+	client, err := gocosmos.NewRestClientWithCredential(nil, "AccountEndpoint="+server.URL, cred)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	// 401 invalidates the cached token
+	for i := 1; i <= 2; i++ {
+		result := client.GetDatabase("mydb")
+		if result.StatusCode != 401 || numCalls != i {
+			t.Fatalf("%s failed: expected status 401 and %d token requests but received %d and %d", name, i, result.StatusCode, numCalls)
+		}
+	}
+
+	// a failed token request fails the call without sending it
+	tokenErr = providerErr
+	numRequests := len(requests)
+	result := client.GetDatabase("mydb")
+	if result.CallErr == nil || !strings.Contains(result.CallErr.Error(), providerErr.Error()) || len(requests) != numRequests {
+		t.Fatalf("%s failed: expected error %q without request but received %#v (%d requests)", name, providerErr, result.CallErr, len(requests)-numRequests)
+	}
+}
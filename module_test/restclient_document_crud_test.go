@@ -1,7 +1,10 @@
 package gocosmos_test
 
 import (
+	"encoding/json"
 	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -518,3 +521,140 @@ func TestRestClient_DeleteDocument(t *testing.T) {
 		t.Fatalf("%s failed: <status-code> expected %#v but received %#v", name, 404, result.StatusCode)
 	}
 }
+
+func TestRestClient_PatchDocument(t *testing.T) {
+	name := "TestRestClient_PatchDocument"
+	requests := make([]recordedRequest, 0)
+	server := _newRecordingServer(200, `{"id":"myid","pk":"mypk","a":2,"_etag":"\"etag\""}`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	result := client.PatchDocument(gocosmos.PatchDocReq{
+		DbName: testDb, CollName: testTable, DocId: "myid", PartitionKeyValues: []interface{}{"mypk"},
+		Condition: "FROM c WHERE c.a > 0",
+		Operations: []gocosmos.PatchOp{
+			{Op: gocosmos.PatchOpSet, Path: "/b", Value: nil},
+			{Op: gocosmos.PatchOpIncrement, Path: "/a", Value: 1},
+			{Op: gocosmos.PatchOpRemove, Path: "/c"},
+			{Op: gocosmos.PatchOpMove, From: "/d", Path: "/e"},
+		},
+	})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if result.DocInfo.Id() != "myid" || result.DocInfo.Etag() != `"etag"` {
+		t.Fatalf("%s failed: unexpected patched document %#v", name, result.DocInfo)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("%s failed: expected %#v request but received %#v", name, 1, len(requests))
+	}
+	req := requests[0]
+	if req.Method != "PATCH" || req.Path != "/dbs/"+testDb+"/colls/"+testTable+"/docs/myid" {
+		t.Fatalf("%s failed: unexpected request %s %s", name, req.Method, req.Path)
+	}
+	if v := req.Header.Get("Content-Type"); v != "application/json_patch+json" {
+		t.Fatalf("%s failed: unexpected Content-Type %#v", name, v)
+	}
+	if v := req.Header.Get("x-ms-documentdb-partitionkey"); v != `["mypk"]` {
+		t.Fatalf("%s failed: unexpected partition key %#v", name, v)
+	}
+	expected := map[string]interface{}{
+		"condition": "FROM c WHERE c.a > 0",
+		"operations": []interface{}{
+			map[string]interface{}{"op": "set", "path": "/b", "value": nil},
+			map[string]interface{}{"op": "incr", "path": "/a", "value": 1.0},
+			map[string]interface{}{"op": "remove", "path": "/c"},
+			map[string]interface{}{"op": "move", "from": "/d", "path": "/e"},
+		},
+	}
+	if !reflect.DeepEqual(req.Body, expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", name, expected, req.Body)
+	}
+}
+
+func TestRestClient_ExecuteBatch(t *testing.T) {
+	name := "TestRestClient_ExecuteBatch"
+	requests := make([]recordedRequest, 0)
+	respBody := `[{"statusCode":201,"requestCharge":1.5,"eTag":"\"e1\"","resourceBody":{"id":"1","_rid":"rid1"}},` +
+		`{"statusCode":200,"requestCharge":1,"eTag":"\"e2\"","resourceBody":{"id":"2"}},{"statusCode":204,"requestCharge":1}]`
+	server := _newRecordingServer(200, respBody, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	result := client.ExecuteBatch(gocosmos.BatchReq{
+		DbName: testDb, CollName: testTable, PartitionKeyValues: []interface{}{"mypk"},
+		Operations: []gocosmos.BatchOp{
+			{Op: gocosmos.BatchOpCreate, Document: gocosmos.DocInfo{"id": "1", "pk": "mypk"}},
+			{Op: gocosmos.BatchOpPatch, Id: "2", MatchEtag: `"etag"`, Operations: []gocosmos.PatchOp{{Op: gocosmos.PatchOpSet, Path: "/a", Value: 1}}},
+			{Op: gocosmos.BatchOpDelete, Id: "3"},
+		},
+	})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if i := result.FailedOperation(); i != -1 {
+		t.Fatalf("%s failed: expected no failed operation but received %#v", name, i)
+	}
+	if len(result.Results) != 3 || result.Results[0].StatusCode != 201 || result.Results[0].DocInfo.Rid() != "rid1" ||
+		result.Results[1].Etag != `"e2"` || result.Results[2].StatusCode != 204 || result.Results[0].RequestCharge != 1.5 {
+		t.Fatalf("%s failed: unexpected results %#v", name, result.Results)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("%s failed: expected %#v request but received %#v", name, 1, len(requests))
+	}
+	req := requests[0]
+	if req.Method != "POST" || req.Path != "/dbs/"+testDb+"/colls/"+testTable+"/docs" {
+		t.Fatalf("%s failed: unexpected request %s %s", name, req.Method, req.Path)
+	}
+	for k, v := range map[string]string{"x-ms-cosmos-is-batch-request": "True", "x-ms-cosmos-batch-atomic": "True", "x-ms-documentdb-partitionkey": `["mypk"]`} {
+		if req.Header.Get(k) != v {
+			t.Fatalf("%s failed: expected header %s=%#v but received %#v", name, k, v, req.Header.Get(k))
+		}
+	}
+	var body []interface{}
+	_ = json.Unmarshal(req.RawBody, &body)
+	expected := []interface{}{
+		map[string]interface{}{"operationType": "Create", "id": "1", "resourceBody": map[string]interface{}{"id": "1", "pk": "mypk"}},
+		map[string]interface{}{"operationType": "Patch", "id": "2", "ifMatch": `"etag"`, "resourceBody": map[string]interface{}{
+			"operations": []interface{}{map[string]interface{}{"op": "set", "path": "/a", "value": 1.0}}}},
+		map[string]interface{}{"operationType": "Delete", "id": "3"},
+	}
+	if !reflect.DeepEqual(body, expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", name, expected, body)
+	}
+}
+
+func TestRestClient_ExecuteBatch_Failed(t *testing.T) {
+	name := "TestRestClient_ExecuteBatch_Failed"
+	requests := make([]recordedRequest, 0)
+	server := _newRecordingServer(409, `[{"statusCode":424},{"statusCode":409},{"statusCode":424}]`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	ops := []gocosmos.BatchOp{
+		{Op: gocosmos.BatchOpUpsert, Document: gocosmos.DocInfo{"id": "1"}},
+		{Op: gocosmos.BatchOpCreate, Document: gocosmos.DocInfo{"id": "2"}},
+		{Op: gocosmos.BatchOpRead, Id: "3"},
+	}
+	result := client.ExecuteBatch(gocosmos.BatchReq{DbName: testDb, CollName: testTable, PartitionKeyValues: []interface{}{"mypk"}, Operations: ops})
+	if result.Error() == nil || result.StatusCode != 409 {
+		t.Fatalf("%s failed: expected error with status 409 but received %#v/%s", name, result.StatusCode, result.Error())
+	}
+	if i := result.FailedOperation(); i != 1 {
+		t.Fatalf("%s failed: expected failed operation %#v but received %#v", name, 1, i)
+	}
+
+	result = client.ExecuteBatch(gocosmos.BatchReq{DbName: testDb, CollName: testTable, PartitionKeyValues: []interface{}{"mypk"}})
+	if result.Error() == nil || len(requests) != 1 {
+		t.Fatalf("%s failed: empty batch should be rejected client-side", name)
+	}
+}
@@ -0,0 +1,253 @@
+package gocosmos_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/microsoft/gocosmos"
+)
+
+// fakeQueryServer simulates the Cosmos DB endpoints used to execute a query: query plan, partition key ranges and
+// paged query results.
+type fakeQueryServer struct {
+	queryPlan string
+	docs      map[string][]interface{} // documents returned from each partition key range
+	mutex     sync.Mutex
+	numPages  int           // number of pages of documents served so far
+	delay     time.Duration // time to wait before serving a page of documents
+	inFlight  int           // number of pages of documents being served
+	maxFlight int           // max number of pages of documents served concurrently
+}
+
+func _newFakeQueryServer(queryPlan string, docs map[string][]interface{}) *fakeQueryServer {
+	return &fakeQueryServer{queryPlan: queryPlan, docs: docs}
+}
+
+func (fs *fakeQueryServer) pages() int {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	return fs.numPages
+}
+
+func (fs *fakeQueryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(io.Discard, r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	if strings.HasSuffix(r.URL.Path, "/pkranges") {
+		pkranges := make([]map[string]interface{}, 0, len(fs.docs))
+		for id := range fs.docs {
+			pkranges = append(pkranges, map[string]interface{}{"id": id})
+		}
+		sort.Slice(pkranges, func(i, j int) bool { return pkranges[i]["id"].(string) < pkranges[j]["id"].(string) })
+		js, _ := json.Marshal(map[string]interface{}{"PartitionKeyRanges": pkranges, "_count": len(pkranges)})
+		_, _ = w.Write(js)
+		return
+	}
+	if r.Header.Get("x-ms-cosmos-is-query-plan-request") != "" {
+		_, _ = w.Write([]byte(fs.queryPlan))
+		return
+	}
+	docs := fs.docs[r.Header.Get("x-ms-documentdb-partitionkeyrangeid")]
+	start, _ := strconv.Atoi(r.Header.Get("x-ms-continuation"))
+	pageSize, err := strconv.Atoi(r.Header.Get("x-ms-max-item-count"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 100
+	}
+	end := start + pageSize
+	if end < len(docs) {
+		w.Header().Set("x-ms-continuation", strconv.Itoa(end))
+	} else {
+		end = len(docs)
+	}
+	fs.mutex.Lock()
+	fs.numPages++
+	fs.inFlight++
+	if fs.inFlight > fs.maxFlight {
+		fs.maxFlight = fs.inFlight
+	}
+	fs.mutex.Unlock()
+	time.Sleep(fs.delay)
+	fs.mutex.Lock()
+	fs.inFlight--
+	fs.mutex.Unlock()
+	w.Header().Set("x-ms-request-charge", "1.5")
+	js, _ := json.Marshal(map[string]interface{}{"Documents": docs[start:end], "_count": end - start})
+	_, _ = w.Write(js)
+}
+
+func _iterateAll(it *gocosmos.QueryDocsIterator) ([]interface{}, error) {
+	docs := make([]interface{}, 0)
+	for {
+		doc, err := it.Next()
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+func TestRestClient_QueryDocumentsIterator_Paging(t *testing.T) {
+	testName := "TestRestClient_QueryDocumentsIterator_Paging"
+	docs := map[string][]interface{}{"0": {}, "1": {}}
+	for i := 0; i < 250; i++ {
+		for pkrangeId := range docs {
+			docs[pkrangeId] = append(docs[pkrangeId], map[string]interface{}{"id": fmt.Sprintf("%s-%03d", pkrangeId, i), "n": i})
+		}
+	}
+	fs := _newFakeQueryServer(`{"queryInfo":{"distinctType":"None"}}`, docs)
+	client, server := _newFakeRestClient(t, testName, fs, "")
+	defer server.Close()
+
+	it := client.QueryDocumentsIterator(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c", MaxItemCount: 100})
+	defer it.Close()
+	if _, err := it.Next(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if fs.pages() != 1 {
+		t.Fatalf("%s failed: expected %#v page fetched but received %#v", testName, 1, fs.pages())
+	}
+	result, err := _iterateAll(it)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(result) != 499 {
+		t.Fatalf("%s failed: expected %#v documents but received %#v", testName, 499, len(result))
+	}
+	if fs.pages() != 6 {
+		t.Fatalf("%s failed: expected %#v pages fetched but received %#v", testName, 6, fs.pages())
+	}
+}
+
+func TestRestClient_QueryDocumentsIterator_OrderByOffsetLimit(t *testing.T) {
+	testName := "TestRestClient_QueryDocumentsIterator_OrderByOffsetLimit"
+	docs := map[string][]interface{}{"0": {}, "1": {}}
+	for i := 0; i < 200; i++ {
+		pkrangeId := strconv.Itoa(i % 2)
+		docs[pkrangeId] = append(docs[pkrangeId], map[string]interface{}{
+			"orderByItems": []interface{}{map[string]interface{}{"item": i}},
+			"payload":      map[string]interface{}{"id": strconv.Itoa(i), "n": i},
+		})
+	}
+	queryPlan := `{"queryInfo":{"distinctType":"None","orderBy":["Ascending"],"orderByExpressions":["c.n"],"offset":5,"limit":10,` +
+		`"rewrittenQuery":"SELECT c._rid, [{\"item\": c.n}] AS orderByItems, c AS payload FROM c WHERE ({documentdb-formattableorderbyquery-filter}) ORDER BY c.n"}}`
+	fs := _newFakeQueryServer(queryPlan, docs)
+	client, server := _newFakeRestClient(t, testName, fs, "")
+	defer server.Close()
+
+	it := client.QueryDocumentsIterator(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c ORDER BY c.n OFFSET 5 LIMIT 10", MaxItemCount: 20})
+	result, err := _iterateAll(it)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(result) != 10 {
+		t.Fatalf("%s failed: expected %#v documents but received %#v", testName, 10, len(result))
+	}
+	for i, doc := range result {
+		expected := map[string]interface{}{"id": strconv.Itoa(i + 5), "n": float64(i + 5)}
+		if !reflect.DeepEqual(doc, expected) {
+			t.Fatalf("%s failed: expected %#v at position %#v but received %#v", testName, expected, i, doc)
+		}
+	}
+	if fs.pages() != 2 {
+		t.Fatalf("%s failed: expected %#v pages fetched but received %#v", testName, 2, fs.pages())
+	}
+}
+
+func TestRestClient_QueryDocumentsIterator_Distinct(t *testing.T) {
+	testName := "TestRestClient_QueryDocumentsIterator_Distinct"
+	docs := map[string][]interface{}{"0": {1, 2, 3, 1}, "1": {2, 3, 4}}
+	fs := _newFakeQueryServer(`{"queryInfo":{"distinctType":"Unordered"}}`, docs)
+	client, server := _newFakeRestClient(t, testName, fs, "")
+	defer server.Close()
+
+	it := client.QueryDocumentsIterator(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT DISTINCT VALUE c.n FROM c", MaxItemCount: 2})
+	result, err := _iterateAll(it)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	expected := []interface{}{1.0, 2.0, 3.0, 4.0}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("%s failed: expected %#v but received %#v", testName, expected, result)
+	}
+}
+
+func TestRestClient_QueryDocumentsCrossPartition_Parallel(t *testing.T) {
+	testName := "TestRestClient_QueryDocumentsCrossPartition_Parallel"
+	docs := map[string][]interface{}{"0": {}, "1": {}, "2": {}, "3": {}}
+	for i := 0; i < 100; i++ {
+		pkrangeId := strconv.Itoa(i % 4)
+		docs[pkrangeId] = append(docs[pkrangeId], map[string]interface{}{"id": strconv.Itoa(i)})
+	}
+	for _, maxDop := range []int{1, 2, -1} {
+		fs := _newFakeQueryServer(`{"queryInfo":{"distinctType":"None"}}`, docs)
+		fs.delay = 20 * time.Millisecond
+		client, server := _newFakeRestClient(t, testName, fs, "")
+		result := client.QueryDocumentsCrossPartition(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c", MaxItemCount: 10, MaxDegreeOfParallelism: maxDop})
+		server.Close()
+		if err := result.Error(); err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+		if result.Count != 100 {
+			t.Fatalf("%s failed: expected %#v documents but received %#v", testName, 100, result.Count)
+		}
+		if result.RequestCharge != 1.5*float64(fs.pages()) {
+			t.Fatalf("%s failed: expected request charge %#v but received %#v", testName, 1.5*float64(fs.pages()), result.RequestCharge)
+		}
+		expectedMaxFlight := maxDop
+		if maxDop < 0 {
+			expectedMaxFlight = len(docs)
+		}
+		if maxDop == 1 && fs.maxFlight != 1 || maxDop != 1 && (fs.maxFlight < 2 || fs.maxFlight > expectedMaxFlight) {
+			t.Fatalf("%s failed: max degree of parallelism %#v, but %#v ranges were queried concurrently", testName, maxDop, fs.maxFlight)
+		}
+	}
+}
+
+func TestRestClient_QueryDocumentsIterator_Parallel(t *testing.T) {
+	testName := "TestRestClient_QueryDocumentsIterator_Parallel"
+	docs := map[string][]interface{}{"0": {}, "1": {}, "2": {}, "3": {}}
+	for i := 0; i < 100; i++ {
+		pkrangeId := strconv.Itoa(i % 4)
+		docs[pkrangeId] = append(docs[pkrangeId], map[string]interface{}{
+			"orderByItems": []interface{}{map[string]interface{}{"item": i}},
+			"payload":      map[string]interface{}{"id": strconv.Itoa(i), "n": i},
+		})
+	}
+	queryPlan := `{"queryInfo":{"distinctType":"None","orderBy":["Ascending"],"orderByExpressions":["c.n"],` +
+		`"rewrittenQuery":"SELECT c._rid, [{\"item\": c.n}] AS orderByItems, c AS payload FROM c WHERE ({documentdb-formattableorderbyquery-filter}) ORDER BY c.n"}}`
+	fs := _newFakeQueryServer(queryPlan, docs)
+	fs.delay = 20 * time.Millisecond
+	client, server := _newFakeRestClient(t, testName, fs, ";MaxDegreeOfParallelism=4")
+	defer server.Close()
+	if client.GetMaxDegreeOfParallelism() != 4 {
+		t.Fatalf("%s failed: expected %#v but received %#v", testName, 4, client.GetMaxDegreeOfParallelism())
+	}
+
+	it := client.QueryDocumentsIterator(gocosmos.QueryReq{DbName: "mydb", CollName: "mytable", Query: "SELECT * FROM c ORDER BY c.n", MaxItemCount: 10})
+	result, err := _iterateAll(it)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(result) != 100 {
+		t.Fatalf("%s failed: expected %#v documents but received %#v", testName, 100, len(result))
+	}
+	for i, doc := range result {
+		if doc.(map[string]interface{})["n"] != float64(i) {
+			t.Fatalf("%s failed: expected n=%#v at position %#v but received %#v", testName, i, i, doc)
+		}
+	}
+	if fs.maxFlight < 2 {
+		t.Fatalf("%s failed: expected partition key ranges to be queried concurrently", testName)
+	}
+}
@@ -3,6 +3,10 @@ package gocosmos_test
 import (
 	"fmt"
 	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 )
@@ -201,3 +205,146 @@ func TestRestClient_ListDocuments_LargeRU(t *testing.T) {
 	}
 	_testRestClientListDocuments(t, testName, client, dbname, collname)
 }
+
+func TestRestClient_ListDocuments_ChangeFeedStart(t *testing.T) {
+	name := "TestRestClient_ListDocuments_ChangeFeedStart"
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		header = r.Header.Clone()
+		w.Header().Set("etag", `"42"`)
+		w.WriteHeader(304)
+	}))
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	startTime := time.Date(2023, 4, 5, 6, 7, 8, 0, time.FixedZone("UTC+7", 7*3600))
+	testCases := []struct {
+		name                    string
+		start                   gocosmos.ChangeFeedStart
+		notMatchEtag            string
+		ifNoneMatch, ifModSince string
+		expectedEtag            string
+	}{
+		{name: "Default"},
+		{name: "Beginning", start: gocosmos.ChangeFeedStartFromBeginning(), expectedEtag: `"42"`},
+		{name: "Now", start: gocosmos.ChangeFeedStartFromNow(), ifNoneMatch: "*", expectedEtag: `"42"`},
+		{name: "Time", start: gocosmos.ChangeFeedStartFromTime(startTime), ifModSince: "Tue, 04 Apr 2023 23:07:08 GMT", expectedEtag: `"42"`},
+		{name: "Continuation", start: gocosmos.ChangeFeedStartFromContinuation(`"12"`), ifNoneMatch: `"12"`, expectedEtag: `"42"`},
+		{name: "NotMatchEtag", start: gocosmos.ChangeFeedStartFromNow(), notMatchEtag: `"7"`, ifNoneMatch: `"7"`},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := client.ListDocuments(gocosmos.ListDocsReq{DbName: testDb, CollName: testTable, IsIncrementalFeed: true,
+				ChangeFeedStart: testCase.start, NotMatchEtag: testCase.notMatchEtag})
+			if err := result.Error(); err != nil {
+				t.Fatalf("%s failed: %s", name+"/"+testCase.name, err)
+			}
+			if v := header.Get("If-None-Match"); v != testCase.ifNoneMatch {
+				t.Fatalf("%s failed: expected If-None-Match %#v but received %#v", name+"/"+testCase.name, testCase.ifNoneMatch, v)
+			}
+			if v := header.Get("If-Modified-Since"); v != testCase.ifModSince {
+				t.Fatalf("%s failed: expected If-Modified-Since %#v but received %#v", name+"/"+testCase.name, testCase.ifModSince, v)
+			}
+			if result.Etag != testCase.expectedEtag {
+				t.Fatalf("%s failed: expected etag %#v but received %#v", name+"/"+testCase.name, testCase.expectedEtag, result.Etag)
+			}
+		})
+	}
+
+	result := client.ListDocuments(gocosmos.ListDocsReq{DbName: testDb, CollName: testTable, IsIncrementalFeed: true, ChangeFeedStart: gocosmos.ChangeFeedStart{Mode: "invalid"}})
+	if result.Error() == nil {
+		t.Fatalf("%s failed: expected error for invalid start mode", name)
+	}
+}
+
+func TestRestClient_ListDocuments_FullFidelity(t *testing.T) {
+	name := "TestRestClient_ListDocuments_FullFidelity"
+	pages := []string{
+		`{"_rid":"x","_count":2,"Documents":[
+			{"current":{"id":"1","value":1,"_ts":100},"metadata":{"operationType":"create","lsn":5,"crts":100}},
+			{"previous":{"id":"2","value":2},"metadata":{"operationType":"delete","lsn":7,"crts":102,"previousImageLSN":3,"timeToLiveExpired":true}}
+		]}`,
+		`{"_rid":"x","_count":1,"Documents":[
+			{"id":"1","value":10,"_ts":101,"_lsn":6,"_metadata":{"operationType":"replace","previousImage":{"id":"1","value":1}}}
+		]}`,
+	}
+	var aim, wireFormat []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		aim = append(aim, r.Header.Get("A-IM"))
+		wireFormat = append(wireFormat, r.Header.Get("x-ms-cosmos-changefeed-wire-format-version"))
+		page := 0
+		if r.Header.Get("x-ms-continuation") != "" {
+			page = 1
+		} else {
+			w.Header().Set("x-ms-continuation", "page2")
+		}
+		w.Header().Set("etag", fmt.Sprintf(`"%d"`, 7+page))
+		_, _ = w.Write([]byte(pages[page]))
+	}))
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	result := client.ListDocuments(gocosmos.ListDocsReq{DbName: testDb, CollName: testTable, IsFullFidelityFeed: true, NotMatchEtag: `"4"`})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if len(aim) != 2 || aim[0] != "Full-Fidelity Feed" || wireFormat[0] == "" {
+		t.Fatalf("%s failed: unexpected A-IM %#v / wire format %#v headers", name, aim, wireFormat)
+	}
+	if result.Count != 3 || len(result.Documents) != 3 || len(result.Changes) != 3 || result.Etag != `"8"` {
+		t.Fatalf("%s failed: expected 3 changes and etag \"8\" but received %d/%d/%d and %#v", name, result.Count, len(result.Documents), len(result.Changes), result.Etag)
+	}
+	expected := []struct {
+		op       string
+		lsn      int64
+		id       string
+		current  bool
+		previous bool
+	}{
+		{gocosmos.ChangeFeedOpCreate, 5, "1", true, false},
+		{gocosmos.ChangeFeedOpReplace, 6, "1", true, true},
+		{gocosmos.ChangeFeedOpDelete, 7, "2", false, true},
+	}
+	for i, e := range expected {
+		change := result.Changes[i]
+		if change.OperationType != e.op || change.Lsn != e.lsn || change.Id() != e.id || (change.Current != nil) != e.current || (change.Previous != nil) != e.previous {
+			t.Fatalf("%s failed: change #%d expected %#v but received %#v", name, i, e, change)
+		}
+	}
+	if v := result.Changes[1].Previous["value"]; v != 1.0 {
+		t.Fatalf("%s failed: expected previous image value 1 but received %#v", name, v)
+	}
+	if del := result.Changes[2]; !del.IsDelete() || del.Crts != 102 || del.PreviousImageLsn != 3 || !del.TimeToLiveExpired {
+		t.Fatalf("%s failed: unexpected delete record %#v", name, del)
+	}
+
+	// the regular incremental feed does not populate Changes
+	aim = nil
+	result = client.ListDocuments(gocosmos.ListDocsReq{DbName: testDb, CollName: testTable, IsIncrementalFeed: true, NotMatchEtag: `"4"`})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if aim[0] != "Incremental feed" || result.Changes != nil {
+		t.Fatalf("%s failed: unexpected A-IM header %#v or changes %#v", name, aim[0], result.Changes)
+	}
+	// the full-fidelity feed can only start from now or from a continuation: other start modes are rejected upfront
+	for _, start := range []gocosmos.ChangeFeedStart{{}, gocosmos.ChangeFeedStartFromBeginning(), gocosmos.ChangeFeedStartFromTime(time.Now()), gocosmos.ChangeFeedStartFromContinuation("")} {
+		aim = nil
+		result = client.ListDocuments(gocosmos.ListDocsReq{DbName: testDb, CollName: testTable, IsFullFidelityFeed: true, ChangeFeedStart: start})
+		if result.Error() == nil || len(aim) != 0 {
+			t.Fatalf("%s failed: expected error without request for start %#v but received %#v (%d requests)", name, start, result.Error(), len(aim))
+		}
+	}
+	result = client.ListDocuments(gocosmos.ListDocsReq{DbName: testDb, CollName: testTable, IsFullFidelityFeed: true, ChangeFeedStart: gocosmos.ChangeFeedStartFromNow()})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+}
@@ -0,0 +1,112 @@
+package gocosmos_test
+
+import (
+	"encoding/json"
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRestClient_StoredProcedures(t *testing.T) {
+	name := "TestRestClient_StoredProcedures"
+	var requests []recordedRequest
+	server := _newRecordingServer(201, `{"id":"mysproc","body":"function () {}","_rid":"rid","_count":2,"StoredProcedures":[{"id":"sproc2"},{"id":"sproc1"}]}`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	spec := gocosmos.StoredProcedureSpec{DbName: "mydb", CollName: "mytable", Id: "mysproc", Body: "function () {}"}
+	if result := client.CreateStoredProcedure(spec); result.Error() != nil || result.Rid != "rid" || result.Body != spec.Body {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.ReplaceStoredProcedure(spec); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.GetStoredProcedure("mydb", "mytable", "mysproc"); result.Error() != nil || result.Id != "mysproc" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.DeleteStoredProcedure("mydb", "mytable", "mysproc"); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.ListStoredProcedures("mydb", "mytable"); result.Error() != nil || result.Count != 2 || result.StoredProcedures[0].Id != "sproc1" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+
+	expected := []struct{ method, path string }{
+		{"POST", "/dbs/mydb/colls/mytable/sprocs"},
+		{"PUT", "/dbs/mydb/colls/mytable/sprocs/mysproc"},
+		{"GET", "/dbs/mydb/colls/mytable/sprocs/mysproc"},
+		{"DELETE", "/dbs/mydb/colls/mytable/sprocs/mysproc"},
+		{"GET", "/dbs/mydb/colls/mytable/sprocs"},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, len(expected), len(requests))
+	}
+	for i, e := range expected {
+		if req := requests[i]; req.Method != e.method || req.Path != e.path {
+			t.Fatalf("%s failed: expected request %#v but received %s %s", name, e, req.Method, req.Path)
+		}
+	}
+	if body := requests[0].Body; body["id"] != "mysproc" || body["body"] != "function () {}" {
+		t.Fatalf("%s failed: unexpected body %#v", name, body)
+	}
+}
+
+func TestRestClient_ExecuteStoredProcedure(t *testing.T) {
+	name := "TestRestClient_ExecuteStoredProcedure"
+	var header http.Header
+	var params []interface{}
+	status := 200
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		header, params = r.Header.Clone(), nil
+		_ = json.Unmarshal(body, &params)
+		w.Header().Set("x-ms-request-charge", "3.5")
+		w.Header().Set("x-ms-documentdb-script-log-results", "processed%202%20items%3B%20done")
+		w.WriteHeader(status)
+		if status == 200 {
+			_, _ = w.Write([]byte(`{"count":2,"ids":["a","b"]}`))
+		} else {
+			_, _ = w.Write([]byte(`{"code":"BadRequest","message":"Encountered exception while executing function"}`))
+		}
+	}))
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	result := client.ExecuteStoredProcedure("mydb", "mytable", "mysproc", []interface{}{"user1"}, "a", 2, map[string]interface{}{"x": true})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	expectedResult := map[string]interface{}{"count": 2.0, "ids": []interface{}{"a", "b"}}
+	if !reflect.DeepEqual(result.Result, expectedResult) || result.RequestCharge != 3.5 || result.ScriptLog != "processed 2 items; done" {
+		t.Fatalf("%s failed: unexpected result %#v / %#v / %#v", name, result.Result, result.RequestCharge, result.ScriptLog)
+	}
+	expectedParams := []interface{}{"a", 2.0, map[string]interface{}{"x": true}}
+	if !reflect.DeepEqual(params, expectedParams) {
+		t.Fatalf("%s failed: expected parameters %#v but received %#v", name, expectedParams, params)
+	}
+	if header.Get("x-ms-documentdb-partitionkey") != `["user1"]` || header.Get("x-ms-documentdb-script-enable-logging") != "true" {
+		t.Fatalf("%s failed: unexpected headers %#v", name, header)
+	}
+
+	// no argument
+	if result := client.ExecuteStoredProcedure("mydb", "mytable", "mysproc", []interface{}{"user1"}); result.Error() != nil || params == nil || len(params) != 0 {
+		t.Fatalf("%s failed: expected empty parameter array but received %#v / %s", name, params, result.Error())
+	}
+
+	// script error
+	status = 400
+	result = client.ExecuteStoredProcedure("mydb", "mytable", "mysproc", []interface{}{"user1"})
+	if result.ApiErr == nil || result.StatusCode != 400 || result.Result != nil || result.ScriptLog == "" {
+		t.Fatalf("%s failed: expected script error but received %#v", name, result)
+	}
+}
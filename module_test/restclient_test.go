@@ -1,10 +1,19 @@
 package gocosmos_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -28,17 +37,227 @@ func TestNewRestClient(t *testing.T) {
 	if _, err := gocosmos.NewRestClient(nil, "AccountEndpoint=dummy;AccountKey=dummy"); err == nil {
 		t.Fatalf("%s failed: connection string should be invalid", name)
 	}
-	accountKey := "C2y6yDjf5/R+ob0N8A7Cgv30VRDJIWEHLM+4QDU5DE2nQ9nDuVTqobD4b8mGGyPMbIZnqyMsEcaGQy67XIw/Jw=="
-	if _, err := gocosmos.NewRestClient(nil, "AccountEndpoint=dummy;AccountKey="+accountKey); err != nil {
+	if _, err := gocosmos.NewRestClient(nil, "AccountEndpoint=dummy;AccountKey="+gocosmostest.DefaultAccountKey); err != nil {
 		t.Fatalf("%s failed: %s", name, err)
 	}
-	if client, err := gocosmos.NewRestClient(nil, "AccountEndpoint=dummy;AccountKey="+accountKey+";Version=1.2.3;TimeoutMs=12345"); err != nil {
+	if client, err := gocosmos.NewRestClient(nil, "AccountEndpoint=dummy;AccountKey="+gocosmostest.DefaultAccountKey+";Version=1.2.3;TimeoutMs=12345"); err != nil {
 		t.Fatalf("%s failed: %s", name, err)
 	} else if v := client.GetApiVersion(); v != "1.2.3" {
 		t.Fatalf("%s failed: expected API version to be %#v but received %#v", name, "1.2.3", v)
 	}
 }
 
+func TestRestClient_ContextDeadline(t *testing.T) {
+	name := "TestRestClient_ContextDeadline"
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		// never respond, wait until the client gives up
+		// (request body must be consumed so that the server can detect the closed connection)
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := client.QueryDocumentsContext(ctx, gocosmos.QueryReq{DbName: testDb, CollName: testTable, Query: "SELECT * FROM c"})
+	if !errors.Is(result.Error(), context.DeadlineExceeded) {
+		t.Fatalf("%s failed: expected error %#v but received %#v", name, context.DeadlineExceeded, result.Error())
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("%s failed: call should have been aborted by context deadline, but it took %s", name, d)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if result := client.GetDatabaseContext(ctx, testDb); !errors.Is(result.Error(), context.Canceled) {
+		t.Fatalf("%s failed: expected error %#v but received %#v", name, context.Canceled, result.Error())
+	}
+}
+
+func TestRestClient_RetryThrottled(t *testing.T) {
+	name := "TestRestClient_RetryThrottled"
+	numThrottled := 3
+	numCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		numCalls++
+		w.Header().Set("Content-Type", "application/json")
+		if numCalls <= numThrottled {
+			w.Header().Set("x-ms-retry-after-ms", "10")
+			w.WriteHeader(429)
+			_, _ = w.Write([]byte(`{"code":"TooManyRequests","message":"Request rate is large"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"` + testDb + `","_rid":"rid"}`))
+	}))
+	defer server.Close()
+
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if policy := client.GetRetryPolicy(); policy.MaxRetries != gocosmos.DefaultMaxRetries {
+		t.Fatalf("%s failed: expected MaxRetries %#v but received %#v", name, gocosmos.DefaultMaxRetries, policy.MaxRetries)
+	}
+	result := client.GetDatabase(testDb)
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if result.RetryCount != numThrottled || numCalls != numThrottled+1 {
+		t.Fatalf("%s failed: expected %#v retries but received %#v (%#v calls)", name, numThrottled, result.RetryCount, numCalls)
+	}
+	if result.RetryWait < time.Duration(numThrottled)*10*time.Millisecond {
+		t.Fatalf("%s failed: expected to wait at least %s but waited %s", name, time.Duration(numThrottled)*10*time.Millisecond, result.RetryWait)
+	}
+
+	numCalls = 0
+	client, err = gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+";MaxRetries=0")
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	result = client.GetDatabase(testDb)
+	if result.Error() == nil || result.StatusCode != 429 {
+		t.Fatalf("%s failed: expected status %#v but received %#v", name, 429, result.StatusCode)
+	}
+	if result.RetryCount != 0 || numCalls != 1 {
+		t.Fatalf("%s failed: expected no retry but received %#v (%#v calls)", name, result.RetryCount, numCalls)
+	}
+
+	numCalls = 0
+	client.SetRetryPolicy(gocosmos.RetryPolicy{MaxRetries: 10, MaxRetryWait: 15 * time.Millisecond})
+	result = client.GetDatabase(testDb)
+	if result.StatusCode != 429 || result.RetryCount != 1 {
+		t.Fatalf("%s failed: expected status %#v after %#v retry but received %#v after %#v", name, 429, 1, result.StatusCode, result.RetryCount)
+	}
+}
+
+func TestRestClient_RetryConnectionReset(t *testing.T) {
+	name := "TestRestClient_RetryConnectionReset"
+	var numCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		atomic.AddInt32(&numCalls, 1)
+		// drop the connection without responding
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	client.SetRetryPolicy(gocosmos.RetryPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond})
+
+	// reads are idempotent and are retried
+	if result := client.GetDatabase(testDb); result.Error() == nil || result.RetryCount != 2 || atomic.LoadInt32(&numCalls) != 3 {
+		t.Fatalf("%s failed: expected 2 retries but received %#v (%#v calls)", name, result.RetryCount, numCalls)
+	}
+
+	// a create may have been applied before the connection was dropped, it must not be retried
+	atomic.StoreInt32(&numCalls, 0)
+	result := client.CreateDocument(gocosmos.DocumentSpec{DbName: testDb, CollName: testTable, PartitionKeyValues: []interface{}{"a"},
+		DocumentData: map[string]interface{}{"id": "1", "pk": "a"}})
+	if result.Error() == nil || result.RetryCount != 0 || atomic.LoadInt32(&numCalls) != 1 {
+		t.Fatalf("%s failed: expected no retry but received %#v (%#v calls)", name, result.RetryCount, numCalls)
+	}
+}
+
+// countingCredential is a credential returning a different token each time a request is signed.
+type countingCredential struct {
+	numCalls int32
+}
+
+func (cred *countingCredential) AuthHeader(context.Context, string, string, string, time.Time) (string, error) {
+	return fmt.Sprintf("type=aad&ver=1.0&sig=token%d", atomic.AddInt32(&cred.numCalls, 1)), nil
+}
+
+func TestRestClient_RetrySignsAgain(t *testing.T) {
+	name := "TestRestClient_RetrySignsAgain"
+	var lock sync.Mutex
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		lock.Lock()
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		numCalls := len(authHeaders)
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if numCalls == 1 {
+			w.WriteHeader(503)
+			_, _ = w.Write([]byte(`{"code":"ServiceUnavailable","message":"Service is currently unavailable"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"` + testDb + `","_rid":"rid"}`))
+	}))
+	defer server.Close()
+	client, err := gocosmos.NewRestClientWithCredential(nil, "AccountEndpoint="+server.URL, &countingCredential{})
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	client.SetRetryPolicy(gocosmos.RetryPolicy{MaxRetries: 2, BaseBackoff: time.Millisecond})
+
+	result := client.GetDatabase(testDb)
+	if err := result.Error(); err != nil || result.RetryCount != 1 {
+		t.Fatalf("%s failed: expected 1 retry but received %#v (%s)", name, result.RetryCount, err)
+	}
+	if len(authHeaders) != 2 || authHeaders[0] == authHeaders[1] {
+		t.Fatalf("%s failed: expected each attempt to be signed anew but received %#v", name, authHeaders)
+	}
+
+	// a create may have been applied before the server became unavailable, it must not be retried
+	authHeaders = nil
+	result2 := client.CreateDocument(gocosmos.DocumentSpec{DbName: testDb, CollName: testTable, PartitionKeyValues: []interface{}{"a"},
+		DocumentData: map[string]interface{}{"id": "1", "pk": "a"}})
+	if result2.StatusCode != 503 || result2.RetryCount != 0 || len(authHeaders) != 1 {
+		t.Fatalf("%s failed: expected status 503 and no retry but received %#v after %#v retries", name, result2.StatusCode, result2.RetryCount)
+	}
+}
+
+// recordedRequest captures a request received by a recording server.
+type recordedRequest struct {
+	Method  string
+	Path    string
+	Header  http.Header
+	Body    map[string]interface{}
+	RawBody []byte
+}
+
+// _newRecordingServer creates a server that records received requests and responds with the supplied status and
+// JSON body.
+func _newRecordingServer(status int, respBody string, requests *[]recordedRequest) *httptest.Server {
+	var lock sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := recordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), RawBody: body}
+		_ = json.Unmarshal(body, &req.Body)
+		lock.Lock()
+		*requests = append(*requests, req)
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(respBody))
+	}))
+}
+
+// _newFakeRestClient starts a server handling requests with the supplied handler, and creates a client connected to it.
+// extraDsn is appended to the connection string, e.g. ";MaxRetries=0".
+func _newFakeRestClient(t *testing.T, testName string, handler http.Handler, extraDsn string) (*gocosmos.RestClient, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+extraDsn)
+	if err != nil {
+		server.Close()
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	return client, server
+}
+
 func _newRestClient(t *testing.T, testName string) *gocosmos.RestClient {
 	cosmosUrl := strings.TrimSpace(strings.ReplaceAll(os.Getenv("COSMOSDB_URL"), `"`, ""))
 	if cosmosUrl == "" {
@@ -0,0 +1,120 @@
+package gocosmos_test
+
+import (
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"testing"
+)
+
+func TestRestClient_Triggers(t *testing.T) {
+	name := "TestRestClient_Triggers"
+	var requests []recordedRequest
+	server := _newRecordingServer(201, `{"id":"mytrigger","body":"function () {}","triggerType":"Pre","triggerOperation":"Create","_rid":"rid","_count":2,"Triggers":[{"id":"trigger2"},{"id":"trigger1"}]}`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	spec := gocosmos.TriggerSpec{DbName: "mydb", CollName: "mytable", Id: "mytrigger", Body: "function () {}", Type: gocosmos.TriggerTypePre, Operation: gocosmos.TriggerOpCreate}
+	if result := client.CreateTrigger(spec); result.Error() != nil || result.Rid != "rid" || result.TriggerType != "Pre" || result.TriggerOperation != "Create" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.ReplaceTrigger(spec); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.DeleteTrigger("mydb", "mytable", "mytrigger"); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.ListTriggers("mydb", "mytable"); result.Error() != nil || result.Count != 2 || result.Triggers[0].Id != "trigger1" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+
+	expected := []struct{ method, path string }{
+		{"POST", "/dbs/mydb/colls/mytable/triggers"},
+		{"PUT", "/dbs/mydb/colls/mytable/triggers/mytrigger"},
+		{"DELETE", "/dbs/mydb/colls/mytable/triggers/mytrigger"},
+		{"GET", "/dbs/mydb/colls/mytable/triggers"},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, len(expected), len(requests))
+	}
+	for i, e := range expected {
+		if req := requests[i]; req.Method != e.method || req.Path != e.path {
+			t.Fatalf("%s failed: expected request %#v but received %s %s", name, e, req.Method, req.Path)
+		}
+	}
+	if body := requests[0].Body; body["id"] != "mytrigger" || body["triggerType"] != "Pre" || body["triggerOperation"] != "Create" || body["body"] != "function () {}" {
+		t.Fatalf("%s failed: unexpected body %#v", name, body)
+	}
+}
+
+func TestRestClient_UserDefinedFunctions(t *testing.T) {
+	name := "TestRestClient_UserDefinedFunctions"
+	var requests []recordedRequest
+	server := _newRecordingServer(201, `{"id":"myudf","body":"function (x) { return x; }","_rid":"rid","_count":2,"UserDefinedFunctions":[{"id":"udf2"},{"id":"udf1"}]}`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	spec := gocosmos.UserDefinedFunctionSpec{DbName: "mydb", CollName: "mytable", Id: "myudf", Body: "function (x) { return x; }"}
+	if result := client.CreateUserDefinedFunction(spec); result.Error() != nil || result.Rid != "rid" || result.Body != spec.Body {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.ReplaceUserDefinedFunction(spec); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.DeleteUserDefinedFunction("mydb", "mytable", "myudf"); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.ListUserDefinedFunctions("mydb", "mytable"); result.Error() != nil || result.Count != 2 || result.UserDefinedFunctions[0].Id != "udf1" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+
+	expected := []struct{ method, path string }{
+		{"POST", "/dbs/mydb/colls/mytable/udfs"},
+		{"PUT", "/dbs/mydb/colls/mytable/udfs/myudf"},
+		{"DELETE", "/dbs/mydb/colls/mytable/udfs/myudf"},
+		{"GET", "/dbs/mydb/colls/mytable/udfs"},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, len(expected), len(requests))
+	}
+	for i, e := range expected {
+		if req := requests[i]; req.Method != e.method || req.Path != e.path {
+			t.Fatalf("%s failed: expected request %#v but received %s %s", name, e, req.Method, req.Path)
+		}
+	}
+}
+
+func TestRestClient_DocumentTriggers(t *testing.T) {
+	name := "TestRestClient_DocumentTriggers"
+	var requests []recordedRequest
+	server := _newRecordingServer(200, `{"id":"1","pk":"a"}`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	spec := gocosmos.DocumentSpec{DbName: "mydb", CollName: "mytable", PartitionKeyValues: []interface{}{"a"}, DocumentData: gocosmos.DocInfo{"id": "1", "pk": "a"},
+		PreTriggers: []string{"validate", "stamp"}, PostTriggers: []string{"audit"}}
+	client.CreateDocument(spec)
+	client.ReplaceDocument("", spec)
+	client.DeleteDocument(gocosmos.DocReq{DbName: "mydb", CollName: "mytable", DocId: "1", PartitionKeyValues: []interface{}{"a"}, PostTriggers: []string{"audit"}})
+	client.GetDocument(gocosmos.DocReq{DbName: "mydb", CollName: "mytable", DocId: "1", PartitionKeyValues: []interface{}{"a"}})
+
+	expected := []struct{ pre, post string }{{"validate,stamp", "audit"}, {"validate,stamp", "audit"}, {"", "audit"}, {"", ""}}
+	if len(requests) != len(expected) {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, len(expected), len(requests))
+	}
+	for i, e := range expected {
+		req := requests[i]
+		pre, post := req.Header.Get("x-ms-documentdb-pre-trigger-include"), req.Header.Get("x-ms-documentdb-post-trigger-include")
+		if pre != e.pre || post != e.post {
+			t.Fatalf("%s failed: expected triggers %#v for %s but received %q/%q", name, e, req.Method, pre, post)
+		}
+	}
+}
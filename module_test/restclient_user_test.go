@@ -0,0 +1,132 @@
+package gocosmos_test
+
+import (
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRestClient_Users(t *testing.T) {
+	name := "TestRestClient_Users"
+	var requests []recordedRequest
+	server := _newRecordingServer(200, `{"id":"myuser","_rid":"rid","_permissions":"permissions/","_count":2,"Users":[{"id":"user2"},{"id":"user1"}]}`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	if result := client.CreateUser("mydb", "myuser"); result.Error() != nil || result.Rid != "rid" || result.Permissions != "permissions/" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.GetUser("mydb", "myuser"); result.Error() != nil || result.Id != "myuser" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.ReplaceUser("mydb", "myuser", "newuser"); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.DeleteUser("mydb", "myuser"); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+	if result := client.ListUsers("mydb"); result.Error() != nil || result.Count != 2 || result.Users[0].Id != "user1" || result.Users[1].Id != "user2" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+
+	expected := []struct{ method, path, id string }{
+		{"POST", "/dbs/mydb/users", "myuser"},
+		{"GET", "/dbs/mydb/users/myuser", ""},
+		{"PUT", "/dbs/mydb/users/myuser", "newuser"},
+		{"DELETE", "/dbs/mydb/users/myuser", ""},
+		{"GET", "/dbs/mydb/users", ""},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("%s failed: expected %d requests but received %d", name, len(expected), len(requests))
+	}
+	for i, e := range expected {
+		req := requests[i]
+		if id, _ := req.Body["id"].(string); req.Method != e.method || req.Path != e.path || id != e.id {
+			t.Fatalf("%s failed: expected request %#v but received %s %s %#v", name, e, req.Method, req.Path, req.Body)
+		}
+	}
+}
+
+func TestRestClient_Permissions(t *testing.T) {
+	name := "TestRestClient_Permissions"
+	var requests []recordedRequest
+	server := _newRecordingServer(201, `{"id":"myperm","permissionMode":"Read","resource":"dbs/mydb/colls/mytable","resourcePartitionKey":["user1"],"_token":"type=resource&ver=1&sig=abc;def;",
+		"_count":2,"Permissions":[{"id":"perm2"},{"id":"perm1"}]}`, &requests)
+	defer server.Close()
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+
+	spec := gocosmos.PermissionSpec{DbName: "mydb", UserName: "myuser", Id: "myperm", Mode: gocosmos.PermissionModeRead,
+		Resource: "dbs/mydb/colls/mytable", PartitionKey: []interface{}{"user1"}, TokenTtl: 2 * time.Hour}
+	result := client.CreatePermission(spec)
+	if result.Error() != nil || result.Token != "type=resource&ver=1&sig=abc;def;" || result.PermissionMode != "Read" || !reflect.DeepEqual(result.ResourcePartitionKey, []interface{}{"user1"}) {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	req := requests[0]
+	if req.Method != "POST" || req.Path != "/dbs/mydb/users/myuser/permissions" || req.Header.Get("x-ms-documentdb-expiry-seconds") != "7200" {
+		t.Fatalf("%s failed: unexpected request %s %s %#v", name, req.Method, req.Path, req.Header)
+	}
+	expectedBody := map[string]interface{}{"id": "myperm", "permissionMode": "Read", "resource": "dbs/mydb/colls/mytable", "resourcePartitionKey": []interface{}{"user1"}}
+	if !reflect.DeepEqual(req.Body, expectedBody) {
+		t.Fatalf("%s failed: expected body %#v but received %#v", name, expectedBody, req.Body)
+	}
+
+	if result := client.ReplacePermission(spec); result.Error() != nil || requests[1].Method != "PUT" || requests[1].Path != "/dbs/mydb/users/myuser/permissions/myperm" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.GetPermission("mydb", "myuser", "myperm", 0); result.Error() != nil || result.Token == "" || requests[2].Header.Get("x-ms-documentdb-expiry-seconds") != "" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.ListPermissions("mydb", "myuser", 15*time.Minute); result.Error() != nil || result.Count != 2 || result.Permissions[0].Id != "perm1" || requests[3].Header.Get("x-ms-documentdb-expiry-seconds") != "900" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+	if result := client.DeletePermission("mydb", "myuser", "myperm"); result.Error() != nil || requests[4].Method != "DELETE" || requests[4].Path != "/dbs/mydb/users/myuser/permissions/myperm" {
+		t.Fatalf("%s failed: %#v", name, result)
+	}
+}
+
+func TestResourceTokenCredential(t *testing.T) {
+	name := "TestResourceTokenCredential"
+	var requests []recordedRequest
+	server := _newRecordingServer(200, `{"id":"mytable"}`, &requests)
+	defer server.Close()
+
+	token := "type=resource&ver=1&sig=abc;def;"
+	client, err := gocosmos.NewRestClient(nil, "AccountEndpoint="+server.URL+";ResourceToken="+url.QueryEscape(token))
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	client.GetCollection("mydb", "mytable")
+	if v := _authHeader(t, name, requests); v != token {
+		t.Fatalf("%s failed: expected authorization header %#v but received %#v", name, token, v)
+	}
+
+	cred := gocosmos.NewResourceTokenCredential("")
+	cred.SetToken("dbs/mydb/colls/mytable", "token-table")
+	cred.SetToken("/dbs/mydb/colls/mytable/docs/doc1/", "token-doc1")
+	client, err = gocosmos.NewRestClientWithCredential(nil, "AccountEndpoint="+server.URL, cred)
+	if err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	testCases := []struct{ docId, token string }{{"doc1", "token-doc1"}, {"doc2", "token-table"}, {"doc10", "token-table"}}
+	for _, testCase := range testCases {
+		client.GetDocument(gocosmos.DocReq{DbName: "mydb", CollName: "mytable", DocId: testCase.docId, PartitionKeyValues: []interface{}{"pk"}})
+		if v := _authHeader(t, name, requests); v != testCase.token {
+			t.Fatalf("%s failed: expected token %#v for document %q but received %#v", name, testCase.token, testCase.docId, v)
+		}
+	}
+
+	// no token for the requested resource: the request is not sent
+	numRequests := len(requests)
+	if result := client.GetCollection("mydb", "othertable"); result.CallErr == nil || len(requests) != numRequests {
+		t.Fatalf("%s failed: expected error without request but received %#v", name, result.CallErr)
+	}
+}
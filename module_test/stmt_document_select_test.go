@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"net/http/httptest"
 	"reflect"
 	"sort"
 	"strconv"
@@ -782,3 +784,51 @@ func TestStmtSelect_Query_SelectPkranges(t *testing.T) {
 		}
 	}
 }
+
+func TestStmtSelect_QueryStreaming(t *testing.T) {
+	testName := "TestStmtSelect_QueryStreaming"
+	docs := map[string][]interface{}{"0": {}, "1": {}}
+	for i := 0; i < 250; i++ {
+		for pkrangeId := range docs {
+			docs[pkrangeId] = append(docs[pkrangeId], map[string]interface{}{"id": fmt.Sprintf("%s-%03d", pkrangeId, i), "n": i, "_rid": "rid"})
+		}
+	}
+	fs := _newFakeQueryServer(`{"queryInfo":{"distinctType":"None"}}`, docs)
+	server := httptest.NewServer(fs)
+	defer server.Close()
+	db, err := sql.Open("gocosmos", "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+";DefaultDb=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	dbRows, err := db.Query("SELECT * FROM c WITH collection=mytable")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer dbRows.Close()
+	if cols, _ := dbRows.Columns(); !reflect.DeepEqual(cols, []string{"id", "n"}) {
+		t.Fatalf("%s failed: expected columns %#v but received %#v", testName, []string{"id", "n"}, cols)
+	}
+	if fs.pages() != 1 {
+		t.Fatalf("%s failed: expected %#v page fetched but received %#v", testName, 1, fs.pages())
+	}
+	numRows := 0
+	for dbRows.Next() {
+		var id string
+		var n float64
+		if err := dbRows.Scan(&id, &n); err != nil {
+			t.Fatalf("%s failed: %s", testName, err)
+		}
+		numRows++
+		if numRows == 150 && fs.pages() != 2 {
+			t.Fatalf("%s failed: expected %#v pages fetched but received %#v", testName, 2, fs.pages())
+		}
+	}
+	if err := dbRows.Err(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows != 500 {
+		t.Fatalf("%s failed: expected %#v rows but received %#v", testName, 500, numRows)
+	}
+}
@@ -1,9 +1,12 @@
 package gocosmos_test
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -884,3 +887,34 @@ func TestStmtUpdate_WithPK_SubPartitions(t *testing.T) {
 		})
 	}
 }
+
+func TestStmtUpdate_Exec_Patch(t *testing.T) {
+	testName := "TestStmtUpdate_Exec_Patch"
+	requests := make([]recordedRequest, 0)
+	server := _newRecordingServer(200, `{"id":"myid","pk":"mypk"}`, &requests)
+	defer server.Close()
+	db, err := sql.Open("gocosmos", "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+";DefaultDb=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	dbResult, err := db.Exec(`UPDATE mytable SET a=1, b="\"a string\"", c=:2 WHERE id=@1 AND pk=$3`, "myid", true, "mypk")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if numRows, err := dbResult.RowsAffected(); err != nil || numRows != 1 {
+		t.Fatalf("%s failed: expected 1 row affected but received %#v/%s", testName, numRows, err)
+	}
+	if len(requests) != 1 || requests[0].Method != "PATCH" {
+		t.Fatalf("%s failed: expected a single PATCH request but received %#v", testName, requests)
+	}
+	expected := []interface{}{
+		map[string]interface{}{"op": "set", "path": "/a", "value": 1.0},
+		map[string]interface{}{"op": "set", "path": "/b", "value": "a string"},
+		map[string]interface{}{"op": "set", "path": "/c", "value": true},
+	}
+	if !reflect.DeepEqual(requests[0].Body["operations"], expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, expected, requests[0].Body["operations"])
+	}
+}
@@ -0,0 +1,232 @@
+package gocosmos_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+)
+
+// _newSprocServer creates a fake server that stores procedures of collection "mydb/mytable" in memory. Procedure
+// "echo" returns its parameters, procedure "get" returns the object it receives as first parameter and procedure
+// "count" returns the number of its parameters.
+func _newSprocServer(requests *[]recordedRequest) *httptest.Server {
+	var lock sync.Mutex
+	sprocs := make(map[string]string)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := recordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone(), RawBody: body}
+		_ = json.Unmarshal(body, &req.Body)
+		lock.Lock()
+		defer lock.Unlock()
+		*requests = append(*requests, req)
+		write := func(status int, v interface{}) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			data, _ := json.Marshal(v)
+			_, _ = w.Write(data)
+		}
+		const prefix = "/dbs/mydb/colls/mytable/sprocs"
+		id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		switch {
+		case !strings.HasPrefix(r.URL.Path, prefix):
+			write(404, map[string]interface{}{"code": "NotFound"})
+		case r.Method == "GET" && id == "":
+			list := make([]map[string]interface{}, 0)
+			for k, v := range sprocs {
+				list = append(list, map[string]interface{}{"id": k, "body": v, "_rid": "rid-" + k})
+			}
+			write(200, map[string]interface{}{"_count": len(list), "StoredProcedures": list})
+		case r.Method == "POST" && id == "":
+			sprocId, _ := req.Body["id"].(string)
+			if _, ok := sprocs[sprocId]; ok {
+				write(409, map[string]interface{}{"code": "Conflict"})
+				return
+			}
+			sprocs[sprocId], _ = req.Body["body"].(string)
+			write(201, map[string]interface{}{"id": sprocId, "_rid": "rid-" + sprocId})
+		case r.Method == "PUT":
+			sprocs[id], _ = req.Body["body"].(string)
+			write(200, map[string]interface{}{"id": id, "_rid": "rid-" + id})
+		case r.Method == "DELETE":
+			if _, ok := sprocs[id]; !ok {
+				write(404, map[string]interface{}{"code": "NotFound"})
+				return
+			}
+			delete(sprocs, id)
+			w.WriteHeader(204)
+		case r.Method == "POST":
+			var params []interface{}
+			_ = json.Unmarshal(body, &params)
+			switch id {
+			case "echo":
+				write(200, params)
+			case "get":
+				write(200, params[0])
+			case "count":
+				write(200, len(params))
+			default:
+				write(404, map[string]interface{}{"code": "NotFound"})
+			}
+		}
+	}))
+}
+
+func TestStmtProcedures(t *testing.T) {
+	testName := "TestStmtProcedures"
+	var requests []recordedRequest
+	server := _newSprocServer(&requests)
+	defer server.Close()
+	db, err := sql.Open("gocosmos", "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+";DefaultDb=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(`CREATE PROCEDURE mytable.echo AS 'function () { return ''v1''; }'`); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create", err)
+	}
+	if body := requests[0].Body["body"]; body != "function () { return 'v1'; }" {
+		t.Fatalf("%s failed: unexpected procedure body %#v", testName+"/create", body)
+	}
+	if _, err := db.Exec(`CREATE PROCEDURE mytable.echo AS 'function () {}'`); !errors.Is(err, gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: expected ErrConflict but received %#v", testName+"/create", err)
+	}
+	if _, err := db.Exec(`CREATE OR REPLACE PROCEDURE mydb.mytable.echo AS 'function () { return ''v2''; }'`); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_or_replace", err)
+	}
+	if req := requests[len(requests)-1]; req.Method != "PUT" || req.Body["body"] != "function () { return 'v2'; }" {
+		t.Fatalf("%s failed: unexpected request %s %#v", testName+"/create_or_replace", req.Method, req.Body)
+	}
+
+	rows, err := db.Query(`LIST PROCEDURES FROM mytable`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/list", err)
+	}
+	var id, body string
+	var rid, self, etag interface{}
+	var ts interface{}
+	numRows := 0
+	for rows.Next() {
+		if err := rows.Scan(&id, &body, &rid, &ts, &self, &etag); err != nil {
+			t.Fatalf("%s failed: %s", testName+"/list", err)
+		}
+		numRows++
+	}
+	if numRows != 1 || id != "echo" || body != "function () { return 'v2'; }" {
+		t.Fatalf("%s failed: unexpected procedures %d / %q / %q", testName+"/list", numRows, id, body)
+	}
+
+	if _, err := db.Exec(`DROP PROCEDURE mytable.echo`); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/drop", err)
+	}
+	if _, err := db.Exec(`DROP PROCEDURE mytable.echo`); !errors.Is(err, gocosmos.ErrNotFound) {
+		t.Fatalf("%s failed: expected ErrNotFound but received %#v", testName+"/drop", err)
+	}
+	if _, err := db.Exec(`DROP PROCEDURE IF EXISTS mytable.echo`); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/drop_if_exists", err)
+	}
+}
+
+func TestStmtExecProcedure(t *testing.T) {
+	testName := "TestStmtExecProcedure"
+	var requests []recordedRequest
+	server := _newSprocServer(&requests)
+	defer server.Close()
+	db, err := sql.Open("gocosmos", "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+";DefaultDb=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	testData := []struct {
+		name     string
+		sql      string
+		args     []interface{}
+		columns  []string
+		rows     [][]interface{}
+		params   []interface{}
+		pkHeader string
+	}{
+		{
+			name:     "array",
+			sql:      `EXEC mytable.echo("{\"id\":\"a\",\"v\":1}", :1) WITH PK=:2`,
+			args:     []interface{}{map[string]interface{}{"id": "b", "v": 2}, "user1"},
+			columns:  []string{"id", "v"},
+			rows:     [][]interface{}{{"a", 1.0}, {"b", 2.0}},
+			params:   []interface{}{map[string]interface{}{"id": "a", "v": 1.0}, map[string]interface{}{"id": "b", "v": 2.0}},
+			pkHeader: `["user1"]`,
+		},
+		{
+			name:     "object",
+			sql:      `EXECUTE mydb.mytable.get(:1) WITH PK=user1,2`,
+			args:     []interface{}{map[string]interface{}{"id": "a", "v": true}},
+			columns:  []string{"id", "v"},
+			rows:     [][]interface{}{{"a", true}},
+			params:   []interface{}{map[string]interface{}{"id": "a", "v": true}},
+			pkHeader: `["user1",2]`,
+		},
+		{
+			name:     "scalar",
+			sql:      `EXEC mytable.count(1, true, null) WITH PK=user1`,
+			columns:  []string{"$1"},
+			rows:     [][]interface{}{{3.0}},
+			params:   []interface{}{1.0, true, nil},
+			pkHeader: `["user1"]`,
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbRows, err := db.Query(testCase.sql, testCase.args...)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			columns, _ := dbRows.Columns()
+			if !reflect.DeepEqual(columns, testCase.columns) {
+				t.Fatalf("%s failed: expected columns %#v but received %#v", testName+"/"+testCase.name, testCase.columns, columns)
+			}
+			rows := make([][]interface{}, 0)
+			for dbRows.Next() {
+				row := make([]interface{}, len(columns))
+				ptrs := make([]interface{}, len(columns))
+				for i := range row {
+					ptrs[i] = &row[i]
+				}
+				if err := dbRows.Scan(ptrs...); err != nil {
+					t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+				}
+				rows = append(rows, row)
+			}
+			if !reflect.DeepEqual(rows, testCase.rows) {
+				t.Fatalf("%s failed: expected rows %#v but received %#v", testName+"/"+testCase.name, testCase.rows, rows)
+			}
+			req := requests[len(requests)-1]
+			var params []interface{}
+			_ = json.Unmarshal(req.RawBody, &params)
+			if !reflect.DeepEqual(params, testCase.params) || req.Header.Get("x-ms-documentdb-partitionkey") != testCase.pkHeader {
+				t.Fatalf("%s failed: unexpected request %#v / %#v", testName+"/"+testCase.name, params, req.Header)
+			}
+		})
+	}
+
+	if result, err := db.Exec(`EXEC mytable.count() WITH PK=user1`); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/exec", err)
+	} else if numRows, err := result.RowsAffected(); err != nil || numRows != 1 {
+		t.Fatalf("%s failed: expected 1 row affected but received %#v/%s", testName+"/exec", numRows, err)
+	}
+	if _, err := db.Query(`EXEC mytable.notfound() WITH PK=user1`); !errors.Is(err, gocosmos.ErrNotFound) {
+		t.Fatalf("%s failed: expected ErrNotFound but received %#v", testName+"/not_found", err)
+	}
+	if _, err := db.Query(`EXEC mytable.echo(:1) WITH PK=user1`); err == nil {
+		t.Fatalf("%s failed: expected error for missing argument", testName+"/num_inputs")
+	}
+}
@@ -0,0 +1,70 @@
+package gocosmos_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/microsoft/gocosmos/gocosmostest"
+)
+
+func TestStmtTriggersAndFunctions(t *testing.T) {
+	testName := "TestStmtTriggersAndFunctions"
+	var requests []recordedRequest
+	server := _newRecordingServer(200, `{"id":"myid","_rid":"rid","_count":1,"Triggers":[{"id":"mytrigger","triggerType":"Post","triggerOperation":"Delete"}],"UserDefinedFunctions":[{"id":"myudf","body":"function () {}"}]}`, &requests)
+	defer server.Close()
+	db, err := sql.Open("gocosmos", "AccountEndpoint="+server.URL+";AccountKey="+gocosmostest.DefaultAccountKey+";DefaultDb=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer db.Close()
+
+	testData := []struct {
+		name   string
+		sql    string
+		method string
+		path   string
+		body   map[string]interface{}
+	}{
+		{name: "create_trigger", sql: `CREATE TRIGGER mytable.mytrigger POST ON DELETE AS 'function () { return ''x''; }'`, method: "POST", path: "/dbs/mydb/colls/mytable/triggers",
+			body: map[string]interface{}{"id": "mytrigger", "body": "function () { return 'x'; }", "triggerType": "Post", "triggerOperation": "Delete"}},
+		{name: "drop_trigger", sql: `DROP TRIGGER IF EXISTS mydb.mytable.mytrigger`, method: "DELETE", path: "/dbs/mydb/colls/mytable/triggers/mytrigger"},
+		{name: "create_function", sql: `CREATE FUNCTION mytable.myudf AS 'function (x) { return x * 2; }'`, method: "POST", path: "/dbs/mydb/colls/mytable/udfs",
+			body: map[string]interface{}{"id": "myudf", "body": "function (x) { return x * 2; }"}},
+		{name: "drop_function", sql: `DROP FUNCTION mytable.myudf`, method: "DELETE", path: "/dbs/mydb/colls/mytable/udfs/myudf"},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, err := db.Exec(testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if numRows, err := result.RowsAffected(); err != nil || numRows != 1 {
+				t.Fatalf("%s failed: expected 1 row affected but received %#v/%s", testName+"/"+testCase.name, numRows, err)
+			}
+			req := requests[len(requests)-1]
+			if req.Method != testCase.method || req.Path != testCase.path {
+				t.Fatalf("%s failed: expected request %s %s but received %s %s", testName+"/"+testCase.name, testCase.method, testCase.path, req.Method, req.Path)
+			}
+			for k, v := range testCase.body {
+				if req.Body[k] != v {
+					t.Fatalf("%s failed: expected %s=%#v but received %#v", testName+"/"+testCase.name, k, v, req.Body[k])
+				}
+			}
+		})
+	}
+
+	var id, body, triggerType, triggerOperation string
+	var rid, ts, self, etag interface{}
+	if err := db.QueryRow(`LIST TRIGGERS FROM mytable`).Scan(&id, &body, &triggerType, &triggerOperation, &rid, &ts, &self, &etag); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/list_triggers", err)
+	}
+	if id != "mytrigger" || triggerType != "Post" || triggerOperation != "Delete" || requests[len(requests)-1].Path != "/dbs/mydb/colls/mytable/triggers" {
+		t.Fatalf("%s failed: unexpected trigger %q/%q/%q", testName+"/list_triggers", id, triggerType, triggerOperation)
+	}
+	if err := db.QueryRow(`LIST FUNCTIONS FROM mydb.mytable`).Scan(&id, &body, &rid, &ts, &self, &etag); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/list_functions", err)
+	}
+	if id != "myudf" || body != "function () {}" || requests[len(requests)-1].Path != "/dbs/mydb/colls/mytable/udfs" {
+		t.Fatalf("%s failed: unexpected function %q/%q", testName+"/list_functions", id, body)
+	}
+}
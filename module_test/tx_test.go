@@ -0,0 +1,147 @@
+package gocosmos_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/microsoft/gocosmos"
+	"github.com/microsoft/gocosmos/gocosmostest"
+)
+
+func _openTxTestDb(t *testing.T, testName, endpoint string) *sql.DB {
+	db, err := sql.Open("gocosmos", "AccountEndpoint="+endpoint+";AccountKey="+gocosmostest.DefaultAccountKey+";DefaultDb=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	db.SetMaxOpenConns(1)
+	return db
+}
+
+func TestTx_Commit(t *testing.T) {
+	testName := "TestTx_Commit"
+	requests := make([]recordedRequest, 0)
+	respBody := `[{"statusCode":201,"resourceBody":{"id":"1","_rid":"rid1"}},{"statusCode":200},{"statusCode":204}]`
+	server := _newRecordingServer(200, respBody, &requests)
+	defer server.Close()
+	db := _openTxTestDb(t, testName, server.URL)
+	defer db.Close()
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	insResult, err := tx.Exec(`INSERT INTO mytable (id, pk, a) VALUES (:1, :2, :3) WITH PK=/pk`, "1", "mypk", 1)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if _, err := tx.Exec(`UPDATE mytable SET a=:2 WHERE id=:1 AND pk=:3`, "2", 2, "mypk"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	delResult, err := tx.Exec(`DELETE FROM mytable WHERE id=:1 AND pk=:2`, "3", "mypk")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("%s failed: no request expected before commit but received %#v", testName, len(requests))
+	}
+	if _, err := delResult.RowsAffected(); !errors.Is(err, gocosmos.ErrTxNotCommitted) {
+		t.Fatalf("%s failed: expected ErrTxNotCommitted but received %s", testName, err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	if numRows, err := delResult.RowsAffected(); err != nil || numRows != 1 {
+		t.Fatalf("%s failed: expected 1 row affected but received %#v/%s", testName, numRows, err)
+	}
+	if _, err := insResult.LastInsertId(); err == nil || err.Error() != `{"last_insert_id":"rid1"}` {
+		t.Fatalf("%s failed: unexpected last insert id %s", testName, err)
+	}
+	if len(requests) != 1 || requests[0].Header.Get("x-ms-cosmos-is-batch-request") != "True" {
+		t.Fatalf("%s failed: expected a single batch request but received %#v", testName, requests)
+	}
+	var body []map[string]interface{}
+	_ = json.Unmarshal(requests[0].RawBody, &body)
+	var opTypes []interface{}
+	for _, op := range body {
+		opTypes = append(opTypes, op["operationType"])
+	}
+	if expected := []interface{}{"Create", "Patch", "Delete"}; !reflect.DeepEqual(opTypes, expected) {
+		t.Fatalf("%s failed: expected operations %#v but received %#v", testName, expected, opTypes)
+	}
+	if v := requests[0].Header.Get("x-ms-documentdb-partitionkey"); v != `["mypk"]` {
+		t.Fatalf("%s failed: unexpected partition key %#v", testName, v)
+	}
+}
+
+func TestTx_CommitFailed(t *testing.T) {
+	testName := "TestTx_CommitFailed"
+	requests := make([]recordedRequest, 0)
+	server := _newRecordingServer(409, `[{"statusCode":424},{"statusCode":409}]`, &requests)
+	defer server.Close()
+	db := _openTxTestDb(t, testName, server.URL)
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	result, err := tx.Exec(`UPSERT INTO mytable (id, pk) VALUES (:1, :2) WITH PK=/pk`, "1", "mypk")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO mytable (id, pk) VALUES (:1, :2) WITH PK=/pk`, "2", "mypk"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if err := tx.Commit(); !errors.Is(err, gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: expected ErrConflict but received %s", testName, err)
+	}
+	if _, err := result.RowsAffected(); !errors.Is(err, gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: expected ErrConflict but received %s", testName, err)
+	}
+}
+
+func TestTx_CrossPartition(t *testing.T) {
+	testName := "TestTx_CrossPartition"
+	requests := make([]recordedRequest, 0)
+	server := _newRecordingServer(200, `[]`, &requests)
+	defer server.Close()
+	db := _openTxTestDb(t, testName, server.URL)
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM mytable WHERE id=:1 AND pk=:2`, "1", "pk1"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM mytable WHERE id=:1 AND pk=:2`, "2", "pk2"); !errors.Is(err, gocosmos.ErrTxCrossPartition) {
+		t.Fatalf("%s failed: expected ErrTxCrossPartition but received %s", testName, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM othertable WHERE id=:1 AND pk=:2`, "2", "pk1"); !errors.Is(err, gocosmos.ErrTxCrossPartition) {
+		t.Fatalf("%s failed: expected ErrTxCrossPartition but received %s", testName, err)
+	}
+	// all rows of a multi-row INSERT must belong to the same partition
+	if _, err := tx.Exec(`INSERT INTO mytable (id, pk) VALUES (:1, :2), (:3, :4) WITH PK=/pk`, "3", "pk1", "4", "pk2"); !errors.Is(err, gocosmos.ErrTxCrossPartition) {
+		t.Fatalf("%s failed: expected ErrTxCrossPartition but received %s", testName, err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("%s failed: no request expected but received %#v", testName, len(requests))
+	}
+
+	// statements executed after the transaction ended are sent to server immediately
+	if _, err := db.Exec(`DELETE FROM mytable WHERE id=:1 AND pk=:2`, "1", "pk1"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(requests) != 1 || requests[0].Method != "DELETE" {
+		t.Fatalf("%s failed: expected a single DELETE request but received %#v", testName, requests)
+	}
+}
@@ -2,19 +2,20 @@ package gocosmos
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
+	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/btnguyen2k/consu/checksum"
@@ -26,10 +27,16 @@ import (
 const (
 	settingEndpoint           = "ACCOUNTENDPOINT"
 	settingAccountKey         = "ACCOUNTKEY"
+	settingAadToken           = "AADTOKEN"
+	settingTokenProvider      = "TOKENPROVIDER"
+	settingResourceToken      = "RESOURCETOKEN"
 	settingTimeout            = "TIMEOUTMS"
 	settingVersion            = "VERSION"
 	settingAutoId             = "AUTOID"
 	settingInsecureSkipVerify = "INSECURESKIPVERIFY"
+	settingMaxRetries         = "MAXRETRIES"
+	settingMaxRetryWait       = "MAXRETRYWAITMS"
+	settingMaxDop             = "MAXDEGREEOFPARALLELISM"
 
 	// DefaultApiVersion holds the default REST API version if not specified in the connection string.
 	//
@@ -37,6 +44,19 @@ const (
 	//
 	// @Available since v0.3.0
 	DefaultApiVersion = "2020-07-15"
+
+	// DefaultMaxRetries holds the default maximum number of retries per request if not specified in the connection string.
+	//
+	// @Available since v1.2.0
+	DefaultMaxRetries = 9
+
+	// DefaultMaxRetryWaitMs holds the default maximum total wait time (in milliseconds) between retries of a request if
+	// not specified in the connection string.
+	//
+	// @Available since v1.2.0
+	DefaultMaxRetryWaitMs = 30000
+
+	defaultRetryBackoff = 100 * time.Millisecond
 )
 
 // NewRestClient constructs a new RestClient instance from the supplied connection string.
@@ -44,13 +64,30 @@ const (
 // httpClient is reused if supplied. Otherwise, a new http.Client instance is created.
 // connStr is expected to be in the following format:
 //
-//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>]
+//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>|AadToken=<access-token>|TokenProvider=<name>|ResourceToken=<url-encoded-resource-token>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>][;MaxRetries=<max-retries>][;MaxRetryWaitMs=<max-retry-wait-in-ms>][;MaxDegreeOfParallelism=<n>]
 //
-// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, and InsecureSkipVerify is false
+// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, InsecureSkipVerify is false,
+// MaxRetries is DefaultMaxRetries, MaxRetryWaitMs is DefaultMaxRetryWaitMs and MaxDegreeOfParallelism is 1.
 //
 // - AutoId is added since v0.1.2
 // - InsecureSkipVerify is added since v0.1.4
+// - MaxRetries and MaxRetryWaitMs are added since v1.2.0
+// - MaxDegreeOfParallelism is added since v1.2.0: max number of partition key ranges queried concurrently by cross-partition queries, negative value means no limit
+// - AadToken and TokenProvider are added since v1.2.0: authenticate with a Microsoft Entra ID (AAD) access token, or with
+// tokens obtained from a provider registered via RegisterTokenProvider, instead of an account key.
+// - ResourceToken is added since v1.2.0: authenticate with a (URL-encoded) resource token, see ResourceTokenCredential.
+//
+// Exactly one of AccountKey, AadToken, TokenProvider or ResourceToken must be supplied.
 func NewRestClient(httpClient *http.Client, connStr string) (*RestClient, error) {
+	return NewRestClientWithCredential(httpClient, connStr, nil)
+}
+
+// NewRestClientWithCredential is similar to NewRestClient but authenticates requests with the supplied credential.
+// If credential is not nil, the AccountKey, AadToken, TokenProvider and ResourceToken settings of the connection string
+// are ignored.
+//
+// @Available since v1.2.0
+func NewRestClientWithCredential(httpClient *http.Client, connStr string, credential Credential) (*RestClient, error) {
 	params := make(map[string]string)
 	parts := strings.Split(connStr, ";")
 	for _, part := range parts {
@@ -66,13 +103,11 @@ func NewRestClient(httpClient *http.Client, connStr string) (*RestClient, error)
 	if endpoint == "" {
 		return nil, errors.New("AccountEndpoint not found in connection string")
 	}
-	accountKey := params[settingAccountKey]
-	if accountKey == "" {
-		return nil, errors.New("AccountKey not found in connection string")
-	}
-	key, err := base64.StdEncoding.DecodeString(accountKey)
-	if err != nil {
-		return nil, fmt.Errorf("cannot base64 decode account key: %s", err)
+	if credential == nil {
+		var err error
+		if credential, err = credentialFromParams(params); err != nil {
+			return nil, err
+		}
 	}
 	timeoutMs, err := strconv.Atoi(params[settingTimeout])
 	if err != nil || timeoutMs < 0 {
@@ -90,6 +125,18 @@ func NewRestClient(httpClient *http.Client, connStr string) (*RestClient, error)
 	if err != nil {
 		insecureSkipVerify = false
 	}
+	maxRetries, err := strconv.Atoi(params[settingMaxRetries])
+	if err != nil || maxRetries < 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	maxRetryWaitMs, err := strconv.Atoi(params[settingMaxRetryWait])
+	if err != nil || maxRetryWaitMs < 0 {
+		maxRetryWaitMs = DefaultMaxRetryWaitMs
+	}
+	maxDop, err := strconv.Atoi(params[settingMaxDop])
+	if err != nil || maxDop == 0 {
+		maxDop = 1
+	}
 	if httpClient == nil {
 		httpClient = &http.Client{
 			Timeout:   time.Duration(timeoutMs) * time.Millisecond,
@@ -99,24 +146,59 @@ func NewRestClient(httpClient *http.Client, connStr string) (*RestClient, error)
 	return &RestClient{
 		client:     gjrc.NewGjrc(httpClient, time.Duration(timeoutMs)*time.Millisecond),
 		endpoint:   endpoint,
-		authKey:    key,
+		credential: credential,
 		apiVersion: apiVersion,
 		autoId:     autoId,
 		params:     params,
+		maxDop:     maxDop,
+		retryPolicy: RetryPolicy{
+			MaxRetries:   maxRetries,
+			MaxRetryWait: time.Duration(maxRetryWaitMs) * time.Millisecond,
+			BaseBackoff:  defaultRetryBackoff,
+		},
 	}, nil
 }
 
 // RestClient is REST-based client for Azure Cosmos DB
 type RestClient struct {
-	client     *gjrc.Gjrc
-	endpoint   string            // Azure Cosmos DB endpoint
-	authKey    []byte            // Account key to authenticate
-	apiVersion string            // Azure Cosmos DB API version
-	autoId     bool              // if true and value for 'id' field is not specified, CreateDocument will automatically generate a new id for document
-	params     map[string]string // parsed parameters
+	client      *gjrc.Gjrc
+	endpoint    string            // Azure Cosmos DB endpoint
+	credential  Credential        // (since v1.2.0) credential to authenticate requests
+	apiVersion  string            // Azure Cosmos DB API version
+	autoId      bool              // if true and value for 'id' field is not specified, CreateDocument will automatically generate a new id for document
+	params      map[string]string // parsed parameters
+	retryPolicy RetryPolicy       // (since v1.2.0) how failed requests are retried
+	maxDop      int               // (since v1.2.0) default max number of partition key ranges queried concurrently, negative value means no limit
+}
+
+// RetryPolicy specifies how RestClient retries requests that fail with a throttling (429) or "retry with" (449) status,
+// or with a transient error (408, 503 or connection reset). Requests failing with a transient error are retried only
+// if they are idempotent (reads, queries, replaces and deletes): a create, upsert, patch, batch or stored procedure
+// execution may have been applied by the server before the connection was dropped or the request timed out.
+//
+// Throttled requests are retried after the delay suggested by the server (response header x-ms-retry-after-ms),
+// other requests are retried with exponential backoff.
+//
+// @Available since v1.2.0
+type RetryPolicy struct {
+	MaxRetries   int           // maximum number of retries per request, 0 disables retrying
+	MaxRetryWait time.Duration // maximum total time waiting between retries of a request, 0 means no limit
+	BaseBackoff  time.Duration // delay before the first retry when the server does not suggest one, doubled after each retry
 }
 
-func (c *RestClient) buildJsonRequest(method, url string, params interface{}) (*http.Request, error) {
+// resourceUrl builds the URL of a resource from its link, e.g. "dbs/my db/colls/mycoll". Each id of the link is
+// URL-escaped, as resource ids may contain spaces or other characters not allowed in a URL path.
+//
+// @Available since v1.2.0
+func (c *RestClient) resourceUrl(resLink string) string {
+	segments := strings.Split(resLink, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return c.endpoint + "/" + strings.Join(segments, "/")
+}
+
+func (c *RestClient) buildJsonRequest(ctx context.Context, method, url string, params interface{}) (*http.Request, error) {
 	var r *bytes.Reader
 	if params != nil {
 		js, _ := json.Marshal(params)
@@ -124,7 +206,7 @@ func (c *RestClient) buildJsonRequest(method, url string, params interface{}) (*
 	} else {
 		r = bytes.NewReader([]byte{})
 	}
-	req, err := http.NewRequest(method, url, r)
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
 	if err != nil {
 		return nil, err
 	}
@@ -134,23 +216,52 @@ func (c *RestClient) buildJsonRequest(method, url string, params interface{}) (*
 	return req, nil
 }
 
-func (c *RestClient) addAuthHeader(req *http.Request, method, resType, resId string) *http.Request {
+// credentialFromParams builds the credential specified by the connection string's settings.
+func credentialFromParams(params map[string]string) (Credential, error) {
+	accountKey, aadToken, providerName := params[settingAccountKey], params[settingAadToken], params[settingTokenProvider]
+	resourceToken := params[settingResourceToken]
+	numCredentials := 0
+	for _, v := range []string{accountKey, aadToken, providerName, resourceToken} {
+		if v != "" {
+			numCredentials++
+		}
+	}
+	switch {
+	case numCredentials == 0:
+		return nil, errors.New("AccountKey not found in connection string")
+	case numCredentials > 1:
+		return nil, errors.New("only one of AccountKey, AadToken, TokenProvider or ResourceToken can be specified in connection string")
+	case aadToken != "":
+		return NewStaticTokenCredential(aadToken), nil
+	case providerName != "":
+		provider := lookupTokenProvider(providerName)
+		if provider == nil {
+			return nil, fmt.Errorf("token provider %q has not been registered", providerName)
+		}
+		return NewTokenCredential(provider), nil
+	case resourceToken != "":
+		// resource tokens contain ';' characters, hence they must be URL-encoded in connection strings
+		token, err := url.QueryUnescape(resourceToken)
+		if err != nil {
+			return nil, fmt.Errorf("cannot URL decode resource token: %s", err)
+		}
+		return NewResourceTokenCredential(token), nil
+	}
+	return NewMasterKeyCredential(accountKey)
+}
+
+// addAuthHeader signs the request. resId is the link of the resource as-is, i.e. _not_ URL-escaped (see resourceUrl).
+// An error is returned if the credential fails to compute the authorization header (e.g. an access token can not be obtained).
+func (c *RestClient) addAuthHeader(req *http.Request, method, resType, resId string) (*http.Request, error) {
 	now := time.Now().In(locGmt)
-	/*
-	 * M.A.I. 2022-02-16
-	 * The original statement had a single ToLower. In the resulting string the resId gets lowered when from MS Docs it should be left unaltered
-	 * I came across an error on a collection with a mixed case name...
-	 * stringToSign := strings.ToLower(fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", method, resType, resId, now.Format(time.RFC1123), ""))
-	 */
-	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", strings.ToLower(method), strings.ToLower(resType), resId, strings.ToLower(now.Format(time.RFC1123)), "")
-	h := hmac.New(sha256.New, c.authKey)
-	h.Write([]byte(stringToSign))
-	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
-	authHeader := "type=master&ver=1.0&sig=" + signature
+	authHeader, err := c.credential.AuthHeader(req.Context(), method, resType, resId, now)
+	if err != nil {
+		return nil, err
+	}
 	authHeader = url.QueryEscape(authHeader)
 	req.Header.Set(httpHeaderAuthorization, authHeader)
 	req.Header.Set(restApiHeaderDate, now.Format(time.RFC1123))
-	return req
+	return req, nil
 }
 
 func (c *RestClient) buildRestResponse(resp *gjrc.GjrcResponse) RestResponse {
@@ -171,7 +282,7 @@ func (c *RestClient) buildRestResponse(resp *gjrc.GjrcResponse) RestResponse {
 			}
 		}
 		if result.CallErr != nil {
-			result.CallErr = fmt.Errorf("status-code: %d / error: %s / response-body: %s", result.StatusCode, result.CallErr, result.RespBody)
+			result.CallErr = fmt.Errorf("status-code: %d / error: %w / response-body: %s", result.StatusCode, result.CallErr, result.RespBody)
 		}
 	}
 	if result.CallErr == nil {
@@ -196,6 +307,139 @@ func (c *RestClient) buildRestResponse(resp *gjrc.GjrcResponse) RestResponse {
 	return result
 }
 
+// backoffDelay returns the exponential backoff delay before the next retry, given the number of retries so far.
+func (c *RestClient) backoffDelay(numRetries int) time.Duration {
+	backoff := c.retryPolicy.BaseBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	if numRetries < 16 {
+		return backoff << numRetries
+	}
+	return backoff << 16
+}
+
+// isIdempotentRequest returns true if sending the request more than once has the same effect as sending it once.
+// Queries are sent with method POST but do not modify any resource.
+func isIdempotentRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return strings.EqualFold(req.Header.Get(restApiHeaderIsQuery), "true") ||
+			strings.EqualFold(req.Header.Get(restApiHeaderIsQueryPlanRequest), "true")
+	}
+	return false
+}
+
+// retryDelay determines if a request should be retried given its response, and how long to wait before retrying.
+func (c *RestClient) retryDelay(req *http.Request, resp *gjrc.GjrcResponse, numRetries int) (time.Duration, bool) {
+	backoff := c.backoffDelay(numRetries)
+	httpResp := resp.HttpResponse()
+	if httpResp == nil {
+		// the request did not reach the server or the connection was dropped: a non-idempotent request may have been
+		// applied by the server, retrying it could write twice
+		err := resp.Error()
+		return backoff, isIdempotentRequest(req) &&
+			(errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF))
+	}
+	switch httpResp.StatusCode {
+	case 429, 449:
+		// the request was rejected before being processed, it is safe to retry whatever the request
+		if ms, err := strconv.ParseFloat(httpResp.Header.Get(respHeaderRetryAfterMs), 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+		return backoff, true
+	case 408, 503:
+		// the request timed out or the server was unavailable while processing it: a non-idempotent request may have
+		// been applied by the server
+		return backoff, isIdempotentRequest(req)
+	}
+	return 0, false
+}
+
+// execRequest signs and sends the request and builds the response, retrying the request according to the client's
+// retry policy. resType and resId identify the requested resource (see addAuthHeader). The request is signed again
+// before each attempt, so that a retry does not send a stale date or an expired access token.
+//
+// @Available since v1.2.0
+func (c *RestClient) execRequest(req *http.Request, resType, resId string) RestResponse {
+	numRetries, totalWait := 0, time.Duration(0)
+	for {
+		if req.GetBody != nil {
+			// the request body may have been consumed by a previous call
+			if body, err := req.GetBody(); err == nil {
+				req.Body = body
+			}
+		}
+		if _, err := c.addAuthHeader(req, req.Method, resType, resId); err != nil {
+			return RestResponse{CallErr: err, RetryCount: numRetries, RetryWait: totalWait}
+		}
+		resp := c.client.Do(req)
+		wait, retry := c.retryDelay(req, resp, numRetries)
+		retry = retry && numRetries < c.retryPolicy.MaxRetries
+		retry = retry && (c.retryPolicy.MaxRetryWait <= 0 || totalWait+wait <= c.retryPolicy.MaxRetryWait)
+		if retry {
+			timer := time.NewTimer(wait)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				retry = false
+			case <-timer.C:
+			}
+		}
+		if !retry {
+			result := c.buildRestResponse(resp)
+			if err := req.Context().Err(); err != nil && result.CallErr == nil {
+				result.CallErr = err
+			}
+			result.RetryCount, result.RetryWait = numRetries, totalWait
+			if cred, ok := c.credential.(*TokenCredential); ok && result.StatusCode == 401 {
+				// the access token may have been revoked, obtain a new one for the next request
+				cred.Invalidate()
+			}
+			return result
+		}
+		numRetries++
+		totalWait += wait
+	}
+}
+
+// GetRetryPolicy returns the retry policy applied to requests sent by this client.
+//
+// @Available since v1.2.0
+func (c *RestClient) GetRetryPolicy() RetryPolicy {
+	return c.retryPolicy
+}
+
+// SetRetryPolicy sets the retry policy applied to requests sent by this client.
+//
+// @Available since v1.2.0
+func (c *RestClient) SetRetryPolicy(policy RetryPolicy) *RestClient {
+	c.retryPolicy = policy
+	return c
+}
+
+// GetMaxDegreeOfParallelism returns the default max number of partition key ranges queried concurrently by
+// cross-partition queries (used if QueryReq.MaxDegreeOfParallelism is not specified).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetMaxDegreeOfParallelism() int {
+	return c.maxDop
+}
+
+// SetMaxDegreeOfParallelism sets the default max number of partition key ranges queried concurrently by
+// cross-partition queries. Value 0 or 1 means partition key ranges are queried sequentially, negative value means no limit.
+//
+// @Available since v1.2.0
+func (c *RestClient) SetMaxDegreeOfParallelism(value int) *RestClient {
+	if value == 0 {
+		value = 1
+	}
+	c.maxDop = value
+	return c
+}
+
 // GetApiVersion returns the Azure Cosmos DB APi version string, either from connection string or default value.
 //
 // @Available since v1.0.0
@@ -232,12 +476,18 @@ type DatabaseSpec struct {
 //
 // Note: ru and maxru must not be supplied together!
 func (c *RestClient) CreateDatabase(spec DatabaseSpec) *RespCreateDb {
-	method, urlEndpoint := "POST", c.endpoint+"/dbs"
-	req, err := c.buildJsonRequest(method, urlEndpoint, map[string]interface{}{"id": spec.Id})
+	return c.CreateDatabaseContext(context.Background(), spec)
+}
+
+// CreateDatabaseContext is similar to CreateDatabase but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateDatabaseContext(ctx context.Context, spec DatabaseSpec) *RespCreateDb {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"id": spec.Id})
 	if err != nil {
 		return &RespCreateDb{RestResponse: RestResponse{CallErr: err}, DbInfo: DbInfo{Id: spec.Id}}
 	}
-	req = c.addAuthHeader(req, method, "dbs", "")
 	if spec.Ru > 0 {
 		req.Header.Set(restApiHeaderOfferThroughput, strconv.Itoa(spec.Ru))
 	}
@@ -245,8 +495,7 @@ func (c *RestClient) CreateDatabase(spec DatabaseSpec) *RespCreateDb {
 		req.Header.Set(restApiHeaderOfferAutopilotSettings, fmt.Sprintf(`{"maxThroughput":%d}`, spec.MaxRu))
 	}
 
-	resp := c.client.Do(req)
-	result := &RespCreateDb{RestResponse: c.buildRestResponse(resp), DbInfo: DbInfo{Id: spec.Id}}
+	result := &RespCreateDb{RestResponse: c.execRequest(req, "dbs", ""), DbInfo: DbInfo{Id: spec.Id}}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DbInfo))
 	}
@@ -257,15 +506,20 @@ func (c *RestClient) CreateDatabase(spec DatabaseSpec) *RespCreateDb {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/get-a-database.
 func (c *RestClient) GetDatabase(dbName string) *RespGetDb {
-	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+dbName
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.GetDatabaseContext(context.Background(), dbName)
+}
+
+// GetDatabaseContext is similar to GetDatabase but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetDatabaseContext(ctx context.Context, dbName string) *RespGetDb {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespGetDb{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "dbs", "dbs/"+dbName)
 
-	resp := c.client.Do(req)
-	result := &RespGetDb{RestResponse: c.buildRestResponse(resp)}
+	result := &RespGetDb{RestResponse: c.execRequest(req, "dbs", "dbs/"+dbName)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DbInfo))
 	}
@@ -276,15 +530,20 @@ func (c *RestClient) GetDatabase(dbName string) *RespGetDb {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/delete-a-database.
 func (c *RestClient) DeleteDatabase(dbName string) *RespDeleteDb {
-	method, urlEndpoint := "DELETE", c.endpoint+"/dbs/"+dbName
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.DeleteDatabaseContext(context.Background(), dbName)
+}
+
+// DeleteDatabaseContext is similar to DeleteDatabase but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteDatabaseContext(ctx context.Context, dbName string) *RespDeleteDb {
+	method, urlEndpoint := "DELETE", c.resourceUrl("dbs/"+dbName)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespDeleteDb{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "dbs", "dbs/"+dbName)
 
-	resp := c.client.Do(req)
-	result := &RespDeleteDb{RestResponse: c.buildRestResponse(resp)}
+	result := &RespDeleteDb{RestResponse: c.execRequest(req, "dbs", "dbs/"+dbName)}
 	return result
 }
 
@@ -292,15 +551,20 @@ func (c *RestClient) DeleteDatabase(dbName string) *RespDeleteDb {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/list-databases.
 func (c *RestClient) ListDatabases() *RespListDb {
-	method, urlEndpoint := "GET", c.endpoint+"/dbs"
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.ListDatabasesContext(context.Background())
+}
+
+// ListDatabasesContext is similar to ListDatabases but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListDatabasesContext(ctx context.Context) *RespListDb {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespListDb{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "dbs", "")
 
-	resp := c.client.Do(req)
-	result := &RespListDb{RestResponse: c.buildRestResponse(resp)}
+	result := &RespListDb{RestResponse: c.execRequest(req, "dbs", "")}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
 		if result.CallErr == nil {
@@ -331,7 +595,14 @@ type CollectionSpec struct {
 //
 // Note: ru and maxru must not be supplied together!
 func (c *RestClient) CreateCollection(spec CollectionSpec) *RespCreateColl {
-	method, urlEndpoint := "POST", c.endpoint+"/dbs/"+spec.DbName+"/colls"
+	return c.CreateCollectionContext(context.Background(), spec)
+}
+
+// CreateCollectionContext is similar to CreateCollection but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateCollectionContext(ctx context.Context, spec CollectionSpec) *RespCreateColl {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+spec.DbName+"/colls")
 	params := map[string]interface{}{"id": spec.CollName, "partitionKey": spec.PartitionKeyInfo}
 	if spec.IndexingPolicy != nil {
 		params[restApiParamIndexingPolicy] = spec.IndexingPolicy
@@ -339,11 +610,10 @@ func (c *RestClient) CreateCollection(spec CollectionSpec) *RespCreateColl {
 	if spec.UniqueKeyPolicy != nil {
 		params[restApiParamUniqueKeyPolicy] = spec.UniqueKeyPolicy
 	}
-	req, err := c.buildJsonRequest(method, urlEndpoint, params)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, params)
 	if err != nil {
 		return &RespCreateColl{RestResponse: RestResponse{CallErr: err}, CollInfo: CollInfo{Id: spec.CollName}}
 	}
-	req = c.addAuthHeader(req, method, "colls", "dbs/"+spec.DbName)
 	if spec.Ru > 0 {
 		req.Header.Set(restApiHeaderOfferThroughput, strconv.Itoa(spec.Ru))
 	}
@@ -351,8 +621,7 @@ func (c *RestClient) CreateCollection(spec CollectionSpec) *RespCreateColl {
 		req.Header.Set(restApiHeaderOfferAutopilotSettings, fmt.Sprintf(`{"maxThroughput":%d}`, spec.MaxRu))
 	}
 
-	resp := c.client.Do(req)
-	result := &RespCreateColl{RestResponse: c.buildRestResponse(resp), CollInfo: CollInfo{Id: spec.CollName}}
+	result := &RespCreateColl{RestResponse: c.execRequest(req, "colls", "dbs/"+spec.DbName), CollInfo: CollInfo{Id: spec.CollName}}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.CollInfo))
 	}
@@ -365,7 +634,14 @@ func (c *RestClient) CreateCollection(spec CollectionSpec) *RespCreateColl {
 //
 // Note: ru and maxru must not be supplied together!
 func (c *RestClient) ReplaceCollection(spec CollectionSpec) *RespReplaceColl {
-	method, urlEndpoint := "PUT", c.endpoint+"/dbs/"+spec.DbName+"/colls/"+spec.CollName
+	return c.ReplaceCollectionContext(context.Background(), spec)
+}
+
+// ReplaceCollectionContext is similar to ReplaceCollection but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceCollectionContext(ctx context.Context, spec CollectionSpec) *RespReplaceColl {
+	method, urlEndpoint := "PUT", c.resourceUrl("dbs/"+spec.DbName+"/colls/"+spec.CollName)
 	params := map[string]interface{}{"id": spec.CollName}
 	if spec.PartitionKeyInfo != nil {
 		params[restApiParamPartitionKey] = spec.PartitionKeyInfo
@@ -377,11 +653,10 @@ func (c *RestClient) ReplaceCollection(spec CollectionSpec) *RespReplaceColl {
 	// if spec.UniqueKeyPolicy != nil {
 	// 	params[restApiParamUniqueKeyPolicy] = spec.UniqueKeyPolicy
 	// }
-	req, err := c.buildJsonRequest(method, urlEndpoint, params)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, params)
 	if err != nil {
 		return &RespReplaceColl{RestResponse: RestResponse{CallErr: err}, CollInfo: CollInfo{Id: spec.CollName}}
 	}
-	req = c.addAuthHeader(req, method, "colls", "dbs/"+spec.DbName+"/colls/"+spec.CollName)
 	if spec.Ru > 0 {
 		req.Header.Set(restApiHeaderOfferThroughput, strconv.Itoa(spec.Ru))
 	}
@@ -389,8 +664,7 @@ func (c *RestClient) ReplaceCollection(spec CollectionSpec) *RespReplaceColl {
 		req.Header.Set(restApiHeaderOfferAutopilotSettings, fmt.Sprintf(`{"maxThroughput":%d}`, spec.MaxRu))
 	}
 
-	resp := c.client.Do(req)
-	result := &RespReplaceColl{RestResponse: c.buildRestResponse(resp), CollInfo: CollInfo{Id: spec.CollName}}
+	result := &RespReplaceColl{RestResponse: c.execRequest(req, "colls", "dbs/"+spec.DbName+"/colls/"+spec.CollName), CollInfo: CollInfo{Id: spec.CollName}}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.CollInfo))
 	}
@@ -401,15 +675,20 @@ func (c *RestClient) ReplaceCollection(spec CollectionSpec) *RespReplaceColl {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/get-a-collection
 func (c *RestClient) GetCollection(dbName, collName string) *RespGetColl {
-	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+dbName+"/colls/"+collName
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.GetCollectionContext(context.Background(), dbName, collName)
+}
+
+// GetCollectionContext is similar to GetCollection but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetCollectionContext(ctx context.Context, dbName, collName string) *RespGetColl {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/colls/"+collName)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespGetColl{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "colls", "dbs/"+dbName+"/colls/"+collName)
 
-	resp := c.client.Do(req)
-	result := &RespGetColl{RestResponse: c.buildRestResponse(resp)}
+	result := &RespGetColl{RestResponse: c.execRequest(req, "colls", "dbs/"+dbName+"/colls/"+collName)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.CollInfo))
 	}
@@ -420,15 +699,20 @@ func (c *RestClient) GetCollection(dbName, collName string) *RespGetColl {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/delete-a-collection.
 func (c *RestClient) DeleteCollection(dbName, collName string) *RespDeleteColl {
-	method, urlEndpoint := "DELETE", c.endpoint+"/dbs/"+dbName+"/colls/"+collName
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.DeleteCollectionContext(context.Background(), dbName, collName)
+}
+
+// DeleteCollectionContext is similar to DeleteCollection but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteCollectionContext(ctx context.Context, dbName, collName string) *RespDeleteColl {
+	method, urlEndpoint := "DELETE", c.resourceUrl("dbs/"+dbName+"/colls/"+collName)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespDeleteColl{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "colls", "dbs/"+dbName+"/colls/"+collName)
 
-	resp := c.client.Do(req)
-	result := &RespDeleteColl{RestResponse: c.buildRestResponse(resp)}
+	result := &RespDeleteColl{RestResponse: c.execRequest(req, "colls", "dbs/"+dbName+"/colls/"+collName)}
 	return result
 }
 
@@ -436,15 +720,20 @@ func (c *RestClient) DeleteCollection(dbName, collName string) *RespDeleteColl {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/list-collections.
 func (c *RestClient) ListCollections(dbName string) *RespListColl {
-	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+dbName+"/colls"
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.ListCollectionsContext(context.Background(), dbName)
+}
+
+// ListCollectionsContext is similar to ListCollections but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListCollectionsContext(ctx context.Context, dbName string) *RespListColl {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/colls")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespListColl{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "colls", "dbs/"+dbName)
 
-	resp := c.client.Do(req)
-	result := &RespListColl{RestResponse: c.buildRestResponse(resp)}
+	result := &RespListColl{RestResponse: c.execRequest(req, "colls", "dbs/"+dbName)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
 		if result.CallErr == nil {
@@ -463,15 +752,20 @@ func (c *RestClient) ListCollections(dbName string) *RespListColl {
 //
 // Available since v0.1.3
 func (c *RestClient) GetPkranges(dbName, collName string) *RespGetPkranges {
-	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+dbName+"/colls/"+collName+"/pkranges"
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.GetPkrangesContext(context.Background(), dbName, collName)
+}
+
+// GetPkrangesContext is similar to GetPkranges but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetPkrangesContext(ctx context.Context, dbName, collName string) *RespGetPkranges {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/colls/"+collName+"/pkranges")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespGetPkranges{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "pkranges", "dbs/"+dbName+"/colls/"+collName)
 
-	resp := c.client.Do(req)
-	result := &RespGetPkranges{RestResponse: c.buildRestResponse(resp)}
+	result := &RespGetPkranges{RestResponse: c.execRequest(req, "pkranges", "dbs/"+dbName+"/colls/"+collName)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
 	}
@@ -485,23 +779,31 @@ type DocumentSpec struct {
 	IndexingDirective  string // accepted value "", "Include" or "Exclude"
 	PartitionKeyValues []interface{}
 	DocumentData       DocInfo
+	PreTriggers        []string // (since v1.2.0) ids of the pre-triggers to fire before the document is created or replaced
+	PostTriggers       []string // (since v1.2.0) ids of the post-triggers to fire after the document is created or replaced
 }
 
 // CreateDocument invokes Cosmos DB API to create a new document.
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/create-a-document.
 func (c *RestClient) CreateDocument(spec DocumentSpec) *RespCreateDoc {
-	method, urlEndpoint := "POST", c.endpoint+"/dbs/"+spec.DbName+"/colls/"+spec.CollName+"/docs"
+	return c.CreateDocumentContext(context.Background(), spec)
+}
+
+// CreateDocumentContext is similar to CreateDocument but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateDocumentContext(ctx context.Context, spec DocumentSpec) *RespCreateDoc {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+spec.DbName+"/colls/"+spec.CollName+"/docs")
 	if c.autoId {
 		if id, ok := spec.DocumentData[docFieldId].(string); !ok || strings.TrimSpace(id) == "" {
 			spec.DocumentData[docFieldId] = strings.ToLower(idGen.Id128Hex())
 		}
 	}
-	req, err := c.buildJsonRequest(method, urlEndpoint, spec.DocumentData)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, spec.DocumentData)
 	if err != nil {
 		return &RespCreateDoc{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "docs", "dbs/"+spec.DbName+"/colls/"+spec.CollName)
 	if spec.IsUpsert {
 		req.Header.Set(restApiHeaderIsUpsert, "true")
 	}
@@ -510,9 +812,9 @@ func (c *RestClient) CreateDocument(spec DocumentSpec) *RespCreateDoc {
 	}
 	jsPkValues, _ := json.Marshal(spec.PartitionKeyValues)
 	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+	setTriggerHeaders(req, spec.PreTriggers, spec.PostTriggers)
 
-	resp := c.client.Do(req)
-	result := &RespCreateDoc{RestResponse: c.buildRestResponse(resp)}
+	result := &RespCreateDoc{RestResponse: c.execRequest(req, "docs", "dbs/"+spec.DbName+"/colls/"+spec.CollName)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DocInfo))
 	}
@@ -523,21 +825,27 @@ func (c *RestClient) CreateDocument(spec DocumentSpec) *RespCreateDoc {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/replace-a-document.
 func (c *RestClient) ReplaceDocument(matchEtag string, spec DocumentSpec) *RespReplaceDoc {
+	return c.ReplaceDocumentContext(context.Background(), matchEtag, spec)
+}
+
+// ReplaceDocumentContext is similar to ReplaceDocument but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceDocumentContext(ctx context.Context, matchEtag string, spec DocumentSpec) *RespReplaceDoc {
 	id, _ := spec.DocumentData[docFieldId].(string)
-	method, urlEndpoint := "PUT", c.endpoint+"/dbs/"+spec.DbName+"/colls/"+spec.CollName+"/docs/"+id
-	req, err := c.buildJsonRequest(method, urlEndpoint, spec.DocumentData)
+	method, urlEndpoint := "PUT", c.resourceUrl("dbs/"+spec.DbName+"/colls/"+spec.CollName+"/docs/"+id)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, spec.DocumentData)
 	if err != nil {
 		return &RespReplaceDoc{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "docs", "dbs/"+spec.DbName+"/colls/"+spec.CollName+"/docs/"+id)
 	if matchEtag != "" {
 		req.Header.Set(httpHeaderIfMatch, matchEtag)
 	}
 	jsPkValues, _ := json.Marshal(spec.PartitionKeyValues)
 	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+	setTriggerHeaders(req, spec.PreTriggers, spec.PostTriggers)
 
-	resp := c.client.Do(req)
-	result := &RespReplaceDoc{RestResponse: c.buildRestResponse(resp)}
+	result := &RespReplaceDoc{RestResponse: c.execRequest(req, "docs", "dbs/"+spec.DbName+"/colls/"+spec.CollName+"/docs/"+id)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DocInfo))
 	}
@@ -548,22 +856,30 @@ func (c *RestClient) ReplaceDocument(matchEtag string, spec DocumentSpec) *RespR
 type DocReq struct {
 	DbName, CollName, DocId string
 	PartitionKeyValues      []interface{}
-	MatchEtag               string // if not empty, add "If-Match" header to request
-	NotMatchEtag            string // if not empty, add "If-None-Match" header to request
-	ConsistencyLevel        string // accepted values: "", "Strong", "Bounded", "Session" or "Eventual"
-	SessionToken            string // string token used with session level consistency
+	MatchEtag               string   // if not empty, add "If-Match" header to request
+	NotMatchEtag            string   // if not empty, add "If-None-Match" header to request
+	ConsistencyLevel        string   // accepted values: "", "Strong", "Bounded", "Session" or "Eventual"
+	SessionToken            string   // string token used with session level consistency
+	PreTriggers             []string // (since v1.2.0) ids of the pre-triggers to fire before the document is deleted
+	PostTriggers            []string // (since v1.2.0) ids of the post-triggers to fire after the document is deleted
 }
 
 // GetDocument invokes Cosmos DB API to get an existing document.
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/get-a-document.
 func (c *RestClient) GetDocument(r DocReq) *RespGetDoc {
-	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.GetDocumentContext(context.Background(), r)
+}
+
+// GetDocumentContext is similar to GetDocument but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetDocumentContext(ctx context.Context, r DocReq) *RespGetDoc {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespGetDoc{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)
 	jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
 	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
 	if r.NotMatchEtag != "" {
@@ -576,8 +892,7 @@ func (c *RestClient) GetDocument(r DocReq) *RespGetDoc {
 		req.Header.Set(restApiHeaderSessionToken, r.SessionToken)
 	}
 
-	resp := c.client.Do(req)
-	result := &RespGetDoc{RestResponse: c.buildRestResponse(resp)}
+	result := &RespGetDoc{RestResponse: c.execRequest(req, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)}
 	if result.CallErr == nil && result.StatusCode != 304 {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DocInfo))
 	}
@@ -588,20 +903,247 @@ func (c *RestClient) GetDocument(r DocReq) *RespGetDoc {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/delete-a-document.
 func (c *RestClient) DeleteDocument(r DocReq) *RespDeleteDoc {
-	method, urlEndpoint := "DELETE", c.endpoint+"/dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.DeleteDocumentContext(context.Background(), r)
+}
+
+// DeleteDocumentContext is similar to DeleteDocument but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteDocumentContext(ctx context.Context, r DocReq) *RespDeleteDoc {
+	method, urlEndpoint := "DELETE", c.resourceUrl("dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespDeleteDoc{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)
 	jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
 	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
 	if r.MatchEtag != "" {
 		req.Header.Set(httpHeaderIfMatch, r.MatchEtag)
 	}
+	setTriggerHeaders(req, r.PreTriggers, r.PostTriggers)
 
-	resp := c.client.Do(req)
-	result := &RespDeleteDoc{RestResponse: c.buildRestResponse(resp)}
+	result := &RespDeleteDoc{RestResponse: c.execRequest(req, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)}
+	return result
+}
+
+// Patch operation types, see PatchOp.
+//
+// @Available since v1.2.0
+const (
+	PatchOpAdd       = "add"
+	PatchOpSet       = "set"
+	PatchOpReplace   = "replace"
+	PatchOpRemove    = "remove"
+	PatchOpIncrement = "incr"
+	PatchOpMove      = "move"
+)
+
+// MaxPatchOperations is the max number of operations Cosmos DB accepts in a single patch request.
+//
+// @Available since v1.2.0
+const MaxPatchOperations = 10
+
+// PatchOp is an operation of a partial document update request.
+//
+// See: https://learn.microsoft.com/en-us/azure/cosmos-db/partial-document-update.
+//
+// @Available since v1.2.0
+type PatchOp struct {
+	Op    string      // operation type, one of PatchOpAdd, PatchOpSet, PatchOpReplace, PatchOpRemove, PatchOpIncrement or PatchOpMove
+	Path  string      // path of the target field, e.g. "/address/city"
+	From  string      // path of the source field, used by "move" operation only
+	Value interface{} // value to add/set/replace, or number to increment by; not used by "remove" and "move" operations
+}
+
+// MarshalJSON implements json.Marshaler/MarshalJSON.
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{"op": op.Op, "path": op.Path}
+	switch op.Op {
+	case PatchOpRemove:
+	case PatchOpMove:
+		data["from"] = op.From
+	default:
+		data["value"] = op.Value
+	}
+	return json.Marshal(data)
+}
+
+// PatchDocReq specifies a partial document update request.
+//
+// @Available since v1.2.0
+type PatchDocReq struct {
+	DbName, CollName, DocId string
+	PartitionKeyValues      []interface{}
+	MatchEtag               string    // if not empty, add "If-Match" header to request
+	Condition               string    // optional filter predicate, the patch is applied only if the document matches it (e.g. "FROM c WHERE c.status = 'active'")
+	Operations              []PatchOp // up to MaxPatchOperations operations, applied atomically
+}
+
+// PatchDocument invokes Cosmos DB API to partially update an existing document.
+//
+// The operations are applied atomically server-side. If PatchDocReq.Condition is specified and the document does not
+// match it, the call fails with status 412.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/patch-a-document.
+//
+// @Available since v1.2.0
+func (c *RestClient) PatchDocument(r PatchDocReq) *RespPatchDoc {
+	return c.PatchDocumentContext(context.Background(), r)
+}
+
+// PatchDocumentContext is similar to PatchDocument but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) PatchDocumentContext(ctx context.Context, r PatchDocReq) *RespPatchDoc {
+	method, urlEndpoint := "PATCH", c.resourceUrl("dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)
+	params := map[string]interface{}{"operations": r.Operations}
+	if r.Condition != "" {
+		params["condition"] = r.Condition
+	}
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, params)
+	if err != nil {
+		return &RespPatchDoc{RestResponse: RestResponse{CallErr: err}}
+	}
+	req.Header.Set(httpHeaderContentType, "application/json_patch+json")
+	jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
+	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+	if r.MatchEtag != "" {
+		req.Header.Set(httpHeaderIfMatch, r.MatchEtag)
+	}
+
+	result := &RespPatchDoc{RestResponse: c.execRequest(req, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName+"/docs/"+r.DocId)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.DocInfo))
+	}
+	return result
+}
+
+// Batch operation types, see BatchOp.
+//
+// @Available since v1.2.0
+const (
+	BatchOpCreate  = "Create"
+	BatchOpUpsert  = "Upsert"
+	BatchOpReplace = "Replace"
+	BatchOpDelete  = "Delete"
+	BatchOpRead    = "Read"
+	BatchOpPatch   = "Patch"
+)
+
+// MaxBatchOperations is the max number of operations Cosmos DB accepts in a single transactional batch request.
+//
+// @Available since v1.2.0
+const MaxBatchOperations = 100
+
+// BatchOp is an operation of a transactional batch request.
+//
+// @Available since v1.2.0
+type BatchOp struct {
+	Op         string    // operation type, one of BatchOpCreate, BatchOpUpsert, BatchOpReplace, BatchOpDelete, BatchOpRead or BatchOpPatch
+	Id         string    // id of the target document; for Create, Upsert and Replace operations it defaults to the "id" field of Document
+	Document   DocInfo   // document to create, upsert or replace
+	MatchEtag  string    // if not empty, the operation is applied only if the document's etag matches
+	Condition  string    // optional filter predicate, used by Patch operation only (see PatchDocReq.Condition)
+	Operations []PatchOp // patch operations, used by Patch operation only
+}
+
+// MarshalJSON implements json.Marshaler/MarshalJSON.
+func (op BatchOp) MarshalJSON() ([]byte, error) {
+	data := map[string]interface{}{"operationType": op.Op}
+	id := op.Id
+	switch op.Op {
+	case BatchOpCreate, BatchOpUpsert, BatchOpReplace:
+		data["resourceBody"] = op.Document
+		if id == "" {
+			id, _ = op.Document[docFieldId].(string)
+		}
+	case BatchOpPatch:
+		body := map[string]interface{}{"operations": op.Operations}
+		if op.Condition != "" {
+			body["condition"] = op.Condition
+		}
+		data["resourceBody"] = body
+	}
+	if id != "" {
+		data["id"] = id
+	}
+	if op.MatchEtag != "" {
+		data["ifMatch"] = op.MatchEtag
+	}
+	return json.Marshal(data)
+}
+
+// BatchReq specifies a transactional batch request.
+//
+// All operations must target documents of the same logical partition, identified by PartitionKeyValues.
+//
+// @Available since v1.2.0
+type BatchReq struct {
+	DbName, CollName   string
+	PartitionKeyValues []interface{}
+	Operations         []BatchOp // up to MaxBatchOperations operations, executed in order
+}
+
+// ExecuteBatch invokes Cosmos DB API to execute a transactional batch of operations on a single logical partition.
+//
+// The operations are executed atomically: either all of them succeed, or none of them is applied. The result of each
+// operation is available via RespExecuteBatch.Results. If an operation fails, RespExecuteBatch.Error() returns non-nil
+// and RespExecuteBatch.FailedOperation() returns the index of the failed operation.
+//
+// See: https://learn.microsoft.com/en-us/azure/cosmos-db/nosql/transactional-batch.
+//
+// @Available since v1.2.0
+func (c *RestClient) ExecuteBatch(r BatchReq) *RespExecuteBatch {
+	return c.ExecuteBatchContext(context.Background(), r)
+}
+
+// ExecuteBatchContext is similar to ExecuteBatch but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ExecuteBatchContext(ctx context.Context, r BatchReq) *RespExecuteBatch {
+	return c.executeBatch(ctx, r, true)
+}
+
+// executeBatch sends a batch request. If atomic is false, operations are executed independently: server continues
+// executing the remaining operations when one fails.
+func (c *RestClient) executeBatch(ctx context.Context, r BatchReq, atomic bool) *RespExecuteBatch {
+	if n := len(r.Operations); n == 0 || n > MaxBatchOperations {
+		return &RespExecuteBatch{RestResponse: RestResponse{CallErr: fmt.Errorf("number of batch operations must be between 1 and %d, got %d", MaxBatchOperations, n)}}
+	}
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+r.DbName+"/colls/"+r.CollName+"/docs")
+	if c.autoId {
+		for _, op := range r.Operations {
+			if (op.Op == BatchOpCreate || op.Op == BatchOpUpsert) && op.Id == "" && op.Document != nil {
+				if id, ok := op.Document[docFieldId].(string); !ok || strings.TrimSpace(id) == "" {
+					op.Document[docFieldId] = strings.ToLower(idGen.Id128Hex())
+				}
+			}
+		}
+	}
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, r.Operations)
+	if err != nil {
+		return &RespExecuteBatch{RestResponse: RestResponse{CallErr: err}}
+	}
+	req.Header.Set(restApiHeaderIsBatchRequest, "True")
+	if atomic {
+		req.Header.Set(restApiHeaderBatchAtomic, "True")
+		req.Header.Set(restApiHeaderBatchContinueOnError, "False")
+	} else {
+		req.Header.Set(restApiHeaderBatchAtomic, "False")
+		req.Header.Set(restApiHeaderBatchContinueOnError, "True")
+	}
+	jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
+	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+
+	result := &RespExecuteBatch{RestResponse: c.execRequest(req, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName)}
+	if result.CallErr == nil {
+		if err := json.Unmarshal(result.RespBody, &(result.Results)); err != nil && result.ApiErr == nil {
+			result.CallErr = err
+		}
+		if i := result.FailedOperation(); i >= 0 && result.ApiErr == nil {
+			result.ApiErr = fmt.Errorf("error executing Azure Cosmos DB batch; Operation=%d;StatusCode=%d", i, result.Results[i].StatusCode)
+		}
+	}
 	return result
 }
 
@@ -617,21 +1159,113 @@ type QueryReq struct {
 	CrossPartitionEnabled bool
 	ConsistencyLevel      string // accepted values: "", "Strong", "Bounded", "Session" or "Eventual"
 	SessionToken          string // string token used with session level consistency
+	// (since v1.2.0) max number of partition key ranges queried concurrently by cross-partition queries (negative value means no limit).
+	// If not specified, the client's default value is used (see RestClient.SetMaxDegreeOfParallelism).
+	MaxDegreeOfParallelism int
+}
+
+// degreeOfParallelism returns the max number of partition key ranges to be queried concurrently for the query.
+//
+// @Available since v1.2.0
+func (c *RestClient) degreeOfParallelism(query QueryReq) int {
+	if query.MaxDegreeOfParallelism != 0 {
+		return query.MaxDegreeOfParallelism
+	}
+	return c.maxDop
 }
 
-func (c *RestClient) buildQueryRequest(query QueryReq) (*http.Request, error) {
-	method, urlEndpoint := "POST", c.endpoint+"/dbs/"+query.DbName+"/colls/"+query.CollName+"/docs"
+// fanOut calls fn(i) for each i in [0, n), running at most maxDop calls concurrently (negative maxDop means no limit).
+// fanOut returns once all calls have completed.
+//
+// @Available since v1.2.0
+func fanOut(n, maxDop int, fn func(i int)) {
+	if maxDop < 0 || maxDop > n {
+		maxDop = n
+	}
+	if maxDop <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxDop)
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// queryPkrangesParallel fetches all documents from each of the partition key ranges, querying at most maxDop ranges
+// concurrently. Each range is queried starting from its continuation token, ranges not present in continuationTokens
+// are skipped. The results are keyed by partition key range id.
+//
+// If a call fails, remaining ranges are not queried and the failed result is returned as the second value.
+//
+// @Available since v1.2.0
+func (c *RestClient) queryPkrangesParallel(ctx context.Context, query QueryReq, pkranges []PkrangeInfo, continuationTokens map[string]string, queryPlan *RespQueryPlan, maxDop int) (map[string]*RespQueryDocs, *RespQueryDocs) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failed *RespQueryDocs
+	var lock sync.Mutex
+	results := make(map[string]*RespQueryDocs, len(pkranges))
+	fanOut(len(pkranges), maxDop, func(i int) {
+		continuationToken, ok := continuationTokens[pkranges[i].Id]
+		if !ok {
+			return
+		}
+		if err := ctx.Err(); err != nil {
+			lock.Lock()
+			defer lock.Unlock()
+			if failed == nil {
+				failed = &RespQueryDocs{RestResponse: RestResponse{CallErr: err}}
+			}
+			return
+		}
+		rangeQuery := query
+		rangeQuery.PkRangeId = pkranges[i].Id
+		rangeQuery.ContinuationToken = continuationToken
+		var result *RespQueryDocs
+		for {
+			result = c.mergeQueryResults(result, c.queryAllAndMerge(ctx, rangeQuery, queryPlan), queryPlan)
+			if result.Error() != nil || result.ContinuationToken == "" {
+				break
+			}
+			rangeQuery.ContinuationToken = result.ContinuationToken
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if result.Error() == nil {
+			results[pkranges[i].Id] = result
+		} else if failed == nil {
+			// fail fast: no need to query other ranges
+			failed = result
+			cancel()
+		}
+	})
+	return results, failed
+}
+
+func (c *RestClient) buildQueryRequest(ctx context.Context, query QueryReq) (*http.Request, error) {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+query.DbName+"/colls/"+query.CollName+"/docs")
 	requestBody := make(map[string]interface{})
 	requestBody[restApiParamQuery] = query.Query
 	if query.Params != nil {
 		// M.A.I. 2022-02-16: server will complain if parameter set to nil
 		requestBody[restApiParamParameters] = query.Params
 	}
-	req, err := c.buildJsonRequest(method, urlEndpoint, requestBody)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, requestBody)
 	if err != nil {
 		return nil, err
 	}
-	req = c.addAuthHeader(req, method, "docs", "dbs/"+query.DbName+"/colls/"+query.CollName)
 	req.Header.Set(httpHeaderContentType, "application/query+json")
 	req.Header.Set(restApiHeaderIsQuery, "true")
 	req.Header.Set(restApiHeaderPopulateMetrics, "true")
@@ -663,6 +1297,8 @@ func (c *RestClient) mergeQueryResults(existingResp, newResp *RespQueryDocs, que
 	result := &temp
 	if existingResp != nil {
 		result.RequestCharge += existingResp.RequestCharge
+		result.RetryCount += existingResp.RetryCount
+		result.RetryWait += existingResp.RetryWait
 		if newResp.Error() == nil {
 			result = result.merge(queryPlan, existingResp)
 		}
@@ -677,10 +1313,10 @@ func (c *RestClient) mergeQueryResults(existingResp, newResp *RespQueryDocs, que
 }
 
 // Note: the query is executed as-is, not rewritten!
-func (c *RestClient) queryAllAndMerge(query QueryReq, queryPlan *RespQueryPlan) *RespQueryDocs {
+func (c *RestClient) queryAllAndMerge(ctx context.Context, query QueryReq, queryPlan *RespQueryPlan) *RespQueryDocs {
 	var result *RespQueryDocs
 	for {
-		result = c.mergeQueryResults(result, c.queryDocumentsCall(query), queryPlan)
+		result = c.mergeQueryResults(result, c.queryDocumentsCall(ctx, query), queryPlan)
 		if result.Error() != nil || result.ContinuationToken == "" || (query.MaxItemCount > 0 && result.Count >= query.MaxItemCount) {
 			break
 		}
@@ -692,7 +1328,7 @@ func (c *RestClient) queryAllAndMerge(query QueryReq, queryPlan *RespQueryPlan)
 // queryAndMerge queries documents then performs merging to build the final result.
 //
 // Note: query is rewritten, executed and flattened (transformed) before returned!
-func (c *RestClient) queryAndMerge(query QueryReq, pkranges *RespGetPkranges, queryPlan *RespQueryPlan) *RespQueryDocs {
+func (c *RestClient) queryAndMerge(ctx context.Context, query QueryReq, pkranges *RespGetPkranges, queryPlan *RespQueryPlan) *RespQueryDocs {
 	queryRewritten := queryPlan.QueryInfo.RewrittenQuery != ""
 	if queryRewritten {
 		query.Query = strings.ReplaceAll(queryPlan.QueryInfo.RewrittenQuery, "{documentdb-formattableorderbyquery-filter}", "true")
@@ -704,7 +1340,7 @@ func (c *RestClient) queryAndMerge(query QueryReq, pkranges *RespGetPkranges, qu
 		if query.PkValue == "" && query.PkRangeId == "" {
 			query.PkRangeId = pkranges.Pkranges[0].Id
 		}
-		result = c.queryDocumentsSimple(query, queryPlan)
+		result = c.queryDocumentsSimple(ctx, query, queryPlan)
 	} else {
 		var cctResult, cctQuery = make(map[string]string), make(map[string]string)
 		if err := json.Unmarshal([]byte(query.ContinuationToken), &cctQuery); err != nil || query.ContinuationToken == "" {
@@ -718,6 +1354,14 @@ func (c *RestClient) queryAndMerge(query QueryReq, pkranges *RespGetPkranges, qu
 		}
 
 		savedMaxItemCount := query.MaxItemCount
+		var prefetched map[string]*RespQueryDocs
+		if maxDop := c.degreeOfParallelism(query); maxDop != 1 && query.MaxItemCount <= 0 {
+			// without paging, ranges do not depend on each other and can be queried concurrently
+			var failed *RespQueryDocs
+			if prefetched, failed = c.queryPkrangesParallel(ctx, query, pkranges.Pkranges, cctQuery, queryPlan, maxDop); failed != nil {
+				return failed
+			}
+		}
 		for _, pkrange := range pkranges.Pkranges {
 			if continuationToken, ok := cctQuery[pkrange.Id]; !ok {
 				// all documents from this withPk-range had been queried
@@ -726,7 +1370,13 @@ func (c *RestClient) queryAndMerge(query QueryReq, pkranges *RespGetPkranges, qu
 				query.ContinuationToken = continuationToken
 				query.PkRangeId = pkrange.Id
 			}
-			result = c.mergeQueryResults(result, c.queryAllAndMerge(query, queryPlan), queryPlan)
+			var rangeResult *RespQueryDocs
+			if prefetched != nil {
+				rangeResult = prefetched[pkrange.Id]
+			} else {
+				rangeResult = c.queryAllAndMerge(ctx, query, queryPlan)
+			}
+			result = c.mergeQueryResults(result, rangeResult, queryPlan)
 			if result.Error() != nil {
 				break
 			}
@@ -808,8 +1458,8 @@ func (c *RestClient) finalPrepareResult(result *RespQueryDocs, queryPlan *RespQu
 // If QueryReq.MaxItemCount <= 0, all matched documents will be returned
 //
 // Note: query is executed as-is, not rewritten!
-func (c *RestClient) queryDocumentsSimple(query QueryReq, queryPlan *RespQueryPlan) *RespQueryDocs {
-	req, err := c.buildQueryRequest(query)
+func (c *RestClient) queryDocumentsSimple(ctx context.Context, query QueryReq, queryPlan *RespQueryPlan) *RespQueryDocs {
+	req, err := c.buildQueryRequest(ctx, query)
 	if err != nil {
 		return &RespQueryDocs{RestResponse: RestResponse{CallErr: err}}
 	}
@@ -819,8 +1469,7 @@ func (c *RestClient) queryDocumentsSimple(query QueryReq, queryPlan *RespQueryPl
 		req.Header.Set(restApiHeaderPageSize, "100")
 	}
 	for {
-		resp := c.client.Do(req)
-		tempResult := &RespQueryDocs{RestResponse: c.buildRestResponse(resp)}
+		tempResult := &RespQueryDocs{RestResponse: c.execRequest(req, "docs", "dbs/"+query.DbName+"/colls/"+query.CollName)}
 		if tempResult.CallErr == nil {
 			tempResult.ContinuationToken = tempResult.RespHeader[respHeaderContinuation]
 			tempResult.CallErr = json.Unmarshal(tempResult.RespBody, &tempResult)
@@ -829,6 +1478,8 @@ func (c *RestClient) queryDocumentsSimple(query QueryReq, queryPlan *RespQueryPl
 			// append returned document list
 			tempResult.Count += result.Count
 			tempResult.RequestCharge += result.RequestCharge
+			tempResult.RetryCount += result.RetryCount
+			tempResult.RetryWait += result.RetryWait
 			tempResult.Documents = append(result.Documents, tempResult.Documents...)
 		}
 		result = tempResult
@@ -843,13 +1494,12 @@ func (c *RestClient) queryDocumentsSimple(query QueryReq, queryPlan *RespQueryPl
 // queryDocumentsCall makes a single query-documents API call.
 //
 // Note: the query is executed as-is!
-func (c *RestClient) queryDocumentsCall(query QueryReq) *RespQueryDocs {
-	req, err := c.buildQueryRequest(query)
+func (c *RestClient) queryDocumentsCall(ctx context.Context, query QueryReq) *RespQueryDocs {
+	req, err := c.buildQueryRequest(ctx, query)
 	if err != nil {
 		return &RespQueryDocs{RestResponse: RestResponse{CallErr: err}}
 	}
-	resp := c.client.Do(req)
-	result := &RespQueryDocs{RestResponse: c.buildRestResponse(resp)}
+	result := &RespQueryDocs{RestResponse: c.execRequest(req, "docs", "dbs/"+query.DbName+"/colls/"+query.CollName)}
 	if result.CallErr == nil {
 		result.ContinuationToken = result.RespHeader[respHeaderContinuation]
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
@@ -876,20 +1526,27 @@ func (c *RestClient) queryDocumentsCall(query QueryReq) *RespQueryDocs {
 //     might not work properly. Resolution/Workaround: use QueryDocumentsCrossPartition or QueryDocuments without
 //     QueryReq.MaxItemCount (caution: intermediate results are kept in memory, be alerted for out-of-memory error).
 func (c *RestClient) QueryDocuments(query QueryReq) *RespQueryDocs {
-	queryPlan := c.QueryPlan(query)
+	return c.QueryDocumentsContext(context.Background(), query)
+}
+
+// QueryDocumentsContext is similar to QueryDocuments but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) QueryDocumentsContext(ctx context.Context, query QueryReq) *RespQueryDocs {
+	queryPlan := c.QueryPlanContext(ctx, query)
 	if queryPlan.Error() != nil {
 		return &RespQueryDocs{RestResponse: queryPlan.RestResponse}
 	}
 
 	if queryPlan.QueryInfo.DistinctType != "None" || queryPlan.QueryInfo.RewrittenQuery != "" {
-		pkranges := c.GetPkranges(query.DbName, query.CollName)
+		pkranges := c.GetPkrangesContext(ctx, query.DbName, query.CollName)
 		if pkranges.Error() != nil {
 			return &RespQueryDocs{RestResponse: pkranges.RestResponse}
 		}
-		return c.queryAndMerge(query, pkranges, queryPlan)
+		return c.queryAndMerge(ctx, query, pkranges, queryPlan)
 	}
 
-	return c.queryDocumentsSimple(query, queryPlan)
+	return c.queryDocumentsSimple(ctx, query, queryPlan)
 }
 
 // QueryDocumentsCrossPartition can be used as a workaround for known issues with QueryDocuments.
@@ -898,25 +1555,56 @@ func (c *RestClient) QueryDocuments(query QueryReq) *RespQueryDocs {
 //
 // Available since v0.2.0
 func (c *RestClient) QueryDocumentsCrossPartition(query QueryReq) *RespQueryDocs {
+	return c.QueryDocumentsCrossPartitionContext(context.Background(), query)
+}
+
+// QueryDocumentsCrossPartitionContext is similar to QueryDocumentsCrossPartition but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) QueryDocumentsCrossPartitionContext(ctx context.Context, query QueryReq) *RespQueryDocs {
 	query.CrossPartitionEnabled = true
-	queryPlan := c.QueryPlan(query)
+	queryPlan := c.QueryPlanContext(ctx, query)
 	if queryPlan.Error() != nil {
 		return &RespQueryDocs{RestResponse: queryPlan.RestResponse}
 	}
-	queryRewritten := queryPlan.QueryInfo.RewrittenQuery != ""
-	pkranges := c.GetPkranges(query.DbName, query.CollName)
+	pkranges := c.GetPkrangesContext(ctx, query.DbName, query.CollName)
 	if pkranges.Error() != nil {
 		return &RespQueryDocs{RestResponse: pkranges.RestResponse}
 	}
+	return c.queryCrossPartition(ctx, query, pkranges, queryPlan)
+}
+
+// queryCrossPartition executes the query on all partition key ranges and merges the results.
+//
+// Caution: intermediate results are kept in memory!
+func (c *RestClient) queryCrossPartition(ctx context.Context, query QueryReq, pkranges *RespGetPkranges, queryPlan *RespQueryPlan) *RespQueryDocs {
+	queryRewritten := queryPlan.QueryInfo.RewrittenQuery != ""
 	if queryRewritten {
 		query.Query = strings.ReplaceAll(queryPlan.QueryInfo.RewrittenQuery, "{documentdb-formattableorderbyquery-filter}", "true")
 	}
 	var result *RespQueryDocs
 	savedContinuationToken := query.ContinuationToken
+	if maxDop := c.degreeOfParallelism(query); maxDop != 1 && len(pkranges.Pkranges) > 1 {
+		continuationTokens := make(map[string]string, len(pkranges.Pkranges))
+		for i, pkrange := range pkranges.Pkranges {
+			continuationTokens[pkrange.Id] = ""
+			if i == 0 {
+				continuationTokens[pkrange.Id] = query.ContinuationToken
+			}
+		}
+		results, failed := c.queryPkrangesParallel(ctx, query, pkranges.Pkranges, continuationTokens, queryPlan, maxDop)
+		if failed != nil {
+			return failed
+		}
+		for _, pkrange := range pkranges.Pkranges {
+			result = c.mergeQueryResults(result, results[pkrange.Id], queryPlan)
+		}
+		return c.finalPrepareResult(result, queryPlan, savedContinuationToken)
+	}
 	for _, pkrange := range pkranges.Pkranges {
 		query.PkRangeId = pkrange.Id
 		for {
-			result = c.mergeQueryResults(result, c.queryAllAndMerge(query, queryPlan), queryPlan)
+			result = c.mergeQueryResults(result, c.queryAllAndMerge(ctx, query, queryPlan), queryPlan)
 			// fmt.Printf("\tDEBUG: num rows: %5d\n", result.Count)
 			if result.Error() != nil || result.ContinuationToken == "" {
 				break
@@ -935,17 +1623,23 @@ func (c *RestClient) QueryDocumentsCrossPartition(query QueryReq) *RespQueryDocs
 //
 // Available since v0.1.8
 func (c *RestClient) QueryPlan(query QueryReq) *RespQueryPlan {
-	method, urlEndpoint := "POST", c.endpoint+"/dbs/"+query.DbName+"/colls/"+query.CollName+"/docs"
+	return c.QueryPlanContext(context.Background(), query)
+}
+
+// QueryPlanContext is similar to QueryPlan but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) QueryPlanContext(ctx context.Context, query QueryReq) *RespQueryPlan {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+query.DbName+"/colls/"+query.CollName+"/docs")
 	requestBody := make(map[string]interface{}, 0)
 	requestBody[restApiParamQuery] = query.Query
 	if query.Params != nil {
 		requestBody[restApiParamParameters] = query.Params
 	}
-	req, err := c.buildJsonRequest(method, urlEndpoint, requestBody)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, requestBody)
 	if err != nil {
 		return &RespQueryPlan{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "docs", "dbs/"+query.DbName+"/colls/"+query.CollName)
 	req.Header.Set(httpHeaderContentType, "application/query+json")
 	if query.MaxItemCount > 0 {
 		req.Header.Set(restApiHeaderPageSize, strconv.Itoa(query.MaxItemCount))
@@ -963,8 +1657,7 @@ func (c *RestClient) QueryPlan(query QueryReq) *RespQueryPlan {
 	req.Header.Set(restApiHeaderSupportedQueryFeatures, "NonValueAggregate, Aggregate, Distinct, MultipleOrderBy, OffsetAndLimit, OrderBy, Top, CompositeAggregate, GroupBy, MultipleAggregates")
 	req.Header.Set(restApiHeaderEnableCrossPartitionQuery, "true")
 	req.Header.Set(restApiHeaderParallelizeCrossPartitionQuery, "true")
-	resp := c.client.Do(req)
-	result := &RespQueryPlan{RestResponse: c.buildRestResponse(resp)}
+	result := &RespQueryPlan{RestResponse: c.execRequest(req, "docs", "dbs/"+query.DbName+"/colls/"+query.CollName)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
 	}
@@ -981,30 +1674,156 @@ type ListDocsReq struct {
 	NotMatchEtag      string
 	PkRangeId         string
 	IsIncrementalFeed bool // (available since v0.1.9) if "true", the request is used to fetch the incremental changes to documents within the collection
+
+	// (since v1.2.0) where the incremental feed starts, used only if NotMatchEtag is empty. If specified and there is no
+	// change yet, RespListDocs.Etag is populated with the current position so that the next call can resume from it.
+	ChangeFeedStart ChangeFeedStart
+
+	// (since v1.2.0) if true, fetches the "all versions and deletes" (full-fidelity) change feed instead of the incremental
+	// one: every create, replace and delete is reported as a ChangeFeedRecord in RespListDocs.Changes. The full-fidelity
+	// feed can only start from now (ChangeFeedStartFromNow) or from a continuation (NotMatchEtag or
+	// ChangeFeedStartFromContinuation), other start modes are rejected with an error; IsIncrementalFeed is implied.
+	IsFullFidelityFeed bool
+}
+
+// validateFullFidelityFeed checks that a full-fidelity change feed starts from now or from a continuation, which are
+// the only start positions accepted by server.
+func (r ListDocsReq) validateFullFidelityFeed() error {
+	if !r.IsFullFidelityFeed || r.NotMatchEtag != "" {
+		return nil
+	}
+	switch {
+	case r.ChangeFeedStart.Mode == ChangeFeedStartModeNow:
+		return nil
+	case r.ChangeFeedStart.Mode == ChangeFeedStartModeContinuation && r.ChangeFeedStart.ContinuationToken != "":
+		return nil
+	}
+	return errors.New("full-fidelity change feed can only start from now or from a continuation")
+}
+
+// Start modes of a change feed, see ChangeFeedStart.
+//
+// @Available since v1.2.0
+const (
+	ChangeFeedStartModeBeginning    = "beginning"
+	ChangeFeedStartModeNow          = "now"
+	ChangeFeedStartModeTime         = "time"
+	ChangeFeedStartModeContinuation = "continuation"
+)
+
+// ChangeFeedStart specifies where a change feed starts. The zero value does not add any header to the request, which
+// lets server start from the beginning.
+//
+// @Available since v1.2.0
+type ChangeFeedStart struct {
+	Mode              string    // one of ChangeFeedStartModeBeginning, ChangeFeedStartModeNow, ChangeFeedStartModeTime or ChangeFeedStartModeContinuation
+	Time              time.Time // changes made at or after this time are returned (ChangeFeedStartModeTime only)
+	ContinuationToken string    // etag returned by a previous call (ChangeFeedStartModeContinuation only)
+}
+
+// ChangeFeedStartFromBeginning returns a ChangeFeedStart that reads the change feed from the beginning.
+//
+// @Available since v1.2.0
+func ChangeFeedStartFromBeginning() ChangeFeedStart {
+	return ChangeFeedStart{Mode: ChangeFeedStartModeBeginning}
+}
+
+// ChangeFeedStartFromNow returns a ChangeFeedStart that reads only changes made from now on.
+//
+// @Available since v1.2.0
+func ChangeFeedStartFromNow() ChangeFeedStart {
+	return ChangeFeedStart{Mode: ChangeFeedStartModeNow}
+}
+
+// ChangeFeedStartFromTime returns a ChangeFeedStart that reads changes made at or after the specified time.
+//
+// Note: server compares time with a precision of one second.
+//
+// @Available since v1.2.0
+func ChangeFeedStartFromTime(t time.Time) ChangeFeedStart {
+	return ChangeFeedStart{Mode: ChangeFeedStartModeTime, Time: t}
+}
+
+// ChangeFeedStartFromContinuation returns a ChangeFeedStart that reads changes made after the specified continuation
+// token, i.e. the etag returned by a previous call (see RespListDocs.Etag).
+//
+// @Available since v1.2.0
+func ChangeFeedStartFromContinuation(continuationToken string) ChangeFeedStart {
+	return ChangeFeedStart{Mode: ChangeFeedStartModeContinuation, ContinuationToken: continuationToken}
+}
+
+func (s ChangeFeedStart) validate() error {
+	switch s.Mode {
+	case "", ChangeFeedStartModeBeginning, ChangeFeedStartModeNow, ChangeFeedStartModeTime, ChangeFeedStartModeContinuation:
+		return nil
+	}
+	return fmt.Errorf("invalid change feed start mode %q", s.Mode)
+}
+
+// setHeaders sets the request headers that specify where the change feed starts.
+func (s ChangeFeedStart) setHeaders(req *http.Request) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	switch s.Mode {
+	case ChangeFeedStartModeNow:
+		req.Header.Set(httpHeaderIfNoneMatch, "*")
+	case ChangeFeedStartModeTime:
+		req.Header.Set(httpHeaderIfModifiedSince, s.Time.UTC().Format(http.TimeFormat))
+	case ChangeFeedStartModeContinuation:
+		if s.ContinuationToken != "" {
+			req.Header.Set(httpHeaderIfNoneMatch, s.ContinuationToken)
+		}
+	}
+	return nil
 }
 
 func (c *RestClient) getChangeFeed(r ListDocsReq, req *http.Request) *RespListDocs {
 	var result *RespListDocs
 	for {
-		resp := c.client.Do(req)
-		tempResult := &RespListDocs{RestResponse: c.buildRestResponse(resp)}
+		tempResult := &RespListDocs{RestResponse: c.execRequest(req, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName)}
 		if 300 <= tempResult.StatusCode && tempResult.StatusCode < 400 {
 			// not an error, the status code 3xx indicates that there is currently no item from the change feed
+			if r.NotMatchEtag == "" && r.ChangeFeedStart.Mode != "" {
+				// (since v1.2.0) the current position, to be used to resume from when starting "from now" or "from a point in time"
+				tempResult.Etag = tempResult.RespHeader[respHeaderEtag]
+			}
 		} else if tempResult.CallErr == nil {
 			tempResult.ContinuationToken = tempResult.RespHeader[respHeaderContinuation]
 			tempResult.Etag = tempResult.RespHeader[respHeaderEtag]
 			tempResult.CallErr = json.Unmarshal(tempResult.RespBody, &tempResult)
+			if tempResult.CallErr == nil && r.IsFullFidelityFeed {
+				tempResult.Changes = make([]ChangeFeedRecord, len(tempResult.Documents))
+				for i, doc := range tempResult.Documents {
+					tempResult.Changes[i] = newChangeFeedRecord(doc)
+				}
+			}
 		}
 		if result == nil {
 			result = tempResult
+		} else if tempResult.Error() != nil {
+			// fetching a subsequent page failed (e.g. context cancelled or deadline exceeded): report the error, not a partial result
+			tempResult.RequestCharge += result.RequestCharge
+			tempResult.RetryCount += result.RetryCount
+			tempResult.RetryWait += result.RetryWait
+			result = tempResult
+			break
 		} else {
 			result.ContinuationToken = tempResult.ContinuationToken
 			result.Etag = tempResult.Etag
 			result.SessionToken = tempResult.SessionToken
 			result.RequestCharge += tempResult.RequestCharge
+			result.RetryCount += tempResult.RetryCount
+			result.RetryWait += tempResult.RetryWait
 			result.Count += tempResult.Count
 			result.Documents = append(result.Documents, tempResult.Documents...)
-			if r.IsIncrementalFeed {
+			if r.IsFullFidelityFeed {
+				// changes are ordered by LSN, documents are kept in the order they were received
+				result.Changes = append(result.Changes, tempResult.Changes...)
+				sort.SliceStable(result.Changes, func(i, j int) bool {
+					return result.Changes[i].Lsn < result.Changes[j].Lsn
+				})
+			} else if r.IsIncrementalFeed {
 				sort.Slice(result.Documents, func(i, j int) bool {
 					return result.Documents[i].Ts() < result.Documents[j].Ts()
 				})
@@ -1023,14 +1842,25 @@ func (c *RestClient) getChangeFeed(r ListDocsReq, req *http.Request) *RespListDo
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/list-documents.
 //
 // Note: if fetching incremental feed (ListDocsReq.IsIncrementalFeed = true), it is the caller responsibility to
-// resubmit the request with proper value of etag (ListDocsReq.NotMatchEtag)
+// resubmit the request with proper value of etag (ListDocsReq.NotMatchEtag). (since v1.2.0) See ChangeFeedProcessor
+// for a managed way to consume the change feed of all partition key ranges, and ListDocsReq.IsFullFidelityFeed to also
+// receive deletes and intermediate versions of documents.
 func (c *RestClient) ListDocuments(r ListDocsReq) *RespListDocs {
-	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+r.DbName+"/colls/"+r.CollName+"/docs"
-	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	return c.ListDocumentsContext(context.Background(), r)
+}
+
+// ListDocumentsContext is similar to ListDocuments but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListDocumentsContext(ctx context.Context, r ListDocsReq) *RespListDocs {
+	if err := r.validateFullFidelityFeed(); err != nil {
+		return &RespListDocs{RestResponse: RestResponse{CallErr: err}}
+	}
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+r.DbName+"/colls/"+r.CollName+"/docs")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
 	if err != nil {
 		return &RespListDocs{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName)
 	req.Header.Set(restApiHeaderEnableCrossPartitionQuery, "true")
 	if r.MaxItemCount > 0 {
 		req.Header.Set(restApiHeaderPageSize, strconv.Itoa(r.MaxItemCount))
@@ -1053,16 +1883,25 @@ func (c *RestClient) ListDocuments(r ListDocsReq) *RespListDocs {
 	if r.PkRangeId != "" {
 		req.Header.Set(restApiHeaderPartitionKeyRangeId, r.PkRangeId)
 	}
-	if r.IsIncrementalFeed {
-		req.Header.Set(restApiHeaderIncremental, "Incremental feed")
+	if r.IsIncrementalFeed || r.IsFullFidelityFeed {
+		if r.IsFullFidelityFeed {
+			req.Header.Set(restApiHeaderIncremental, "Full-Fidelity Feed")
+			req.Header.Set(restApiHeaderChangeFeedWireFormatVersion, "2021-09-15")
+		} else {
+			req.Header.Set(restApiHeaderIncremental, "Incremental feed")
+		}
+		if r.NotMatchEtag == "" {
+			if err := r.ChangeFeedStart.setHeaders(req); err != nil {
+				return &RespListDocs{RestResponse: RestResponse{CallErr: err}}
+			}
+		}
 		return c.getChangeFeed(r, req)
 	}
 
 	// fetch documents from table/collection
 	var result *RespListDocs
 	for {
-		resp := c.client.Do(req)
-		tempResult := &RespListDocs{RestResponse: c.buildRestResponse(resp)}
+		tempResult := &RespListDocs{RestResponse: c.execRequest(req, "docs", "dbs/"+r.DbName+"/colls/"+r.CollName)}
 		if tempResult.CallErr == nil {
 			tempResult.ContinuationToken = tempResult.RespHeader[respHeaderContinuation]
 			tempResult.Etag = tempResult.RespHeader[respHeaderEtag]
@@ -1070,11 +1909,20 @@ func (c *RestClient) ListDocuments(r ListDocsReq) *RespListDocs {
 		}
 		if result == nil {
 			result = tempResult
+		} else if tempResult.Error() != nil {
+			// fetching a subsequent page failed (e.g. context cancelled or deadline exceeded): report the error, not a partial result
+			tempResult.RequestCharge += result.RequestCharge
+			tempResult.RetryCount += result.RetryCount
+			tempResult.RetryWait += result.RetryWait
+			result = tempResult
+			break
 		} else {
 			result.ContinuationToken = tempResult.ContinuationToken
 			result.Etag = tempResult.Etag
 			result.SessionToken = tempResult.SessionToken
 			result.RequestCharge += tempResult.RequestCharge
+			result.RetryCount += tempResult.RetryCount
+			result.RetryWait += tempResult.RetryWait
 			result.Count += tempResult.Count
 			result.Documents = append(result.Documents, tempResult.Documents...)
 		}
@@ -1090,7 +1938,14 @@ func (c *RestClient) ListDocuments(r ListDocsReq) *RespListDocs {
 //
 // Available since v0.1.1
 func (c *RestClient) GetOfferForResource(rid string) *RespGetOffer {
-	queryResult := c.QueryOffers(`SELECT * FROM root WHERE root.offerResourceId="` + rid + `"`)
+	return c.GetOfferForResourceContext(context.Background(), rid)
+}
+
+// GetOfferForResourceContext is similar to GetOfferForResource but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetOfferForResourceContext(ctx context.Context, rid string) *RespGetOffer {
+	queryResult := c.QueryOffersContext(ctx, `SELECT * FROM root WHERE root.offerResourceId="`+rid+`"`)
 	result := &RespGetOffer{RestResponse: queryResult.RestResponse}
 	if result.Error() == nil {
 		if len(queryResult.Offers) == 0 {
@@ -1109,17 +1964,22 @@ func (c *RestClient) GetOfferForResource(rid string) *RespGetOffer {
 //
 // Available since v0.1.1
 func (c *RestClient) QueryOffers(query string) *RespQueryOffers {
-	method, urlEndpoint := "POST", c.endpoint+"/offers"
-	req, err := c.buildJsonRequest(method, urlEndpoint, map[string]interface{}{"query": query})
+	return c.QueryOffersContext(context.Background(), query)
+}
+
+// QueryOffersContext is similar to QueryOffers but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) QueryOffersContext(ctx context.Context, query string) *RespQueryOffers {
+	method, urlEndpoint := "POST", c.resourceUrl("offers")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"query": query})
 	if err != nil {
 		return &RespQueryOffers{RestResponse: RestResponse{CallErr: err}}
 	}
-	req = c.addAuthHeader(req, method, "offers", "")
 	req.Header.Set(httpHeaderContentType, "application/query+json")
 	req.Header.Set(restApiHeaderIsQuery, "true")
 
-	resp := c.client.Do(req)
-	result := &RespQueryOffers{RestResponse: c.buildRestResponse(resp)}
+	result := &RespQueryOffers{RestResponse: c.execRequest(req, "offers", "")}
 	if result.CallErr == nil {
 		result.ContinuationToken = result.RespHeader[respHeaderContinuation]
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
@@ -1166,6 +2026,13 @@ func (c *RestClient) buildReplaceOfferContentAndHeaders(currentOffer OfferInfo,
 //
 // Available since v0.1.1
 func (c *RestClient) ReplaceOfferForResource(rid string, ru, maxru int) *RespReplaceOffer {
+	return c.ReplaceOfferForResourceContext(context.Background(), rid, ru, maxru)
+}
+
+// ReplaceOfferForResourceContext is similar to ReplaceOfferForResource but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceOfferForResourceContext(ctx context.Context, rid string, ru, maxru int) *RespReplaceOffer {
 	if ru > 0 && maxru > 0 {
 		return &RespReplaceOffer{
 			RestResponse: RestResponse{
@@ -1175,9 +2042,9 @@ func (c *RestClient) ReplaceOfferForResource(rid string, ru, maxru int) *RespRep
 		}
 	}
 
-	getResult := c.GetOfferForResource(rid)
+	getResult := c.GetOfferForResourceContext(ctx, rid)
 	if getResult.Error() == nil {
-		method, urlEndpoint := "PUT", c.endpoint+"/offers/"+getResult.OfferInfo.Rid
+		method, urlEndpoint := "PUT", c.resourceUrl("offers/"+getResult.OfferInfo.Rid)
 		params := map[string]interface{}{
 			"offerVersion": "V2", "offerType": "Invalid",
 			"resource":        getResult.OfferInfo.Resource,
@@ -1190,7 +2057,7 @@ func (c *RestClient) ReplaceOfferForResource(rid string, ru, maxru int) *RespRep
 			return &RespReplaceOffer{RestResponse: getResult.RestResponse, OfferInfo: getResult.OfferInfo}
 		}
 		params[restApiParamContent] = content
-		req, err := c.buildJsonRequest(method, urlEndpoint, params)
+		req, err := c.buildJsonRequest(ctx, method, urlEndpoint, params)
 		if err != nil {
 			return &RespReplaceOffer{RestResponse: RestResponse{CallErr: err}}
 		}
@@ -1200,15 +2067,13 @@ func (c *RestClient) ReplaceOfferForResource(rid string, ru, maxru int) *RespRep
 		 * issuing the 'replace-offer' request.
 		 * Not sure if this is intended or a bug of Cosmos DB.
 		 */
-		req = c.addAuthHeader(req, method, "offers", strings.ToLower(getResult.OfferInfo.Rid))
 		for k, v := range headers {
 			req.Header.Set(k, v)
 		}
-		resp := c.client.Do(req)
-		result := &RespReplaceOffer{RestResponse: c.buildRestResponse(resp)}
+		result := &RespReplaceOffer{RestResponse: c.execRequest(req, "offers", strings.ToLower(getResult.OfferInfo.Rid))}
 		if result.CallErr == nil {
 			if (headers[restApiHeaderMigrateToAutopilotThroughput] == "true" && maxru > 0) || (headers[restApiHeaderMigrateToManualThroughput] == "true" && ru > 0) {
-				return c.ReplaceOfferForResource(rid, ru, maxru)
+				return c.ReplaceOfferForResourceContext(ctx, rid, ru, maxru)
 			}
 			result.CallErr = json.Unmarshal(result.RespBody, &result.OfferInfo)
 		}
@@ -1235,6 +2100,14 @@ type RestResponse struct {
 	RequestCharge float64
 	// SessionToken is used with session level consistency. Clients must save this value and set it for subsequent read requests for session consistency.
 	SessionToken string
+	// RetryCount is the number of times the request was retried (see RetryPolicy).
+	//
+	// @Available since v1.2.0
+	RetryCount int
+	// RetryWait is the total time spent waiting between retries.
+	//
+	// @Available since v1.2.0
+	RetryWait time.Duration
 }
 
 // Error returns CallErr if not nil, ApiErr otherwise.
@@ -1505,28 +2378,35 @@ func _convertToFloats(i, j interface{}) (float64, float64, bool) {
 func (docs QueriedDocs) mergeOrderBy(queryPlan *RespQueryPlan, otherDocs QueriedDocs) QueriedDocs {
 	result := append(docs, otherDocs...)
 	sort.Slice(result, func(i, j int) bool {
-		iOrderByItems := result[i].(map[string]interface{})["orderByItems"].([]interface{})
-		jOrderByItems := result[j].(map[string]interface{})["orderByItems"].([]interface{})
-		for index, odir := range queryPlan.QueryInfo.OrderBy {
-			odir = strings.ToUpper(odir)
-			iItem := iOrderByItems[index].(map[string]interface{})["item"]
-			jItem := jOrderByItems[index].(map[string]interface{})["item"]
-			if iItem == jItem {
-				continue
-			}
-
-			if istr, jstr, ok := _convertToStrings(iItem, jItem); ok {
-				return (odir == "DESCENDING" && istr > jstr) || (odir != "DESCENDING" && istr < jstr)
-			}
-			if ifloat, jfloat, ok := _convertToFloats(iItem, jItem); ok {
-				return (odir == "DESCENDING" && ifloat > jfloat) || (odir != "DESCENDING" && ifloat < jfloat)
-			}
-		}
-		return false
+		return _orderByLess(queryPlan, result[i], result[j])
 	})
 	return result
 }
 
+// _orderByLess reports whether document i must come before document j according to the query's "order by" clause.
+//
+// This function assumes each document has the following structure: `{"orderByItems": [...], payload: {...}}`.
+func _orderByLess(queryPlan *RespQueryPlan, i, j interface{}) bool {
+	iOrderByItems := i.(map[string]interface{})["orderByItems"].([]interface{})
+	jOrderByItems := j.(map[string]interface{})["orderByItems"].([]interface{})
+	for index, odir := range queryPlan.QueryInfo.OrderBy {
+		odir = strings.ToUpper(odir)
+		iItem := iOrderByItems[index].(map[string]interface{})["item"]
+		jItem := jOrderByItems[index].(map[string]interface{})["item"]
+		if iItem == jItem {
+			continue
+		}
+
+		if istr, jstr, ok := _convertToStrings(iItem, jItem); ok {
+			return (odir == "DESCENDING" && istr > jstr) || (odir != "DESCENDING" && istr < jstr)
+		}
+		if ifloat, jfloat, ok := _convertToFloats(iItem, jItem); ok {
+			return (odir == "DESCENDING" && ifloat > jfloat) || (odir != "DESCENDING" && ifloat < jfloat)
+		}
+	}
+	return false
+}
+
 // AsDocInfoAt returns the i-th queried document as a DocInfo.
 func (docs QueriedDocs) AsDocInfoAt(i int) DocInfo {
 	switch docInfo := docs[i].(type) {
@@ -1601,18 +2481,8 @@ func (docs QueriedDocs) Flatten(queryPlan *RespQueryPlan) QueriedDocs {
 func (docs QueriedDocs) ReduceDistinct(queryPlan *RespQueryPlan) QueriedDocs {
 	itemMap := make(map[string]bool)
 	result := make(QueriedDocs, 0)
-	queryRewritten := queryPlan.QueryInfo.RewrittenQuery != ""
-	hf1, hf2 := checksum.Crc32HashFunc, checksum.Md5HashFunc // CRC32 + MD5 hashing is fast (is MD5 + SHA1 better?)
 	for _, doc := range docs {
-		item := doc
-		if docAsMap, typOk := doc.(map[string]interface{}); typOk && queryRewritten {
-			ok := false
-			if item, ok = docAsMap["payload"]; !ok {
-				// fallback
-				item = doc
-			}
-		}
-		key := fmt.Sprintf("%x:%x", checksum.Checksum(hf1, item), checksum.Checksum(hf2, item))
+		key := _distinctKey(queryPlan, doc)
 		if _, ok := itemMap[key]; !ok {
 			itemMap[key] = true
 			result = append(result, doc)
@@ -1621,6 +2491,20 @@ func (docs QueriedDocs) ReduceDistinct(queryPlan *RespQueryPlan) QueriedDocs {
 	return result
 }
 
+// _distinctKey returns the key identifying a row of a SELECT DISTINCT query, rows having the same key are duplicates.
+func _distinctKey(queryPlan *RespQueryPlan, doc interface{}) string {
+	item := doc
+	if docAsMap, typOk := doc.(map[string]interface{}); typOk && queryPlan.QueryInfo.RewrittenQuery != "" {
+		ok := false
+		if item, ok = docAsMap["payload"]; !ok {
+			// fallback
+			item = doc
+		}
+	}
+	hf1, hf2 := checksum.Crc32HashFunc, checksum.Md5HashFunc // CRC32 + MD5 hashing is fast (is MD5 + SHA1 better?)
+	return fmt.Sprintf("%x:%x", checksum.Checksum(hf1, item), checksum.Checksum(hf2, item))
+}
+
 // ReduceGroupBy merge rows returned from a SELECT...GROUP BY "rewritten" query.
 //
 // Available since v0.2.0
@@ -1790,6 +2674,46 @@ type RespDeleteDoc struct {
 	RestResponse
 }
 
+// RespPatchDoc captures the response from RestClient.PatchDocument call.
+//
+// @Available since v1.2.0
+type RespPatchDoc struct {
+	RestResponse
+	DocInfo
+}
+
+// BatchOpResult captures the result of an operation of a transactional batch.
+//
+// @Available since v1.2.0
+type BatchOpResult struct {
+	StatusCode    int     `json:"statusCode"`
+	SubStatusCode int     `json:"subStatusCode"`
+	RequestCharge float64 `json:"requestCharge"`
+	Etag          string  `json:"eTag"`
+	RetryAfterMs  float64 `json:"retryAfterMilliseconds"` // delay suggested by server before retrying a throttled (429) operation
+	DocInfo       DocInfo `json:"resourceBody"`           // the document, if returned by the operation
+}
+
+// RespExecuteBatch captures the response from RestClient.ExecuteBatch call.
+//
+// @Available since v1.2.0
+type RespExecuteBatch struct {
+	RestResponse
+	Results []BatchOpResult // results of the operations, in the same order as in the request
+}
+
+// FailedOperation returns the index of the operation that caused the batch to fail, or -1 if the batch succeeded.
+//
+// Note: when a batch fails, other operations are reported with status 424 (Failed Dependency).
+func (r *RespExecuteBatch) FailedOperation() int {
+	for i, opResult := range r.Results {
+		if opResult.StatusCode >= 400 && opResult.StatusCode != 424 {
+			return i
+		}
+	}
+	return -1
+}
+
 // RespQueryDocs captures the response from RestClient.QueryDocuments call.
 type RespQueryDocs struct {
 	RestResponse       `json:"-"`
@@ -1874,6 +2798,10 @@ type RespListDocs struct {
 	Documents         []DocInfo `json:"Documents"`
 	ContinuationToken string    `json:"-"`
 	Etag              string    `json:"-"` // logical sequence number (LSN) of last document returned in the response
+
+	// (since v1.2.0) changes returned by a full-fidelity change feed (ListDocsReq.IsFullFidelityFeed), ordered by LSN.
+	// Documents contains the raw change records in this case.
+	Changes []ChangeFeedRecord `json:"-"`
 }
 
 // OfferInfo captures info of a Cosmos DB offer.
@@ -1966,6 +2894,9 @@ type PkrangeInfo struct {
 	Ts           int64  `json:"_ts"`          // (system-generated property) _ts attribute of the pkrange
 	Self         string `json:"_self"`        // (system-generated property) _self attribute of the pkrange
 	Etag         string `json:"_etag"`        // (system-generated property) _etag attribute of the pkrange
+
+	// (since v1.2.0) ids of the partition key ranges this range was split from, if any
+	Parents []string `json:"parents,omitempty"`
 }
 
 // RespGetPkranges captures the response from GetPkranges call.
@@ -0,0 +1,373 @@
+package gocosmos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultBulkConcurrency is the number of requests RestClient.ExecuteBulk sends concurrently if
+// BulkReq.MaxConcurrency is not specified.
+//
+// @Available since v1.2.0
+const DefaultBulkConcurrency = 8
+
+// bulkChunkMaxBytes is the (approximate) max payload size of a request sent by RestClient.ExecuteBulk, below the 2MB
+// limit of a batch request.
+const bulkChunkMaxBytes = 1 << 20
+
+// bulkMaxPendingItems is the max number of items RestClient.ExecuteBulk keeps waiting for their group to fill up.
+const bulkMaxPendingItems = 10000
+
+// bulkFlushInterval is how long RestClient.ExecuteBulk waits for the next item from BulkReq.Source before sending
+// incomplete groups.
+const bulkFlushInterval = 100 * time.Millisecond
+
+// BulkItem is an item of a bulk request.
+//
+// @Available since v1.2.0
+type BulkItem struct {
+	// Op is the operation type, one of BatchOpCreate, BatchOpUpsert, BatchOpReplace, BatchOpDelete or BatchOpPatch.
+	// If empty, BatchOpUpsert is used if DocumentSpec.IsUpsert is true, BatchOpCreate otherwise.
+	Op string
+
+	// DocumentSpec specifies the target collection, the partition key value and the document.
+	// For BatchOpDelete and BatchOpPatch operations, only the "id" field of DocumentSpec.DocumentData is used.
+	DocumentSpec
+
+	// MatchEtag, if not empty, applies the operation only if the document's etag matches (optimistic concurrency);
+	// otherwise the item fails with status code 412.
+	MatchEtag string
+
+	// Operations are the patch operations of a BatchOpPatch operation.
+	Operations []PatchOp
+}
+
+// BulkReq specifies a bulk request.
+//
+// @Available since v1.2.0
+type BulkReq struct {
+	Items          []BulkItem             // items to process
+	Source         <-chan BulkItem        // optional stream of items, read until closed (after Items have been dispatched)
+	MaxConcurrency int                    // max number of concurrent requests, DefaultBulkConcurrency if not specified
+	OnResult       func(r BulkItemResult) // optional, if specified it is called for each processed item (one call at a time) and RespBulk.Results is not populated
+}
+
+// BulkItemResult captures the result of an item of a bulk request.
+//
+// @Available since v1.2.0
+type BulkItemResult struct {
+	Index         int     // index of the item: items from BulkReq.Items come first, followed by items read from BulkReq.Source
+	StatusCode    int     // status code of the operation
+	RequestCharge float64 // request units consumed by the operation
+	Etag          string  // etag of the document, if returned by the operation
+	DocInfo       DocInfo // the document, if returned by the operation
+	RetryCount    int     // number of times the item was retried because it was throttled
+	Err           error   // nil if the item was processed successfully
+}
+
+// RespBulk captures the response from RestClient.ExecuteBulk call.
+//
+// RestResponse.RequestCharge, RestResponse.RetryCount and RestResponse.RetryWait are totals across all requests.
+// RestResponse.ApiErr is set if at least one item failed, RestResponse.CallErr is set if the context was done before
+// all items had been processed.
+//
+// @Available since v1.2.0
+type RespBulk struct {
+	RestResponse
+	Results   []BulkItemResult // results of the items, sorted by BulkItemResult.Index
+	NumItems  int              // number of processed items
+	NumFailed int              // number of failed items
+}
+
+// bulkEntry is an item of a bulk request, waiting to be dispatched.
+type bulkEntry struct {
+	index   int
+	op      BatchOp
+	retries int
+}
+
+// bulkChunk is a group of entries targeting the same logical partition, sent in one request.
+type bulkChunk struct {
+	dbName, collName   string
+	partitionKeyValues []interface{}
+	entries            []*bulkEntry
+	size               int
+}
+
+// bulkCollector aggregates results of a bulk request.
+type bulkCollector struct {
+	lock     sync.Mutex
+	result   *RespBulk
+	onResult func(r BulkItemResult)
+}
+
+func (col *bulkCollector) addResponse(resp RestResponse) {
+	col.lock.Lock()
+	defer col.lock.Unlock()
+	if resp.RequestCharge > 0 {
+		col.result.RequestCharge += resp.RequestCharge
+	}
+	col.result.RetryCount += resp.RetryCount
+	col.result.RetryWait += resp.RetryWait
+}
+
+func (col *bulkCollector) addItem(r BulkItemResult) {
+	col.lock.Lock()
+	defer col.lock.Unlock()
+	col.result.NumItems++
+	if r.Err != nil {
+		col.result.NumFailed++
+	}
+	if col.onResult != nil {
+		col.onResult(r)
+	} else {
+		col.result.Results = append(col.result.Results, r)
+	}
+}
+
+// ExecuteBulk invokes Cosmos DB API to create, upsert, replace or delete a large number of documents.
+//
+// Items are grouped by collection and logical partition (documents of a logical partition always belong to the same
+// partition key range), and each group is sent as non-atomic batches of up to MaxBatchOperations operations; up to
+// BulkReq.MaxConcurrency requests are in flight at any time. Unlike ExecuteBatch, operations are independent: a failed
+// item does not affect the others. Items that are throttled (429) are retried individually, honoring the delay
+// suggested by the server, up to RetryPolicy.MaxRetries times.
+//
+// Note: items are not grouped by partition key range, so throughput depends on the cardinality of the partition key.
+// Items of a logical partition share requests, but items whose partition key values are all different (e.g. with
+// partition key /id) are sent one request per item, i.e. no faster than BulkReq.MaxConcurrency concurrent
+// CreateDocument calls.
+//
+// @Available since v1.2.0
+func (c *RestClient) ExecuteBulk(r BulkReq) *RespBulk {
+	return c.ExecuteBulkContext(context.Background(), r)
+}
+
+// ExecuteBulkContext is similar to ExecuteBulk but with a context.Context that is attached to the underlying HTTP request(s).
+// Once the context is done, no more items are read from BulkReq.Source and items not processed yet fail with the
+// context's error.
+//
+// @Available since v1.2.0
+func (c *RestClient) ExecuteBulkContext(ctx context.Context, r BulkReq) *RespBulk {
+	concurrency := r.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+	col := &bulkCollector{result: &RespBulk{}, onResult: r.OnResult}
+	chunks := make(chan *bulkChunk)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunks {
+				c.execBulkChunk(ctx, chunk, col)
+			}
+		}()
+	}
+	c.dispatchBulkItems(ctx, r, chunks, col)
+	close(chunks)
+	wg.Wait()
+
+	result := col.result
+	sort.Slice(result.Results, func(i, j int) bool { return result.Results[i].Index < result.Results[j].Index })
+	if err := ctx.Err(); err != nil {
+		result.CallErr = err
+	} else if result.NumFailed > 0 {
+		result.ApiErr = fmt.Errorf("error executing Azure Cosmos DB bulk request; %d of %d items failed", result.NumFailed, result.NumItems)
+	}
+	return result
+}
+
+// dispatchBulkItems groups items by logical partition and sends them to workers in chunks. Items of different logical
+// partitions are never sent in the same chunk, even if they belong to the same partition key range.
+func (c *RestClient) dispatchBulkItems(ctx context.Context, r BulkReq, chunks chan<- *bulkChunk, col *bulkCollector) {
+	groups, pending := make(map[string]*bulkChunk), 0
+	send := func(key string) {
+		chunk := groups[key]
+		delete(groups, key)
+		pending -= len(chunk.entries)
+		chunks <- chunk
+	}
+	flushAll := func() {
+		for key := range groups {
+			send(key)
+		}
+	}
+	index := 0
+	add := func(item BulkItem) {
+		defer func() { index++ }()
+		op, err := item.batchOp()
+		if err != nil {
+			col.addItem(BulkItemResult{Index: index, Err: err})
+			return
+		}
+		jsPkValues, err := json.Marshal(item.PartitionKeyValues)
+		if err != nil {
+			col.addItem(BulkItemResult{Index: index, Err: err})
+			return
+		}
+		size := 64
+		if item.DocumentData != nil {
+			js, _ := json.Marshal(item.DocumentData)
+			size += len(js)
+		}
+		if item.Operations != nil {
+			js, _ := json.Marshal(item.Operations)
+			size += len(js)
+		}
+		key := item.DbName + "/" + item.CollName + "/" + string(jsPkValues)
+		if chunk := groups[key]; chunk != nil && chunk.size+size > bulkChunkMaxBytes {
+			send(key)
+		}
+		chunk := groups[key]
+		if chunk == nil {
+			chunk = &bulkChunk{dbName: item.DbName, collName: item.CollName, partitionKeyValues: item.PartitionKeyValues}
+			groups[key] = chunk
+		}
+		chunk.entries = append(chunk.entries, &bulkEntry{index: index, op: op})
+		chunk.size += size
+		pending++
+		if len(chunk.entries) >= MaxBatchOperations {
+			send(key)
+		} else if pending >= bulkMaxPendingItems {
+			// too many items waiting for their group to fill up
+			flushAll()
+		}
+	}
+	for _, item := range r.Items {
+		add(item)
+	}
+	if r.Source != nil {
+	loop:
+		for {
+			timer := time.NewTimer(bulkFlushInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				break loop
+			case <-timer.C:
+				// the source is slow, do not let items wait
+				flushAll()
+			case item, ok := <-r.Source:
+				timer.Stop()
+				if !ok {
+					break loop
+				}
+				add(item)
+			}
+		}
+	}
+	flushAll()
+}
+
+// batchOp converts the bulk item into a batch operation.
+func (item BulkItem) batchOp() (BatchOp, error) {
+	op := item.Op
+	if op == "" {
+		op = BatchOpCreate
+		if item.IsUpsert {
+			op = BatchOpUpsert
+		}
+	}
+	switch op {
+	case BatchOpCreate, BatchOpUpsert, BatchOpReplace:
+		return BatchOp{Op: op, Document: item.DocumentData, MatchEtag: item.MatchEtag}, nil
+	case BatchOpDelete:
+		id, _ := item.DocumentData[docFieldId].(string)
+		return BatchOp{Op: op, Id: id, MatchEtag: item.MatchEtag}, nil
+	case BatchOpPatch:
+		id, _ := item.DocumentData[docFieldId].(string)
+		return BatchOp{Op: op, Id: id, MatchEtag: item.MatchEtag, Operations: item.Operations}, nil
+	}
+	return BatchOp{}, fmt.Errorf("unsupported bulk operation %q", op)
+}
+
+// execBulkChunk sends the chunk's entries as a non-atomic batch, retrying throttled entries. As for other requests (see
+// RetryPolicy), entries are retried up to MaxRetries times, and while the total wait time does not exceed MaxRetryWait.
+func (c *RestClient) execBulkChunk(ctx context.Context, chunk *bulkChunk, col *bulkCollector) {
+	entries := chunk.entries
+	totalWait := time.Duration(0)
+	for len(entries) > 0 {
+		if err := ctx.Err(); err != nil {
+			for _, e := range entries {
+				col.addItem(BulkItemResult{Index: e.index, RetryCount: e.retries, Err: err})
+			}
+			return
+		}
+		batchReq := BatchReq{DbName: chunk.dbName, CollName: chunk.collName, PartitionKeyValues: chunk.partitionKeyValues}
+		for _, e := range entries {
+			batchReq.Operations = append(batchReq.Operations, e.op)
+		}
+		resp := c.executeBatch(ctx, batchReq, false)
+		col.addResponse(resp.RestResponse)
+		if len(resp.Results) != len(entries) {
+			// the whole request failed
+			err := resp.Error()
+			if err == nil {
+				err = fmt.Errorf("error executing Azure Cosmos DB bulk request; expected %d results but received %d", len(entries), len(resp.Results))
+			}
+			for _, e := range entries {
+				col.addItem(BulkItemResult{Index: e.index, StatusCode: resp.StatusCode, RetryCount: e.retries, Err: err})
+			}
+			return
+		}
+
+		retry := make([]bool, len(entries))
+		var wait time.Duration
+		for i, e := range entries {
+			opResult := resp.Results[i]
+			if opResult.StatusCode == 429 && e.retries < c.retryPolicy.MaxRetries {
+				delay := time.Duration(opResult.RetryAfterMs * float64(time.Millisecond))
+				if delay <= 0 {
+					delay = c.backoffDelay(e.retries)
+				}
+				if delay > wait {
+					wait = delay
+				}
+				retry[i] = true
+			}
+		}
+		if c.retryPolicy.MaxRetryWait > 0 && totalWait+wait > c.retryPolicy.MaxRetryWait {
+			// waiting would exceed the max total wait time: throttled entries are reported as failed
+			retry = make([]bool, len(entries))
+		}
+
+		var throttled []*bulkEntry
+		for i, e := range entries {
+			if retry[i] {
+				e.retries++
+				throttled = append(throttled, e)
+				continue
+			}
+			opResult := resp.Results[i]
+			itemResult := BulkItemResult{
+				Index:         e.index,
+				StatusCode:    opResult.StatusCode,
+				RequestCharge: opResult.RequestCharge,
+				Etag:          opResult.Etag,
+				DocInfo:       opResult.DocInfo,
+				RetryCount:    e.retries,
+			}
+			if opResult.StatusCode >= 400 {
+				itemResult.Err = fmt.Errorf("error executing Azure Cosmos DB bulk operation; StatusCode=%d;SubStatusCode=%d", opResult.StatusCode, opResult.SubStatusCode)
+			}
+			col.addItem(itemResult)
+		}
+		if len(throttled) > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+			col.addResponse(RestResponse{RetryWait: wait})
+			totalWait += wait
+		}
+		entries = throttled
+	}
+}
@@ -0,0 +1,630 @@
+package gocosmos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btnguyen2k/consu/reddo"
+)
+
+// Default settings of ChangeFeedProcessorOptions.
+//
+// @Available since v1.2.0
+const (
+	DefaultChangeFeedPollInterval         = 5 * time.Second
+	DefaultChangeFeedLeaseExpiration      = 60 * time.Second
+	DefaultChangeFeedLeaseRenewInterval   = 17 * time.Second
+	DefaultChangeFeedLeaseAcquireInterval = 13 * time.Second
+)
+
+// ChangeFeedHandler processes a batch of changed documents. If it returns an error, the batch is not checkpointed and
+// is delivered again later.
+//
+// @Available since v1.2.0
+type ChangeFeedHandler func(ctx context.Context, docs []DocInfo) error
+
+// ChangeFeedProcessorOptions specifies a change feed processor.
+//
+// @Available since v1.2.0
+type ChangeFeedProcessorOptions struct {
+	DbName, CollName           string            // the monitored collection
+	LeaseDbName, LeaseCollName string            // the lease collection, must be partitioned by /id; LeaseDbName defaults to DbName
+	ProcessorName              string            // name of the processor, instances with the same name share the work
+	InstanceName               string            // unique name of this instance, a random one is generated if empty
+	Handler                    ChangeFeedHandler // (required) invoked with batches of changed documents
+	ErrorHandler               func(err error)   // optional, invoked with errors that do not stop the processor (e.g. failed handler or lost lease)
+	MaxItemCount               int               // max number of documents per batch, default 100
+	PollInterval               time.Duration     // delay before polling a partition key range again when there is no new change
+	LeaseExpiration            time.Duration     // a lease that has not been renewed for this duration can be taken over by other instances
+	LeaseRenewInterval         time.Duration     // how often owned leases are renewed
+	LeaseAcquireInterval       time.Duration     // how often leases are discovered and balanced across instances
+	StartFrom                  ChangeFeedStart   // where to start reading partition key ranges that have no checkpoint yet, from the beginning by default
+}
+
+// ChangeFeedLease is a lease document, stored in the lease collection. Each lease tracks the progress of a partition
+// key range of the monitored collection.
+//
+// @Available since v1.2.0
+type ChangeFeedLease struct {
+	Id                string `json:"id"`
+	Processor         string `json:"processor"`
+	PkRangeId         string `json:"pkRangeId"`
+	Owner             string `json:"owner"`             // name of the instance owning the lease, empty if not owned
+	ContinuationToken string `json:"continuationToken"` // etag (logical sequence number) of the last processed change
+	Timestamp         int64  `json:"timestamp"`         // UNIX timestamp (in milliseconds) of the last time the lease was acquired or renewed
+	Etag              string `json:"_etag,omitempty"`
+}
+
+// ChangeFeedProcessor reads the change feed of a collection, one goroutine per partition key range, and delivers
+// changed documents to a handler. Progress is checkpointed into lease documents, so that processing resumes where it
+// left off after a restart. Multiple instances (e.g. running in different processes) with the same processor name
+// distribute the leases among themselves; leases of an instance that stops renewing them are taken over by the others.
+//
+// Documents are delivered at least once: if an instance stops before checkpointing a batch, the batch is delivered
+// again. The handler is invoked concurrently for different partition key ranges, but batches of a partition key range
+// are delivered in order, one at a time.
+//
+// @Available since v1.2.0
+type ChangeFeedProcessor struct {
+	client  *RestClient
+	opts    ChangeFeedProcessorOptions
+	prefix  string // prefix of lease ids
+	lock    sync.Mutex
+	owned   map[string]*cfLeaseState // owned leases, indexed by lease id
+	workers sync.WaitGroup
+}
+
+// cfLeaseState is a lease owned by the instance.
+type cfLeaseState struct {
+	id, pkRangeId string
+	lock          sync.Mutex
+	lease         ChangeFeedLease // current content of the lease document, guarded by lock
+	cancel        context.CancelFunc
+}
+
+// NewChangeFeedProcessor creates a new change feed processor. Call ChangeFeedProcessor.Run to start processing.
+//
+// @Available since v1.2.0
+func (c *RestClient) NewChangeFeedProcessor(opts ChangeFeedProcessorOptions) (*ChangeFeedProcessor, error) {
+	if opts.DbName == "" || opts.CollName == "" {
+		return nil, errors.New("monitored database/collection is missing")
+	}
+	if opts.LeaseCollName == "" {
+		return nil, errors.New("lease collection is missing")
+	}
+	if opts.Handler == nil {
+		return nil, errors.New("handler is missing")
+	}
+	if err := opts.StartFrom.validate(); err != nil {
+		return nil, err
+	}
+	if opts.LeaseDbName == "" {
+		opts.LeaseDbName = opts.DbName
+	}
+	if opts.InstanceName == "" {
+		opts.InstanceName = strings.ToLower(idGen.Id128Hex())
+	}
+	if opts.MaxItemCount <= 0 {
+		opts.MaxItemCount = 100
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultChangeFeedPollInterval
+	}
+	if opts.LeaseExpiration <= 0 {
+		opts.LeaseExpiration = DefaultChangeFeedLeaseExpiration
+	}
+	if opts.LeaseRenewInterval <= 0 {
+		opts.LeaseRenewInterval = DefaultChangeFeedLeaseRenewInterval
+	}
+	if opts.LeaseAcquireInterval <= 0 {
+		opts.LeaseAcquireInterval = DefaultChangeFeedLeaseAcquireInterval
+	}
+	return &ChangeFeedProcessor{
+		client: c,
+		opts:   opts,
+		prefix: opts.ProcessorName + "." + opts.DbName + "." + opts.CollName + "..",
+		owned:  make(map[string]*cfLeaseState),
+	}, nil
+}
+
+// InstanceName returns the name of this processor instance.
+func (p *ChangeFeedProcessor) InstanceName() string {
+	return p.opts.InstanceName
+}
+
+// OwnedLeases returns the ids of the partition key ranges whose lease is currently owned by this instance.
+func (p *ChangeFeedProcessor) OwnedLeases() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	result := make([]string, 0, len(p.owned))
+	for _, st := range p.owned {
+		result = append(result, st.pkRangeId)
+	}
+	return result
+}
+
+// Run processes the change feed until ctx is done, then releases the owned leases so that other instances can take
+// them over immediately. It returns an error only if the processor can not start, e.g. the monitored or lease
+// collection does not exist.
+func (p *ChangeFeedProcessor) Run(ctx context.Context) error {
+	if err := p.balance(ctx); err != nil {
+		p.shutdown()
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	renewTicker := time.NewTicker(p.opts.LeaseRenewInterval)
+	defer renewTicker.Stop()
+	acquireTicker := time.NewTicker(p.opts.LeaseAcquireInterval)
+	defer acquireTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.shutdown()
+			return nil
+		case <-renewTicker.C:
+			p.renewAll(ctx)
+		case <-acquireTicker.C:
+			if err := p.balance(ctx); err != nil && ctx.Err() == nil {
+				p.reportError(err)
+			}
+		}
+	}
+}
+
+func (p *ChangeFeedProcessor) reportError(err error) {
+	if p.opts.ErrorHandler != nil {
+		p.opts.ErrorHandler(err)
+	}
+}
+
+// shutdown stops the workers and releases the owned leases.
+func (p *ChangeFeedProcessor) shutdown() {
+	p.lock.Lock()
+	owned := make([]*cfLeaseState, 0, len(p.owned))
+	for _, st := range p.owned {
+		st.cancel()
+		owned = append(owned, st)
+	}
+	p.lock.Unlock()
+	p.workers.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, st := range owned {
+		p.updateLease(ctx, st, func(lease *ChangeFeedLease) { lease.Owner = "" })
+		p.removeOwned(st)
+	}
+}
+
+/*----------------------------------------------------------------------*/
+
+func leaseFromDoc(doc DocInfo) (ChangeFeedLease, error) {
+	var lease ChangeFeedLease
+	js, err := json.Marshal(doc)
+	if err == nil {
+		err = json.Unmarshal(js, &lease)
+	}
+	return lease, err
+}
+
+func (lease ChangeFeedLease) toDoc() DocInfo {
+	js, _ := json.Marshal(lease)
+	var doc DocInfo
+	_ = json.Unmarshal(js, &doc)
+	return doc.RemoveSystemAttrs()
+}
+
+// listLeases returns all leases of the processor, indexed by lease id.
+func (p *ChangeFeedProcessor) listLeases(ctx context.Context) (map[string]ChangeFeedLease, error) {
+	resp := p.client.ListDocumentsContext(ctx, ListDocsReq{DbName: p.opts.LeaseDbName, CollName: p.opts.LeaseCollName})
+	if err := resp.Error(); err != nil {
+		return nil, err
+	}
+	leases := make(map[string]ChangeFeedLease)
+	for _, doc := range resp.Documents {
+		if !strings.HasPrefix(doc.Id(), p.prefix) {
+			continue
+		}
+		lease, err := leaseFromDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		leases[lease.Id] = lease
+	}
+	return leases, nil
+}
+
+// createLease creates the lease of a partition key range if it does not exist yet.
+func (p *ChangeFeedProcessor) createLease(ctx context.Context, pkRangeId, continuationToken string) error {
+	lease := ChangeFeedLease{Id: p.prefix + pkRangeId, Processor: p.opts.ProcessorName, PkRangeId: pkRangeId, ContinuationToken: continuationToken}
+	resp := p.client.CreateDocumentContext(ctx, DocumentSpec{
+		DbName:             p.opts.LeaseDbName,
+		CollName:           p.opts.LeaseCollName,
+		PartitionKeyValues: []interface{}{lease.Id},
+		DocumentData:       lease.toDoc(),
+	})
+	if resp.StatusCode == 409 {
+		// another instance created it first
+		return nil
+	}
+	return resp.Error()
+}
+
+// replaceLease replaces the lease document if its etag has not changed, returns the new lease and the status code of
+// the operation.
+func (p *ChangeFeedProcessor) replaceLease(ctx context.Context, lease ChangeFeedLease) (ChangeFeedLease, int, error) {
+	resp := p.client.ReplaceDocumentContext(ctx, lease.Etag, DocumentSpec{
+		DbName:             p.opts.LeaseDbName,
+		CollName:           p.opts.LeaseCollName,
+		PartitionKeyValues: []interface{}{lease.Id},
+		DocumentData:       lease.toDoc(),
+	})
+	if err := resp.Error(); err != nil {
+		return lease, resp.StatusCode, err
+	}
+	newLease, err := leaseFromDoc(resp.DocInfo)
+	return newLease, resp.StatusCode, err
+}
+
+// updateLease applies mutate to an owned lease and saves it. If the lease has been modified concurrently, it is
+// reloaded and mutate is applied again, unless the lease is no longer owned by this instance. Returns false if the
+// lease has been lost.
+func (p *ChangeFeedProcessor) updateLease(ctx context.Context, st *cfLeaseState, mutate func(lease *ChangeFeedLease)) bool {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	for {
+		lease := st.lease
+		mutate(&lease)
+		newLease, statusCode, err := p.replaceLease(ctx, lease)
+		if err == nil {
+			st.lease = newLease
+			return true
+		}
+		if statusCode != 412 {
+			if statusCode == 404 {
+				p.reportError(fmt.Errorf("lease %s has been deleted", st.lease.Id))
+				return false
+			}
+			// transient error, the lease is still owned
+			p.reportError(fmt.Errorf("error updating lease %s: %w", st.lease.Id, err))
+			return true
+		}
+		resp := p.client.GetDocumentContext(ctx, DocReq{DbName: p.opts.LeaseDbName, CollName: p.opts.LeaseCollName, DocId: st.lease.Id, PartitionKeyValues: []interface{}{st.lease.Id}})
+		if resp.Error() != nil {
+			p.reportError(fmt.Errorf("error reloading lease %s: %w", st.lease.Id, resp.Error()))
+			return resp.StatusCode != 404
+		}
+		current, err := leaseFromDoc(resp.DocInfo)
+		if err != nil || current.Owner != p.opts.InstanceName {
+			p.reportError(fmt.Errorf("lease %s has been taken over by instance %q", st.lease.Id, current.Owner))
+			return false
+		}
+		st.lease = current
+	}
+}
+
+func (p *ChangeFeedProcessor) removeOwned(st *cfLeaseState) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.owned[st.id] == st {
+		delete(p.owned, st.id)
+	}
+	st.cancel()
+}
+
+// renewAll renews the owned leases.
+func (p *ChangeFeedProcessor) renewAll(ctx context.Context) {
+	p.lock.Lock()
+	owned := make([]*cfLeaseState, 0, len(p.owned))
+	for _, st := range p.owned {
+		owned = append(owned, st)
+	}
+	p.lock.Unlock()
+	for _, st := range owned {
+		if !p.updateLease(ctx, st, func(lease *ChangeFeedLease) { lease.Timestamp = time.Now().UnixMilli() }) {
+			p.removeOwned(st)
+		}
+	}
+}
+
+// balance discovers partition key ranges, creates missing leases and acquires leases so that work is evenly
+// distributed among active instances.
+func (p *ChangeFeedProcessor) balance(ctx context.Context) error {
+	leases, err := p.listLeases(ctx)
+	if err != nil {
+		return err
+	}
+	pkranges := p.client.GetPkrangesContext(ctx, p.opts.DbName, p.opts.CollName)
+	if err := pkranges.Error(); err != nil {
+		return err
+	}
+	created := false
+	for _, pkrange := range pkranges.Pkranges {
+		if _, ok := leases[p.prefix+pkrange.Id]; ok {
+			continue
+		}
+		splitPending := false
+		for _, parent := range pkrange.Parents {
+			if _, ok := leases[p.prefix+parent]; ok {
+				// the lease of the parent range will be split by its owner
+				splitPending = true
+			}
+		}
+		if !splitPending {
+			if err := p.createLease(ctx, pkrange.Id, ""); err != nil {
+				return err
+			}
+			created = true
+		}
+	}
+	if created {
+		if leases, err = p.listLeases(ctx); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	expired := func(lease ChangeFeedLease) bool {
+		return lease.Owner == "" || now-lease.Timestamp > p.opts.LeaseExpiration.Milliseconds()
+	}
+	leasesPerOwner := map[string]int{p.opts.InstanceName: 0}
+	for _, lease := range leases {
+		if !expired(lease) {
+			leasesPerOwner[lease.Owner]++
+		}
+	}
+
+	// leases owned by this instance that have been taken over by other instances
+	p.lock.Lock()
+	for id, st := range p.owned {
+		if lease, ok := leases[id]; !ok || lease.Owner != p.opts.InstanceName {
+			delete(p.owned, id)
+			st.cancel()
+		}
+	}
+	numOwned := len(p.owned)
+	p.lock.Unlock()
+
+	target := (len(leases) + len(leasesPerOwner) - 1) / len(leasesPerOwner)
+	stolen := false
+	for _, lease := range leases {
+		if numOwned >= target {
+			break
+		}
+		p.lock.Lock()
+		_, isOwned := p.owned[lease.Id]
+		p.lock.Unlock()
+		if isOwned {
+			continue
+		}
+		if lease.Owner != p.opts.InstanceName && !expired(lease) {
+			// take over a lease from the busiest instance, one lease per round so that instances converge smoothly
+			if stolen || leasesPerOwner[lease.Owner] <= target {
+				continue
+			}
+			stolen = true
+			leasesPerOwner[lease.Owner]--
+		}
+		if p.acquire(ctx, lease) {
+			numOwned++
+		}
+	}
+	return nil
+}
+
+// acquire takes ownership of a lease and starts processing its partition key range.
+func (p *ChangeFeedProcessor) acquire(ctx context.Context, lease ChangeFeedLease) bool {
+	lease.Owner, lease.Timestamp = p.opts.InstanceName, time.Now().UnixMilli()
+	newLease, _, err := p.replaceLease(ctx, lease)
+	if err != nil {
+		// most likely, another instance acquired it first
+		return false
+	}
+	workerCtx, cancel := context.WithCancel(ctx)
+	st := &cfLeaseState{id: newLease.Id, pkRangeId: newLease.PkRangeId, lease: newLease, cancel: cancel}
+	p.lock.Lock()
+	p.owned[newLease.Id] = st
+	p.lock.Unlock()
+	p.workers.Add(1)
+	go func() {
+		defer p.workers.Done()
+		p.process(workerCtx, st)
+	}()
+	return true
+}
+
+// sleep waits for the duration, returns false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// process reads the change feed of the lease's partition key range and delivers changes to the handler until ctx is
+// done or the lease is lost.
+func (p *ChangeFeedProcessor) process(ctx context.Context, st *cfLeaseState) {
+	for ctx.Err() == nil {
+		st.lock.Lock()
+		lease := st.lease
+		st.lock.Unlock()
+		resp := p.client.ListDocumentsContext(ctx, ListDocsReq{
+			DbName:            p.opts.DbName,
+			CollName:          p.opts.CollName,
+			MaxItemCount:      p.opts.MaxItemCount,
+			PkRangeId:         lease.PkRangeId,
+			NotMatchEtag:      lease.ContinuationToken,
+			IsIncrementalFeed: true,
+			ChangeFeedStart:   p.opts.StartFrom,
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if resp.StatusCode == 410 {
+			// the partition key range has been split (or merged)
+			if p.split(ctx, st) {
+				return
+			}
+			sleep(ctx, p.opts.PollInterval)
+			continue
+		}
+		if err := resp.Error(); err != nil {
+			p.reportError(fmt.Errorf("error reading change feed of pkrange %s: %w", lease.PkRangeId, err))
+			sleep(ctx, p.opts.PollInterval)
+			continue
+		}
+		if len(resp.Documents) > 0 {
+			if err := p.opts.Handler(ctx, resp.Documents); err != nil {
+				p.reportError(fmt.Errorf("error handling changes of pkrange %s: %w", lease.PkRangeId, err))
+				sleep(ctx, p.opts.PollInterval)
+				continue
+			}
+		}
+		if resp.Etag != "" && resp.Etag != lease.ContinuationToken {
+			checkpointed := p.updateLease(ctx, st, func(lease *ChangeFeedLease) {
+				lease.ContinuationToken, lease.Timestamp = resp.Etag, time.Now().UnixMilli()
+			})
+			if !checkpointed {
+				p.removeOwned(st)
+				return
+			}
+		}
+		if len(resp.Documents) == 0 {
+			sleep(ctx, p.opts.PollInterval)
+		}
+	}
+}
+
+// split replaces the lease of a partition key range that has been split with leases of the child ranges, which
+// continue from the parent's checkpoint. Returns true if the split has been handled.
+func (p *ChangeFeedProcessor) split(ctx context.Context, st *cfLeaseState) bool {
+	st.lock.Lock()
+	lease := st.lease
+	st.lock.Unlock()
+	pkranges := p.client.GetPkrangesContext(ctx, p.opts.DbName, p.opts.CollName)
+	if err := pkranges.Error(); err != nil {
+		p.reportError(fmt.Errorf("error handling split of pkrange %s: %w", lease.PkRangeId, err))
+		return false
+	}
+	numChildren := 0
+	for _, pkrange := range pkranges.Pkranges {
+		for _, parent := range pkrange.Parents {
+			if parent == lease.PkRangeId {
+				if err := p.createLease(ctx, pkrange.Id, lease.ContinuationToken); err != nil {
+					p.reportError(fmt.Errorf("error handling split of pkrange %s: %w", lease.PkRangeId, err))
+					return false
+				}
+				numChildren++
+			}
+		}
+	}
+	if numChildren == 0 {
+		p.reportError(fmt.Errorf("error handling split of pkrange %s: child ranges not found", lease.PkRangeId))
+		return false
+	}
+	resp := p.client.DeleteDocumentContext(ctx, DocReq{DbName: p.opts.LeaseDbName, CollName: p.opts.LeaseCollName,
+		DocId: lease.Id, PartitionKeyValues: []interface{}{lease.Id}})
+	if err := resp.Error(); err != nil && resp.StatusCode != 404 {
+		p.reportError(fmt.Errorf("error deleting lease %s: %w", lease.Id, err))
+	}
+	p.removeOwned(st)
+	return true
+}
+
+// Operation types of a ChangeFeedRecord.
+//
+// @Available since v1.2.0
+const (
+	ChangeFeedOpCreate  = "create"
+	ChangeFeedOpReplace = "replace"
+	ChangeFeedOpDelete  = "delete"
+)
+
+// ChangeFeedRecord is a change returned by the full-fidelity ("all versions and deletes") change feed, see
+// ListDocsReq.IsFullFidelityFeed.
+//
+// @Available since v1.2.0
+type ChangeFeedRecord struct {
+	OperationType     string  // one of ChangeFeedOpCreate, ChangeFeedOpReplace or ChangeFeedOpDelete
+	Lsn               int64   // logical sequence number of the change
+	Crts              int64   // conflict resolution timestamp of the change (epoch seconds)
+	PreviousImageLsn  int64   // logical sequence number of the previous version of the document, 0 if none
+	TimeToLiveExpired bool    // true if the document was deleted because its time-to-live expired
+	Current           DocInfo // the document after the change, nil for delete operations
+	Previous          DocInfo // the document before the change, nil if not retained by the server
+	Metadata          DocInfo // raw metadata of the change
+}
+
+// Id returns the id of the changed document.
+func (r ChangeFeedRecord) Id() string {
+	if id := r.Current.Id(); id != "" {
+		return id
+	}
+	if id := r.Previous.Id(); id != "" {
+		return id
+	}
+	return r.Metadata.Id()
+}
+
+// IsDelete returns true if the change is a deletion.
+func (r ChangeFeedRecord) IsDelete() bool {
+	return r.OperationType == ChangeFeedOpDelete
+}
+
+// docInfoAttr returns the attribute of the document as a DocInfo, nil if it is not an object.
+func docInfoAttr(d DocInfo, attrName string) DocInfo {
+	if v, ok := d[attrName].(map[string]interface{}); ok {
+		return v
+	}
+	return nil
+}
+
+// docInt64Attr returns the attribute of the document as an int64, 0 if it is not a number.
+func docInt64Attr(d DocInfo, attrName string) int64 {
+	if v, ok := d.GetAttrAsTypeUnsafe(attrName, reddo.TypeInt).(int64); ok {
+		return v
+	}
+	return 0
+}
+
+// newChangeFeedRecord parses a raw record of the full-fidelity change feed. Both the current envelope
+// ({"current", "previous", "metadata"}) and the earlier one (the document itself, with "_lsn" and "_metadata"
+// attributes) are recognized.
+func newChangeFeedRecord(doc DocInfo) ChangeFeedRecord {
+	var r ChangeFeedRecord
+	if meta := docInfoAttr(doc, "metadata"); meta != nil {
+		r.Metadata = meta
+		r.Current = docInfoAttr(doc, "current")
+		r.Previous = docInfoAttr(doc, "previous")
+		r.Lsn = docInt64Attr(meta, "lsn")
+	} else {
+		r.Metadata = docInfoAttr(doc, "_metadata")
+		r.Previous = docInfoAttr(r.Metadata, "previousImage")
+		r.Lsn = docInt64Attr(doc, "_lsn")
+		r.Current = doc
+	}
+	r.OperationType, _ = r.Metadata["operationType"].(string)
+	r.OperationType = strings.ToLower(r.OperationType)
+	r.Crts = docInt64Attr(r.Metadata, "crts")
+	r.PreviousImageLsn = docInt64Attr(r.Metadata, "previousImageLSN")
+	r.TimeToLiveExpired, _ = r.Metadata["timeToLiveExpired"].(bool)
+	if r.OperationType == ChangeFeedOpDelete {
+		if r.Previous == nil {
+			// earlier envelope: the deleted document is reported as the current image
+			r.Previous = r.Current
+		}
+		r.Current = nil
+	}
+	return r
+}
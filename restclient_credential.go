@@ -0,0 +1,268 @@
+package gocosmos
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before its expiry a cached access token is refreshed.
+const tokenRefreshMargin = 5 * time.Minute
+
+// Credential produces the authorization header of requests sent to Azure Cosmos DB.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/access-control-on-cosmosdb-resources
+//
+// @Available since v1.2.0
+type Credential interface {
+	// AuthHeader returns the value of the "Authorization" header (before URL-encoding) of a request. date is the value
+	// of the request's "x-ms-date" header.
+	AuthHeader(ctx context.Context, method, resType, resId string, date time.Time) (string, error)
+}
+
+// MasterKeyCredential authenticates requests with HMAC signatures computed from an account key ("type=master").
+//
+// @Available since v1.2.0
+type MasterKeyCredential struct {
+	key []byte
+}
+
+// NewMasterKeyCredential creates a MasterKeyCredential from a base64-encoded account key.
+//
+// @Available since v1.2.0
+func NewMasterKeyCredential(accountKey string) (*MasterKeyCredential, error) {
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot base64 decode account key: %s", err)
+	}
+	return &MasterKeyCredential{key: key}, nil
+}
+
+// AuthHeader implements Credential/AuthHeader.
+func (cred *MasterKeyCredential) AuthHeader(_ context.Context, method, resType, resId string, date time.Time) (string, error) {
+	/*
+	 * M.A.I. 2022-02-16
+	 * The original statement had a single ToLower. In the resulting string the resId gets lowered when from MS Docs it should be left unaltered
+	 * I came across an error on a collection with a mixed case name...
+	 * stringToSign := strings.ToLower(fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", method, resType, resId, now.Format(time.RFC1123), ""))
+	 */
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", strings.ToLower(method), strings.ToLower(resType), resId, strings.ToLower(date.Format(time.RFC1123)), "")
+	h := hmac.New(sha256.New, cred.key)
+	h.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	return "type=master&ver=1.0&sig=" + signature, nil
+}
+
+// StaticTokenCredential authenticates requests with a fixed Microsoft Entra ID (AAD) access token ("type=aad").
+// The token is not refreshed; use TokenCredential for long-running applications.
+//
+// @Available since v1.2.0
+type StaticTokenCredential struct {
+	token string
+}
+
+// NewStaticTokenCredential creates a StaticTokenCredential from an access token.
+//
+// @Available since v1.2.0
+func NewStaticTokenCredential(token string) *StaticTokenCredential {
+	return &StaticTokenCredential{token: token}
+}
+
+// AuthHeader implements Credential/AuthHeader.
+func (cred *StaticTokenCredential) AuthHeader(context.Context, string, string, string, time.Time) (string, error) {
+	return "type=aad&ver=1.0&sig=" + cred.token, nil
+}
+
+// ResourceTokenCredential authenticates requests with resource tokens, i.e. tokens of permissions (see
+// RestClient.CreatePermission and PermissionInfo.Token). Resource tokens grant access to specific resources only.
+//
+// A request uses the token registered for the most specific resource link covering the requested resource (e.g. the
+// token of "dbs/mydb/colls/mytable" is used for documents of collection "mytable"), or the default token if none.
+//
+// @Available since v1.2.0
+type ResourceTokenCredential struct {
+	lock         sync.RWMutex
+	defaultToken string
+	tokens       map[string]string // resource link -> resource token
+}
+
+// NewResourceTokenCredential creates a ResourceTokenCredential with a default resource token, which may be empty if
+// tokens are registered per resource via SetToken.
+//
+// @Available since v1.2.0
+func NewResourceTokenCredential(defaultToken string) *ResourceTokenCredential {
+	return &ResourceTokenCredential{defaultToken: defaultToken, tokens: make(map[string]string)}
+}
+
+// SetToken registers the resource token to use for a resource (and its child resources), e.g. after the token has
+// been renewed. An empty token removes the registration.
+func (cred *ResourceTokenCredential) SetToken(resourceLink, token string) {
+	resourceLink = strings.Trim(resourceLink, "/")
+	cred.lock.Lock()
+	defer cred.lock.Unlock()
+	if token == "" {
+		delete(cred.tokens, resourceLink)
+	} else {
+		cred.tokens[resourceLink] = token
+	}
+}
+
+// AuthHeader implements Credential/AuthHeader.
+func (cred *ResourceTokenCredential) AuthHeader(_ context.Context, _, _, resId string, _ time.Time) (string, error) {
+	cred.lock.RLock()
+	defer cred.lock.RUnlock()
+	token, matched := cred.defaultToken, ""
+	for link, t := range cred.tokens {
+		if (resId == link || strings.HasPrefix(resId, link+"/")) && len(link) > len(matched) {
+			token, matched = t, link
+		}
+	}
+	if token == "" {
+		return "", fmt.Errorf("no resource token for resource %q", resId)
+	}
+	return token, nil
+}
+
+// AccessToken is a Microsoft Entra ID (AAD) access token.
+//
+// @Available since v1.2.0
+type AccessToken struct {
+	Token     string    // the access token
+	ExpiresOn time.Time // when the token expires, zero value means the token never expires
+}
+
+// TokenProvider obtains Microsoft Entra ID (AAD) access tokens for Azure Cosmos DB, e.g. by calling
+// azidentity.DefaultAzureCredential.GetToken with scope "https://<account>.documents.azure.com/.default".
+//
+// @Available since v1.2.0
+type TokenProvider func(ctx context.Context) (AccessToken, error)
+
+// TokenCredential authenticates requests with Microsoft Entra ID (AAD) access tokens ("type=aad") obtained from a
+// TokenProvider. Tokens are cached and refreshed shortly before they expire.
+//
+// @Available since v1.2.0
+type TokenCredential struct {
+	provider  TokenProvider
+	lock      sync.Mutex
+	token     AccessToken
+	refreshAt time.Time
+}
+
+// NewTokenCredential creates a TokenCredential that obtains access tokens from the supplied provider.
+//
+// @Available since v1.2.0
+func NewTokenCredential(provider TokenProvider) *TokenCredential {
+	return &TokenCredential{provider: provider}
+}
+
+// Token returns the cached access token, or a new one if the cached token is about to expire.
+// If refreshing fails but the cached token has not expired yet, the cached token is returned.
+func (cred *TokenCredential) Token(ctx context.Context) (AccessToken, error) {
+	cred.lock.Lock()
+	defer cred.lock.Unlock()
+	now := time.Now()
+	if cred.token.Token != "" && (cred.refreshAt.IsZero() || now.Before(cred.refreshAt)) {
+		return cred.token, nil
+	}
+	token, err := cred.provider(ctx)
+	if err == nil && token.Token == "" {
+		err = errors.New("token provider returned an empty access token")
+	}
+	if err != nil {
+		if cred.token.Token != "" && now.Before(cred.token.ExpiresOn) {
+			return cred.token, nil
+		}
+		return AccessToken{}, fmt.Errorf("cannot obtain access token: %s", err)
+	}
+	cred.token, cred.refreshAt = token, time.Time{}
+	if !token.ExpiresOn.IsZero() {
+		cred.refreshAt = token.ExpiresOn.Add(-tokenRefreshMargin)
+		if half := now.Add(token.ExpiresOn.Sub(now) / 2); cred.refreshAt.Before(half) {
+			// short-lived token
+			cred.refreshAt = half
+		}
+	}
+	return cred.token, nil
+}
+
+// Invalidate discards the cached access token, so that a new one is obtained for the next request.
+func (cred *TokenCredential) Invalidate() {
+	cred.lock.Lock()
+	defer cred.lock.Unlock()
+	cred.token, cred.refreshAt = AccessToken{}, time.Time{}
+}
+
+// AuthHeader implements Credential/AuthHeader.
+func (cred *TokenCredential) AuthHeader(ctx context.Context, _, _, _ string, _ time.Time) (string, error) {
+	token, err := cred.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "type=aad&ver=1.0&sig=" + token.Token, nil
+}
+
+var (
+	tokenProviders     = make(map[string]TokenProvider)
+	tokenProvidersLock sync.RWMutex
+)
+
+// RegisterTokenProvider makes a TokenProvider available by name, so that it can be selected with the TokenProvider
+// setting of connection strings (e.g. "AccountEndpoint=...;TokenProvider=<name>"). Registering a provider with the name
+// of an existing one replaces it; a nil provider removes the registration.
+//
+// @Available since v1.2.0
+func RegisterTokenProvider(name string, provider TokenProvider) {
+	tokenProvidersLock.Lock()
+	defer tokenProvidersLock.Unlock()
+	if provider == nil {
+		delete(tokenProviders, name)
+	} else {
+		tokenProviders[name] = provider
+	}
+}
+
+func lookupTokenProvider(name string) TokenProvider {
+	tokenProvidersLock.RLock()
+	defer tokenProvidersLock.RUnlock()
+	return tokenProviders[name]
+}
+
+// NewFakeTokenProvider returns a TokenProvider that issues locally generated, unsigned JWT-like tokens valid for the
+// given lifetime (1 hour if not positive). It does not contact any identity provider and is meant to be used in tests,
+// e.g. against a fake server. Each token has a unique, increasing "jti" claim.
+//
+// @Available since v1.2.0
+func NewFakeTokenProvider(lifetime time.Duration) TokenProvider {
+	if lifetime <= 0 {
+		lifetime = time.Hour
+	}
+	var lock sync.Mutex
+	seq := 0
+	return func(ctx context.Context) (AccessToken, error) {
+		if err := ctx.Err(); err != nil {
+			return AccessToken{}, err
+		}
+		lock.Lock()
+		seq++
+		jti := seq
+		lock.Unlock()
+		now := time.Now()
+		expiresOn := now.Add(lifetime)
+		header, _ := json.Marshal(map[string]interface{}{"alg": "none", "typ": "JWT"})
+		claims, _ := json.Marshal(map[string]interface{}{
+			"aud": "https://cosmos.azure.com",
+			"iat": now.Unix(),
+			"exp": expiresOn.Unix(),
+			"jti": jti,
+		})
+		token := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims) + "."
+		return AccessToken{Token: token, ExpiresOn: expiresOn}, nil
+	}
+}
@@ -0,0 +1,284 @@
+package gocosmos
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// defaultQueryPageSize is the number of documents fetched per request when iterating query results.
+const defaultQueryPageSize = 100
+
+// QueryDocsIterator iterates over documents returned from a query, fetching pages of documents from server on demand
+// (using continuation tokens) instead of keeping the whole result set in memory.
+//
+//   - The query is executed across all partition key ranges, unless QueryReq.PkRangeId or QueryReq.PkValue is specified.
+//   - Documents are returned one partition key range after another. For ORDER BY queries, one page per partition key
+//     range is kept in memory and pages are merged so that documents are returned in the expected order.
+//   - Pages from up to QueryReq.MaxDegreeOfParallelism partition key ranges are fetched concurrently.
+//   - SELECT DISTINCT, OFFSET...LIMIT and TOP are applied while iterating. For SELECT DISTINCT queries, the hash of
+//     each returned document is kept in memory to detect duplicates.
+//   - GROUP BY queries and aggregate functions can only be resolved once all documents have been fetched: the final
+//     result (one document per group) is computed when the iterator is created.
+//
+// @Available since v1.2.0
+type QueryDocsIterator struct {
+	client        *RestClient
+	ctx           context.Context
+	query         QueryReq
+	queryPlan     *RespQueryPlan
+	err           error
+	statusCode    int                // status code of the failed call, if any
+	sources       []*queryPageSource // one source per partition key range
+	current       int                // index of the source being iterated (queries without ORDER BY)
+	buffer        QueriedDocs        // fully computed result (GROUP BY queries and aggregate functions)
+	seen          map[string]bool    // keys of the returned documents (SELECT DISTINCT queries)
+	skip          int                // number of documents to skip (OFFSET)
+	remaining     int                // number of documents left to return (LIMIT or TOP), negative value means "no limit"
+	requestCharge float64
+	closed        bool
+	maxDop        int // max number of partition key ranges fetched concurrently
+}
+
+// queryPageSource holds the current page of documents fetched from a partition key range.
+type queryPageSource struct {
+	pkRangeId         string
+	continuationToken string
+	docs              QueriedDocs
+	pos               int
+	done              bool // true if there is no more page to fetch
+}
+
+// QueryDocumentsIterator executes a query and returns an iterator over the matched documents.
+//
+// Documents are fetched from server page by page, the page size is QueryReq.MaxItemCount (100 if not specified).
+// QueryReq.ContinuationToken is ignored.
+//
+// @Available since v1.2.0
+func (c *RestClient) QueryDocumentsIterator(query QueryReq) *QueryDocsIterator {
+	return c.QueryDocumentsIteratorContext(context.Background(), query)
+}
+
+// QueryDocumentsIteratorContext is similar to QueryDocumentsIterator but with a context.Context that is attached to the underlying HTTP request(s).
+// The context must remain valid until the iterator is no longer used.
+//
+// @Available since v1.2.0
+func (c *RestClient) QueryDocumentsIteratorContext(ctx context.Context, query QueryReq) *QueryDocsIterator {
+	query.CrossPartitionEnabled = true
+	query.ContinuationToken = ""
+	if query.MaxItemCount <= 0 {
+		query.MaxItemCount = defaultQueryPageSize
+	}
+	it := &QueryDocsIterator{client: c, ctx: ctx, query: query, remaining: -1, maxDop: c.degreeOfParallelism(query)}
+	queryPlan := c.QueryPlanContext(ctx, query)
+	if !it.setError(queryPlan.RestResponse) {
+		return it
+	}
+	it.queryPlan = queryPlan
+
+	var pkranges *RespGetPkranges
+	if query.PkRangeId == "" && query.PkValue == "" {
+		pkranges = c.GetPkrangesContext(ctx, query.DbName, query.CollName)
+		if !it.setError(pkranges.RestResponse) {
+			return it
+		}
+	}
+
+	if queryPlan.IsGroupByQuery() {
+		// aggregation needs all documents
+		query.MaxItemCount = 0
+		var result *RespQueryDocs
+		if pkranges == nil {
+			result = c.queryAndMerge(ctx, query, nil, queryPlan)
+		} else {
+			result = c.queryCrossPartition(ctx, query, pkranges, queryPlan)
+		}
+		it.requestCharge += result.RequestCharge
+		if it.setError(result.RestResponse) {
+			it.buffer = result.Documents
+		}
+		return it
+	}
+
+	if queryPlan.QueryInfo.RewrittenQuery != "" {
+		it.query.Query = strings.ReplaceAll(queryPlan.QueryInfo.RewrittenQuery, "{documentdb-formattableorderbyquery-filter}", "true")
+	}
+	if pkranges == nil {
+		it.sources = []*queryPageSource{{pkRangeId: query.PkRangeId}}
+	} else {
+		for _, pkrange := range pkranges.Pkranges {
+			it.sources = append(it.sources, &queryPageSource{pkRangeId: pkrange.Id})
+		}
+	}
+	if queryPlan.IsDistinctQuery() {
+		it.seen = make(map[string]bool)
+	}
+	if queryPlan.QueryInfo.Limit > 0 {
+		it.skip, it.remaining = queryPlan.QueryInfo.Offset, queryPlan.QueryInfo.Limit
+	} else if queryPlan.QueryInfo.Top > 0 {
+		it.remaining = queryPlan.QueryInfo.Top
+	}
+	return it
+}
+
+// setError records the error of a failed call, returns false if the call failed.
+func (it *QueryDocsIterator) setError(resp RestResponse) bool {
+	if err := resp.Error(); err != nil {
+		it.err, it.statusCode = err, resp.StatusCode
+		return false
+	}
+	return true
+}
+
+// needFetch returns true if all documents of the source's current page have been consumed and there are more pages to fetch.
+func (src *queryPageSource) needFetch() bool {
+	return src.pos >= len(src.docs) && !src.done
+}
+
+// fetch fetches the next non-empty page of documents from the source. The request charge is added up to the supplied
+// value.
+func (it *QueryDocsIterator) fetch(src *queryPageSource, requestCharge *float64) RestResponse {
+	for src.needFetch() {
+		query := it.query
+		query.PkRangeId = src.pkRangeId
+		query.ContinuationToken = src.continuationToken
+		result := it.client.queryDocumentsCall(it.ctx, query)
+		*requestCharge += result.RequestCharge
+		if result.Error() != nil {
+			return result.RestResponse
+		}
+		src.docs, src.pos = result.Documents, 0
+		src.continuationToken = result.ContinuationToken
+		src.done = src.continuationToken == ""
+	}
+	return RestResponse{}
+}
+
+// fetchSources fetches the next page of documents from the sources that need it, concurrently.
+func (it *QueryDocsIterator) fetchSources(sources []*queryPageSource) error {
+	toFetch := make([]*queryPageSource, 0, len(sources))
+	for _, src := range sources {
+		if src.needFetch() {
+			toFetch = append(toFetch, src)
+		}
+	}
+	responses := make([]RestResponse, len(toFetch))
+	requestCharges := make([]float64, len(toFetch))
+	fanOut(len(toFetch), it.maxDop, func(i int) {
+		responses[i] = it.fetch(toFetch[i], &requestCharges[i])
+	})
+	for i := range toFetch {
+		it.requestCharge += requestCharges[i]
+	}
+	for _, resp := range responses {
+		if !it.setError(resp) {
+			return it.err
+		}
+	}
+	return nil
+}
+
+// nextDoc returns the next document as returned by server, without applying DISTINCT, OFFSET...LIMIT or TOP.
+func (it *QueryDocsIterator) nextDoc() (interface{}, error) {
+	if it.queryPlan.IsOrderByQuery() {
+		if err := it.fetchSources(it.sources); err != nil {
+			return nil, err
+		}
+		var next *queryPageSource
+		for _, src := range it.sources {
+			if src.pos < len(src.docs) && (next == nil || _orderByLess(it.queryPlan, src.docs[src.pos], next.docs[next.pos])) {
+				next = src
+			}
+		}
+		if next == nil {
+			return nil, io.EOF
+		}
+		next.pos++
+		return next.docs[next.pos-1], nil
+	}
+	for ; it.current < len(it.sources); it.current++ {
+		src := it.sources[it.current]
+		if src.needFetch() {
+			// also prefetch the first page of the next ranges, if parallelism allows
+			batch := []*queryPageSource{src}
+			for j := it.current + 1; j < len(it.sources) && (it.maxDop < 0 || len(batch) < it.maxDop); j++ {
+				if next := it.sources[j]; next.docs == nil && !next.done {
+					batch = append(batch, next)
+				}
+			}
+			if err := it.fetchSources(batch); err != nil {
+				return nil, err
+			}
+		}
+		if src.pos < len(src.docs) {
+			src.pos++
+			return src.docs[src.pos-1], nil
+		}
+		src.docs = nil
+	}
+	return nil, io.EOF
+}
+
+// Next returns the next document, fetching a new page from server if needed. io.EOF is returned when there is no
+// more document.
+//
+// Note: the returned document is usually a map[string]interface{}, but can also be a scalar value (e.g. results
+// from a query like "SELECT VALUE c.name FROM c").
+func (it *QueryDocsIterator) Next() (interface{}, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	if it.closed {
+		return nil, io.EOF
+	}
+	if it.buffer != nil {
+		if len(it.buffer) == 0 {
+			return nil, io.EOF
+		}
+		doc := it.buffer[0]
+		it.buffer = it.buffer[1:]
+		return doc, nil
+	}
+	for it.remaining != 0 {
+		doc, err := it.nextDoc()
+		if err != nil {
+			return nil, err
+		}
+		if it.seen != nil {
+			key := _distinctKey(it.queryPlan, doc)
+			if it.seen[key] {
+				continue
+			}
+			it.seen[key] = true
+		}
+		if it.skip > 0 {
+			it.skip--
+			continue
+		}
+		if it.remaining > 0 {
+			it.remaining--
+		}
+		if it.queryPlan.QueryInfo.RewrittenQuery != "" {
+			doc = QueriedDocs{doc}.Flatten(it.queryPlan)[0]
+		}
+		return doc, nil
+	}
+	return nil, io.EOF
+}
+
+// QueryPlan returns the query plan used to execute the query, nil if the query plan could not be retrieved.
+func (it *QueryDocsIterator) QueryPlan() *RespQueryPlan {
+	return it.queryPlan
+}
+
+// RequestCharge returns the total request units consumed by the calls made so far.
+func (it *QueryDocsIterator) RequestCharge() float64 {
+	return it.requestCharge
+}
+
+// Close releases resources held by the iterator, subsequent calls to Next return io.EOF.
+func (it *QueryDocsIterator) Close() error {
+	it.closed = true
+	it.sources, it.buffer, it.seen = nil, nil, nil
+	return nil
+}
@@ -0,0 +1,260 @@
+package gocosmos
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+)
+
+// StoredProcedureSpec specifies a Cosmos DB stored procedure specifications for creation or replacement.
+//
+// @Available since v1.2.0
+type StoredProcedureSpec struct {
+	DbName, CollName string
+	Id               string // unique name of the stored procedure within the collection
+	Body             string // JavaScript function of the stored procedure, e.g. "function () { getContext().getResponse().setBody('Hello'); }"
+}
+
+// StoredProcedureInfo captures info of a Cosmos DB stored procedure.
+//
+// @Available since v1.2.0
+type StoredProcedureInfo struct {
+	Id   string `json:"id"`    // user-generated unique name for the stored procedure
+	Body string `json:"body"`  // JavaScript function of the stored procedure
+	Rid  string `json:"_rid"`  // (system generated property) _rid attribute of the stored procedure
+	Ts   int64  `json:"_ts"`   // (system-generated property) _ts attribute of the stored procedure
+	Self string `json:"_self"` // (system-generated property) _self attribute of the stored procedure
+	Etag string `json:"_etag"` // (system-generated property) _etag attribute of the stored procedure
+}
+
+// RespCreateStoredProcedure captures the response from RestClient.CreateStoredProcedure call.
+//
+// @Available since v1.2.0
+type RespCreateStoredProcedure struct {
+	RestResponse
+	StoredProcedureInfo
+}
+
+// RespReplaceStoredProcedure captures the response from RestClient.ReplaceStoredProcedure call.
+//
+// @Available since v1.2.0
+type RespReplaceStoredProcedure struct {
+	RestResponse
+	StoredProcedureInfo
+}
+
+// RespGetStoredProcedure captures the response from RestClient.GetStoredProcedure call.
+//
+// @Available since v1.2.0
+type RespGetStoredProcedure struct {
+	RestResponse
+	StoredProcedureInfo
+}
+
+// RespDeleteStoredProcedure captures the response from RestClient.DeleteStoredProcedure call.
+//
+// @Available since v1.2.0
+type RespDeleteStoredProcedure struct {
+	RestResponse
+}
+
+// RespListStoredProcedures captures the response from RestClient.ListStoredProcedures call.
+//
+// @Available since v1.2.0
+type RespListStoredProcedures struct {
+	RestResponse     `json:"-"`
+	Count            int                   `json:"_count"` // number of stored procedures returned from the list operation
+	StoredProcedures []StoredProcedureInfo `json:"StoredProcedures"`
+}
+
+// RespExecuteStoredProcedure captures the response from RestClient.ExecuteStoredProcedure call.
+//
+// @Available since v1.2.0
+type RespExecuteStoredProcedure struct {
+	RestResponse
+	Result    interface{} // the value set by the script via getContext().getResponse().setBody(...), decoded from JSON; raw value is available via RestResponse.RespBody
+	ScriptLog string      // messages logged by the script via console.log(...)
+}
+
+// CreateStoredProcedure invokes Cosmos DB API to create a new stored procedure in a collection.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/create-a-stored-procedure.
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateStoredProcedure(spec StoredProcedureSpec) *RespCreateStoredProcedure {
+	return c.CreateStoredProcedureContext(context.Background(), spec)
+}
+
+// CreateStoredProcedureContext is similar to CreateStoredProcedure but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateStoredProcedureContext(ctx context.Context, spec StoredProcedureSpec) *RespCreateStoredProcedure {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+spec.DbName+"/colls/"+spec.CollName+"/sprocs")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"id": spec.Id, "body": spec.Body})
+	if err != nil {
+		return &RespCreateStoredProcedure{RestResponse: RestResponse{CallErr: err}, StoredProcedureInfo: StoredProcedureInfo{Id: spec.Id}}
+	}
+
+	result := &RespCreateStoredProcedure{RestResponse: c.execRequest(req, "sprocs", "dbs/"+spec.DbName+"/colls/"+spec.CollName), StoredProcedureInfo: StoredProcedureInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.StoredProcedureInfo))
+	}
+	return result
+}
+
+// ReplaceStoredProcedure invokes Cosmos DB API to replace the body of an existing stored procedure.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/replace-a-stored-procedure.
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceStoredProcedure(spec StoredProcedureSpec) *RespReplaceStoredProcedure {
+	return c.ReplaceStoredProcedureContext(context.Background(), spec)
+}
+
+// ReplaceStoredProcedureContext is similar to ReplaceStoredProcedure but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceStoredProcedureContext(ctx context.Context, spec StoredProcedureSpec) *RespReplaceStoredProcedure {
+	resId := "dbs/" + spec.DbName + "/colls/" + spec.CollName + "/sprocs/" + spec.Id
+	method, urlEndpoint := "PUT", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"id": spec.Id, "body": spec.Body})
+	if err != nil {
+		return &RespReplaceStoredProcedure{RestResponse: RestResponse{CallErr: err}, StoredProcedureInfo: StoredProcedureInfo{Id: spec.Id}}
+	}
+
+	result := &RespReplaceStoredProcedure{RestResponse: c.execRequest(req, "sprocs", resId), StoredProcedureInfo: StoredProcedureInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.StoredProcedureInfo))
+	}
+	return result
+}
+
+// GetStoredProcedure invokes Cosmos DB API to get an existing stored procedure.
+//
+// @Available since v1.2.0
+func (c *RestClient) GetStoredProcedure(dbName, collName, sprocId string) *RespGetStoredProcedure {
+	return c.GetStoredProcedureContext(context.Background(), dbName, collName, sprocId)
+}
+
+// GetStoredProcedureContext is similar to GetStoredProcedure but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetStoredProcedureContext(ctx context.Context, dbName, collName, sprocId string) *RespGetStoredProcedure {
+	resId := "dbs/" + dbName + "/colls/" + collName + "/sprocs/" + sprocId
+	method, urlEndpoint := "GET", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetStoredProcedure{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespGetStoredProcedure{RestResponse: c.execRequest(req, "sprocs", resId)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.StoredProcedureInfo))
+	}
+	return result
+}
+
+// DeleteStoredProcedure invokes Cosmos DB API to delete an existing stored procedure.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/delete-a-stored-procedure.
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteStoredProcedure(dbName, collName, sprocId string) *RespDeleteStoredProcedure {
+	return c.DeleteStoredProcedureContext(context.Background(), dbName, collName, sprocId)
+}
+
+// DeleteStoredProcedureContext is similar to DeleteStoredProcedure but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteStoredProcedureContext(ctx context.Context, dbName, collName, sprocId string) *RespDeleteStoredProcedure {
+	resId := "dbs/" + dbName + "/colls/" + collName + "/sprocs/" + sprocId
+	method, urlEndpoint := "DELETE", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespDeleteStoredProcedure{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespDeleteStoredProcedure{RestResponse: c.execRequest(req, "sprocs", resId)}
+	return result
+}
+
+// ListStoredProcedures invokes Cosmos DB API to list all stored procedures of a collection.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/list-stored-procedures.
+//
+// @Available since v1.2.0
+func (c *RestClient) ListStoredProcedures(dbName, collName string) *RespListStoredProcedures {
+	return c.ListStoredProceduresContext(context.Background(), dbName, collName)
+}
+
+// ListStoredProceduresContext is similar to ListStoredProcedures but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListStoredProceduresContext(ctx context.Context, dbName, collName string) *RespListStoredProcedures {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/colls/"+collName+"/sprocs")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespListStoredProcedures{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespListStoredProcedures{RestResponse: c.execRequest(req, "sprocs", "dbs/"+dbName+"/colls/"+collName)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &result)
+		if result.CallErr == nil {
+			sort.Slice(result.StoredProcedures, func(i, j int) bool {
+				// sort stored procedures by id
+				return result.StoredProcedures[i].Id < result.StoredProcedures[j].Id
+			})
+		}
+	}
+	return result
+}
+
+// ExecuteStoredProcedure invokes Cosmos DB API to execute a stored procedure on a logical partition, passing args as
+// the parameters of the stored procedure's function. All writes of the stored procedure are committed atomically
+// (if the stored procedure fails, none of them is applied).
+//
+// The value set by the script via getContext().getResponse().setBody(...) is returned in
+// RespExecuteStoredProcedure.Result, messages logged via console.log(...) in RespExecuteStoredProcedure.ScriptLog.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/execute-a-stored-procedure.
+//
+// @Available since v1.2.0
+func (c *RestClient) ExecuteStoredProcedure(dbName, collName, sprocId string, partitionKeyValues []interface{}, args ...interface{}) *RespExecuteStoredProcedure {
+	return c.ExecuteStoredProcedureContext(context.Background(), dbName, collName, sprocId, partitionKeyValues, args...)
+}
+
+// ExecuteStoredProcedureContext is similar to ExecuteStoredProcedure but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ExecuteStoredProcedureContext(ctx context.Context, dbName, collName, sprocId string, partitionKeyValues []interface{}, args ...interface{}) *RespExecuteStoredProcedure {
+	if args == nil {
+		// parameters are always sent as an array
+		args = []interface{}{}
+	}
+	resId := "dbs/" + dbName + "/colls/" + collName + "/sprocs/" + sprocId
+	method, urlEndpoint := "POST", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, args)
+	if err != nil {
+		return &RespExecuteStoredProcedure{RestResponse: RestResponse{CallErr: err}}
+	}
+	if len(partitionKeyValues) > 0 {
+		jsPkValues, _ := json.Marshal(partitionKeyValues)
+		req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+	}
+	req.Header.Set(restApiHeaderScriptEnableLogging, "true")
+
+	result := &RespExecuteStoredProcedure{RestResponse: c.execRequest(req, "sprocs", resId)}
+	if result.CallErr == nil {
+		if log := result.RespHeader[respHeaderScriptLog]; log != "" {
+			if result.ScriptLog, err = url.PathUnescape(log); err != nil {
+				result.ScriptLog = log
+			}
+		}
+		if result.ApiErr == nil && len(result.RespBody) > 0 {
+			result.CallErr = json.Unmarshal(result.RespBody, &result.Result)
+		}
+	}
+	return result
+}
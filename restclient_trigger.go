@@ -0,0 +1,228 @@
+package gocosmos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Trigger types, see TriggerSpec.
+//
+// @Available since v1.2.0
+const (
+	TriggerTypePre  = "Pre"
+	TriggerTypePost = "Post"
+)
+
+// Trigger operations, see TriggerSpec.
+//
+// @Available since v1.2.0
+const (
+	TriggerOpAll     = "All"
+	TriggerOpCreate  = "Create"
+	TriggerOpReplace = "Replace"
+	TriggerOpDelete  = "Delete"
+)
+
+// TriggerSpec specifies a Cosmos DB trigger specifications for creation or replacement.
+//
+// Triggers are not fired automatically, they must be included in document requests, see DocumentSpec.PreTriggers and
+// DocumentSpec.PostTriggers.
+//
+// @Available since v1.2.0
+type TriggerSpec struct {
+	DbName, CollName string
+	Id               string // unique name of the trigger within the collection
+	Body             string // JavaScript function of the trigger
+	Type             string // accepted values: TriggerTypePre or TriggerTypePost
+	Operation        string // operation the trigger is fired on, accepted values: TriggerOpAll, TriggerOpCreate, TriggerOpReplace or TriggerOpDelete
+}
+
+func (spec TriggerSpec) params() map[string]interface{} {
+	return map[string]interface{}{
+		"id":               spec.Id,
+		"body":             spec.Body,
+		"triggerType":      spec.Type,
+		"triggerOperation": spec.Operation,
+	}
+}
+
+// TriggerInfo captures info of a Cosmos DB trigger.
+//
+// @Available since v1.2.0
+type TriggerInfo struct {
+	Id               string `json:"id"`               // user-generated unique name for the trigger
+	Body             string `json:"body"`             // JavaScript function of the trigger
+	TriggerType      string `json:"triggerType"`      // type of the trigger, "Pre" or "Post"
+	TriggerOperation string `json:"triggerOperation"` // operation the trigger is fired on, "All", "Create", "Replace" or "Delete"
+	Rid              string `json:"_rid"`             // (system generated property) _rid attribute of the trigger
+	Ts               int64  `json:"_ts"`              // (system-generated property) _ts attribute of the trigger
+	Self             string `json:"_self"`            // (system-generated property) _self attribute of the trigger
+	Etag             string `json:"_etag"`            // (system-generated property) _etag attribute of the trigger
+}
+
+func (info TriggerInfo) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"id":               info.Id,
+		"body":             info.Body,
+		"triggerType":      info.TriggerType,
+		"triggerOperation": info.TriggerOperation,
+		"_rid":             info.Rid,
+		"_ts":              info.Ts,
+		"_self":            info.Self,
+		"_etag":            info.Etag,
+	}
+}
+
+// RespCreateTrigger captures the response from RestClient.CreateTrigger call.
+//
+// @Available since v1.2.0
+type RespCreateTrigger struct {
+	RestResponse
+	TriggerInfo
+}
+
+// RespReplaceTrigger captures the response from RestClient.ReplaceTrigger call.
+//
+// @Available since v1.2.0
+type RespReplaceTrigger struct {
+	RestResponse
+	TriggerInfo
+}
+
+// RespDeleteTrigger captures the response from RestClient.DeleteTrigger call.
+//
+// @Available since v1.2.0
+type RespDeleteTrigger struct {
+	RestResponse
+}
+
+// RespListTriggers captures the response from RestClient.ListTriggers call.
+//
+// @Available since v1.2.0
+type RespListTriggers struct {
+	RestResponse `json:"-"`
+	Count        int           `json:"_count"` // number of triggers returned from the list operation
+	Triggers     []TriggerInfo `json:"Triggers"`
+}
+
+// CreateTrigger invokes Cosmos DB API to create a new trigger in a collection.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/create-a-trigger.
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateTrigger(spec TriggerSpec) *RespCreateTrigger {
+	return c.CreateTriggerContext(context.Background(), spec)
+}
+
+// CreateTriggerContext is similar to CreateTrigger but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateTriggerContext(ctx context.Context, spec TriggerSpec) *RespCreateTrigger {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+spec.DbName+"/colls/"+spec.CollName+"/triggers")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, spec.params())
+	if err != nil {
+		return &RespCreateTrigger{RestResponse: RestResponse{CallErr: err}, TriggerInfo: TriggerInfo{Id: spec.Id}}
+	}
+
+	result := &RespCreateTrigger{RestResponse: c.execRequest(req, "triggers", "dbs/"+spec.DbName+"/colls/"+spec.CollName), TriggerInfo: TriggerInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.TriggerInfo))
+	}
+	return result
+}
+
+// ReplaceTrigger invokes Cosmos DB API to replace an existing trigger.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/replace-a-trigger.
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceTrigger(spec TriggerSpec) *RespReplaceTrigger {
+	return c.ReplaceTriggerContext(context.Background(), spec)
+}
+
+// ReplaceTriggerContext is similar to ReplaceTrigger but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceTriggerContext(ctx context.Context, spec TriggerSpec) *RespReplaceTrigger {
+	resId := "dbs/" + spec.DbName + "/colls/" + spec.CollName + "/triggers/" + spec.Id
+	method, urlEndpoint := "PUT", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, spec.params())
+	if err != nil {
+		return &RespReplaceTrigger{RestResponse: RestResponse{CallErr: err}, TriggerInfo: TriggerInfo{Id: spec.Id}}
+	}
+
+	result := &RespReplaceTrigger{RestResponse: c.execRequest(req, "triggers", resId), TriggerInfo: TriggerInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.TriggerInfo))
+	}
+	return result
+}
+
+// DeleteTrigger invokes Cosmos DB API to delete an existing trigger.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/delete-a-trigger.
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteTrigger(dbName, collName, triggerId string) *RespDeleteTrigger {
+	return c.DeleteTriggerContext(context.Background(), dbName, collName, triggerId)
+}
+
+// DeleteTriggerContext is similar to DeleteTrigger but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteTriggerContext(ctx context.Context, dbName, collName, triggerId string) *RespDeleteTrigger {
+	resId := "dbs/" + dbName + "/colls/" + collName + "/triggers/" + triggerId
+	method, urlEndpoint := "DELETE", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespDeleteTrigger{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespDeleteTrigger{RestResponse: c.execRequest(req, "triggers", resId)}
+	return result
+}
+
+// ListTriggers invokes Cosmos DB API to list all triggers of a collection.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/list-triggers.
+//
+// @Available since v1.2.0
+func (c *RestClient) ListTriggers(dbName, collName string) *RespListTriggers {
+	return c.ListTriggersContext(context.Background(), dbName, collName)
+}
+
+// ListTriggersContext is similar to ListTriggers but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListTriggersContext(ctx context.Context, dbName, collName string) *RespListTriggers {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/colls/"+collName+"/triggers")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespListTriggers{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespListTriggers{RestResponse: c.execRequest(req, "triggers", "dbs/"+dbName+"/colls/"+collName)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &result)
+		if result.CallErr == nil {
+			sort.Slice(result.Triggers, func(i, j int) bool {
+				// sort triggers by id
+				return result.Triggers[i].Id < result.Triggers[j].Id
+			})
+		}
+	}
+	return result
+}
+
+// setTriggerHeaders adds the headers to fire the specified pre- and post-triggers to a document request.
+func setTriggerHeaders(req *http.Request, preTriggers, postTriggers []string) {
+	if len(preTriggers) > 0 {
+		req.Header.Set(restApiHeaderPreTriggerInclude, strings.Join(preTriggers, ","))
+	}
+	if len(postTriggers) > 0 {
+		req.Header.Set(restApiHeaderPostTriggerInclude, strings.Join(postTriggers, ","))
+	}
+}
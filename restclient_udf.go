@@ -0,0 +1,180 @@
+package gocosmos
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+)
+
+// UserDefinedFunctionSpec specifies a Cosmos DB user-defined function (UDF) specifications for creation or replacement.
+//
+// @Available since v1.2.0
+type UserDefinedFunctionSpec struct {
+	DbName, CollName string
+	Id               string // unique name of the UDF within the collection, used in queries as "udf.<id>(...)"
+	Body             string // JavaScript function of the UDF, e.g. "function (price) { return price * 1.1; }"
+}
+
+// UserDefinedFunctionInfo captures info of a Cosmos DB user-defined function (UDF).
+//
+// @Available since v1.2.0
+type UserDefinedFunctionInfo struct {
+	Id   string `json:"id"`    // user-generated unique name for the UDF
+	Body string `json:"body"`  // JavaScript function of the UDF
+	Rid  string `json:"_rid"`  // (system generated property) _rid attribute of the UDF
+	Ts   int64  `json:"_ts"`   // (system-generated property) _ts attribute of the UDF
+	Self string `json:"_self"` // (system-generated property) _self attribute of the UDF
+	Etag string `json:"_etag"` // (system-generated property) _etag attribute of the UDF
+}
+
+func (info UserDefinedFunctionInfo) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"id":    info.Id,
+		"body":  info.Body,
+		"_rid":  info.Rid,
+		"_ts":   info.Ts,
+		"_self": info.Self,
+		"_etag": info.Etag,
+	}
+}
+
+// RespCreateUserDefinedFunction captures the response from RestClient.CreateUserDefinedFunction call.
+//
+// @Available since v1.2.0
+type RespCreateUserDefinedFunction struct {
+	RestResponse
+	UserDefinedFunctionInfo
+}
+
+// RespReplaceUserDefinedFunction captures the response from RestClient.ReplaceUserDefinedFunction call.
+//
+// @Available since v1.2.0
+type RespReplaceUserDefinedFunction struct {
+	RestResponse
+	UserDefinedFunctionInfo
+}
+
+// RespDeleteUserDefinedFunction captures the response from RestClient.DeleteUserDefinedFunction call.
+//
+// @Available since v1.2.0
+type RespDeleteUserDefinedFunction struct {
+	RestResponse
+}
+
+// RespListUserDefinedFunctions captures the response from RestClient.ListUserDefinedFunctions call.
+//
+// @Available since v1.2.0
+type RespListUserDefinedFunctions struct {
+	RestResponse         `json:"-"`
+	Count                int                       `json:"_count"` // number of UDFs returned from the list operation
+	UserDefinedFunctions []UserDefinedFunctionInfo `json:"UserDefinedFunctions"`
+}
+
+// CreateUserDefinedFunction invokes Cosmos DB API to create a new user-defined function in a collection.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/create-a-user-defined-function.
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateUserDefinedFunction(spec UserDefinedFunctionSpec) *RespCreateUserDefinedFunction {
+	return c.CreateUserDefinedFunctionContext(context.Background(), spec)
+}
+
+// CreateUserDefinedFunctionContext is similar to CreateUserDefinedFunction but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateUserDefinedFunctionContext(ctx context.Context, spec UserDefinedFunctionSpec) *RespCreateUserDefinedFunction {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+spec.DbName+"/colls/"+spec.CollName+"/udfs")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"id": spec.Id, "body": spec.Body})
+	if err != nil {
+		return &RespCreateUserDefinedFunction{RestResponse: RestResponse{CallErr: err}, UserDefinedFunctionInfo: UserDefinedFunctionInfo{Id: spec.Id}}
+	}
+
+	result := &RespCreateUserDefinedFunction{RestResponse: c.execRequest(req, "udfs", "dbs/"+spec.DbName+"/colls/"+spec.CollName), UserDefinedFunctionInfo: UserDefinedFunctionInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.UserDefinedFunctionInfo))
+	}
+	return result
+}
+
+// ReplaceUserDefinedFunction invokes Cosmos DB API to replace the body of an existing user-defined function.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/replace-a-user-defined-function.
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceUserDefinedFunction(spec UserDefinedFunctionSpec) *RespReplaceUserDefinedFunction {
+	return c.ReplaceUserDefinedFunctionContext(context.Background(), spec)
+}
+
+// ReplaceUserDefinedFunctionContext is similar to ReplaceUserDefinedFunction but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceUserDefinedFunctionContext(ctx context.Context, spec UserDefinedFunctionSpec) *RespReplaceUserDefinedFunction {
+	resId := "dbs/" + spec.DbName + "/colls/" + spec.CollName + "/udfs/" + spec.Id
+	method, urlEndpoint := "PUT", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"id": spec.Id, "body": spec.Body})
+	if err != nil {
+		return &RespReplaceUserDefinedFunction{RestResponse: RestResponse{CallErr: err}, UserDefinedFunctionInfo: UserDefinedFunctionInfo{Id: spec.Id}}
+	}
+
+	result := &RespReplaceUserDefinedFunction{RestResponse: c.execRequest(req, "udfs", resId), UserDefinedFunctionInfo: UserDefinedFunctionInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.UserDefinedFunctionInfo))
+	}
+	return result
+}
+
+// DeleteUserDefinedFunction invokes Cosmos DB API to delete an existing user-defined function.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/delete-a-user-defined-function.
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteUserDefinedFunction(dbName, collName, udfId string) *RespDeleteUserDefinedFunction {
+	return c.DeleteUserDefinedFunctionContext(context.Background(), dbName, collName, udfId)
+}
+
+// DeleteUserDefinedFunctionContext is similar to DeleteUserDefinedFunction but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteUserDefinedFunctionContext(ctx context.Context, dbName, collName, udfId string) *RespDeleteUserDefinedFunction {
+	resId := "dbs/" + dbName + "/colls/" + collName + "/udfs/" + udfId
+	method, urlEndpoint := "DELETE", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespDeleteUserDefinedFunction{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespDeleteUserDefinedFunction{RestResponse: c.execRequest(req, "udfs", resId)}
+	return result
+}
+
+// ListUserDefinedFunctions invokes Cosmos DB API to list all user-defined functions of a collection.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/list-user-defined-functions.
+//
+// @Available since v1.2.0
+func (c *RestClient) ListUserDefinedFunctions(dbName, collName string) *RespListUserDefinedFunctions {
+	return c.ListUserDefinedFunctionsContext(context.Background(), dbName, collName)
+}
+
+// ListUserDefinedFunctionsContext is similar to ListUserDefinedFunctions but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListUserDefinedFunctionsContext(ctx context.Context, dbName, collName string) *RespListUserDefinedFunctions {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/colls/"+collName+"/udfs")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespListUserDefinedFunctions{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespListUserDefinedFunctions{RestResponse: c.execRequest(req, "udfs", "dbs/"+dbName+"/colls/"+collName)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &result)
+		if result.CallErr == nil {
+			sort.Slice(result.UserDefinedFunctions, func(i, j int) bool {
+				// sort UDFs by id
+				return result.UserDefinedFunctions[i].Id < result.UserDefinedFunctions[j].Id
+			})
+		}
+	}
+	return result
+}
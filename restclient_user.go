@@ -0,0 +1,431 @@
+package gocosmos
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Permission modes, see PermissionSpec.
+//
+// @Available since v1.2.0
+const (
+	PermissionModeAll  = "All"
+	PermissionModeRead = "Read"
+)
+
+// UserInfo captures info of a Cosmos DB user.
+//
+// @Available since v1.2.0
+type UserInfo struct {
+	Id          string `json:"id"`           // user-generated unique name for the user
+	Rid         string `json:"_rid"`         // (system generated property) _rid attribute of the user
+	Ts          int64  `json:"_ts"`          // (system-generated property) _ts attribute of the user
+	Self        string `json:"_self"`        // (system-generated property) _self attribute of the user
+	Etag        string `json:"_etag"`        // (system-generated property) _etag attribute of the user
+	Permissions string `json:"_permissions"` // (system-generated property) _permissions attribute of the user
+}
+
+// RespCreateUser captures the response from RestClient.CreateUser call.
+//
+// @Available since v1.2.0
+type RespCreateUser struct {
+	RestResponse
+	UserInfo
+}
+
+// RespGetUser captures the response from RestClient.GetUser call.
+//
+// @Available since v1.2.0
+type RespGetUser struct {
+	RestResponse
+	UserInfo
+}
+
+// RespReplaceUser captures the response from RestClient.ReplaceUser call.
+//
+// @Available since v1.2.0
+type RespReplaceUser struct {
+	RestResponse
+	UserInfo
+}
+
+// RespDeleteUser captures the response from RestClient.DeleteUser call.
+//
+// @Available since v1.2.0
+type RespDeleteUser struct {
+	RestResponse
+}
+
+// RespListUsers captures the response from RestClient.ListUsers call.
+//
+// @Available since v1.2.0
+type RespListUsers struct {
+	RestResponse `json:"-"`
+	Count        int        `json:"_count"` // number of users returned from the list operation
+	Users        []UserInfo `json:"Users"`
+}
+
+// CreateUser invokes Cosmos DB API to create a new user in a database.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/create-a-user.
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateUser(dbName, userName string) *RespCreateUser {
+	return c.CreateUserContext(context.Background(), dbName, userName)
+}
+
+// CreateUserContext is similar to CreateUser but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreateUserContext(ctx context.Context, dbName, userName string) *RespCreateUser {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+dbName+"/users")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"id": userName})
+	if err != nil {
+		return &RespCreateUser{RestResponse: RestResponse{CallErr: err}, UserInfo: UserInfo{Id: userName}}
+	}
+
+	result := &RespCreateUser{RestResponse: c.execRequest(req, "users", "dbs/"+dbName), UserInfo: UserInfo{Id: userName}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.UserInfo))
+	}
+	return result
+}
+
+// GetUser invokes Cosmos DB API to get an existing user.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/get-a-user.
+//
+// @Available since v1.2.0
+func (c *RestClient) GetUser(dbName, userName string) *RespGetUser {
+	return c.GetUserContext(context.Background(), dbName, userName)
+}
+
+// GetUserContext is similar to GetUser but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetUserContext(ctx context.Context, dbName, userName string) *RespGetUser {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/users/"+userName)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetUser{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespGetUser{RestResponse: c.execRequest(req, "users", "dbs/"+dbName+"/users/"+userName)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.UserInfo))
+	}
+	return result
+}
+
+// ReplaceUser invokes Cosmos DB API to rename an existing user. Permissions of the user are kept.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/replace-a-user.
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceUser(dbName, userName, newUserName string) *RespReplaceUser {
+	return c.ReplaceUserContext(context.Background(), dbName, userName, newUserName)
+}
+
+// ReplaceUserContext is similar to ReplaceUser but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplaceUserContext(ctx context.Context, dbName, userName, newUserName string) *RespReplaceUser {
+	method, urlEndpoint := "PUT", c.resourceUrl("dbs/"+dbName+"/users/"+userName)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, map[string]interface{}{"id": newUserName})
+	if err != nil {
+		return &RespReplaceUser{RestResponse: RestResponse{CallErr: err}, UserInfo: UserInfo{Id: newUserName}}
+	}
+
+	result := &RespReplaceUser{RestResponse: c.execRequest(req, "users", "dbs/"+dbName+"/users/"+userName), UserInfo: UserInfo{Id: newUserName}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.UserInfo))
+	}
+	return result
+}
+
+// DeleteUser invokes Cosmos DB API to delete an existing user, together with its permissions.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/delete-a-user.
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteUser(dbName, userName string) *RespDeleteUser {
+	return c.DeleteUserContext(context.Background(), dbName, userName)
+}
+
+// DeleteUserContext is similar to DeleteUser but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeleteUserContext(ctx context.Context, dbName, userName string) *RespDeleteUser {
+	method, urlEndpoint := "DELETE", c.resourceUrl("dbs/"+dbName+"/users/"+userName)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespDeleteUser{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespDeleteUser{RestResponse: c.execRequest(req, "users", "dbs/"+dbName+"/users/"+userName)}
+	return result
+}
+
+// ListUsers invokes Cosmos DB API to list all users of a database.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/list-users.
+//
+// @Available since v1.2.0
+func (c *RestClient) ListUsers(dbName string) *RespListUsers {
+	return c.ListUsersContext(context.Background(), dbName)
+}
+
+// ListUsersContext is similar to ListUsers but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListUsersContext(ctx context.Context, dbName string) *RespListUsers {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/users")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespListUsers{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespListUsers{RestResponse: c.execRequest(req, "users", "dbs/"+dbName)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &result)
+		if result.CallErr == nil {
+			sort.Slice(result.Users, func(i, j int) bool {
+				// sort users by id
+				return result.Users[i].Id < result.Users[j].Id
+			})
+		}
+	}
+	return result
+}
+
+/*----------------------------------------------------------------------*/
+
+// PermissionSpec specifies a Cosmos DB permission specifications for creation or replacement.
+//
+// @Available since v1.2.0
+type PermissionSpec struct {
+	DbName, UserName string
+	Id               string        // unique name of the permission within the user
+	Mode             string        // access mode on the resource, PermissionModeAll or PermissionModeRead
+	Resource         string        // link of the resource the permission applies to, e.g. "dbs/mydb/colls/mytable"
+	PartitionKey     []interface{} // optional, restricts the permission to documents of a logical partition
+	TokenTtl         time.Duration // optional, validity of the returned resource token (between 10 minutes and 5 hours, 1 hour by default)
+}
+
+func (spec PermissionSpec) params() map[string]interface{} {
+	params := map[string]interface{}{"id": spec.Id, "permissionMode": spec.Mode, "resource": spec.Resource}
+	if len(spec.PartitionKey) > 0 {
+		params["resourcePartitionKey"] = spec.PartitionKey
+	}
+	return params
+}
+
+// PermissionInfo captures info of a Cosmos DB permission.
+//
+// @Available since v1.2.0
+type PermissionInfo struct {
+	Id                   string        `json:"id"`                             // user-generated unique name for the permission
+	PermissionMode       string        `json:"permissionMode"`                 // access mode on the resource, "All" or "Read"
+	Resource             string        `json:"resource"`                       // link of the resource the permission applies to
+	ResourcePartitionKey []interface{} `json:"resourcePartitionKey,omitempty"` // partition key the permission is restricted to, if any
+	Rid                  string        `json:"_rid"`                           // (system generated property) _rid attribute of the permission
+	Ts                   int64         `json:"_ts"`                            // (system-generated property) _ts attribute of the permission
+	Self                 string        `json:"_self"`                          // (system-generated property) _self attribute of the permission
+	Etag                 string        `json:"_etag"`                          // (system-generated property) _etag attribute of the permission
+	Token                string        `json:"_token"`                         // (system-generated property) resource token granting access to the resource, see ResourceTokenCredential
+}
+
+// RespCreatePermission captures the response from RestClient.CreatePermission call.
+//
+// @Available since v1.2.0
+type RespCreatePermission struct {
+	RestResponse
+	PermissionInfo
+}
+
+// RespReplacePermission captures the response from RestClient.ReplacePermission call.
+//
+// @Available since v1.2.0
+type RespReplacePermission struct {
+	RestResponse
+	PermissionInfo
+}
+
+// RespGetPermission captures the response from RestClient.GetPermission call.
+//
+// @Available since v1.2.0
+type RespGetPermission struct {
+	RestResponse
+	PermissionInfo
+}
+
+// RespDeletePermission captures the response from RestClient.DeletePermission call.
+//
+// @Available since v1.2.0
+type RespDeletePermission struct {
+	RestResponse
+}
+
+// RespListPermissions captures the response from RestClient.ListPermissions call.
+//
+// @Available since v1.2.0
+type RespListPermissions struct {
+	RestResponse `json:"-"`
+	Count        int              `json:"_count"` // number of permissions returned from the list operation
+	Permissions  []PermissionInfo `json:"Permissions"`
+}
+
+// CreatePermission invokes Cosmos DB API to grant a user access to a resource. The response contains a resource token
+// (PermissionInfo.Token) that can be handed out to clients, see ResourceTokenCredential.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/create-a-permission.
+//
+// @Available since v1.2.0
+func (c *RestClient) CreatePermission(spec PermissionSpec) *RespCreatePermission {
+	return c.CreatePermissionContext(context.Background(), spec)
+}
+
+// CreatePermissionContext is similar to CreatePermission but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) CreatePermissionContext(ctx context.Context, spec PermissionSpec) *RespCreatePermission {
+	method, urlEndpoint := "POST", c.resourceUrl("dbs/"+spec.DbName+"/users/"+spec.UserName+"/permissions")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, spec.params())
+	if err != nil {
+		return &RespCreatePermission{RestResponse: RestResponse{CallErr: err}, PermissionInfo: PermissionInfo{Id: spec.Id}}
+	}
+	if spec.TokenTtl > 0 {
+		req.Header.Set(restApiHeaderTokenExpiry, strconv.Itoa(int(spec.TokenTtl/time.Second)))
+	}
+
+	result := &RespCreatePermission{RestResponse: c.execRequest(req, "permissions", "dbs/"+spec.DbName+"/users/"+spec.UserName), PermissionInfo: PermissionInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.PermissionInfo))
+	}
+	return result
+}
+
+// ReplacePermission invokes Cosmos DB API to replace an existing permission.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/replace-a-permission.
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplacePermission(spec PermissionSpec) *RespReplacePermission {
+	return c.ReplacePermissionContext(context.Background(), spec)
+}
+
+// ReplacePermissionContext is similar to ReplacePermission but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ReplacePermissionContext(ctx context.Context, spec PermissionSpec) *RespReplacePermission {
+	resId := "dbs/" + spec.DbName + "/users/" + spec.UserName + "/permissions/" + spec.Id
+	method, urlEndpoint := "PUT", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, spec.params())
+	if err != nil {
+		return &RespReplacePermission{RestResponse: RestResponse{CallErr: err}, PermissionInfo: PermissionInfo{Id: spec.Id}}
+	}
+	if spec.TokenTtl > 0 {
+		req.Header.Set(restApiHeaderTokenExpiry, strconv.Itoa(int(spec.TokenTtl/time.Second)))
+	}
+
+	result := &RespReplacePermission{RestResponse: c.execRequest(req, "permissions", resId), PermissionInfo: PermissionInfo{Id: spec.Id}}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.PermissionInfo))
+	}
+	return result
+}
+
+// GetPermission invokes Cosmos DB API to get an existing permission. The response contains a fresh resource token,
+// valid for tokenTtl (1 hour if tokenTtl is not positive).
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/get-a-permission.
+//
+// @Available since v1.2.0
+func (c *RestClient) GetPermission(dbName, userName, permId string, tokenTtl time.Duration) *RespGetPermission {
+	return c.GetPermissionContext(context.Background(), dbName, userName, permId, tokenTtl)
+}
+
+// GetPermissionContext is similar to GetPermission but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) GetPermissionContext(ctx context.Context, dbName, userName, permId string, tokenTtl time.Duration) *RespGetPermission {
+	resId := "dbs/" + dbName + "/users/" + userName + "/permissions/" + permId
+	method, urlEndpoint := "GET", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetPermission{RestResponse: RestResponse{CallErr: err}}
+	}
+	if tokenTtl > 0 {
+		req.Header.Set(restApiHeaderTokenExpiry, strconv.Itoa(int(tokenTtl/time.Second)))
+	}
+
+	result := &RespGetPermission{RestResponse: c.execRequest(req, "permissions", resId)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.PermissionInfo))
+	}
+	return result
+}
+
+// DeletePermission invokes Cosmos DB API to delete an existing permission. Resource tokens issued for the permission
+// are no longer accepted.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/delete-a-permission.
+//
+// @Available since v1.2.0
+func (c *RestClient) DeletePermission(dbName, userName, permId string) *RespDeletePermission {
+	return c.DeletePermissionContext(context.Background(), dbName, userName, permId)
+}
+
+// DeletePermissionContext is similar to DeletePermission but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) DeletePermissionContext(ctx context.Context, dbName, userName, permId string) *RespDeletePermission {
+	resId := "dbs/" + dbName + "/users/" + userName + "/permissions/" + permId
+	method, urlEndpoint := "DELETE", c.resourceUrl(resId)
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespDeletePermission{RestResponse: RestResponse{CallErr: err}}
+	}
+
+	result := &RespDeletePermission{RestResponse: c.execRequest(req, "permissions", resId)}
+	return result
+}
+
+// ListPermissions invokes Cosmos DB API to list all permissions of a user. Each returned permission contains a fresh
+// resource token, valid for tokenTtl (1 hour if tokenTtl is not positive).
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/list-permissions.
+//
+// @Available since v1.2.0
+func (c *RestClient) ListPermissions(dbName, userName string, tokenTtl time.Duration) *RespListPermissions {
+	return c.ListPermissionsContext(context.Background(), dbName, userName, tokenTtl)
+}
+
+// ListPermissionsContext is similar to ListPermissions but with a context.Context that is attached to the underlying HTTP request(s).
+//
+// @Available since v1.2.0
+func (c *RestClient) ListPermissionsContext(ctx context.Context, dbName, userName string, tokenTtl time.Duration) *RespListPermissions {
+	method, urlEndpoint := "GET", c.resourceUrl("dbs/"+dbName+"/users/"+userName+"/permissions")
+	req, err := c.buildJsonRequest(ctx, method, urlEndpoint, nil)
+	if err != nil {
+		return &RespListPermissions{RestResponse: RestResponse{CallErr: err}}
+	}
+	if tokenTtl > 0 {
+		req.Header.Set(restApiHeaderTokenExpiry, strconv.Itoa(int(tokenTtl/time.Second)))
+	}
+
+	result := &RespListPermissions{RestResponse: c.execRequest(req, "permissions", "dbs/"+dbName+"/users/"+userName)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &result)
+		if result.CallErr == nil {
+			sort.Slice(result.Permissions, func(i, j int) bool {
+				// sort permissions by id
+				return result.Permissions[i].Id < result.Permissions[j].Id
+			})
+		}
+	}
+	return result
+}
@@ -3,205 +3,188 @@ package gocosmos
 import (
 	"database/sql/driver"
 	"fmt"
+	"github.com/btnguyen2k/consu/g18"
 	"io"
 	"reflect"
-	"regexp"
 	"sort"
 	"strings"
 )
 
-const (
-	field       = `([\w\-]+)`
-	ifNotExists = `(\s+IF\s+NOT\s+EXISTS)?`
-	ifExists    = `(\s+IF\s+EXISTS)?`
-	with        = `(\s+WITH\s+.*)?`
-)
-
-var (
-	reCreateDb = regexp.MustCompile(`(?is)^CREATE\s+DATABASE` + ifNotExists + `\s+` + field + with + `$`)
-	reAlterDb  = regexp.MustCompile(`(?is)^ALTER\s+DATABASE` + `\s+` + field + with + `$`)
-	reDropDb   = regexp.MustCompile(`(?is)^DROP\s+DATABASE` + ifExists + `\s+` + field + `$`)
-	reListDbs  = regexp.MustCompile(`(?is)^LIST\s+DATABASES?$`)
-
-	reCreateColl = regexp.MustCompile(`(?is)^CREATE\s+(COLLECTION|TABLE)` + ifNotExists + `\s+(` + field + `\.)?` + field + with + `$`)
-	reAlterColl  = regexp.MustCompile(`(?is)^ALTER\s+(COLLECTION|TABLE)` + `\s+(` + field + `\.)?` + field + with + `$`)
-	reDropColl   = regexp.MustCompile(`(?is)^DROP\s+(COLLECTION|TABLE)` + ifExists + `\s+(` + field + `\.)?` + field + `$`)
-	reListColls  = regexp.MustCompile(`(?is)^LIST\s+(COLLECTIONS?|TABLES?)(\s+FROM\s+` + field + `)?$`)
-
-	reInsert = regexp.MustCompile(`(?is)^(INSERT|UPSERT)\s+INTO\s+(` + field + `\.)?` + field + `\s*\(([^)]*?)\)\s*VALUES\s*\(([^)]*?)\)` + with + `$`)
-	reSelect = regexp.MustCompile(`(?is)^SELECT\s+(CROSS\s+PARTITION\s+)?.*?\s+FROM\s+` + field + `.*?` + with + `$`)
-	//reUpdate = regexp.MustCompile(`(?is)^UPDATE\s+(` + field + `\.)?` + field + `\s+SET\s+(.*)\s+WHERE\s+id\s*=\s*(.*?)` + with + `$`)
-	reUpdate = regexp.MustCompile(`(?is)^UPDATE\s+(` + field + `\.)?` + field + `\s+SET\s+(.*)\s+WHERE\s+(.*?)` + with + `$`)
-	reDelete = regexp.MustCompile(`(?is)^DELETE\s+FROM\s+(` + field + `\.)?` + field + `\s+WHERE\s+(.*?)` + with + `$`)
-)
-
 // ParseQueryWithDefaultDb parses the given query and returns a Stmt.
 //
+// Since v1.2.0, the query is parsed by a recursive-descent parser (instead of regular expressions): comments ("-- ..."
+// and "/* ... */") are allowed, and a malformed query is reported as a *SyntaxError locating the offending token.
+//
 // @Available since v1.0.0
 func ParseQueryWithDefaultDb(c *Conn, defaultDb, query string) (driver.Stmt, error) {
-	query = strings.TrimSpace(query)
-	if re := reCreateDb; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtCreateDatabase{
-			Stmt:        &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:      strings.TrimSpace(groups[0][2]),
-			ifNotExists: strings.TrimSpace(groups[0][1]) != "",
-		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][3])); err != nil {
-			return nil, err
-		}
-		return stmt, stmt.validate()
+	node, err := parseQuery(query)
+	if err != nil {
+		return nil, err
 	}
-	if re := reAlterDb; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtAlterDatabase{
-			Stmt:   &Stmt{query: query, conn: c, numInputs: 0},
-			dbName: strings.TrimSpace(groups[0][1]),
+	base := &Stmt{query: strings.TrimSpace(query), conn: c, numInputs: 0}
+	dbOrDefault := func(dbName string) string {
+		if dbName == "" {
+			return defaultDb
 		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][2])); err != nil {
+		return dbName
+	}
+	switch n := node.(type) {
+	case *astCreateDatabase:
+		stmt := &StmtCreateDatabase{Stmt: base, dbName: n.dbName, ifNotExists: n.ifNotExists}
+		if err := stmt.parse(n.withOpts); err != nil {
 			return nil, err
 		}
 		return stmt, stmt.validate()
-	}
-	if re := reDropDb; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtDropDatabase{
-			Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:   strings.TrimSpace(groups[0][2]),
-			ifExists: strings.TrimSpace(groups[0][1]) != "",
+	case *astAlterDatabase:
+		stmt := &StmtAlterDatabase{Stmt: base, dbName: n.dbName}
+		if err := stmt.parse(n.withOpts); err != nil {
+			return nil, err
 		}
 		return stmt, stmt.validate()
-	}
-	if re := reListDbs; re.MatchString(query) {
-		stmt := &StmtListDatabases{
-			Stmt: &Stmt{query: query, conn: c, numInputs: 0},
-		}
+	case *astDropDatabase:
+		stmt := &StmtDropDatabase{Stmt: base, dbName: n.dbName, ifExists: n.ifExists}
+		return stmt, stmt.validate()
+	case *astListDatabases:
+		stmt := &StmtListDatabases{Stmt: base}
 		return stmt, stmt.validate()
-	}
 
-	if re := reCreateColl; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtCreateCollection{
-			Stmt:        &Stmt{query: query, conn: c, numInputs: 0},
-			ifNotExists: strings.TrimSpace(groups[0][2]) != "",
-			dbName:      strings.TrimSpace(groups[0][4]),
-			collName:    strings.TrimSpace(groups[0][5]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][6])); err != nil {
+	case *astCreateCollection:
+		stmt := &StmtCreateCollection{Stmt: base, ifNotExists: n.ifNotExists, dbName: dbOrDefault(n.db), collName: n.coll}
+		if err := stmt.parse(n.withOpts); err != nil {
 			return nil, err
 		}
 		return stmt, stmt.validate()
-	}
-	if re := reAlterColl; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtAlterCollection{
-			Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:   strings.TrimSpace(groups[0][3]),
-			collName: strings.TrimSpace(groups[0][4]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][5])); err != nil {
+	case *astAlterCollection:
+		stmt := &StmtAlterCollection{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll}
+		if err := stmt.parse(n.withOpts); err != nil {
 			return nil, err
 		}
 		return stmt, stmt.validate()
-	}
-	if re := reDropColl; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtDropCollection{
-			Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:   strings.TrimSpace(groups[0][4]),
-			collName: strings.TrimSpace(groups[0][5]),
-			ifExists: strings.TrimSpace(groups[0][2]) != "",
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
+	case *astDropCollection:
+		stmt := &StmtDropCollection{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, ifExists: n.ifExists}
 		return stmt, stmt.validate()
-	}
-	if re := reListColls; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtListCollections{
-			Stmt:   &Stmt{query: query, conn: c, numInputs: 0},
-			dbName: strings.TrimSpace(groups[0][3]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
+	case *astListCollections:
+		stmt := &StmtListCollections{Stmt: base, dbName: dbOrDefault(n.dbName)}
 		return stmt, stmt.validate()
-	}
 
-	if re := reInsert; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
+	case *astInsert:
 		stmt := &StmtInsert{
-			StmtCRUD: &StmtCRUD{
-				Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-				dbName:   strings.TrimSpace(groups[0][3]),
-				collName: strings.TrimSpace(groups[0][4]),
-			},
-			isUpsert:  strings.ToUpper(strings.TrimSpace(groups[0][1])) == "UPSERT",
-			fieldsStr: strings.TrimSpace(groups[0][5]),
-			valuesStr: strings.TrimSpace(groups[0][6]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(groups[0][7]); err != nil {
+			StmtCRUD:  &StmtCRUD{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, returning: n.returning},
+			isUpsert:  n.isUpsert,
+			fieldsStr: n.fieldsStr,
+			valuesStr: n.valuesStr,
+			fields:    n.fields,
+			values:    n.values,
+			extraRows: n.extraRows,
+		}
+		if err := stmt.parse(n.withOpts); err != nil {
 			return nil, err
 		}
 		return stmt, stmt.validate()
-	}
-	if re := reSelect; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
+	case *astInsertSelect:
+		query := &StmtSelect{
+			Stmt:             &Stmt{query: base.query, conn: c, numInputs: n.query.numInputs, namedInputs: n.query.namedParams},
+			isCrossPartition: n.query.isCrossPartition,
+			collName:         n.query.collName,
+			dbName:           dbOrDefault(n.db),
+			selectQuery:      n.query.selectQuery,
+			placeholders:     n.query.placeholders,
+		}
+		stmt := &StmtInsertSelect{
+			StmtCRUD: &StmtCRUD{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll},
+			isUpsert: n.isUpsert,
+			query:    query,
+		}
+		if err := stmt.parse(n.query.withOpts); err != nil {
+			return nil, err
+		}
+		return stmt, stmt.validate()
+	case *astSelect:
 		stmt := &StmtSelect{
-			Stmt:             &Stmt{query: query, conn: c, numInputs: 0},
-			isCrossPartition: strings.TrimSpace(groups[0][1]) != "",
-			collName:         strings.TrimSpace(groups[0][2]),
+			Stmt:             base,
+			isCrossPartition: n.isCrossPartition,
+			collName:         n.collName,
 			dbName:           defaultDb,
-			selectQuery:      strings.ReplaceAll(strings.ReplaceAll(query, groups[0][1], ""), groups[0][3], ""),
+			selectQuery:      n.selectQuery,
+			placeholders:     n.placeholders,
 		}
-		if err := stmt.parse(groups[0][3]); err != nil {
+		if err := stmt.parse(n.withOpts); err != nil {
 			return nil, err
 		}
+		stmt.numInputs, stmt.namedInputs = n.numInputs, n.namedParams
 		return stmt, stmt.validate()
-	}
-	if re := reUpdate; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
+	case *astUpdate:
 		stmt := &StmtUpdate{
-			StmtCRUD: &StmtCRUD{
-				Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-				dbName:   strings.TrimSpace(groups[0][2]),
-				collName: strings.TrimSpace(groups[0][3]),
-			},
-			updateStr: strings.TrimSpace(groups[0][4]),
-			whereStr:  strings.TrimSpace(groups[0][5]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(groups[0][6]); err != nil {
+			StmtCRUD:  &StmtCRUD{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, returning: n.returning},
+			updateStr: n.updateStr,
+			whereStr:  n.whereStr,
+			fields:    n.fields,
+			values:    n.values,
+			setBased:  setBased{predicate: n.predicate},
+		}
+		if err := stmt.parse(n.withOpts, n.where); err != nil {
 			return nil, err
 		}
 		return stmt, stmt.validate()
-	}
-	if re := reDelete; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
+	case *astDelete:
 		stmt := &StmtDelete{
-			StmtCRUD: &StmtCRUD{
-				Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-				dbName:   strings.TrimSpace(groups[0][2]),
-				collName: strings.TrimSpace(groups[0][3]),
-			},
-			whereStr: strings.TrimSpace(groups[0][4]),
+			StmtCRUD: &StmtCRUD{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, returning: n.returning},
+			whereStr: n.whereStr,
+			setBased: setBased{predicate: n.predicate},
 		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
+		if err := stmt.parse(n.withOpts, n.where); err != nil {
+			return nil, err
 		}
-		if err := stmt.parse(groups[0][5]); err != nil {
+		return stmt, stmt.validate()
+
+	case *astCreateScript:
+		switch n.kind {
+		case "PROCEDURE":
+			stmt := &StmtCreateProcedure{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, procName: n.name, orReplace: n.orReplace, body: n.body}
+			return stmt, stmt.validate()
+		case "TRIGGER":
+			stmt := &StmtCreateTrigger{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, triggerName: n.name, orReplace: n.orReplace, body: n.body}
+			stmt.parse(n.triggerType, n.operation)
+			return stmt, stmt.validate()
+		default:
+			stmt := &StmtCreateFunction{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, funcName: n.name, orReplace: n.orReplace, body: n.body}
+			return stmt, stmt.validate()
+		}
+	case *astDropScript:
+		switch n.kind {
+		case "PROCEDURE":
+			stmt := &StmtDropProcedure{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, procName: n.name, ifExists: n.ifExists}
+			return stmt, stmt.validate()
+		case "TRIGGER":
+			stmt := &StmtDropTrigger{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, triggerName: n.name, ifExists: n.ifExists}
+			return stmt, stmt.validate()
+		default:
+			stmt := &StmtDropFunction{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll, funcName: n.name, ifExists: n.ifExists}
+			return stmt, stmt.validate()
+		}
+	case *astListScripts:
+		switch n.kind {
+		case "PROCEDURE":
+			stmt := &StmtListProcedures{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll}
+			return stmt, stmt.validate()
+		case "TRIGGER":
+			stmt := &StmtListTriggers{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll}
+			return stmt, stmt.validate()
+		default:
+			stmt := &StmtListFunctions{Stmt: base, dbName: dbOrDefault(n.db), collName: n.coll}
+			return stmt, stmt.validate()
+		}
+	case *astExecProcedure:
+		stmt := &StmtExecProcedure{
+			Stmt:     base,
+			dbName:   dbOrDefault(n.db),
+			collName: n.coll,
+			procName: n.name,
+			argsStr:  n.argsStr,
+			args:     n.args,
+			pkValues: n.pkValues,
+		}
+		if err := stmt.parse(n.withOpts); err != nil {
 			return nil, err
 		}
 		return stmt, stmt.validate()
@@ -212,10 +195,11 @@ func ParseQueryWithDefaultDb(c *Conn, defaultDb, query string) (driver.Stmt, err
 
 // Stmt is Azure Cosmos DB abstract implementation of driver.Stmt.
 type Stmt struct {
-	query     string // the SQL query
-	conn      *Conn  // the connection that this prepared statement is bound to
-	numInputs int    // number of placeholder parameters, INCLUDING PK values!
-	withOpts  map[string]string
+	query       string   // the SQL query
+	conn        *Conn    // the connection that this prepared statement is bound to
+	numInputs   int      // number of placeholder parameters, INCLUDING PK values!
+	namedInputs []string // (since v1.2.0) distinct names of the named placeholders, if the statement uses named placeholders
+	withOpts    map[string]string
 }
 
 // String implements interface fmt.Stringer/String.
@@ -237,33 +221,87 @@ func (s *Stmt) onlyOneWithOption(errmsg string, optKeys ...string) error {
 	return nil
 }
 
-var reWithOpts = regexp.MustCompile(`(?is)^(\s+|\s*,\s+|\s+,\s*)WITH\s+` + field + `(\s*=\s*([\w/\.\*,;:'"-]+))?`)
-
-// parseWithOpts parses "WITH..." clause and store result in withOpts map.
+// parseWithOpts stores the options of the "WITH..." clause in withOpts map.
 // This function returns no error. Sub-implementations may override this behavior.
-func (s *Stmt) parseWithOpts(withOptsStr string) error {
-	withOptsStr = " " + withOptsStr
+func (s *Stmt) parseWithOpts(withOpts []withOption) error {
 	s.withOpts = make(map[string]string)
-	for {
-		matches := reWithOpts.FindStringSubmatch(withOptsStr)
-		if matches == nil {
-			break
+	for _, opt := range withOpts {
+		s.withOpts[opt.name] = opt.value
+	}
+	return nil
+}
+
+// addInput counts the value as an input parameter of the statement if it is a placeholder.
+//
+// @Available since v1.2.0
+func (s *Stmt) addInput(value interface{}) {
+	switch v := value.(type) {
+	case placeholder:
+		s.numInputs = g18.Max(s.numInputs, v.index)
+	case namedPlaceholder:
+		if g18.FindInSlice(v.name, s.namedInputs) < 0 {
+			s.namedInputs = append(s.namedInputs, v.name)
+			s.numInputs = len(s.namedInputs)
+		}
+	}
+}
+
+// checkNamedArgs verifies that a value is supplied for every named placeholder of the statement, and that every named
+// argument matches a named placeholder.
+//
+// @Available since v1.2.0
+func (s *Stmt) checkNamedArgs(args []driver.NamedValue) error {
+	for _, name := range s.namedInputs {
+		if findNamedArg(args, name) < 0 {
+			return fmt.Errorf("missing value for named parameter @%s", name)
+		}
+	}
+	for _, arg := range args {
+		if arg.Name != "" && g18.FindInSlice(arg.Name, s.namedInputs) < 0 {
+			return fmt.Errorf("there is no placeholder @%s", arg.Name)
 		}
-		k := strings.TrimSpace(strings.ToUpper(matches[2]))
-		s.withOpts[k] = strings.TrimSpace(strings.TrimSuffix(matches[4], ","))
-		withOptsStr = withOptsStr[len(matches[0]):]
 	}
 	return nil
 }
 
+func findNamedArg(args []driver.NamedValue, name string) int {
+	for i, arg := range args {
+		if arg.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// placeholderValue returns the value of the argument bound to the placeholder, or value as-is if it is not a
+// placeholder. Positional placeholders (e.g. $1) are bound to arguments by position, named placeholders (e.g. @name)
+// are bound to sql.Named arguments by name.
+//
+// @Available since v1.2.0
+func placeholderValue(value interface{}, args []driver.NamedValue) interface{} {
+	switch v := value.(type) {
+	case placeholder:
+		return args[v.index-1].Value
+	case namedPlaceholder:
+		if i := findNamedArg(args, v.name); i >= 0 {
+			return args[i].Value
+		}
+		return nil
+	}
+	return value
+}
+
 // Close implements driver.Stmt/Close.
 func (s *Stmt) Close() error {
 	return nil
 }
 
 // NumInput implements driver.Stmt/NumInput.
+//
+// Since v1.2.0, NumInput returns the number of input parameters of the statement (i.e. the highest index of the
+// positional placeholders, or the number of distinct named placeholders), instead of -1.
 func (s *Stmt) NumInput() int {
-	return -1
+	return s.numInputs
 }
 
 /*----------------------------------------------------------------------*/
@@ -304,6 +342,7 @@ func buildResultNoResultSet(restResponse *RestResponse, supportLastInsertId bool
 		lastInsertId:        rid,
 		supportLastInsertId: supportLastInsertId,
 	}
+	result.setResponseInfo(restResponse)
 	if result.err == nil {
 		result.affectedRows = 1
 	}
@@ -311,17 +350,87 @@ func buildResultNoResultSet(restResponse *RestResponse, supportLastInsertId bool
 	return result
 }
 
+// Result is the result of a statement executed with Exec. Besides the number of affected rows, it exposes the info
+// returned by the server for the written document.
+//
+// database/sql does not give access to the driver's result; use Conn.LastResult to retrieve it, for example:
+//
+//	conn, _ := db.Conn(ctx)
+//	defer conn.Close()
+//	_, err := conn.ExecContext(ctx, `INSERT INTO mydb.mytable (id, pk) VALUES (:1, :2)`, "myid", "mypk")
+//	var result gocosmos.Result
+//	_ = conn.Raw(func(driverConn any) error {
+//		result = driverConn.(*gocosmos.Conn).LastResult()
+//		return nil
+//	})
+//	fmt.Println(result.Rid(), result.Etag(), result.RequestCharge())
+//
+// @Available since v1.2.0
+type Result interface {
+	driver.Result
+
+	// DocId returns the id of the written document, the last one if the statement writes several documents.
+	// It returns "" if the statement does not write documents (e.g. CREATE COLLECTION).
+	DocId() string
+
+	// Rid returns the "_rid" of the written document, "" if not returned by the server (e.g. DELETE).
+	Rid() string
+
+	// Etag returns the "_etag" of the written document (or resource), "" if not returned by the server (e.g. DELETE).
+	Etag() string
+
+	// RequestCharge returns the request units consumed by the statement.
+	RequestCharge() float64
+
+	// SessionToken returns the session token returned by the server, used with session consistency level.
+	SessionToken() string
+
+	// ActivityId returns the id the server assigned to the request, useful when troubleshooting with Azure support.
+	ActivityId() string
+}
+
 // ResultNoResultSet captures the result from statements that do not expect a ResultSet to be returned.
 //
+// Since v1.2.0, ResultNoResultSet implements Result.
+//
 // @Available since v0.2.1
 type ResultNoResultSet struct {
 	err                 error
 	affectedRows        int64
 	supportLastInsertId bool
-	lastInsertId        string // holds the "_rid" if the operation returns it
+	lastInsertId        string    // holds the "_rid" if the operation returns it
+	requestCharge       float64   // (since v1.2.0) request units consumed by the statement
+	documents           []DocInfo // (since v1.2.0) server's version of the written documents, used by RETURNING
+	docId               string    // (since v1.2.0) id of the target document, if known by the statement (e.g. DELETE)
+	etag                string    // (since v1.2.0) ETag header of the response
+	sessionToken        string    // (since v1.2.0) session token of the response
+	activityId          string    // (since v1.2.0) activity id of the response
+}
+
+// setResponseInfo records the info of the server's response exposed by Result.
+//
+// @Available since v1.2.0
+func (r *ResultNoResultSet) setResponseInfo(restResponse *RestResponse) {
+	r.requestCharge = restResponse.RequestCharge
+	r.sessionToken = restResponse.SessionToken
+	r.activityId = restResponse.RespHeader[respHeaderActivityId]
+	r.etag = restResponse.RespHeader[respHeaderEtag]
+}
+
+// lastDocument returns the last written document, nil if none.
+func (r *ResultNoResultSet) lastDocument() DocInfo {
+	if len(r.documents) == 0 {
+		return nil
+	}
+	return r.documents[len(r.documents)-1]
 }
 
 // LastInsertId implements driver.Result/LastInsertId.
+//
+// Cosmos DB does not generate integer ids, the "_rid" of the inserted document is returned wrapped in the error
+// as {"last_insert_id":"<_rid>"}.
+//
+// Deprecated: use Result.Rid instead (since v1.2.0).
 func (r *ResultNoResultSet) LastInsertId() (int64, error) {
 	if r.err != nil {
 		return 0, r.err
@@ -337,6 +446,57 @@ func (r *ResultNoResultSet) RowsAffected() (int64, error) {
 	return r.affectedRows, r.err
 }
 
+// RequestCharge returns the request units consumed by the statement.
+//
+// @Available since v1.2.0
+func (r *ResultNoResultSet) RequestCharge() float64 {
+	return r.requestCharge
+}
+
+// DocId implements Result/DocId.
+//
+// @Available since v1.2.0
+func (r *ResultNoResultSet) DocId() string {
+	if r.docId != "" {
+		return r.docId
+	}
+	return r.lastDocument().Id()
+}
+
+// Rid implements Result/Rid.
+//
+// @Available since v1.2.0
+func (r *ResultNoResultSet) Rid() string {
+	if rid := r.lastDocument().Rid(); rid != "" {
+		return rid
+	}
+	return r.lastInsertId
+}
+
+// Etag implements Result/Etag.
+//
+// @Available since v1.2.0
+func (r *ResultNoResultSet) Etag() string {
+	if etag := r.lastDocument().Etag(); etag != "" {
+		return etag
+	}
+	return r.etag
+}
+
+// SessionToken implements Result/SessionToken.
+//
+// @Available since v1.2.0
+func (r *ResultNoResultSet) SessionToken() string {
+	return r.sessionToken
+}
+
+// ActivityId implements Result/ActivityId.
+//
+// @Available since v1.2.0
+func (r *ResultNoResultSet) ActivityId() string {
+	return r.activityId
+}
+
 /*----------------------------------------------------------------------*/
 
 // ResultResultSet captures the result from statements that expect a ResultSet to be returned.
@@ -350,6 +510,55 @@ type ResultResultSet struct {
 	columnTypes map[string]reflect.Type
 	rows        []DocInfo
 	documents   QueriedDocs
+	iterator    *QueryDocsIterator // (since v1.2.0) if not nil, rows following the prefetched ones are pulled from the iterator on demand
+	statusCode  int                // (since v1.2.0) status code of the failed REST call, if any
+	valueOnly   bool               // (since v1.2.0) true if rows are scalar values wrapped in column "$1"
+}
+
+// newStreamingResultSet builds a ResultResultSet that pulls rows from the iterator on demand. The first prefetch rows
+// are fetched upfront to determine the result's columns.
+//
+// @Available since v1.2.0
+func newStreamingResultSet(it *QueryDocsIterator, prefetch int) *ResultResultSet {
+	result := &ResultResultSet{columnList: make([]string, 0), iterator: it}
+	documents := make(QueriedDocs, 0, prefetch)
+	for len(documents) < prefetch {
+		doc, err := it.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			result.err, result.statusCode = err, it.statusCode
+			return result
+		}
+		documents = append(documents, doc)
+	}
+	result.documents = documents
+	return result.init()
+}
+
+// newReturningResultSet builds the result of a RETURNING clause from the written documents: all fields, system
+// attributes included, for "RETURNING *", or the listed fields in order.
+//
+// @Available since v1.2.0
+func newReturningResultSet(docs []DocInfo, fields []string) *ResultResultSet {
+	allFields := len(fields) == 1 && fields[0] == "*"
+	result := &ResultResultSet{rows: make([]DocInfo, len(docs))}
+	for i, doc := range docs {
+		if allFields {
+			result.rows[i] = doc
+			continue
+		}
+		result.rows[i] = make(DocInfo, len(fields))
+		for _, field := range fields {
+			result.rows[i][field] = doc[field]
+		}
+	}
+	result.init()
+	if !allFields {
+		result.columnList = fields
+	}
+	return result
 }
 
 func (r *ResultResultSet) init() *ResultResultSet {
@@ -361,6 +570,7 @@ func (r *ResultResultSet) init() *ResultResultSet {
 		documents := r.documents.AsDocInfoSlice()
 		if documents == nil {
 			// special case: result from a query like "SELECT COUNT(...)"
+			r.valueOnly = true
 			documents = make([]DocInfo, len(r.documents))
 			for i, doc := range r.documents {
 				var docInfo DocInfo = map[string]interface{}{"$1": doc}
@@ -371,6 +581,7 @@ func (r *ResultResultSet) init() *ResultResultSet {
 			documents[i] = doc.RemoveSystemAttrs()
 		}
 		r.rows = documents
+		r.documents = nil
 	}
 
 	if r.columnTypes == nil {
@@ -412,19 +623,50 @@ func (r *ResultResultSet) ColumnTypeDatabaseTypeName(index int) string {
 
 // Close implements driver.Rows/Close.
 func (r *ResultResultSet) Close() error {
+	if r.iterator != nil {
+		_ = r.iterator.Close()
+	}
 	return r.err
 }
 
+// nextRow returns the next row, pulling it from the iterator once all prefetched rows have been consumed.
+func (r *ResultResultSet) nextRow() (DocInfo, error) {
+	if r.cursorCount < r.count {
+		rowData := r.rows[r.cursorCount]
+		r.rows[r.cursorCount] = nil // release memory
+		r.cursorCount++
+		return rowData, nil
+	}
+	if r.iterator == nil {
+		return nil, io.EOF
+	}
+	doc, err := r.iterator.Next()
+	if err != nil {
+		return nil, err
+	}
+	r.cursorCount++
+	if docInfo := (QueriedDocs{doc}).AsDocInfoAt(0); docInfo != nil && !r.valueOnly {
+		return docInfo.RemoveSystemAttrs(), nil
+	}
+	return DocInfo{"$1": doc}, nil
+}
+
 // Next implements driver.Rows/Next.
+//
+// Note: since v1.2.0, columns are determined from the prefetched rows (see StmtSelect). If a row is pulled from the
+// server afterward, its fields that do not match any column are not returned.
 func (r *ResultResultSet) Next(dest []driver.Value) error {
 	if r.err != nil {
 		return r.err
 	}
-	if r.cursorCount >= r.count {
-		return io.EOF
+	rowData, err := r.nextRow()
+	if err == io.EOF {
+		return err
+	}
+	if err != nil {
+		r.err = normalizeError(r.iterator.statusCode, 0, err)
+		return r.err
 	}
-	rowData := r.rows[r.cursorCount]
-	r.cursorCount++
 	for i, colName := range r.columnList {
 		dest[i] = rowData[colName]
 	}
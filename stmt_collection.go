@@ -44,8 +44,8 @@ func (s *StmtCreateCollection) String() string {
 		s.Stmt, s.dbName, s.collName, s.ifNotExists, s.ru, s.maxru, s.pk, s.uk)
 }
 
-func (s *StmtCreateCollection) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtCreateCollection) parse(withOpts []withOption) error {
+	if err := s.Stmt.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
@@ -109,7 +109,7 @@ func (s *StmtCreateCollection) Exec(args []driver.Value) (driver.Result, error)
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtCreateCollection) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *StmtCreateCollection) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
@@ -134,8 +134,7 @@ func (s *StmtCreateCollection) ExecContext(_ context.Context, args []driver.Name
 		spec.UniqueKeyPolicy = map[string]interface{}{"uniqueKeys": uniqueKeys}
 	}
 
-	// TODO: pass ctx to REST API client
-	restResult := s.conn.restClient.CreateCollection(spec)
+	restResult := s.conn.restClient.CreateCollectionContext(ctx, spec)
 	ignoreErrorCode := 0
 	if s.ifNotExists {
 		ignoreErrorCode = 409
@@ -170,8 +169,8 @@ func (s *StmtAlterCollection) String() string {
 		s.Stmt, s.dbName, s.collName, s.ru, s.maxru)
 }
 
-func (s *StmtAlterCollection) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtAlterCollection) parse(withOpts []withOption) error {
+	if err := s.Stmt.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
@@ -221,12 +220,12 @@ func (s *StmtAlterCollection) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtAlterCollection) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *StmtAlterCollection) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
 
-	getResult := s.conn.restClient.GetCollection(s.dbName, s.collName)
+	getResult := s.conn.restClient.GetCollectionContext(ctx, s.dbName, s.collName)
 	if err := getResult.Error(); err != nil {
 		switch getResult.StatusCode {
 		case 403:
@@ -237,8 +236,7 @@ func (s *StmtAlterCollection) ExecContext(_ context.Context, args []driver.Named
 		return nil, err
 	}
 
-	// TODO: pass ctx to REST API client
-	restResult := s.conn.restClient.ReplaceOfferForResource(getResult.Rid, s.ru, s.maxru)
+	restResult := s.conn.restClient.ReplaceOfferForResourceContext(ctx, getResult.Rid, s.ru, s.maxru)
 	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
 	return result, result.err
 }
@@ -288,13 +286,12 @@ func (s *StmtDropCollection) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtDropCollection) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *StmtDropCollection) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
 
-	// TODO: pass ctx to REST API client
-	restResult := s.conn.restClient.DeleteCollection(s.dbName, s.collName)
+	restResult := s.conn.restClient.DeleteCollectionContext(ctx, s.dbName, s.collName)
 	ignoreErrorCode := 0
 	if s.ifExists {
 		ignoreErrorCode = 404
@@ -343,13 +340,12 @@ func (s *StmtListCollections) Query(args []driver.Value) (driver.Rows, error) {
 // QueryContext implements driver.StmtQueryContext/QueryContext.
 //
 // @Available since v1.1.1
-func (s *StmtListCollections) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+func (s *StmtListCollections) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
 
-	// TODO: pass ctx to REST API client
-	restResult := s.conn.restClient.ListCollections(s.dbName)
+	restResult := s.conn.restClient.ListCollectionsContext(ctx, s.dbName)
 	result := &ResultResultSet{
 		err:        restResult.Error(),
 		columnList: []string{"id", "indexingPolicy", "_rid", "_ts", "_self", "_etag", "_docs", "_sprocs", "_triggers", "_udfs", "_conflicts"},
@@ -32,8 +32,8 @@ func (s *StmtCreateDatabase) String() string {
 		s.Stmt, s.dbName, s.ifNotExists, s.ru, s.maxru)
 }
 
-func (s *StmtCreateDatabase) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtCreateDatabase) parse(withOpts []withOption) error {
+	if err := s.Stmt.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
@@ -80,13 +80,12 @@ func (s *StmtCreateDatabase) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtCreateDatabase) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *StmtCreateDatabase) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
 
-	// TODO: pass ctx to REST API client
-	restResult := s.conn.restClient.CreateDatabase(DatabaseSpec{Id: s.dbName, Ru: s.ru, MaxRu: s.maxru})
+	restResult := s.conn.restClient.CreateDatabaseContext(ctx, DatabaseSpec{Id: s.dbName, Ru: s.ru, MaxRu: s.maxru})
 	ignoreErrorCode := 0
 	if s.ifNotExists {
 		ignoreErrorCode = 409
@@ -120,8 +119,8 @@ func (s *StmtAlterDatabase) String() string {
 		s.Stmt, s.dbName, s.ru, s.maxru)
 }
 
-func (s *StmtAlterDatabase) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtAlterDatabase) parse(withOpts []withOption) error {
+	if err := s.Stmt.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
@@ -168,13 +167,12 @@ func (s *StmtAlterDatabase) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtAlterDatabase) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *StmtAlterDatabase) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
 
-	// TODO: pass ctx to REST API client
-	getResult := s.conn.restClient.GetDatabase(s.dbName)
+	getResult := s.conn.restClient.GetDatabaseContext(ctx, s.dbName)
 	if err := getResult.Error(); err != nil {
 		switch getResult.StatusCode {
 		case 403:
@@ -184,7 +182,7 @@ func (s *StmtAlterDatabase) ExecContext(_ context.Context, args []driver.NamedVa
 		}
 		return nil, err
 	}
-	restResult := s.conn.restClient.ReplaceOfferForResource(getResult.Rid, s.ru, s.maxru)
+	restResult := s.conn.restClient.ReplaceOfferForResourceContext(ctx, getResult.Rid, s.ru, s.maxru)
 	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
 	return result, result.err
 }
@@ -230,13 +228,12 @@ func (s *StmtDropDatabase) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtDropDatabase) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *StmtDropDatabase) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
 
-	// TODO: pass ctx to REST API client
-	restResult := s.conn.restClient.DeleteDatabase(s.dbName)
+	restResult := s.conn.restClient.DeleteDatabaseContext(ctx, s.dbName)
 	ignoreErrorCode := 0
 	if s.ifExists {
 		ignoreErrorCode = 404
@@ -281,13 +278,12 @@ func (s *StmtListDatabases) Query(args []driver.Value) (driver.Rows, error) {
 // QueryContext implements driver.StmtQueryContext/QueryContext.
 //
 // @Available since v1.1.1
-func (s *StmtListDatabases) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+func (s *StmtListDatabases) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
 	if len(args) != 0 {
 		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
 	}
 
-	// TODO: pass ctx to REST API client
-	restResult := s.conn.restClient.ListDatabases()
+	restResult := s.conn.restClient.ListDatabasesContext(ctx)
 	result := &ResultResultSet{
 		err:        restResult.Error(),
 		columnList: []string{"id", "_rid", "_ts", "_self", "_etag", "_colls", "_users"},
@@ -6,176 +6,273 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/btnguyen2k/consu/g18"
 	"github.com/btnguyen2k/consu/reddo"
+	"io"
 	"os"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
-var (
-	reValNull          = regexp.MustCompile(`(?i)(null)\s*?`)
-	reValNumber        = regexp.MustCompile(`([\d\.xe+-]+)\s*?`)
-	reValBoolean       = regexp.MustCompile(`(?i)(true|false)\s*?`)
-	reValString        = regexp.MustCompile(`(?i)("(\\"|[^"])*?")\s*?`)
-	reValPlaceholder   = regexp.MustCompile(`(?i)[$@:](\d+)\s*?`)
-	reValStringNoQuote = regexp.MustCompile(`(?i)([a-z0-9_./;:\\-]+)`)
-
-	reFieldEqual = regexp.MustCompile(field + `\s*=(.*?)`)
-)
-
 type placeholder struct {
 	index int
 }
 
-func _buildLeftover(input string, reSep *regexp.Regexp) string {
-	if sep := reSep.FindString(input); sep != "" {
-		input = input[len(sep):]
-	}
-	return input
+// namedPlaceholder is a placeholder bound to a sql.Named argument, e.g. @name.
+//
+// @Available since v1.2.0
+type namedPlaceholder struct {
+	name string
 }
 
-func _parseValue(input string, separator rune) (value interface{}, leftOver string, err error) {
-	reSep := regexp.MustCompile(`^\s*\` + string(separator) + `\s*`)
+// StmtCRUD is abstract implementation of "INSERT|UPSERT|UPDATE|DELETE|SELECT" operations.
+//
+// @Available since v0.3.0
+type StmtCRUD struct {
+	*Stmt
+	dbName         string
+	collName       string
+	isSinglePathPk bool
+	withPk         string
+	pkPaths        []string
+	numPkPaths     int      // number of PK paths
+	returning      []string // (since v1.2.0) fields of the RETURNING clause, ["*"] for all fields
+}
 
-	if loc := reValPlaceholder.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		token := strings.TrimSpace(input[loc[0]+1 : loc[1]])
-		index, err := strconv.Atoi(token)
-		return placeholder{index}, _buildLeftover(input[loc[1]:], reSep), err
-	}
+// String implements interface fmt.Stringer/String.
+//
+// @Available since v1.1.0
+func (s *StmtCRUD) String() string {
+	return fmt.Sprintf(`StmtCRUD{Stmt: %s, db: %q, collection: %q, is_single_pk: %v, with_pk: %q, pk_paths: %v, num_pk_paths: %d}`,
+		s.Stmt, s.dbName, s.collName, s.isSinglePathPk, s.withPk, s.pkPaths, s.numPkPaths)
+}
 
-	if loc := reValNull.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		return nil, _buildLeftover(input[loc[1]:], reSep), nil
+// queryReturning executes a write statement having a RETURNING clause and returns the server's version of the written
+// documents as rows.
+//
+// @Available since v1.2.0
+func (s *StmtCRUD) queryReturning(ctx context.Context, exec func() (driver.Result, error)) (driver.Rows, error) {
+	if s.returning == nil {
+		return nil, ErrQueryNotSupported
 	}
-
-	if loc := reValNumber.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		token := strings.TrimSpace(input[loc[0]:loc[1]])
-		var data interface{}
-		err := json.Unmarshal([]byte(token), &data)
-		if err != nil {
-			err = errors.New("(number) cannot parse query, invalid token at: " + token)
-		}
-		return data, _buildLeftover(input[loc[1]:], reSep), err
+	if txFromConn(s.conn) != nil {
+		return nil, errors.New("RETURNING is not supported inside a transaction, use Exec instead")
 	}
-
-	if loc := reValBoolean.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		token := strings.TrimSpace(input[loc[0]:loc[1]])
-		var data bool
-		err := json.Unmarshal([]byte(token), &data)
-		if err != nil {
-			err = errors.New("(bool) cannot parse query, invalid token at: " + token)
-		}
-		return data, _buildLeftover(input[loc[1]:], reSep), err
+	result, err := exec()
+	if err != nil {
+		return nil, err
 	}
+	return newReturningResultSet(result.(*ResultNoResultSet).documents, s.returning), nil
+}
 
-	if loc := reValString.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		var data interface{}
-		token, err := strconv.Unquote(strings.TrimSpace(input[loc[0]:loc[1]]))
-		if err == nil {
-			err = json.Unmarshal([]byte(token), &data)
-			if err != nil {
-				err = errors.New("(unmarshal) cannot parse query, invalid token at: " + token)
+// setBased holds the WHERE clause and options of a set-based UPDATE or DELETE statement, which modifies all documents
+// matching a Cosmos DB SQL predicate instead of a single document specified by id.
+//
+// @Available since v1.2.0
+type setBased struct {
+	predicate *astPredicate // nil if the statement targets a single document
+	maxRows   int           // if > 0, the statement fails with ErrTooManyRows if more documents match
+	dryRun    bool          // if true, matching documents are counted but not modified
+}
+
+// parseSetBasedOpts validates the WITH options of an UPDATE or DELETE statement: WITH MAX_ROWS and WITH DRY_RUN are
+// supported by set-based statements only, WITH SINGLE_PK by single-document statements only.
+func (s *StmtCRUD) parseSetBasedOpts(sb *setBased) error {
+	if err := s.onlyOneWithOption("max rows is specified more than once, only one of MAX_ROWS or MaxRows should be specified", "MAX_ROWS", "MAXROWS"); err != nil {
+		return err
+	}
+	if err := s.onlyOneWithOption("dry run is specified more than once, only one of DRY_RUN or DryRun should be specified", "DRY_RUN", "DRYRUN"); err != nil {
+		return err
+	}
+	for k, v := range s.withOpts {
+		switch {
+		case (k == "SINGLE_PK" || k == "SINGLEPK") && sb.predicate == nil:
+		case (k == "MAX_ROWS" || k == "MAXROWS") && sb.predicate != nil:
+			maxRows, err := strconv.ParseInt(v, 10, 32)
+			if err != nil || maxRows <= 0 {
+				return fmt.Errorf("invalid value at WITH %s: %s", k, v)
 			}
-		} else {
-			err = errors.New("(unquote) cannot parse query, invalid token at: " + token)
+			sb.maxRows = int(maxRows)
+		case (k == "DRY_RUN" || k == "DRYRUN") && sb.predicate != nil:
+			sb.dryRun = true
+			if v != "" {
+				val, err := strconv.ParseBool(v)
+				if err != nil {
+					return fmt.Errorf("invalid value at WITH %s: %s", k, v)
+				}
+				sb.dryRun = val
+			}
+		default:
+			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
 		}
-		return data, _buildLeftover(input[loc[1]:], reSep), err
 	}
-	if loc := reValStringNoQuote.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		data := strings.TrimSpace(input[loc[0]:loc[1]])
-		return data, _buildLeftover(input[loc[1]:], reSep), err
+	if sb.predicate != nil {
+		for _, param := range sb.predicate.params {
+			s.addInput(param)
+		}
 	}
+	return nil
+}
 
-	return nil, input, errors.New("cannot parse query, invalid token at: " + input)
+// matchedDoc is a document matching the predicate of a set-based statement.
+type matchedDoc struct {
+	id       string
+	etag     string
+	pkValues []interface{}
+	doc      DocInfo // the whole document, if requested
 }
 
-func _parseFieldValueAnd(input string) (field string, value interface{}, leftOver string, err error) {
-	reSep := regexp.MustCompile(`(?i)^\s*and\s+`)
+// lookupQuery builds the query looking up the documents matching the predicate of a set-based statement: for each
+// document, its id, etag, partition key values and, if fullDocs is true, the whole document.
+func (s *StmtCRUD) lookupQuery(predicate string, fullDocs bool) string {
+	pkValues := make([]string, len(s.pkPaths))
+	for i, pkPath := range s.pkPaths {
+		pkValues[i] = "c"
+		for _, name := range strings.Split(pkPath[1:], "/") {
+			js, _ := json.Marshal(name)
+			pkValues[i] += "[" + string(js) + "]"
+		}
+	}
+	doc := ""
+	if fullDocs {
+		doc = `, "doc": c`
+	}
+	return fmt.Sprintf(`SELECT VALUE {"id": c.id, "etag": c._etag, "pk": [%s]%s} FROM c WHERE %s`, strings.Join(pkValues, ", "), doc, predicate)
+}
 
-	//must star with <field>\s*=
-	if loc := reFieldEqual.FindStringSubmatchIndex(input); loc != nil && loc[0] == 0 {
-		field = input[loc[2]:loc[3]]
-		input = strings.TrimSpace(input[loc[1]:])
+// execSetBased looks up the documents matching the predicate of a set-based statement, then writes them concurrently
+// with the items built by toItem. Items apply only if the document has not been modified since it was looked up:
+// documents modified or deleted in the meantime are skipped.
+//
+// The result holds the written documents returned by the server or, if returnMatched is true, the documents as they
+// were looked up (fullDocs must be true then).
+//
+// @Available since v1.2.0
+func (s *StmtCRUD) execSetBased(ctx context.Context, args []driver.NamedValue, sb setBased, fullDocs, returnMatched bool,
+	toItem func(m matchedDoc) BulkItem) (*ResultNoResultSet, error) {
+	if txFromConn(s.conn) != nil {
+		return nil, errors.New("set-based UPDATE/DELETE is not supported inside a transaction")
+	}
+	if len(args) != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, len(args))
+	}
+	if err := s.checkNamedArgs(args); err != nil {
+		return nil, err
+	}
+	if err := s.fetchPkInfo(ctx); err != nil {
+		return nil, err
+	}
 
-		if loc := reValPlaceholder.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			token := strings.TrimSpace(input[loc[0]+1 : loc[1]])
-			index, err := strconv.Atoi(token)
-			return field, placeholder{index}, _buildLeftover(input[loc[1]:], reSep), err
+	queryReq := QueryReq{
+		DbName:                s.dbName,
+		CollName:              s.collName,
+		Query:                 s.lookupQuery(sb.predicate.text, fullDocs),
+		Params:                make([]interface{}, len(sb.predicate.params)),
+		CrossPartitionEnabled: true,
+	}
+	for i, param := range sb.predicate.params {
+		name := ""
+		switch v := param.(type) {
+		case placeholder:
+			name = "@_" + strconv.Itoa(v.index)
+		case namedPlaceholder:
+			name = "@" + v.name
 		}
-
-		if loc := reValNull.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			return field, nil, _buildLeftover(input[loc[1]:], reSep), nil
+		queryReq.Params[i] = map[string]interface{}{"name": name, "value": placeholderValue(param, args)}
+	}
+	it := s.conn.restClient.QueryDocumentsIteratorContext(ctx, queryReq)
+	defer func() { _ = it.Close() }()
+	var matched []matchedDoc
+	var rows []int // positions of the matched documents in the query's result, starting from 1
+	var rowsErr RowsError
+	for row := 1; ; row++ {
+		doc, err := it.Next()
+		if err == io.EOF {
+			break
 		}
-
-		if loc := reValNumber.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			token := strings.TrimSpace(input[loc[0]:loc[1]])
-			var data interface{}
-			err := json.Unmarshal([]byte(token), &data)
-			if err != nil {
-				err = errors.New("(number) cannot parse query, invalid token at: " + token)
-			}
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
+		if err != nil {
+			return nil, normalizeError(it.statusCode, 0, err)
+		}
+		if sb.maxRows > 0 && row > sb.maxRows {
+			return nil, fmt.Errorf("%w, more than WITH MAX_ROWS=%d", ErrTooManyRows, sb.maxRows)
 		}
+		m, err := s.matchedDoc(doc, fullDocs)
+		if err != nil {
+			rowsErr.Rows = append(rowsErr.Rows, RowError{Row: row, Err: err})
+			continue
+		}
+		matched, rows = append(matched, m), append(rows, row)
+	}
+	result := &ResultNoResultSet{requestCharge: it.RequestCharge()}
 
-		if loc := reValBoolean.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			token := strings.TrimSpace(input[loc[0]:loc[1]])
-			var data bool
-			err := json.Unmarshal([]byte(token), &data)
-			if err != nil {
-				err = errors.New("(bool) cannot parse query, invalid token at: " + token)
+	if sb.dryRun {
+		result.affectedRows = int64(len(matched))
+		for _, m := range matched {
+			if m.doc != nil {
+				result.documents = append(result.documents, m.doc)
 			}
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
 		}
-
-		if loc := reValString.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			var data interface{}
-			token, err := strconv.Unquote(strings.TrimSpace(input[loc[0]:loc[1]]))
-			if err == nil {
-				err = json.Unmarshal([]byte(token), &data)
-				if err != nil {
-					err = errors.New("(unmarshal) cannot parse query, invalid token at: " + token)
+	} else if len(matched) > 0 {
+		bulkReq := BulkReq{Items: make([]BulkItem, len(matched))}
+		for i, m := range matched {
+			bulkReq.Items[i] = toItem(m)
+		}
+		bulkResult := s.conn.restClient.ExecuteBulkContext(ctx, bulkReq)
+		result.requestCharge += bulkResult.RequestCharge
+		for _, r := range bulkResult.Results {
+			switch {
+			case r.Err == nil:
+				result.affectedRows++
+				if returnMatched {
+					if doc := matched[r.Index].doc; doc != nil {
+						result.documents = append(result.documents, doc)
+					}
+				} else {
+					result.documents = append(result.documents, r.DocInfo)
 				}
-			} else {
-				err = errors.New("(unquote) cannot parse query, invalid token at: " + token)
+			case r.StatusCode == 404 || r.StatusCode == 412:
+				// the document has been deleted or modified since it was looked up
+			default:
+				rowsErr.Rows = append(rowsErr.Rows, RowError{Row: rows[r.Index], Err: normalizeError(r.StatusCode, 0, r.Err)})
 			}
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
 		}
-		if loc := reValStringNoQuote.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			data := strings.TrimSpace(input[loc[0]:loc[1]])
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
+		if bulkResult.CallErr != nil {
+			result.err = bulkResult.CallErr
 		}
 	}
-	return "", nil, input, errors.New("cannot parse query, invalid token at: " + input)
-}
-
-// StmtCRUD is abstract implementation of "INSERT|UPSERT|UPDATE|DELETE|SELECT" operations.
-//
-// @Available since v0.3.0
-type StmtCRUD struct {
-	*Stmt
-	dbName         string
-	collName       string
-	isSinglePathPk bool
-	withPk         string
-	pkPaths        []string
-	numPkPaths     int // number of PK paths
+	if len(rowsErr.Rows) > 0 {
+		sort.Slice(rowsErr.Rows, func(i, j int) bool { return rowsErr.Rows[i].Row < rowsErr.Rows[j].Row })
+		rowsErr.RowsAffected = result.affectedRows
+		result.err = &rowsErr
+	}
+	return result, result.err
 }
 
-// String implements interface fmt.Stringer/String.
-//
-// @Available since v1.1.0
-func (s *StmtCRUD) String() string {
-	return fmt.Sprintf(`StmtCRUD{Stmt: %s, db: %q, collection: %q, is_single_pk: %v, with_pk: %q, pk_paths: %v, num_pk_paths: %d}`,
-		s.Stmt, s.dbName, s.collName, s.isSinglePathPk, s.withPk, s.pkPaths, s.numPkPaths)
+// matchedDoc extracts a document returned by the query built by lookupQuery.
+func (s *StmtCRUD) matchedDoc(doc interface{}, fullDoc bool) (matchedDoc, error) {
+	var m matchedDoc
+	data := QueriedDocs{doc}.AsDocInfoAt(0)
+	if data == nil {
+		return m, fmt.Errorf("expected a document but the query returned %T", doc)
+	}
+	m.id, _ = data["id"].(string)
+	m.etag, _ = data["etag"].(string)
+	if m.pkValues, _ = data["pk"].([]interface{}); len(m.pkValues) != len(s.pkPaths) {
+		// undefined values are left out of the array
+		return m, fmt.Errorf("missing value for PK %s of document %q", strings.Join(s.pkPaths, ","), m.id)
+	}
+	if fullDoc {
+		m.doc = QueriedDocs{data["doc"]}.AsDocInfoAt(0)
+	}
+	return m, nil
 }
 
-func (s *StmtCRUD) fetchPkInfo() error {
+func (s *StmtCRUD) fetchPkInfo(ctx context.Context) error {
 	if s.numPkPaths > 0 || s.conn == nil || s.isSinglePathPk {
 		return nil
 	}
 
-	getCollResult := s.conn.restClient.GetCollection(s.dbName, s.collName)
+	getCollResult := s.conn.restClient.GetCollectionContext(ctx, s.dbName, s.collName)
 	if getCollResult.Error() == nil {
 		s.pkPaths = getCollResult.CollInfo.PartitionKey.Paths()
 		s.numPkPaths = len(s.pkPaths)
@@ -183,8 +280,28 @@ func (s *StmtCRUD) fetchPkInfo() error {
 	return normalizeError(getCollResult.StatusCode, 0, getCollResult.Error())
 }
 
-func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
+// parseIdAndPk extracts the document id and the partition key values from the conditions of the WHERE clause
+// "id=<id-value> [AND pk1-path=<pk1-value>...]".
+func (s *StmtCRUD) parseIdAndPk(where []condition) (id interface{}, pkValues []interface{}) {
+	s.pkPaths = make([]string, 0)
+	pkValues = make([]interface{}, 0)
+	for _, cond := range where {
+		if strings.ToLower(cond.field) == "id" {
+			id = cond.value
+		} else {
+			s.pkPaths = append(s.pkPaths, "/"+strings.TrimLeft(cond.field, "/"))
+			pkValues = append(pkValues, cond.value)
+		}
+		s.addInput(cond.value)
+	}
+	if !s.isSinglePathPk {
+		s.numPkPaths = len(s.pkPaths)
+	}
+	return id, pkValues
+}
+
+func (s *StmtCRUD) parseWithOpts(withOpts []withOption) error {
+	if err := s.Stmt.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
@@ -217,6 +334,10 @@ func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
 
 	if s.withPk != "" {
 		s.pkPaths = strings.Split(s.withPk, ",")
+		for i, pkPath := range s.pkPaths {
+			// each path may be quoted, e.g. WITH pk=/`tenant id`,/`user id`
+			s.pkPaths[i] = "/" + unquoteIdent(strings.TrimLeft(strings.TrimSpace(pkPath), "/"))
+		}
 		s.numPkPaths = len(s.pkPaths)
 	} else if s.isSinglePathPk {
 		s.numPkPaths = 1
@@ -225,18 +346,57 @@ func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
 	return nil
 }
 
+// NumInput implements driver.Stmt/NumInput.
+//
+// If the statement uses named placeholders, NumInput returns the number of distinct named placeholders. Otherwise, -1
+// is returned as PK values may also be supplied at the end of the parameter list (deprecated).
+//
+// @Available since v1.2.0
+func (s *StmtCRUD) NumInput() int {
+	if len(s.namedInputs) > 0 {
+		return s.numInputs
+	}
+	return -1
+}
+
+// splitPkArgs checks the number of supplied arguments and, if PK values are supplied at the end of the parameter list
+// (deprecated), splits them from the arguments.
+//
+// @Available since v1.2.0
+func (s *StmtCRUD) splitPkArgs(args []driver.NamedValue, warnMsg string) ([]driver.NamedValue, []driver.NamedValue, error) {
+	if err := s.checkNamedArgs(args); err != nil {
+		return nil, nil, err
+	}
+	n := len(args)
+	if len(s.namedInputs) == 0 && n == s.numInputs+s.numPkPaths && s.numPkPaths > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "[WARN] %s\n", warnMsg)
+		return args[:s.numInputs], args[s.numInputs:], nil
+	}
+	if n == s.numInputs {
+		return args, nil, nil
+	}
+	if len(s.namedInputs) > 0 {
+		return nil, nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, n)
+	}
+	return nil, nil, fmt.Errorf("expected %d or %d input values, got %d", s.numInputs, s.numInputs+s.numPkPaths, n)
+}
+
 // StmtInsert implements "INSERT" operation.
 //
 // Syntax:
 //
 //	INSERT|UPSERT INTO <db-name>.<collection-name>
 //	(<field-list>)
-//	VALUES (<value-list>)
+//	VALUES (<value-list>)[, (<value-list>)]*
 //	[WITH PK=/pk-path]
 //
 //	- values are comma separated.
+//	- (since v1.2.0) several rows can be inserted at once: the documents are written concurrently, grouped by logical
+//	  partition (see RestClient.ExecuteBulk). Rows are written independently: if some of them fail, the others are still
+//	  written and a *RowsError listing the failed rows is returned.
 //	- a value is either:
-//	  - a placeholder (e.g. :1, @2 or $3)
+//	  - a placeholder (e.g. :1, @2 or $3), or (since v1.2.0) a named placeholder (e.g. @name) bound to sql.Named("name", value)
+//	    (positional and named placeholders must not be mixed in the same statement)
 //	  - a null
 //	  - a number
 //	  - a boolean (true/false)
@@ -250,6 +410,7 @@ func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
 //
 //	- Using WITH PK is highly recommended to save one round-trip to server to fetch the collection's partition key info.
 //	- If collection's PK has more than one path (i.e. sub-partition is used), the partition paths are comma separated, prefixed with '/', and must be specified in the same order as in the collection (.e.g. WITH PK=/field1,/field2...).
+//	- (since v1.2.0) Inside a transaction, the insertion is buffered and committed as part of a transactional batch (see Tx).
 //
 // CosmosDB automatically creates a few extra fields for the insert document.
 // See https://docs.microsoft.com/en-us/azure/cosmos-db/account-databases-containers-items#properties-of-an-item.
@@ -260,18 +421,19 @@ type StmtInsert struct {
 	valuesStr string
 	fields    []string
 	values    []interface{}
+	extraRows [][]interface{} // (since v1.2.0) values of the rows following the first one, multi-row VALUES list only
 }
 
 // String implements interface fmt.Stringer/String.
 //
 // @Available since v1.1.0
 func (s *StmtInsert) String() string {
-	return fmt.Sprintf(`StmtInsert{StmtCRUD: %s, upsert: %v, field_str: %q, value_str: %q, fields: %v, values: %v}`,
-		s.StmtCRUD, s.isUpsert, s.fieldsStr, s.valuesStr, s.fields, s.values)
+	return fmt.Sprintf(`StmtInsert{StmtCRUD: %s, upsert: %v, field_str: %q, value_str: %q, fields: %v, values: %v, num_rows: %d}`,
+		s.StmtCRUD, s.isUpsert, s.fieldsStr, s.valuesStr, s.fields, s.values, 1+len(s.extraRows))
 }
 
-func (s *StmtInsert) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtInsert) parse(withOpts []withOption) error {
+	if err := s.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
@@ -281,29 +443,31 @@ func (s *StmtInsert) parse(withOptsStr string) error {
 		}
 	}
 
-	s.fields = regexp.MustCompile(`[,\s]+`).Split(s.fieldsStr, -1)
-	s.values = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.valuesStr); temp != ""; temp = strings.TrimSpace(temp) {
-		value, leftOver, err := _parseValue(temp, ',')
-		if err == nil {
-			s.values = append(s.values, value)
-			temp = leftOver
-			switch v := value.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
-			}
-			continue
+	for _, row := range s.rows() {
+		for _, value := range row {
+			s.addInput(value)
 		}
-		return err
 	}
 
 	return nil
 }
 
+// rows returns the values of all rows of the VALUES list.
+//
+// @Available since v1.2.0
+func (s *StmtInsert) rows() [][]interface{} {
+	return append([][]interface{}{s.values}, s.extraRows...)
+}
+
 func (s *StmtInsert) validate() error {
 	if len(s.fields) != len(s.values) {
 		return fmt.Errorf("number of fields (%d) does not match number of values (%d)", len(s.fields), len(s.values))
 	}
+	for i, row := range s.extraRows {
+		if len(s.fields) != len(row) {
+			return fmt.Errorf("number of fields (%d) does not match number of values (%d) of row #%d", len(s.fields), len(row), i+2)
+		}
+	}
 	if s.dbName == "" || s.collName == "" {
 		return errors.New("database/collection is missing")
 	}
@@ -321,69 +485,358 @@ func (s *StmtInsert) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtInsert) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
-	// TODO: pass ctx to REST API client
-
-	if err := s.fetchPkInfo(); err != nil {
+func (s *StmtInsert) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.fetchPkInfo(ctx); err != nil {
 		return nil, err
 	}
 
-	pkValues := make([]driver.NamedValue, s.numPkPaths)
-	if n := len(args); n == s.numInputs+s.numPkPaths {
-		_, _ = fmt.Fprintf(os.Stderr, "[WARN] supplying PK value at the end of parameter list is deprecated, please use WITH PK\n")
-		copy(pkValues, args[s.numInputs:])
-		args = args[:s.numInputs]
-	} else if n == s.numInputs {
-		fieldValMap := make(map[string]interface{})
-		for i, field := range s.fields {
-			fieldValMap[field] = s.values[i]
+	args, pkArgs, err := s.splitPkArgs(args, "supplying PK value at the end of parameter list is deprecated, please use WITH PK")
+	if err != nil {
+		return nil, err
+	}
+	if pkArgs != nil && len(s.extraRows) > 0 {
+		return nil, errors.New("PK values at the end of parameter list are not supported by multi-row INSERT, please use WITH PK")
+	}
+	rows := s.rows()
+	specs := make([]DocumentSpec, len(rows))
+	for i, row := range rows {
+		if specs[i], err = s.docSpec(row, args, pkArgs); err != nil {
+			return nil, err
 		}
-		for i, pkPath := range s.pkPaths {
-			v, ok := fieldValMap[pkPath[1:]]
-			if !ok {
-				return nil, fmt.Errorf("missing value for PK %s", pkPath)
+	}
+
+	if tx := txFromConn(s.conn); tx != nil {
+		jsPkValues, _ := json.Marshal(specs[0].PartitionKeyValues)
+		ops := make([]BatchOp, len(specs))
+		for i, spec := range specs {
+			if js, _ := json.Marshal(spec.PartitionKeyValues); string(js) != string(jsPkValues) {
+				// all rows must belong to the partition of the transaction, which a transactional batch is bound to
+				return nil, ErrTxCrossPartition
+			}
+			ops[i] = BatchOp{Op: BatchOpCreate, Document: spec.DocumentData}
+			if s.isUpsert {
+				ops[i].Op = BatchOpUpsert
 			}
-			pkValues[i] = driver.NamedValue{Name: pkPath[1:], Value: v}
 		}
-	} else {
-		return nil, fmt.Errorf("expected %d or %d input values, got %d", s.numInputs, s.numInputs+s.numPkPaths, n)
+		return tx.addOps(s.dbName, s.collName, specs[0].PartitionKeyValues, ops, true)
+	}
+	if len(specs) > 1 {
+		return s.execBulk(ctx, specs)
 	}
+	restResult := s.conn.restClient.CreateDocumentContext(ctx, specs[0])
+	rid := ""
+	if restResult.DocInfo != nil {
+		rid, _ = restResult.DocInfo["_rid"].(string)
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, true, rid, 0)
+	if result.err == nil {
+		result.documents = []DocInfo{restResult.DocInfo}
+	}
+	return result, result.err
+}
 
+// docSpec builds the document to be written from the values of a row.
+//
+// @Available since v1.2.0
+func (s *StmtInsert) docSpec(values []interface{}, args, pkArgs []driver.NamedValue) (DocumentSpec, error) {
 	spec := DocumentSpec{
 		DbName:             s.dbName,
 		CollName:           s.collName,
 		IsUpsert:           s.isUpsert,
-		PartitionKeyValues: make([]any, len(pkValues)),
+		PartitionKeyValues: make([]any, s.numPkPaths),
 		DocumentData:       make(map[string]any),
 	}
-	for i, pkValue := range pkValues {
-		switch v := pkValue.Value.(type) {
-		case placeholder:
-			spec.PartitionKeyValues[i] = args[v.index-1].Value
-		default:
-			spec.PartitionKeyValues[i] = v
+	for i, field := range s.fields {
+		spec.DocumentData[field] = placeholderValue(values[i], args)
+	}
+	if pkArgs != nil {
+		for i, pkArg := range pkArgs {
+			spec.PartitionKeyValues[i] = pkArg.Value
 		}
+		return spec, nil
 	}
-	for i, field := range s.fields {
-		switch v := s.values[i].(type) {
-		case placeholder:
-			spec.DocumentData[field] = args[v.index-1].Value
-		default:
-			spec.DocumentData[field] = s.values[i]
+	for i, pkPath := range s.pkPaths {
+		v, ok := spec.DocumentData[pkPath[1:]]
+		if !ok {
+			return spec, fmt.Errorf("missing value for PK %s", pkPath)
 		}
+		spec.PartitionKeyValues[i] = v
 	}
-	restResult := s.conn.restClient.CreateDocument(spec)
-	rid := ""
-	if restResult.DocInfo != nil {
-		rid, _ = restResult.DocInfo["_rid"].(string)
+	return spec, nil
+}
+
+// execBulk writes the documents of a multi-row INSERT concurrently.
+//
+// @Available since v1.2.0
+func (s *StmtInsert) execBulk(ctx context.Context, specs []DocumentSpec) (driver.Result, error) {
+	bulkReq := BulkReq{Items: make([]BulkItem, len(specs))}
+	for i, spec := range specs {
+		bulkReq.Items[i] = BulkItem{DocumentSpec: spec}
+	}
+	bulkResult := s.conn.restClient.ExecuteBulkContext(ctx, bulkReq)
+	result := &ResultNoResultSet{supportLastInsertId: true, requestCharge: bulkResult.RequestCharge}
+	var rowsErr *RowsError
+	for _, r := range bulkResult.Results {
+		if r.Err != nil {
+			if rowsErr == nil {
+				rowsErr = &RowsError{}
+			}
+			rowsErr.Rows = append(rowsErr.Rows, RowError{Row: r.Index + 1, Err: normalizeError(r.StatusCode, 0, r.Err)})
+			continue
+		}
+		result.affectedRows++
+		result.documents = append(result.documents, r.DocInfo)
+		if rid := r.DocInfo.Rid(); rid != "" {
+			result.lastInsertId = rid
+		}
+	}
+	if rowsErr == nil && bulkResult.CallErr != nil {
+		result.err = bulkResult.CallErr
+	} else if rowsErr != nil {
+		rowsErr.RowsAffected = result.affectedRows
+		result.err = rowsErr
+	}
+	return result, result.err
+}
+
+// RowError reports the failure of a row of a multi-row statement.
+//
+// @Available since v1.2.0
+type RowError struct {
+	Row int   // position of the row in the statement, starting from 1
+	Err error // e.g. ErrConflict
+}
+
+// RowsError is returned by a multi-row statement (e.g. INSERT INTO ... VALUES (...), (...)) if some of the rows failed.
+// Rows that are not listed have been written.
+//
+// @Available since v1.2.0
+type RowsError struct {
+	Rows         []RowError // failed rows, sorted by position
+	RowsAffected int64      // number of rows that have been written
+}
+
+// Error implements error/Error.
+func (e *RowsError) Error() string {
+	msgs := make([]string, len(e.Rows))
+	for i, r := range e.Rows {
+		msgs[i] = fmt.Sprintf("row #%d: %s", r.Row, r.Err)
+	}
+	return fmt.Sprintf("%d row(s) failed, %d row(s) affected: %s", len(e.Rows), e.RowsAffected, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of the first failed row, so that errors.Is(err, ErrConflict) reports whether the first
+// failed row was a conflict.
+func (e *RowsError) Unwrap() error {
+	if len(e.Rows) == 0 {
+		return nil
+	}
+	return e.Rows[0].Err
+}
+
+// Query implements driver.Stmt/Query.
+// This function is supported only if the statement has a RETURNING clause (since v1.2.0), use Exec otherwise.
+func (s *StmtInsert) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// It writes the documents and returns the server's version of them, projected onto the RETURNING clause.
+//
+// @Available since v1.2.0
+func (s *StmtInsert) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.queryReturning(ctx, func() (driver.Result, error) { return s.ExecContext(ctx, args) })
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtInsertSelect implements "INSERT ... SELECT" operation, which copies documents between collections.
+//
+// Syntax:
+//
+//	INSERT|UPSERT INTO [<db-name>.]<collection-name>
+//	SELECT ... FROM <source-collection-name> ...
+//	[WITH database|db=<source-db-name>]
+//	[WITH collection|table=<source-collection-name>]
+//	[WITH cross_partition|CrossPartition[=true]]
+//	[WITH max_degree_of_parallelism|MaxDegreeOfParallelism=<n>]
+//	[WITH PK=/pk-path]
+//
+//	- The SELECT query follows the same syntax as StmtSelect. If WITH database is not specified, the source collection
+//	  belongs to the target's database.
+//	- Documents returned by the query are written to the target collection as-is (system attributes removed); hence,
+//	  the query must return objects having an "id" field, e.g. SELECT c.id, c.name, UPPER(c.code) AS code FROM c.
+//	- WITH PK specifies the partition key of the target collection. If not specified, it is fetched from the server.
+//	- Documents are streamed from the query and written concurrently, grouped by logical partition (see
+//	  RestClient.ExecuteBulk). Documents are written independently: if some of them fail, the others are still written
+//	  and a *RowsError listing the failed documents (by position in the query's result) is returned.
+//	- The statement is not supported inside a transaction.
+//
+// The result's RequestCharge is the total request units consumed by the query and the writes.
+//
+// @Available since v1.2.0
+type StmtInsertSelect struct {
+	*StmtCRUD
+	isUpsert bool
+	query    *StmtSelect
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtInsertSelect) String() string {
+	return fmt.Sprintf(`StmtInsertSelect{StmtCRUD: %s, upsert: %v, query: %s}`, s.StmtCRUD, s.isUpsert, s.query)
+}
+
+// parse dispatches the options of the WITH clause: PK applies to the target collection, the others to the query.
+func (s *StmtInsertSelect) parse(withOpts []withOption) error {
+	targetOpts, queryOpts := make([]withOption, 0), make([]withOption, 0)
+	for _, opt := range withOpts {
+		if opt.name == "PK" {
+			targetOpts = append(targetOpts, opt)
+		} else {
+			queryOpts = append(queryOpts, opt)
+		}
+	}
+	if err := s.parseWithOpts(targetOpts); err != nil {
+		return err
+	}
+	if err := s.query.parse(queryOpts); err != nil {
+		return err
+	}
+	s.numInputs, s.namedInputs = s.query.numInputs, s.query.namedInputs
+	return nil
+}
+
+func (s *StmtInsertSelect) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return s.query.validate()
+}
+
+// NumInput implements driver.Stmt/NumInput.
+func (s *StmtInsertSelect) NumInput() int {
+	return s.numInputs
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtInsertSelect) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtInsertSelect) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if txFromConn(s.conn) != nil {
+		return nil, errors.New("INSERT ... SELECT is not supported inside a transaction")
+	}
+	if len(args) != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, len(args))
+	}
+	queryReq, err := s.query.queryReq(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fetchPkInfo(ctx); err != nil {
+		return nil, err
+	}
+
+	it := s.conn.restClient.QueryDocumentsIteratorContext(ctx, queryReq)
+	defer func() { _ = it.Close() }()
+	source, done := make(chan BulkItem), make(chan struct{})
+	var readErr error
+	var failedRows []RowError // documents that can not be written, e.g. missing PK value, in the query's order
+	go func() {
+		defer close(done)
+		defer close(source)
+		for row := 1; ; row++ {
+			doc, err := it.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr = normalizeError(it.statusCode, 0, err)
+				return
+			}
+			item, err := s.bulkItem(doc)
+			if err != nil {
+				// the document is skipped, the following ones are still written
+				failedRows = append(failedRows, RowError{Row: row, Err: err})
+				continue
+			}
+			select {
+			case source <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	result := &ResultNoResultSet{}
+	var rowsErr RowsError
+	bulkResult := s.conn.restClient.ExecuteBulkContext(ctx, BulkReq{Source: source, OnResult: func(r BulkItemResult) {
+		if r.Err != nil {
+			// r.Index is the position among the written documents, mapped to the query's result once reading is done
+			rowsErr.Rows = append(rowsErr.Rows, RowError{Row: r.Index, Err: normalizeError(r.StatusCode, 0, r.Err)})
+		} else {
+			result.affectedRows++
+		}
+	}})
+	<-done
+	result.requestCharge = it.RequestCharge() + bulkResult.RequestCharge
+	for i := range rowsErr.Rows {
+		row := rowsErr.Rows[i].Row + 1
+		for _, failed := range failedRows {
+			if failed.Row <= row {
+				row++
+			}
+		}
+		rowsErr.Rows[i].Row = row
+	}
+	rowsErr.Rows = append(rowsErr.Rows, failedRows...)
+	switch {
+	case readErr != nil:
+		result.err = readErr
+	case len(rowsErr.Rows) > 0:
+		sort.Slice(rowsErr.Rows, func(i, j int) bool { return rowsErr.Rows[i].Row < rowsErr.Rows[j].Row })
+		rowsErr.RowsAffected = result.affectedRows
+		result.err = &rowsErr
+	case bulkResult.CallErr != nil:
+		result.err = bulkResult.CallErr
 	}
-	result := buildResultNoResultSet(&restResult.RestResponse, true, rid, 0)
 	return result, result.err
 }
 
+// bulkItem builds the write operation of a document returned by the query.
+func (s *StmtInsertSelect) bulkItem(doc interface{}) (BulkItem, error) {
+	docInfo := QueriedDocs{doc}.AsDocInfoAt(0)
+	if docInfo == nil {
+		return BulkItem{}, fmt.Errorf("expected a document but the query returned %T", doc)
+	}
+	item := BulkItem{DocumentSpec: DocumentSpec{
+		DbName:             s.dbName,
+		CollName:           s.collName,
+		IsUpsert:           s.isUpsert,
+		PartitionKeyValues: make([]interface{}, len(s.pkPaths)),
+		DocumentData:       docInfo.RemoveSystemAttrs(),
+	}}
+	for i, pkPath := range s.pkPaths {
+		var value interface{} = map[string]interface{}(item.DocumentData)
+		for _, name := range strings.Split(pkPath[1:], "/") {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return item, fmt.Errorf("missing value for PK %s", pkPath)
+			}
+			if value, ok = m[name]; !ok {
+				return item, fmt.Errorf("missing value for PK %s", pkPath)
+			}
+		}
+		item.PartitionKeyValues[i] = value
+	}
+	return item, nil
+}
+
 // Query implements driver.Stmt/Query.
 // This function is not implemented, use Exec instead.
-func (s *StmtInsert) Query(_ []driver.Value) (driver.Rows, error) {
+func (s *StmtInsertSelect) Query(_ []driver.Value) (driver.Rows, error) {
 	return nil, ErrQueryNotSupported
 }
 
@@ -402,6 +855,21 @@ func (s *StmtInsert) Query(_ []driver.Value) (driver.Rows, error) {
 //	- <id-value> and <pk-value> must be a placeholder (e.g. :1, @2 or $3), or JSON value.
 //	- Supplying pk-paths and pk-values is highly recommended to save one round-trip to server to fetch the collection's partition key info.
 //	- If collection's PK has more than one path (i.e. sub-partition is used), the partition paths must be specified in the same order as in the collection (.e.g. AND field1=value1 AND field2=value2...).
+//	- (since v1.2.0) Inside a transaction, the deletion is buffered and committed as part of a transactional batch (see Tx).
+//	  If the document does not exist, the transaction fails with ErrNotFound.
+//
+// (since v1.2.0) Set-based syntax, deleting all documents matching a Cosmos DB SQL predicate:
+//
+//	DELETE FROM <db-name>.<collection-name>
+//	WHERE <predicate>
+//	[WITH MAX_ROWS=<n>] [WITH DRY_RUN[=true]]
+//
+//	- The predicate references the document as 'c', e.g. WHERE c.grade < :1 AND IS_DEFINED(c.email).
+//	- Matching documents are looked up with a cross-partition query, then deleted concurrently; a document is deleted
+//	  only if it has not been modified since it was looked up.
+//	- WITH MAX_ROWS: the statement fails with ErrTooManyRows, deleting nothing, if more than <n> documents match.
+//	- WITH DRY_RUN: matching documents are counted (RowsAffected) but not deleted.
+//	- Set-based DELETE is not supported inside a transaction.
 //
 // See StmtInsert for details on <id-value> and <pk-value>.
 type StmtDelete struct {
@@ -409,6 +877,7 @@ type StmtDelete struct {
 	whereStr string
 	id       interface{}
 	pkValues []interface{}
+	setBased // (since v1.2.0)
 }
 
 // String implements interface fmt.Stringer/String.
@@ -419,46 +888,22 @@ func (s *StmtDelete) String() string {
 		s.StmtCRUD, s.whereStr, s.id, s.pkValues)
 }
 
-func (s *StmtDelete) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtDelete) parse(withOpts []withOption, where []condition) error {
+	if err := s.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
-	for k := range s.withOpts {
-		if k != "SINGLE_PK" && k != "SINGLEPK" {
-			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
-		}
-	}
-
-	s.pkPaths = make([]string, 0)
-	s.pkValues = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.whereStr); temp != ""; temp = strings.TrimSpace(temp) {
-		pkPath, pkValue, leftOver, err := _parseFieldValueAnd(temp)
-		if err == nil {
-			if strings.ToLower(pkPath) == "id" {
-				s.id = pkValue
-			} else {
-				s.pkPaths = append(s.pkPaths, "/"+strings.TrimLeft(pkPath, "/"))
-				s.pkValues = append(s.pkValues, pkValue)
-			}
-			temp = leftOver
-			switch v := pkValue.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
-			}
-			continue
-		}
+	if err := s.parseSetBasedOpts(&s.setBased); err != nil {
 		return err
 	}
-	if !s.isSinglePathPk {
-		s.numPkPaths = len(s.pkPaths)
-	}
+
+	s.id, s.pkValues = s.parseIdAndPk(where)
 
 	return nil
 }
 
 func (s *StmtDelete) validate() error {
-	if s.id == nil {
+	if s.id == nil && s.predicate == nil {
 		return errors.New("id value is missing")
 	}
 	if s.dbName == "" || s.collName == "" {
@@ -478,51 +923,90 @@ func (s *StmtDelete) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtDelete) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
-	// TODO: pass ctx to REST API client
+func (s *StmtDelete) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.exec(ctx, args, false)
+}
+
+// NumInput implements driver.Stmt/NumInput.
+//
+// @Available since v1.2.0
+func (s *StmtDelete) NumInput() int {
+	if s.predicate != nil {
+		return s.numInputs
+	}
+	return s.StmtCRUD.NumInput()
+}
 
-	if err := s.fetchPkInfo(); err != nil {
+// exec deletes the document. If fetchDoc is true, the document is fetched beforehand so that it can be returned by the
+// RETURNING clause, and is deleted only if it has not been modified in the meantime.
+//
+// @Available since v1.2.0
+func (s *StmtDelete) exec(ctx context.Context, args []driver.NamedValue, fetchDoc bool) (driver.Result, error) {
+	if s.predicate != nil {
+		return s.execSetBased(ctx, args, s.setBased, fetchDoc, true, func(m matchedDoc) BulkItem {
+			return BulkItem{
+				Op:           BatchOpDelete,
+				DocumentSpec: DocumentSpec{DbName: s.dbName, CollName: s.collName, PartitionKeyValues: m.pkValues, DocumentData: map[string]interface{}{docFieldId: m.id}},
+				MatchEtag:    m.etag,
+			}
+		})
+	}
+	if err := s.fetchPkInfo(ctx); err != nil {
 		return nil, err
 	}
 
+	args, pkArgs, err := s.splitPkArgs(args, "supplying PK value at the end of parameter list is deprecated, please use WHERE pk=value")
+	if err != nil {
+		return nil, err
+	}
 	pkValues := make([]driver.NamedValue, s.numPkPaths)
-	if n := len(args); n == s.numInputs+s.numPkPaths {
-		_, _ = fmt.Fprintf(os.Stderr, "[WARN] supplying PK value at the end of parameter list is deprecated, please use WHERE pk=value\n")
-		copy(pkValues, args[s.numInputs:])
-		args = args[:s.numInputs]
-	} else if n == s.numInputs {
+	if pkArgs != nil {
+		copy(pkValues, pkArgs)
+	} else {
 		for i, pkValue := range s.pkValues {
 			pkValues[i] = driver.NamedValue{Name: s.pkPaths[i][1:], Value: pkValue}
 		}
-	} else {
-		return nil, fmt.Errorf("expected %d or %d input values, got %d", s.numInputs, s.numInputs+s.numPkPaths, n)
 	}
 
-	id := s.id
-	switch v := s.id.(type) {
-	case placeholder:
-		id = args[v.index-1].Value
-	}
-	id, _ = reddo.ToString(id)
+	id, _ := reddo.ToString(placeholderValue(s.id, args))
 
 	docReq := DocReq{
 		DbName:             s.dbName,
 		CollName:           s.collName,
-		DocId:              id.(string),
+		DocId:              id,
 		PartitionKeyValues: make([]any, len(pkValues)),
 	}
 
 	for i, pkValue := range pkValues {
-		switch v := pkValue.Value.(type) {
-		case placeholder:
-			docReq.PartitionKeyValues[i] = args[v.index-1].Value
-		default:
-			docReq.PartitionKeyValues[i] = v
+		docReq.PartitionKeyValues[i] = placeholderValue(pkValue.Value, args)
+	}
+
+	if tx := txFromConn(s.conn); tx != nil {
+		return tx.addOp(s.dbName, s.collName, docReq.PartitionKeyValues, BatchOp{Op: BatchOpDelete, Id: docReq.DocId}, false)
+	}
+	var doc DocInfo
+	var requestCharge float64
+	if fetchDoc {
+		getDocResult := s.conn.restClient.GetDocumentContext(ctx, docReq)
+		if err := getDocResult.Error(); err != nil {
+			result := buildResultNoResultSet(&getDocResult.RestResponse, false, "", 0)
+			result.docId = id
+			switch getDocResult.StatusCode {
+			case 404:
+				// consider "document not found" as successful operation
+				// but database/collection not found is not!
+				if strings.Contains(fmt.Sprintf("%s", err), "ResourceType: Document") {
+					result.err = nil
+				}
+			}
+			return result, result.err
 		}
+		doc, requestCharge = getDocResult.DocInfo, getDocResult.RequestCharge
+		docReq.MatchEtag = doc.Etag()
 	}
-
-	restResult := s.conn.restClient.DeleteDocument(docReq)
-	result := buildResultNoResultSet(&restResult.RestResponse, false, "", 0)
+	restResult := s.conn.restClient.DeleteDocumentContext(ctx, docReq)
+	result := buildResultNoResultSet(&restResult.RestResponse, false, "", 412)
+	result.docId, result.requestCharge = id, result.requestCharge+requestCharge
 	switch restResult.StatusCode {
 	case 404:
 		// consider "document not found" as successful operation
@@ -531,13 +1015,24 @@ func (s *StmtDelete) ExecContext(_ context.Context, args []driver.NamedValue) (d
 			result.err = nil
 		}
 	}
+	if doc != nil && result.affectedRows > 0 {
+		result.documents = []DocInfo{doc}
+	}
 	return result, result.err
 }
 
 // Query implements driver.Stmt/Query.
-// This function is not implemented, use Exec instead.
-func (s *StmtDelete) Query(_ []driver.Value) (driver.Rows, error) {
-	return nil, ErrQueryNotSupported
+// This function is supported only if the statement has a RETURNING clause (since v1.2.0), use Exec otherwise.
+func (s *StmtDelete) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// It deletes the document and returns its last version, projected onto the RETURNING clause.
+//
+// @Available since v1.2.0
+func (s *StmtDelete) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.queryReturning(ctx, func() (driver.Result, error) { return s.exec(ctx, args, true) })
 }
 
 /*----------------------------------------------------------------------*/
@@ -551,6 +1046,8 @@ func (s *StmtDelete) Query(_ []driver.Value) (driver.Rows, error) {
 //	WITH database|db=<db-name>
 //	[WITH collection|table=<collection/table-name>]
 //	[WITH cross_partition|CrossPartition[=true]]
+//	[WITH max_degree_of_parallelism|MaxDegreeOfParallelism=<n>]
+//	[WITH prefetch=<n>]
 //
 //	- (extension) If the collection is partitioned, specify "CROSS PARTITION" to allow execution across multiple partitions.
 //	  This clause is not required if query is to be executed on a single partition.
@@ -559,6 +1056,15 @@ func (s *StmtDelete) Query(_ []driver.Value) (driver.Rows, error) {
 //	- (extension) Use "WITH collection=<coll-name>" (or "WITH table=<coll-name>") to specify the collection/table on which the query is to be executed.
 //	  If not specified, collection/table name is extracted from the "FROM <collection/table-name>" clause.
 //	- (extension) Use placeholder syntax @i, $i or :i (where i denotes the i-th parameter, the first parameter is 1)
+//	- (since v1.2.0) Alternatively, use named placeholders @name and supply the parameters with sql.Named("name", value).
+//	  Positional and named placeholders must not be mixed in the same query.
+//	- (extension, since v1.2.0) Use "WITH max_degree_of_parallelism=<n>" (or "WITH MaxDegreeOfParallelism=<n>") to query up to n partition key ranges
+//	  concurrently (negative value means no limit). If not specified, the value from the DSN is used.
+//	- (extension, since v1.2.0) Use "WITH prefetch=<n>" to fetch the first n rows (default 100) when the query is executed.
+//
+// Since v1.2.0, rows are fetched from server page by page while iterating the result (see QueryDocsIterator). Columns
+// are determined from the rows fetched when the query is executed; fields that appear only in the following rows are
+// not returned.
 type StmtSelect struct {
 	*Stmt
 	isCrossPartition bool
@@ -566,18 +1072,20 @@ type StmtSelect struct {
 	collName         string
 	selectQuery      string
 	placeholders     map[int]string
+	maxDop           int // (since v1.2.0) max number of partition key ranges queried concurrently, 0 means "use the DSN's value"
+	prefetch         int // (since v1.2.0) number of rows fetched when the query is executed, 0 means defaultQueryPageSize
 }
 
 // String implements interface fmt.Stringer/String.
 //
 // @Available since v1.1.0
 func (s *StmtSelect) String() string {
-	return fmt.Sprintf(`StmtSelect{Stmt: %s, cross_partition: %v, db: %q, collection: %q}`,
-		s.Stmt, s.isCrossPartition, s.dbName, s.collName)
+	return fmt.Sprintf(`StmtSelect{Stmt: %s, cross_partition: %v, db: %q, collection: %q, max_dop: %d, prefetch: %d}`,
+		s.Stmt, s.isCrossPartition, s.dbName, s.collName, s.maxDop, s.prefetch)
 }
 
-func (s *StmtSelect) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtSelect) parse(withOpts []withOption) error {
+	if err := s.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
@@ -587,13 +1095,28 @@ func (s *StmtSelect) parse(withOptsStr string) error {
 	if err := s.onlyOneWithOption("collection is specified more than once, only one of COLLECTION or TABLE should be specified", "COLLECTION", "TABLE"); err != nil {
 		return err
 	}
+	if err := s.onlyOneWithOption("max degree of parallelism is specified more than once, only one of MAX_DEGREE_OF_PARALLELISM or MaxDegreeOfParallelism should be specified", "MAX_DEGREE_OF_PARALLELISM", "MAXDEGREEOFPARALLELISM"); err != nil {
+		return err
+	}
 
 	for k, v := range s.withOpts {
 		switch k {
 		case "DATABASE", "DB":
-			s.dbName = v
+			s.dbName = unquoteIdent(v)
 		case "COLLECTION", "TABLE":
-			s.collName = v
+			s.collName = unquoteIdent(v)
+		case "MAX_DEGREE_OF_PARALLELISM", "MAXDEGREEOFPARALLELISM":
+			maxDop, err := strconv.ParseInt(v, 10, 32)
+			if err != nil || maxDop == 0 {
+				return fmt.Errorf("invalid value at WITH %s: %s", k, v)
+			}
+			s.maxDop = int(maxDop)
+		case "PREFETCH":
+			prefetch, err := strconv.ParseInt(v, 10, 32)
+			if err != nil || prefetch <= 0 {
+				return fmt.Errorf("invalid value at WITH %s: %s", k, v)
+			}
+			s.prefetch = int(prefetch)
 		case "CROSS_PARTITION", "CROSSPARTITION":
 			if s.isCrossPartition {
 				return fmt.Errorf("cross-partition is specified more than once, only one of CROSS_PARTITION or CrossPartition should be specified")
@@ -612,19 +1135,6 @@ func (s *StmtSelect) parse(withOptsStr string) error {
 		}
 	}
 
-	matches := reValPlaceholder.FindAllStringSubmatch(s.selectQuery, -1)
-	s.numInputs = len(matches)
-	s.placeholders = make(map[int]string)
-	for _, match := range matches {
-		v, _ := strconv.Atoi(match[1])
-		key := "@_" + match[1]
-		s.placeholders[v] = key
-		if strings.HasSuffix(match[0], " ") {
-			key += " "
-		}
-		s.selectQuery = strings.ReplaceAll(s.selectQuery, match[0], key)
-	}
-
 	return nil
 }
 
@@ -643,33 +1153,51 @@ func (s *StmtSelect) Query(args []driver.Value) (driver.Rows, error) {
 // QueryContext implements driver.StmtQueryContext/QueryContext.
 //
 // @Available since v1.1.1
-func (s *StmtSelect) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
-	// TODO: pass ctx to REST API client
+func (s *StmtSelect) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	query, err := s.queryReq(args)
+	if err != nil {
+		return nil, err
+	}
+
+	it := s.conn.restClient.QueryDocumentsIteratorContext(ctx, query)
+	prefetch := s.prefetch
+	if prefetch <= 0 {
+		prefetch = defaultQueryPageSize
+	}
+	result := newStreamingResultSet(it, prefetch)
+	result.err = normalizeError(result.statusCode, 0, result.err)
+	return result, result.err
+}
 
+// queryReq builds the query request, binding the arguments to the query parameters.
+//
+// @Available since v1.2.0
+func (s *StmtSelect) queryReq(args []driver.NamedValue) (QueryReq, error) {
+	if err := s.checkNamedArgs(args); err != nil {
+		return QueryReq{}, err
+	}
+	if len(s.namedInputs) == 0 && len(args) < s.numInputs {
+		return QueryReq{}, fmt.Errorf("expected %d input values, got %d", s.numInputs, len(args))
+	}
 	params := make([]interface{}, 0)
 	for i, arg := range args {
-		v, ok := s.placeholders[i+1]
-		if !ok {
-			return nil, fmt.Errorf("there is no placeholder #%d", i+1)
+		if arg.Name != "" {
+			params = append(params, map[string]interface{}{"name": "@" + arg.Name, "value": arg.Value})
+			continue
+		}
+		// arguments not referenced by the query are not bound, e.g. the first one of "... WHERE c.a=$2"
+		if v, ok := s.placeholders[i+1]; ok {
+			params = append(params, map[string]interface{}{"name": v, "value": arg.Value})
 		}
-		params = append(params, map[string]interface{}{"name": v, "value": arg.Value})
-	}
-	query := QueryReq{
-		DbName:                s.dbName,
-		CollName:              s.collName,
-		Query:                 s.selectQuery,
-		Params:                params,
-		CrossPartitionEnabled: s.isCrossPartition,
-	}
-
-	restResult := s.conn.restClient.QueryDocumentsCrossPartition(query)
-	result := &ResultResultSet{err: restResult.Error(), columnList: make([]string, 0)}
-	if result.err == nil {
-		result.documents = restResult.Documents
-		result.init()
 	}
-	result.err = normalizeError(restResult.StatusCode, 0, result.err)
-	return result, result.err
+	return QueryReq{
+		DbName:                 s.dbName,
+		CollName:               s.collName,
+		Query:                  s.selectQuery,
+		Params:                 params,
+		CrossPartitionEnabled:  s.isCrossPartition,
+		MaxDegreeOfParallelism: s.maxDop,
+	}, nil
 }
 
 // Exec implements driver.Stmt/Exec.
@@ -694,6 +1222,25 @@ func (s *StmtSelect) Exec(_ []driver.Value) (driver.Result, error) {
 //	- <id-value> and <pk-value> must be a placeholder (e.g. :1, @2 or $3), or JSON value.
 //	- Supplying pk-paths and pk-values is highly recommended to save one round-trip to server to fetch the collection's partition key info.
 //	- If collection's PK has more than one path (i.e. sub-partition is used), the partition paths must be specified in the same order as in the collection (.e.g. AND field1=value1 AND field2=value2...).
+//	- (since v1.2.0) The SET clause is compiled into "set" patch operations: the document is updated atomically server-side in one round-trip (see RestClient.PatchDocument).
+//	  If the SET clause has more than MaxPatchOperations fields, the document is fetched, then replaced.
+//	- (since v1.2.0) Inside a transaction, the update is buffered and committed as part of a transactional batch (see Tx).
+//	  If the document does not exist, the transaction fails with ErrNotFound.
+//
+// (since v1.2.0) Set-based syntax, updating all documents matching a Cosmos DB SQL predicate:
+//
+//	UPDATE <db-name>.<collection-name>
+//	SET <field-name1>=<value1>[,<field-nameN>=<valueN>]*
+//	WHERE <predicate>
+//	[WITH MAX_ROWS=<n>] [WITH DRY_RUN[=true]]
+//
+//	- The predicate references the document as 'c', e.g. WHERE c.grade < :1 AND IS_DEFINED(c.email).
+//	- Matching documents are looked up with a cross-partition query, then patched (or replaced, if the SET clause has
+//	  more than MaxPatchOperations fields) concurrently; a document is updated only if it has not been modified since
+//	  it was looked up.
+//	- WITH MAX_ROWS: the statement fails with ErrTooManyRows, updating nothing, if more than <n> documents match.
+//	- WITH DRY_RUN: matching documents are counted (RowsAffected) but not updated.
+//	- Set-based UPDATE is not supported inside a transaction.
 //
 // See StmtInsert for details on <id-value> and <pk-value>.
 type StmtUpdate struct {
@@ -704,6 +1251,7 @@ type StmtUpdate struct {
 	fields    []string
 	values    []interface{}
 	pkValues  []interface{}
+	setBased  // (since v1.2.0)
 }
 
 // String implements interface fmt.Stringer/String.
@@ -714,83 +1262,25 @@ func (s *StmtUpdate) String() string {
 		s.StmtCRUD, s.fields, s.values, s.whereStr, s.id, s.pkValues)
 }
 
-var (
-	reFieldPart = regexp.MustCompile(`\s*([\w\-]+)\s*=`)
-)
-
-func (s *StmtUpdate) _parseUpdateClause() error {
-	s.fields = make([]string, 0)
-	s.values = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.updateStr); temp != ""; temp = strings.TrimSpace(temp) {
-		// firstly, extract the field name
-		if loc := reFieldPart.FindStringIndex(temp); loc != nil && loc[0] == 0 {
-			field := strings.TrimSpace(temp[loc[0] : loc[1]-1])
-			s.fields = append(s.fields, field)
-			temp = strings.TrimSpace(temp[loc[1]:])
-		} else {
-			return errors.New("(field) cannot parse query, invalid token at: " + temp)
-		}
-
-		// secondly, parse the value part
-		value, leftOver, err := _parseValue(temp, ',')
-		if err == nil {
-			s.values = append(s.values, value)
-			temp = leftOver
-			switch v := value.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
-			}
-			continue
-		}
-		return err
-	}
-	return nil
-}
-
-func (s *StmtUpdate) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtUpdate) parse(withOpts []withOption, where []condition) error {
+	if err := s.parseWithOpts(withOpts); err != nil {
 		return err
 	}
 
-	for k := range s.withOpts {
-		if k != "SINGLE_PK" && k != "SINGLEPK" {
-			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
-		}
-	}
-
-	if err := s._parseUpdateClause(); err != nil {
+	if err := s.parseSetBasedOpts(&s.setBased); err != nil {
 		return err
 	}
 
-	s.pkPaths = make([]string, 0)
-	s.pkValues = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.whereStr); temp != ""; temp = strings.TrimSpace(temp) {
-		pkPath, pkValue, leftOver, err := _parseFieldValueAnd(temp)
-		if err == nil {
-			if strings.ToLower(pkPath) == "id" {
-				s.id = pkValue
-			} else {
-				s.pkPaths = append(s.pkPaths, "/"+strings.TrimLeft(pkPath, "/"))
-				s.pkValues = append(s.pkValues, pkValue)
-			}
-			temp = leftOver
-			switch v := pkValue.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
-			}
-			continue
-		}
-		return err
-	}
-	if !s.isSinglePathPk {
-		s.numPkPaths = len(s.pkPaths)
+	for _, value := range s.values {
+		s.addInput(value)
 	}
+	s.id, s.pkValues = s.parseIdAndPk(where)
 
 	return nil
 }
 
 func (s *StmtUpdate) validate() error {
-	if s.id == nil {
+	if s.id == nil && s.predicate == nil {
 		return errors.New("id value is missing")
 	}
 	if s.dbName == "" || s.collName == "" {
@@ -813,51 +1303,47 @@ func (s *StmtUpdate) Exec(args []driver.Value) (driver.Result, error) {
 // ExecContext implements driver.StmtExecContext/ExecContext.
 //
 // @Available since v1.1.1
-func (s *StmtUpdate) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
-	// TODO: pass ctx to REST API client
-
-	if err := s.fetchPkInfo(); err != nil {
+func (s *StmtUpdate) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if s.predicate != nil {
+		return s.execSetBased(ctx, args)
+	}
+	if err := s.fetchPkInfo(ctx); err != nil {
 		return nil, err
 	}
 
+	args, pkArgs, err := s.splitPkArgs(args, "suplying PK value at the end of parameter list is deprecated, please use WHERE pk=value")
+	if err != nil {
+		return nil, err
+	}
 	pkValues := make([]driver.NamedValue, s.numPkPaths)
-	if n := len(args); n == s.numInputs+s.numPkPaths {
-		_, _ = fmt.Fprintf(os.Stderr, "[WARN] suplying PK value at the end of parameter list is deprecated, please use WHERE pk=value\n")
-		copy(pkValues, args[s.numInputs:])
-		args = args[:s.numInputs]
-	} else if n == s.numInputs {
+	if pkArgs != nil {
+		copy(pkValues, pkArgs)
+	} else {
 		for i, pkValue := range s.pkValues {
 			pkValues[i] = driver.NamedValue{Name: s.pkPaths[i][1:], Value: pkValue}
 		}
-	} else {
-		return nil, fmt.Errorf("expected %d or %d input values, got %d", s.numInputs, s.numInputs+s.numPkPaths, n)
 	}
 	pkValuesForApiCall := make([]any, len(pkValues))
 	for i, pkValue := range pkValues {
-		switch v := pkValue.Value.(type) {
-		case placeholder:
-			pkValuesForApiCall[i] = args[v.index-1].Value
-		default:
-			pkValuesForApiCall[i] = v
-		}
+		pkValuesForApiCall[i] = placeholderValue(pkValue.Value, args)
 	}
 
-	// firstly, fetch the document
-	id := s.id
-	switch v := s.id.(type) {
-	case placeholder:
-		id = args[v.index-1].Value
+	id, _ := reddo.ToString(placeholderValue(s.id, args))
+	if len(s.fields) <= MaxPatchOperations {
+		return s.execPatch(ctx, args, id, pkValuesForApiCall)
 	}
-	id, _ = reddo.ToString(id)
+
+	// firstly, fetch the document
 	docReq := DocReq{
 		DbName:             s.dbName,
 		CollName:           s.collName,
-		DocId:              id.(string),
+		DocId:              id,
 		PartitionKeyValues: pkValuesForApiCall,
 	}
-	getDocResult := s.conn.restClient.GetDocument(docReq)
+	getDocResult := s.conn.restClient.GetDocumentContext(ctx, docReq)
 	if err := getDocResult.Error(); err != nil {
 		result := buildResultNoResultSet(&getDocResult.RestResponse, false, "", 0)
+		result.docId = id
 		switch getDocResult.StatusCode {
 		case 404:
 			// consider "document not found" as successful operation
@@ -878,15 +1364,17 @@ func (s *StmtUpdate) ExecContext(_ context.Context, args []driver.NamedValue) (d
 		DocumentData:       getDocResult.DocInfo.RemoveSystemAttrs(),
 	}
 	for i, field := range s.fields {
-		switch v := s.values[i].(type) {
-		case placeholder:
-			spec.DocumentData[field] = args[v.index-1].Value
-		default:
-			spec.DocumentData[field] = s.values[i]
-		}
+		spec.DocumentData[field] = placeholderValue(s.values[i], args)
 	}
-	replaceDocResult := s.conn.restClient.ReplaceDocument(etag, spec)
+	if tx := txFromConn(s.conn); tx != nil {
+		return tx.addOp(s.dbName, s.collName, spec.PartitionKeyValues, BatchOp{Op: BatchOpReplace, Document: spec.DocumentData, MatchEtag: etag}, false)
+	}
+	replaceDocResult := s.conn.restClient.ReplaceDocumentContext(ctx, etag, spec)
 	result := buildResultNoResultSet(&replaceDocResult.RestResponse, false, "", 412)
+	result.docId, result.requestCharge = id, result.requestCharge+getDocResult.RequestCharge
+	if result.err == nil && result.affectedRows > 0 {
+		result.documents = []DocInfo{replaceDocResult.DocInfo}
+	}
 	switch replaceDocResult.StatusCode {
 	case 404: // rare case, but possible!
 		// consider "document not found" as successful operation
@@ -898,8 +1386,92 @@ func (s *StmtUpdate) ExecContext(_ context.Context, args []driver.NamedValue) (d
 	return result, result.err
 }
 
+// NumInput implements driver.Stmt/NumInput.
+//
+// @Available since v1.2.0
+func (s *StmtUpdate) NumInput() int {
+	if s.predicate != nil {
+		return s.numInputs
+	}
+	return s.StmtCRUD.NumInput()
+}
+
+// execSetBased updates the documents matching the predicate of a set-based UPDATE.
+//
+// @Available since v1.2.0
+func (s *StmtUpdate) execSetBased(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	replace := len(s.fields) > MaxPatchOperations
+	fullDocs := replace || (s.dryRun && s.returning != nil)
+	return s.StmtCRUD.execSetBased(ctx, args, s.setBased, fullDocs, s.dryRun, func(m matchedDoc) BulkItem {
+		item := BulkItem{
+			Op:           BatchOpPatch,
+			DocumentSpec: DocumentSpec{DbName: s.dbName, CollName: s.collName, PartitionKeyValues: m.pkValues, DocumentData: map[string]interface{}{docFieldId: m.id}},
+			MatchEtag:    m.etag,
+			Operations:   s.patchOps(args),
+		}
+		if replace {
+			item.Op, item.Operations, item.DocumentData = BatchOpReplace, nil, m.doc.RemoveSystemAttrs()
+			for i, field := range s.fields {
+				item.DocumentData[field] = placeholderValue(s.values[i], args)
+			}
+		}
+		return item
+	})
+}
+
+// patchOps compiles the SET clause into patch operations.
+//
+// @Available since v1.2.0
+func (s *StmtUpdate) patchOps(args []driver.NamedValue) []PatchOp {
+	ops := make([]PatchOp, len(s.fields))
+	for i, field := range s.fields {
+		// field names are escaped as JSON pointer tokens, see RFC 6901
+		ops[i] = PatchOp{Op: PatchOpSet, Path: "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(field), Value: placeholderValue(s.values[i], args)}
+	}
+	return ops
+}
+
+// execPatch updates the document with a single patch request.
+//
+// @Available since v1.2.0
+func (s *StmtUpdate) execPatch(ctx context.Context, args []driver.NamedValue, id string, pkValues []interface{}) (driver.Result, error) {
+	patchReq := PatchDocReq{
+		DbName:             s.dbName,
+		CollName:           s.collName,
+		DocId:              id,
+		PartitionKeyValues: pkValues,
+		Operations:         s.patchOps(args),
+	}
+	if tx := txFromConn(s.conn); tx != nil {
+		return tx.addOp(s.dbName, s.collName, pkValues, BatchOp{Op: BatchOpPatch, Id: id, Operations: patchReq.Operations}, false)
+	}
+	patchResult := s.conn.restClient.PatchDocumentContext(ctx, patchReq)
+	result := buildResultNoResultSet(&patchResult.RestResponse, false, "", 0)
+	result.docId = id
+	if result.err == nil {
+		result.documents = []DocInfo{patchResult.DocInfo}
+	}
+	switch patchResult.StatusCode {
+	case 404:
+		// consider "document not found" as successful operation
+		// but database/collection not found is not!
+		if strings.Contains(fmt.Sprintf("%s", patchResult.Error()), "ResourceType: Document") {
+			result.err = nil
+		}
+	}
+	return result, result.err
+}
+
 // Query implements driver.Stmt/Query.
-// This function is not implemented, use Exec instead.
-func (s *StmtUpdate) Query(_ []driver.Value) (driver.Rows, error) {
-	return nil, ErrQueryNotSupported
+// This function is supported only if the statement has a RETURNING clause (since v1.2.0), use Exec otherwise.
+func (s *StmtUpdate) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// It updates the document and returns its new version, projected onto the RETURNING clause.
+//
+// @Available since v1.2.0
+func (s *StmtUpdate) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.queryReturning(ctx, func() (driver.Result, error) { return s.ExecContext(ctx, args) })
 }
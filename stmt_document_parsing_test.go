@@ -101,6 +101,16 @@ $1, :3, @2)`,
 			sql:       `INSERT INTO db.table (a,b,c) VALUES (:1,$2,3) WITH Pk=/mypk WITH SINGLE_PK`,
 			mustError: true,
 		},
+		{
+			name:     "multi_rows",
+			sql:      `INSERT INTO db.table (a,b) VALUES (:1,2), ($2,"\"x\""), (null,$3) WITH pk=/a`,
+			expected: &StmtInsert{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 3}, dbName: "db", collName: "table", numPkPaths: 1, withPk: "/a", pkPaths: []string{"/a"}}, fields: []string{"a", "b"}, values: []interface{}{placeholder{1}, 2.0}, extraRows: [][]interface{}{{placeholder{2}, "x"}, {nil, placeholder{3}}}},
+		},
+		{
+			name:      "multi_rows_num_values_not_match",
+			sql:       `INSERT INTO db.table (a,b) VALUES (1,2), (3)`,
+			mustError: true,
+		},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -665,6 +675,10 @@ func TestStmtSelect_parse(t *testing.T) {
 		{name: "error_cross_partition_more_than_once2", sql: `SELECT CROSS PARTITION * FROM c WITH db=dbname WITH collection=collname WITH CrossPartition`, mustError: true},
 		{name: "error_invalid_with", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH a`, mustError: true},
 		{name: "error_invalid_with2", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH a=1`, mustError: true},
+		{name: "error_max_dop_invalid", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH max_degree_of_parallelism=a`, mustError: true},
+		{name: "error_max_dop_zero", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH MaxDegreeOfParallelism=0`, mustError: true},
+		{name: "error_max_dop_more_than_once", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH max_degree_of_parallelism=2 WITH MaxDegreeOfParallelism=4`, mustError: true},
+		{name: "error_prefetch_zero", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH prefetch=0`, mustError: true},
 
 		{
 			name:     "basic",
@@ -686,6 +700,21 @@ func TestStmtSelect_parse(t *testing.T) {
 			sql:      `SELECT a,b,c FROM user u WHERE u.id="1" WITH db=dbtemp WITH CrossPartition`,
 			expected: &StmtSelect{dbName: "dbtemp", collName: "user", isCrossPartition: true, selectQuery: `SELECT a,b,c FROM user u WHERE u.id="1"`, placeholders: map[int]string{}},
 		},
+		{
+			name:     "max_degree_of_parallelism",
+			sql:      `SELECT * FROM c WITH db=db WITH table=tbl WITH cross_partition WITH max_degree_of_parallelism=8`,
+			expected: &StmtSelect{dbName: "db", collName: "tbl", isCrossPartition: true, selectQuery: `SELECT * FROM c`, placeholders: map[int]string{}, maxDop: 8},
+		},
+		{
+			name:     "max_degree_of_parallelism_unlimited",
+			sql:      `SELECT * FROM c WITH db=db WITH table=tbl WITH MaxDegreeOfParallelism=-1`,
+			expected: &StmtSelect{dbName: "db", collName: "tbl", selectQuery: `SELECT * FROM c`, placeholders: map[int]string{}, maxDop: -1},
+		},
+		{
+			name:     "prefetch",
+			sql:      `SELECT * FROM c WITH db=db WITH table=tbl WITH prefetch=500`,
+			expected: &StmtSelect{dbName: "db", collName: "tbl", selectQuery: `SELECT * FROM c`, placeholders: map[int]string{}, prefetch: 500},
+		},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -778,7 +807,7 @@ func TestStmtUpdate_parse(t *testing.T) {
 		mustError bool
 	}{
 		{name: "error_no_collection", sql: `UPDATE db SET a=1,b=2,c=3 WHERE id=4`, mustError: true},
-		{name: "error_where", sql: `UPDATE db.table SET a=1,b=2,c=3 WHERE username=4`, mustError: true},
+		{name: "error_where", sql: `UPDATE db.table SET a=1,b=2,c=3 WHERE username=4 id=5`, mustError: true},
 		{name: "error_no_where", sql: `UPDATE db.table SET a=1,b=2,c=3`, mustError: true},
 		{name: "error_no_set", sql: `UPDATE db.table WHERE id=1`, mustError: true},
 		{name: "error_empty_set", sql: `UPDATE db.table SET      WHERE id=1`, mustError: true},
@@ -0,0 +1,193 @@
+package gocosmos
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StmtCreateFunction implements "CREATE FUNCTION" statement, which creates a user-defined function (UDF).
+//
+// Syntax:
+//
+//	CREATE [OR REPLACE] FUNCTION [<db-name>.]<collection-name>.<function-name> AS '<javascript-function>'
+//
+// - The body of the function is enclosed in single quotes; single quotes inside the body are escaped by doubling them.
+//
+// - If "OR REPLACE" is specified, an existing function with the same name is replaced. Otherwise, Exec returns ErrConflict.
+//
+// - The function can then be used in queries on the collection as "udf.<function-name>(...)".
+//
+// @Available since v1.2.0
+type StmtCreateFunction struct {
+	*Stmt
+	dbName    string
+	collName  string
+	funcName  string
+	orReplace bool
+	body      string
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtCreateFunction) String() string {
+	return fmt.Sprintf(`StmtCreateFunction{Stmt: %s, db: %q, collection: %q, function: %q, or_replace: %t}`,
+		s.Stmt, s.dbName, s.collName, s.funcName, s.orReplace)
+}
+
+func (s *StmtCreateFunction) validate() error {
+	if s.dbName == "" || s.collName == "" || s.funcName == "" {
+		return errors.New("database/collection/function is missing")
+	}
+	if strings.TrimSpace(s.body) == "" {
+		return errors.New("function body is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtCreateFunction) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtCreateFunction) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtCreateFunction) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	spec := UserDefinedFunctionSpec{DbName: s.dbName, CollName: s.collName, Id: s.funcName, Body: s.body}
+	restResult := s.conn.restClient.CreateUserDefinedFunctionContext(ctx, spec)
+	if restResult.StatusCode == 409 && s.orReplace {
+		replaceResult := s.conn.restClient.ReplaceUserDefinedFunctionContext(ctx, spec)
+		result := buildResultNoResultSet(&replaceResult.RestResponse, true, replaceResult.Rid, 0)
+		return result, result.err
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtDropFunction implements "DROP FUNCTION" statement, which deletes a user-defined function (UDF).
+//
+// Syntax:
+//
+//	DROP FUNCTION [IF EXISTS] [<db-name>.]<collection-name>.<function-name>
+//
+// If "IF EXISTS" is specified, Exec will silently swallow the error "404 Not Found".
+//
+// @Available since v1.2.0
+type StmtDropFunction struct {
+	*Stmt
+	dbName   string
+	collName string
+	funcName string
+	ifExists bool
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtDropFunction) String() string {
+	return fmt.Sprintf(`StmtDropFunction{Stmt: %s, db: %q, collection: %q, function: %q, if_exists: %t}`,
+		s.Stmt, s.dbName, s.collName, s.funcName, s.ifExists)
+}
+
+func (s *StmtDropFunction) validate() error {
+	if s.dbName == "" || s.collName == "" || s.funcName == "" {
+		return errors.New("database/collection/function is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtDropFunction) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtDropFunction) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtDropFunction) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	restResult := s.conn.restClient.DeleteUserDefinedFunctionContext(ctx, s.dbName, s.collName, s.funcName)
+	ignoreErrorCode := 0
+	if s.ifExists {
+		ignoreErrorCode = 404
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, false, "", ignoreErrorCode)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtListFunctions implements "LIST FUNCTIONS" statement, which lists the user-defined functions (UDFs) of a collection.
+//
+// Syntax:
+//
+//	LIST FUNCTIONS|FUNCTION FROM [<db-name>.]<collection-name>
+//
+// @Available since v1.2.0
+type StmtListFunctions struct {
+	*Stmt
+	dbName   string
+	collName string
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtListFunctions) String() string {
+	return fmt.Sprintf(`StmtListFunctions{Stmt: %s, db: %q, collection: %q}`, s.Stmt, s.dbName, s.collName)
+}
+
+func (s *StmtListFunctions) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtListFunctions) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtListFunctions) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+func (s *StmtListFunctions) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	restResult := s.conn.restClient.ListUserDefinedFunctionsContext(ctx, s.dbName, s.collName)
+	result := &ResultResultSet{
+		err:        restResult.Error(),
+		columnList: []string{"id", "body", "_rid", "_ts", "_self", "_etag"},
+	}
+	if result.err == nil {
+		result.count = len(restResult.UserDefinedFunctions)
+		result.rows = make([]DocInfo, result.count)
+		for i, udf := range restResult.UserDefinedFunctions {
+			result.rows[i] = udf.toMap()
+		}
+	}
+	result.err = normalizeError(restResult.StatusCode, 0, result.err)
+	return result, result.err
+}
@@ -0,0 +1,131 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStmtCreateFunction_parse(t *testing.T) {
+	testName := "TestStmtCreateFunction_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtCreateFunction
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: "CREATE FUNCTION udf AS 'function () {}'", mustError: true},
+		{name: "error_no_default_db", sql: "CREATE FUNCTION table.udf AS 'function () {}'", mustError: true},
+		{name: "error_no_body", sql: "CREATE FUNCTION db.table.udf AS ''", mustError: true},
+		{name: "error_unquoted_body", sql: "CREATE FUNCTION db.table.udf AS function () {}", mustError: true},
+		{name: "error_unescaped_quote", sql: "CREATE FUNCTION db.table.udf AS 'function () { return 'a'; }'", mustError: true},
+
+		{name: "basic", sql: "CREATE FUNCTION db1.table1.udf1 AS 'function () {}'", expected: &StmtCreateFunction{dbName: "db1", collName: "table1", funcName: "udf1", body: "function () {}"}},
+		{name: "or_replace", sql: "create\nor\treplace function db-2.table_2.udf-2\r\nas\n'function () {\n  return ''a'';\n}'", expected: &StmtCreateFunction{dbName: "db-2", collName: "table_2", funcName: "udf-2", orReplace: true, body: "function () {\n  return 'a';\n}"}},
+		{name: "default_db", db: "mydb", sql: "CREATE FUNCTION table.udf AS 'function () {}'", expected: &StmtCreateFunction{dbName: "mydb", collName: "table", funcName: "udf", body: "function () {}"}},
+		{name: "db_in_query", db: "mydb", sql: "CREATE FUNCTION db.table.udf AS 'function () {}'", expected: &StmtCreateFunction{dbName: "db", collName: "table", funcName: "udf", body: "function () {}"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtCreateFunction)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtCreateFunction but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtDropFunction_parse(t *testing.T) {
+	testName := "TestStmtDropFunction_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtDropFunction
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: "DROP FUNCTION udf", mustError: true},
+		{name: "error_no_default_db", sql: "DROP FUNCTION table.udf", mustError: true},
+		{name: "error_if_exist", sql: "DROP FUNCTION IF EXIST db.table.udf", mustError: true},
+
+		{name: "basic", sql: "DROP FUNCTION db1.table1.udf1", expected: &StmtDropFunction{dbName: "db1", collName: "table1", funcName: "udf1"}},
+		{name: "if_exists", sql: "drop\nfunction\tif\r\nexists db-2.table_2.udf-2", expected: &StmtDropFunction{dbName: "db-2", collName: "table_2", funcName: "udf-2", ifExists: true}},
+		{name: "default_db", db: "mydb", sql: "DROP FUNCTION IF EXISTS table.udf", expected: &StmtDropFunction{dbName: "mydb", collName: "table", funcName: "udf", ifExists: true}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtDropFunction)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtDropFunction but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtListFunctions_parse(t *testing.T) {
+	testName := "TestStmtListFunctions_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtListFunctions
+		mustError bool
+	}{
+		{name: "error_no_collection", db: "mydb", sql: "LIST FUNCTIONS", mustError: true},
+		{name: "error_no_default_db", sql: "LIST FUNCTIONS FROM table", mustError: true},
+
+		{name: "basic", sql: "LIST FUNCTIONS FROM db1.table1", expected: &StmtListFunctions{dbName: "db1", collName: "table1"}},
+		{name: "function", sql: "list\nfunction\r\nfrom\tdb-2.table_2", expected: &StmtListFunctions{dbName: "db-2", collName: "table_2"}},
+		{name: "default_db", db: "mydb", sql: "LIST FUNCTIONS FROM table", expected: &StmtListFunctions{dbName: "mydb", collName: "table"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtListFunctions)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtListFunctions but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
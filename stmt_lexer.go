@@ -0,0 +1,321 @@
+package gocosmos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError is returned when a statement cannot be parsed. Line and Column (both starting from 1) locate the
+// offending token in the statement.
+//
+// @Available since v1.2.0
+type SyntaxError struct {
+	Line   int
+	Column int
+	Msg    string // e.g. `expected "(" but found "VALUES"`
+}
+
+// Error implements error/Error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF         tokenKind = iota
+	tokIdent                 // identifier, keyword or unsigned integer, e.g. mydb, table-1, SELECT, 100
+	tokQuotedIdent           // (since v1.2.0) identifier enclosed in backticks or brackets, e.g. `my db` or [my.table]
+	tokString                // double-quoted string, e.g. "\"a string\""; single-quoted ones are also accepted in SELECT
+	tokScript                // single-quoted script body, single quotes inside are doubled, e.g. 'function () { return ''a''; }'
+	tokPlaceholder           // positional placeholder, e.g. :1, @2 or $3, or (since v1.2.0) named placeholder, e.g. @name
+	tokComment               // "-- ..." or "/* ... */", only returned by nextSelectToken
+	tokOther                 // punctuation and operators, one character each
+)
+
+type token struct {
+	kind  tokenKind
+	text  string // the token as written in the query
+	start int    // byte offset of the token's first character
+	end   int    // byte offset following the token's last character
+}
+
+// describe returns the token, as displayed in error messages.
+func (t token) describe() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	text := t.text
+	if len(text) > 32 {
+		text = text[:29] + "..."
+	}
+	return fmt.Sprintf("%q", text)
+}
+
+// lexer splits a statement into tokens. Tokens are produced on demand, as the same characters are read differently
+// depending on the context, e.g. "/a,/b" is a single value of a WITH option but several tokens elsewhere.
+type lexer struct {
+	input string
+	pos   int // byte offset of the next character to read
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// errorAt builds a SyntaxError located at the byte offset.
+func (l *lexer) errorAt(offset int, format string, args ...interface{}) *SyntaxError {
+	line, column := 1, 1
+	for _, r := range l.input[:offset] {
+		if r == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return &SyntaxError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(l.input[l.pos:], prefix)
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+}
+
+// scanComment reads a comment starting at the current position, if any.
+func (l *lexer) scanComment() (token, bool, error) {
+	start := l.pos
+	switch {
+	case l.hasPrefix("--"):
+		if n := strings.IndexByte(l.input[l.pos:], '\n'); n >= 0 {
+			l.pos += n
+		} else {
+			l.pos = len(l.input)
+		}
+	case l.hasPrefix("/*"):
+		n := strings.Index(l.input[l.pos+2:], "*/")
+		if n < 0 {
+			return token{}, false, l.errorAt(start, "unterminated comment")
+		}
+		l.pos += n + 4
+	default:
+		return token{}, false, nil
+	}
+	return token{kind: tokComment, text: l.input[start:l.pos], start: start, end: l.pos}, true, nil
+}
+
+// skipSpaceAndComments skips whitespaces and comments preceding the next token.
+func (l *lexer) skipSpaceAndComments() error {
+	for {
+		l.skipSpace()
+		_, found, err := l.scanComment()
+		if err != nil || !found {
+			return err
+		}
+	}
+}
+
+// scanWhile reads the longest run of characters satisfying the predicate.
+func (l *lexer) scanWhile(pred func(c byte) bool) string {
+	start := l.pos
+	for l.pos < len(l.input) && pred(l.input[l.pos]) {
+		l.pos++
+	}
+	return l.input[start:l.pos]
+}
+
+// scanWord reads an identifier: letters, digits, underscores and hyphens (e.g. db-2 or table_3-0), "--" excepted as it
+// starts a comment.
+func (l *lexer) scanWord() string {
+	start := l.pos
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		if !isWordChar(c) && (c != '-' || l.hasPrefix("--")) {
+			break
+		}
+		l.pos++
+	}
+	return l.input[start:l.pos]
+}
+
+// scanQuoted reads a string enclosed in quote. If doubling is true, a quote inside the string is escaped by doubling
+// it, otherwise by a backslash.
+func (l *lexer) scanQuoted(quote byte, doubling bool) (string, error) {
+	start := l.pos
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		switch c := l.input[l.pos]; {
+		case c == '\\' && !doubling:
+			l.pos++
+		case c == quote && doubling && l.pos+1 < len(l.input) && l.input[l.pos+1] == quote:
+			l.pos++
+		case c == quote:
+			l.pos++
+			return l.input[start:l.pos], nil
+		}
+	}
+	return "", l.errorAt(start, "unterminated string")
+}
+
+// scanBracketed reads an identifier enclosed in brackets, where a closing bracket inside the identifier is doubled.
+func (l *lexer) scanBracketed() (string, error) {
+	start := l.pos
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		if l.input[l.pos] == ']' {
+			if l.pos+1 < len(l.input) && l.input[l.pos+1] == ']' {
+				l.pos++
+				continue
+			}
+			l.pos++
+			return l.input[start:l.pos], nil
+		}
+	}
+	return "", l.errorAt(start, "unterminated identifier")
+}
+
+// scanPlaceholder reads a placeholder starting at the current position, if any: either a positional one (e.g. :1, @2
+// or $3) or a named one (e.g. @name).
+func (l *lexer) scanPlaceholder() (string, bool) {
+	if l.pos+1 >= len(l.input) || strings.IndexByte(":@$", l.input[l.pos]) < 0 {
+		return "", false
+	}
+	start, c := l.pos, l.input[l.pos+1]
+	switch {
+	case isDigit(c):
+		l.pos++
+		l.scanWhile(isDigit)
+	case l.input[l.pos] == '@' && isWordChar(c):
+		l.pos++
+		l.scanWhile(isWordChar)
+	default:
+		return "", false
+	}
+	return l.input[start:l.pos], true
+}
+
+// next reads the next token of a statement of the driver's dialect, skipping whitespaces and comments.
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+	tok := token{start: l.pos}
+	var err error
+	switch {
+	case l.pos >= len(l.input):
+		tok.kind = tokEOF
+	case isWordChar(l.input[l.pos]):
+		tok.kind, tok.text = tokIdent, l.scanWord()
+	case l.input[l.pos] == '"':
+		tok.kind = tokString
+		tok.text, err = l.scanQuoted('"', false)
+	case l.input[l.pos] == '\'':
+		tok.kind = tokScript
+		tok.text, err = l.scanQuoted('\'', true)
+	case l.input[l.pos] == '`':
+		tok.kind = tokQuotedIdent
+		tok.text, err = l.scanQuoted('`', true)
+	case l.input[l.pos] == '[':
+		tok.kind = tokQuotedIdent
+		tok.text, err = l.scanBracketed()
+	default:
+		var found bool
+		if tok.text, found = l.scanPlaceholder(); found {
+			tok.kind = tokPlaceholder
+		} else {
+			_, size := utf8.DecodeRuneInString(l.input[l.pos:])
+			tok.kind, tok.text = tokOther, l.input[l.pos:l.pos+size]
+			l.pos += size
+		}
+	}
+	tok.end = l.pos
+	return tok, err
+}
+
+// nextSelectToken reads the next token of a SELECT query, which follows Cosmos DB's SQL grammar: strings are enclosed
+// in either single or double quotes, and comments are returned as tokens so that they can be stripped from the query.
+func (l *lexer) nextSelectToken() (token, error) {
+	l.skipSpace()
+	if tok, found, err := l.scanComment(); found || err != nil {
+		return tok, err
+	}
+	if l.pos < len(l.input) && l.input[l.pos] == '\'' {
+		text, err := l.scanQuoted('\'', false)
+		return token{kind: tokString, text: text, start: l.pos - len(text), end: l.pos}, err
+	}
+	if l.pos < len(l.input) && l.input[l.pos] == '[' {
+		// brackets are array accessors, not quoted identifiers
+		l.pos++
+		return token{kind: tokOther, text: "[", start: l.pos - 1, end: l.pos}, nil
+	}
+	return l.next()
+}
+
+func isLiteralChar(c byte) bool {
+	return isWordChar(c) || strings.IndexByte("./;:\\+-", c) >= 0
+}
+
+// scanLiteral reads an unquoted literal value, i.e. a number, null, a boolean or an unquoted string.
+func (l *lexer) scanLiteral() string {
+	return l.scanWhile(isLiteralChar)
+}
+
+func isOptionValueChar(c byte) bool {
+	return isWordChar(c) || strings.IndexByte("/.*,;:'-$@", c) >= 0
+}
+
+// scanOptionValue reads the value of a WITH option, e.g. the "/a,/b" of "WITH pk=/a,/b". Strings enclosed in double
+// quotes, and identifiers enclosed in backticks or brackets, are read as a whole.
+func (l *lexer) scanOptionValue() (string, error) {
+	start := l.pos
+	for l.pos < len(l.input) && !l.hasPrefix("--") && !l.hasPrefix("/*") {
+		var err error
+		switch c := l.input[l.pos]; {
+		case c == '"':
+			_, err = l.scanQuoted('"', false)
+		case c == '`':
+			_, err = l.scanQuoted('`', true)
+		case c == '[':
+			_, err = l.scanBracketed()
+		case isOptionValueChar(c):
+			l.pos++
+		default:
+			return l.input[start:l.pos], nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return l.input[start:l.pos], nil
+}
+
+// unquoteIdent returns the name enclosed in backticks, brackets or double quotes, or the name as-is if it is not
+// enclosed in any of them.
+func unquoteIdent(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	switch first, last := s[0], s[len(s)-1]; {
+	case first == '`' && last == '`':
+		return strings.ReplaceAll(s[1:len(s)-1], "``", "`")
+	case first == '[' && last == ']':
+		return strings.ReplaceAll(s[1:len(s)-1], "]]", "]")
+	case first == '"' && last == '"':
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	}
+	return s
+}
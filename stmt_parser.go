@@ -0,0 +1,968 @@
+package gocosmos
+
+import (
+	"encoding/json"
+	"github.com/btnguyen2k/consu/g18"
+	"strconv"
+	"strings"
+)
+
+// withOption is an option of the WITH clause, e.g. "WITH pk=/id" or "WITH singlePK".
+type withOption struct {
+	name  string // upper-cased
+	value string // empty if the option has no value
+	start int    // byte offset of the value in the query
+}
+
+// condition is a "<field>=<value>" condition of a WHERE clause.
+type condition struct {
+	field string
+	value interface{}
+}
+
+// objectName is a (possibly partially) qualified name, e.g. "mydb.mytable" or "mytable.myproc".
+type objectName struct {
+	db, coll, name string
+}
+
+// astStatement is the root of the abstract syntax tree of a statement, one node type per supported statement.
+type astStatement interface {
+	statement()
+}
+
+type astNode struct{}
+
+func (astNode) statement() {}
+
+type (
+	astCreateDatabase struct {
+		astNode
+		ifNotExists bool
+		dbName      string
+		withOpts    []withOption
+	}
+	astAlterDatabase struct {
+		astNode
+		dbName   string
+		withOpts []withOption
+	}
+	astDropDatabase struct {
+		astNode
+		ifExists bool
+		dbName   string
+	}
+	astListDatabases struct {
+		astNode
+	}
+
+	astCreateCollection struct {
+		astNode
+		ifNotExists bool
+		objectName
+		withOpts []withOption
+	}
+	astAlterCollection struct {
+		astNode
+		objectName
+		withOpts []withOption
+	}
+	astDropCollection struct {
+		astNode
+		ifExists bool
+		objectName
+	}
+	astListCollections struct {
+		astNode
+		dbName string
+	}
+
+	astInsert struct {
+		astNode
+		isUpsert bool
+		objectName
+		fields    []string
+		values    []interface{}
+		extraRows [][]interface{} // (since v1.2.0) values of the rows following the first one, multi-row VALUES list only
+		fieldsStr string
+		valuesStr string
+		returning []string // (since v1.2.0) fields of the RETURNING clause, nil if there is no RETURNING clause
+		withOpts  []withOption
+	}
+	// astInsertSelect is an "INSERT|UPSERT INTO ... SELECT ..." statement.
+	astInsertSelect struct {
+		astNode
+		isUpsert bool
+		objectName
+		query *astSelect
+	}
+	astSelect struct {
+		astNode
+		isCrossPartition bool
+		collName         string
+		selectQuery      string // the query sent to the server: "CROSS PARTITION" and WITH clause removed, placeholders renamed
+		placeholders     map[int]string
+		namedParams      []string // (since v1.2.0) distinct names of the named placeholders, in order of appearance
+		numInputs        int
+		withOpts         []withOption
+	}
+	astUpdate struct {
+		astNode
+		objectName
+		fields    []string
+		values    []interface{}
+		where     []condition
+		predicate *astPredicate // (since v1.2.0) WHERE clause of a set-based UPDATE, nil if where is used
+		updateStr string
+		whereStr  string
+		returning []string
+		withOpts  []withOption
+	}
+	astDelete struct {
+		astNode
+		objectName
+		where     []condition
+		predicate *astPredicate // (since v1.2.0) WHERE clause of a set-based DELETE, nil if where is used
+		whereStr  string
+		returning []string
+		withOpts  []withOption
+	}
+	// astPredicate is a WHERE clause written in Cosmos DB SQL, e.g. "c.grade > :1", passed through to the server.
+	astPredicate struct {
+		text   string        // the predicate sent to the server: comments removed, positional placeholders renamed
+		params []interface{} // distinct placeholders of the predicate, in order of appearance
+	}
+
+	// astCreateScript is a "CREATE [OR REPLACE] PROCEDURE|TRIGGER|FUNCTION" statement.
+	astCreateScript struct {
+		astNode
+		kind      string // PROCEDURE, TRIGGER or FUNCTION
+		orReplace bool
+		objectName
+		triggerType string // PRE or POST, triggers only
+		operation   string // ALL, CREATE, REPLACE, DELETE or empty, triggers only
+		body        string
+	}
+	// astDropScript is a "DROP PROCEDURE|TRIGGER|FUNCTION" statement.
+	astDropScript struct {
+		astNode
+		kind     string
+		ifExists bool
+		objectName
+	}
+	// astListScripts is a "LIST PROCEDURES|TRIGGERS|FUNCTIONS" statement.
+	astListScripts struct {
+		astNode
+		kind string
+		objectName
+	}
+	astExecProcedure struct {
+		astNode
+		objectName
+		args     []interface{}
+		argsStr  string
+		pkValues []interface{}
+		withOpts []withOption
+	}
+)
+
+/*----------------------------------------------------------------------*/
+
+// parser is a recursive-descent parser of the driver's SQL dialect. Syntax errors are raised as panics of type
+// *SyntaxError, and recovered by parseQuery.
+type parser struct {
+	lex        *lexer
+	tok        token // lookahead token
+	prevEnd    int   // byte offset following the last consumed token
+	positional bool  // true if a positional placeholder has been read
+	named      bool  // true if a named placeholder has been read
+}
+
+// parseQuery parses a statement and returns its abstract syntax tree.
+func parseQuery(query string) (node astStatement, err error) {
+	p := &parser{lex: &lexer{input: query}}
+	defer func() {
+		if r := recover(); r != nil {
+			syntaxErr, ok := r.(*SyntaxError)
+			if !ok {
+				panic(r)
+			}
+			node, err = nil, syntaxErr
+		}
+	}()
+	p.advance()
+	return p.parseStatement(), nil
+}
+
+func (p *parser) advance() {
+	tok, err := p.lex.next()
+	if err != nil {
+		panic(err)
+	}
+	p.prevEnd, p.tok = p.tok.end, tok
+}
+
+// rewind moves the lexer back to the start of the lookahead token, so that it can be read differently.
+func (p *parser) rewind() int {
+	p.lex.pos = p.tok.start
+	return p.tok.start
+}
+
+func (p *parser) errorf(format string, args ...interface{}) {
+	panic(p.lex.errorAt(p.tok.start, format, args...))
+}
+
+// unexpected raises a syntax error "expected <alternatives> but found <lookahead token>".
+func (p *parser) unexpected(alternatives ...string) {
+	expected := alternatives[len(alternatives)-1]
+	if n := len(alternatives); n > 1 {
+		expected = strings.Join(alternatives[:n-1], ", ") + " or " + expected
+	}
+	p.errorf("expected %s but found %s", expected, p.tok.describe())
+}
+
+func (p *parser) isKeyword(keywords ...string) bool {
+	if p.tok.kind != tokIdent {
+		return false
+	}
+	for _, kw := range keywords {
+		if strings.EqualFold(p.tok.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptKeyword consumes the lookahead token if it is one of the keywords.
+func (p *parser) acceptKeyword(keywords ...string) bool {
+	if p.isKeyword(keywords...) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+// expectKeyword consumes the lookahead token, which must be one of the keywords, and returns it upper-cased.
+func (p *parser) expectKeyword(keywords ...string) string {
+	if !p.isKeyword(keywords...) {
+		p.unexpected(keywords...)
+	}
+	kw := strings.ToUpper(p.tok.text)
+	p.advance()
+	return kw
+}
+
+func (p *parser) isPunct(punct string) bool {
+	return p.tok.kind == tokOther && p.tok.text == punct
+}
+
+func (p *parser) expectPunct(punct string) {
+	if !p.isPunct(punct) {
+		p.unexpected(strconv.Quote(punct))
+	}
+	p.advance()
+}
+
+// expectIdent reads a name, which may be enclosed in backticks, brackets or double quotes, e.g. `my db`, [my.table] or
+// "my field".
+func (p *parser) expectIdent(what string) string {
+	if p.tok.kind != tokIdent && p.tok.kind != tokQuotedIdent && p.tok.kind != tokString {
+		p.unexpected(what)
+	}
+	ident := unquoteIdent(p.tok.text)
+	if ident == "" {
+		p.errorf("%s must not be empty", what)
+	}
+	p.advance()
+	return ident
+}
+
+// expectEnd checks that the whole query has been consumed; alternatives lists what else could follow.
+func (p *parser) expectEnd(alternatives ...string) {
+	if p.tok.kind != tokEOF {
+		p.unexpected(append(alternatives, "end of query")...)
+	}
+}
+
+// textSince returns the query's text from offset start to the last consumed token.
+func (p *parser) textSince(start int) string {
+	if p.prevEnd < start {
+		return ""
+	}
+	return p.lex.input[start:p.prevEnd]
+}
+
+/*----------------------------------------------------------------------*/
+
+func (p *parser) parseStatement() astStatement {
+	switch {
+	case p.acceptKeyword("CREATE"):
+		return p.parseCreate()
+	case p.acceptKeyword("ALTER"):
+		return p.parseAlter()
+	case p.acceptKeyword("DROP"):
+		return p.parseDrop()
+	case p.acceptKeyword("LIST"):
+		return p.parseList()
+	case p.isKeyword("INSERT", "UPSERT"):
+		return p.parseInsert()
+	case p.isKeyword("SELECT"):
+		return p.parseSelect()
+	case p.acceptKeyword("UPDATE"):
+		return p.parseUpdate()
+	case p.acceptKeyword("DELETE"):
+		return p.parseDelete()
+	case p.acceptKeyword("EXEC", "EXECUTE"):
+		return p.parseExec()
+	}
+	p.unexpected("CREATE", "ALTER", "DROP", "LIST", "INSERT", "UPSERT", "SELECT", "UPDATE", "DELETE", "EXEC")
+	return nil
+}
+
+// parseIfExists parses an optional "IF EXISTS" (or "IF NOT EXISTS" if not is true) clause.
+func (p *parser) parseIfExists(not bool) bool {
+	if !p.acceptKeyword("IF") {
+		return false
+	}
+	if not {
+		p.expectKeyword("NOT")
+	}
+	p.expectKeyword("EXISTS")
+	return true
+}
+
+// parseName parses a dot-separated name of minParts to maxParts identifiers.
+func (p *parser) parseName(minParts, maxParts int) []string {
+	parts := []string{p.expectIdent("name")}
+	for len(parts) < maxParts && p.isPunct(".") {
+		p.advance()
+		parts = append(parts, p.expectIdent("name"))
+	}
+	if len(parts) < minParts {
+		p.unexpected(`"."`)
+	}
+	return parts
+}
+
+// parseCollName parses "[<db-name>.]<collection-name>".
+func (p *parser) parseCollName() objectName {
+	if parts := p.parseName(1, 2); len(parts) == 2 {
+		return objectName{db: parts[0], coll: parts[1]}
+	} else {
+		return objectName{coll: parts[0]}
+	}
+}
+
+// parseScriptName parses "[<db-name>.]<collection-name>.<name>".
+func (p *parser) parseScriptName() objectName {
+	if parts := p.parseName(2, 3); len(parts) == 3 {
+		return objectName{db: parts[0], coll: parts[1], name: parts[2]}
+	} else {
+		return objectName{coll: parts[0], name: parts[1]}
+	}
+}
+
+// parseWithOpts parses the "WITH <name>[=<value>]" options, which may be separated by commas.
+func (p *parser) parseWithOpts() []withOption {
+	opts := make([]withOption, 0)
+	for p.acceptKeyword("WITH") {
+		opt := withOption{name: strings.ToUpper(p.expectIdent("option name"))}
+		if p.isPunct("=") {
+			p.lex.pos = p.tok.end
+			p.lex.skipSpace()
+			opt.start = p.lex.pos
+			value, err := p.lex.scanOptionValue()
+			if err != nil {
+				panic(err)
+			}
+			if opt.value = strings.TrimSuffix(value, ","); opt.value == "" {
+				p.advance()
+				p.unexpected("value of option " + opt.name)
+			}
+			p.tok.end = p.lex.pos
+			p.advance()
+		}
+		opts = append(opts, opt)
+		if p.isPunct(",") {
+			p.advance()
+		}
+	}
+	return opts
+}
+
+// placeholder returns the placeholder of the lookahead token: a placeholder for a positional one (e.g. :1, @2 or $3),
+// or a namedPlaceholder for a named one (e.g. @name). Positional and named placeholders must not be mixed in a
+// statement.
+func (p *parser) placeholder() interface{} {
+	if !isDigit(p.tok.text[1]) {
+		if p.positional {
+			p.errorf("positional and named placeholders must not be mixed, found %s", p.tok.describe())
+		}
+		p.named = true
+		return namedPlaceholder{p.tok.text[1:]}
+	}
+	index, err := strconv.Atoi(p.tok.text[1:])
+	if err != nil || index < 1 {
+		p.errorf("invalid placeholder %s", p.tok.describe())
+	}
+	if p.named {
+		p.errorf("positional and named placeholders must not be mixed, found %s", p.tok.describe())
+	}
+	p.positional = true
+	return placeholder{index}
+}
+
+// parseValue parses a value: a placeholder, null, a number, a boolean, a double-quoted JSON value or an unquoted
+// string.
+func (p *parser) parseValue() interface{} {
+	switch p.tok.kind {
+	case tokPlaceholder:
+		value := p.placeholder()
+		p.advance()
+		return value
+	case tokString:
+		var data interface{}
+		token, err := strconv.Unquote(p.tok.text)
+		if err != nil {
+			p.errorf("invalid string %s", p.tok.describe())
+		}
+		if err = json.Unmarshal([]byte(token), &data); err != nil {
+			p.errorf("invalid JSON value in string %s: %s", p.tok.describe(), err)
+		}
+		p.advance()
+		return data
+	}
+
+	start := p.rewind()
+	literal := p.lex.scanLiteral()
+	// the literal replaces the lookahead token, so that textSince covers the whole literal
+	p.tok.end = p.lex.pos
+	if literal == "" {
+		p.advance()
+		p.unexpected("value")
+	}
+	var data interface{}
+	switch strings.ToLower(literal) {
+	case "null":
+	case "true":
+		data = true
+	case "false":
+		data = false
+	default:
+		if c := literal[0]; isDigit(c) || c == '-' || c == '+' || c == '.' {
+			if err := json.Unmarshal([]byte(literal), &data); err != nil {
+				panic(p.lex.errorAt(start, "invalid number %q", literal))
+			}
+		} else {
+			data = literal
+		}
+	}
+	p.advance()
+	return data
+}
+
+// parseValueList parses a comma-separated list of values, which may be empty if followed by a closing parenthesis.
+func (p *parser) parseValueList() []interface{} {
+	values := make([]interface{}, 0)
+	if p.isPunct(")") {
+		return values
+	}
+	for {
+		values = append(values, p.parseValue())
+		if !p.isPunct(",") {
+			return values
+		}
+		p.advance()
+	}
+}
+
+// parseConditions parses "<field>=<value> [AND <field>=<value>...]".
+func (p *parser) parseConditions() []condition {
+	conditions := make([]condition, 0)
+	for {
+		cond := condition{field: p.expectIdent("field name")}
+		p.expectPunct("=")
+		cond.value = p.parseValue()
+		conditions = append(conditions, cond)
+		if !p.acceptKeyword("AND") {
+			return conditions
+		}
+	}
+}
+
+// parseWhere parses the WHERE clause of UPDATE and DELETE statements. A clause consisting only of the conditions
+// "id=<value> [AND <pk-field>=<value>...]" targets a single document; (since v1.2.0) any other clause is a Cosmos DB
+// SQL predicate, referencing the document as "c" (e.g. "c.grade > :1" or "id=:1 OR c.grade > 1").
+func (p *parser) parseWhere() ([]condition, *astPredicate) {
+	if conditions := p.tryParseConditions(); conditions != nil {
+		return conditions, nil
+	}
+	return nil, p.parsePredicate()
+}
+
+// tryParseConditions parses the WHERE clause as conditions "id=<value> [AND <pk-field>=<value>...]" if the whole
+// clause has this form, i.e. is followed by RETURNING, WITH or the end of the query. Otherwise, it returns nil and the
+// parser is left unchanged.
+func (p *parser) tryParseConditions() (conditions []condition) {
+	lex := *p.lex
+	sub := *p
+	sub.lex = &lex
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*SyntaxError); !ok {
+				panic(r)
+			}
+			conditions = nil
+		}
+	}()
+	conditions = sub.parseConditions()
+	if sub.tok.kind != tokEOF && !sub.isKeyword("RETURNING", "WITH") {
+		return nil
+	}
+	numIds := 0
+	for _, cond := range conditions {
+		if strings.EqualFold(cond.field, "id") {
+			numIds++
+		}
+	}
+	if numIds != 1 {
+		return nil
+	}
+	*p = sub
+	return conditions
+}
+
+// parsePredicate parses a Cosmos DB SQL predicate, up to the end of the query or the first RETURNING or WITH outside
+// parentheses. Like in SELECT queries, positional placeholders are renamed to Cosmos DB parameters (e.g. :1 to @_1).
+// The predicate is passed through to the server; only obvious mistakes outside parentheses are reported, i.e. two
+// operands not separated by an operator (e.g. "id=1 pk=2") and a missing operand at the end (e.g. "id=").
+func (p *parser) parsePredicate() *astPredicate {
+	node := &astPredicate{}
+	var text strings.Builder
+	var prev, last token // last is the last token outside parentheses, comments excluded
+	p.rewind()
+	for depth, first := 0, true; ; first = false {
+		tok, err := p.lex.nextSelectToken()
+		if err != nil {
+			panic(err)
+		}
+		if tok.kind == tokEOF || (depth == 0 && tok.kind == tokIdent && (strings.EqualFold(tok.text, "RETURNING") || strings.EqualFold(tok.text, "WITH"))) {
+			if first {
+				p.tok = tok
+				p.unexpected("predicate")
+			}
+			if !isPredicateOperandEnd(last) {
+				p.tok = tok
+				p.unexpected("value")
+			}
+			// the rest of the statement is read by the regular lexer
+			p.lex.pos, p.tok = tok.start, prev
+			p.advance()
+			break
+		}
+		if !first {
+			text.WriteString(p.lex.input[prev.end:tok.start])
+		}
+		if tok.kind == tokOther && strings.Contains(")]}", tok.text) {
+			depth--
+		}
+		if depth == 0 && tok.kind != tokComment {
+			if isPredicateOperandEnd(last) && isPredicateOperandStart(tok) {
+				p.tok = tok
+				p.unexpected("operator")
+			}
+			last = tok
+		}
+		if tok.kind == tokOther && strings.Contains("([{", tok.text) {
+			depth++
+		}
+		switch {
+		case tok.kind == tokComment:
+			// stripped
+		case isQueryPlaceholder(tok, prev):
+			p.tok = tok
+			value := p.placeholder()
+			node.addParam(value)
+			if v, ok := value.(placeholder); ok {
+				text.WriteString("@_" + strconv.Itoa(v.index))
+			} else {
+				text.WriteString(tok.text)
+			}
+		default:
+			text.WriteString(tok.text)
+		}
+		prev = tok
+	}
+	node.text = strings.TrimSpace(text.String())
+	return node
+}
+
+// predicateKeywords are the keywords that combine operands of a predicate.
+var predicateKeywords = map[string]bool{"AND": true, "OR": true, "NOT": true, "IN": true, "BETWEEN": true, "LIKE": true, "ESCAPE": true}
+
+// isPredicateOperandStart reports whether tok starts an operand of a predicate, e.g. a property name or a value.
+func isPredicateOperandStart(tok token) bool {
+	switch tok.kind {
+	case tokIdent:
+		return !predicateKeywords[strings.ToUpper(tok.text)]
+	case tokQuotedIdent, tokString, tokPlaceholder:
+		return true
+	}
+	return false
+}
+
+// isPredicateOperandEnd reports whether tok ends an operand of a predicate, e.g. a value or a closing parenthesis.
+func isPredicateOperandEnd(tok token) bool {
+	return isPredicateOperandStart(tok) || (tok.kind == tokOther && strings.Contains(")]}", tok.text))
+}
+
+// addParam records a placeholder of the predicate, unless already recorded.
+func (node *astPredicate) addParam(value interface{}) {
+	for _, param := range node.params {
+		if param == value {
+			return
+		}
+	}
+	node.params = append(node.params, value)
+}
+
+// isQueryPlaceholder reports whether tok, following prev in a Cosmos DB SQL query, is a placeholder: ":n" right after
+// a name or a value is part of an object literal or a conditional expression, e.g. {"a":1}.
+func isQueryPlaceholder(tok, prev token) bool {
+	return tok.kind == tokPlaceholder && (tok.text[0] != ':' || prev.end < tok.start ||
+		(prev.kind != tokIdent && prev.kind != tokString && !strings.Contains(")]}", prev.text)))
+}
+
+// parseReturningAndWithOpts parses the optional RETURNING clause and WITH options ending INSERT, UPDATE and DELETE
+// statements, in either order; alternatives lists what else could follow the statement.
+func (p *parser) parseReturningAndWithOpts(alternatives ...string) ([]string, []withOption) {
+	returning := p.parseReturning()
+	withOpts := p.parseWithOpts()
+	if returning == nil {
+		returning = p.parseReturning()
+	}
+	if returning == nil {
+		alternatives = append(alternatives, "RETURNING")
+	} else {
+		alternatives = nil
+	}
+	p.expectEnd(append(alternatives, "WITH")...)
+	return returning, withOpts
+}
+
+// parseReturning parses an optional "RETURNING *|<field>[,<field>...]" clause, "*" standing for all fields.
+func (p *parser) parseReturning() []string {
+	if !p.acceptKeyword("RETURNING") {
+		return nil
+	}
+	if p.isPunct("*") {
+		p.advance()
+		return []string{"*"}
+	}
+	fields := []string{p.expectIdent("field name")}
+	for p.isPunct(",") {
+		p.advance()
+		fields = append(fields, p.expectIdent("field name"))
+	}
+	return fields
+}
+
+/*----------------------------------------------------------------------*/
+
+func (p *parser) parseCreate() astStatement {
+	orReplace := false
+	if p.acceptKeyword("OR") {
+		p.expectKeyword("REPLACE")
+		orReplace = true
+	}
+	var kind string
+	if orReplace {
+		kind = p.expectKeyword("PROCEDURE", "TRIGGER", "FUNCTION")
+	} else {
+		kind = p.expectKeyword("DATABASE", "COLLECTION", "TABLE", "PROCEDURE", "TRIGGER", "FUNCTION")
+	}
+	switch kind {
+	case "DATABASE":
+		node := &astCreateDatabase{ifNotExists: p.parseIfExists(true), dbName: p.expectIdent("database name")}
+		node.withOpts = p.parseWithOpts()
+		p.expectEnd("WITH")
+		return node
+	case "COLLECTION", "TABLE":
+		node := &astCreateCollection{ifNotExists: p.parseIfExists(true), objectName: p.parseCollName()}
+		node.withOpts = p.parseWithOpts()
+		p.expectEnd("WITH")
+		return node
+	}
+
+	node := &astCreateScript{kind: kind, orReplace: orReplace, objectName: p.parseScriptName()}
+	if kind == "TRIGGER" {
+		node.triggerType = p.expectKeyword("PRE", "POST")
+		if p.acceptKeyword("ON") {
+			node.operation = p.expectKeyword("ALL", "CREATE", "REPLACE", "DELETE")
+		}
+	}
+	p.expectKeyword("AS")
+	if p.tok.kind != tokScript {
+		p.unexpected("script body enclosed in single quotes")
+	}
+	node.body = strings.ReplaceAll(p.tok.text[1:len(p.tok.text)-1], "''", "'")
+	p.advance()
+	p.expectEnd()
+	return node
+}
+
+func (p *parser) parseAlter() astStatement {
+	var node astStatement
+	var withOpts *[]withOption
+	if p.expectKeyword("DATABASE", "COLLECTION", "TABLE") == "DATABASE" {
+		n := &astAlterDatabase{dbName: p.expectIdent("database name")}
+		node, withOpts = n, &n.withOpts
+	} else {
+		n := &astAlterCollection{objectName: p.parseCollName()}
+		node, withOpts = n, &n.withOpts
+	}
+	*withOpts = p.parseWithOpts()
+	p.expectEnd("WITH")
+	return node
+}
+
+func (p *parser) parseDrop() astStatement {
+	var node astStatement
+	switch kind := p.expectKeyword("DATABASE", "COLLECTION", "TABLE", "PROCEDURE", "TRIGGER", "FUNCTION"); kind {
+	case "DATABASE":
+		node = &astDropDatabase{ifExists: p.parseIfExists(false), dbName: p.expectIdent("database name")}
+	case "COLLECTION", "TABLE":
+		node = &astDropCollection{ifExists: p.parseIfExists(false), objectName: p.parseCollName()}
+	default:
+		node = &astDropScript{kind: kind, ifExists: p.parseIfExists(false), objectName: p.parseScriptName()}
+	}
+	p.expectEnd()
+	return node
+}
+
+func (p *parser) parseList() astStatement {
+	var node astStatement
+	switch kind := p.expectKeyword("DATABASES", "DATABASE", "COLLECTIONS", "COLLECTION", "TABLES", "TABLE",
+		"PROCEDURES", "PROCEDURE", "TRIGGERS", "TRIGGER", "FUNCTIONS", "FUNCTION"); strings.TrimSuffix(kind, "S") {
+	case "DATABASE":
+		node = &astListDatabases{}
+	case "COLLECTION", "TABLE":
+		n := &astListCollections{}
+		if p.acceptKeyword("FROM") {
+			n.dbName = p.expectIdent("database name")
+		}
+		node = n
+	default:
+		n := &astListScripts{kind: strings.TrimSuffix(kind, "S")}
+		if p.acceptKeyword("FROM") {
+			n.objectName = p.parseCollName()
+		}
+		node = n
+	}
+	p.expectEnd()
+	return node
+}
+
+func (p *parser) parseInsert() astStatement {
+	node := &astInsert{isUpsert: p.expectKeyword("INSERT", "UPSERT") == "UPSERT"}
+	p.expectKeyword("INTO")
+	node.objectName = p.parseCollName()
+	if p.isKeyword("SELECT") {
+		return &astInsertSelect{isUpsert: node.isUpsert, objectName: node.objectName, query: p.parseSelect().(*astSelect)}
+	}
+	if !p.isPunct("(") {
+		p.unexpected(`"("`, "SELECT")
+	}
+	p.advance()
+	start := p.tok.start
+	node.fields = []string{p.expectIdent("field name")}
+	for p.isPunct(",") {
+		p.advance()
+		node.fields = append(node.fields, p.expectIdent("field name"))
+	}
+	node.fieldsStr = p.textSince(start)
+	p.expectPunct(")")
+	p.expectKeyword("VALUES")
+	p.expectPunct("(")
+	start = p.tok.start
+	node.values = p.parseValueList()
+	node.valuesStr = p.textSince(start)
+	p.expectPunct(")")
+	for p.isPunct(",") {
+		p.advance()
+		p.expectPunct("(")
+		node.extraRows = append(node.extraRows, p.parseValueList())
+		p.expectPunct(")")
+	}
+	node.returning, node.withOpts = p.parseReturningAndWithOpts(`","`)
+	return node
+}
+
+func (p *parser) parseUpdate() astStatement {
+	node := &astUpdate{objectName: p.parseCollName()}
+	p.expectKeyword("SET")
+	start := p.tok.start
+	node.fields, node.values = make([]string, 0), make([]interface{}, 0)
+	for {
+		node.fields = append(node.fields, p.expectIdent("field name"))
+		p.expectPunct("=")
+		node.values = append(node.values, p.parseValue())
+		if !p.isPunct(",") {
+			break
+		}
+		p.advance()
+	}
+	node.updateStr = p.textSince(start)
+	p.expectKeyword("WHERE")
+	start = p.tok.start
+	node.where, node.predicate = p.parseWhere()
+	node.whereStr = p.textSince(start)
+	node.returning, node.withOpts = p.parseReturningAndWithOpts("AND")
+	return node
+}
+
+func (p *parser) parseDelete() astStatement {
+	p.expectKeyword("FROM")
+	node := &astDelete{objectName: p.parseCollName()}
+	p.expectKeyword("WHERE")
+	start := p.tok.start
+	node.where, node.predicate = p.parseWhere()
+	node.whereStr = p.textSince(start)
+	node.returning, node.withOpts = p.parseReturningAndWithOpts("AND")
+	return node
+}
+
+func (p *parser) parseExec() astStatement {
+	node := &astExecProcedure{objectName: p.parseScriptName()}
+	p.expectPunct("(")
+	start := p.tok.start
+	node.args = p.parseValueList()
+	node.argsStr = p.textSince(start)
+	p.expectPunct(")")
+	node.withOpts = p.parseWithOpts()
+	p.expectEnd("WITH")
+
+	// the value of "WITH PK" is a list of values, parsed in place so that errors are located in the query
+	for _, opt := range node.withOpts {
+		if opt.name == "PK" {
+			sub := &parser{lex: &lexer{input: p.lex.input[:opt.start+len(opt.value)], pos: opt.start}, positional: p.positional, named: p.named}
+			sub.advance()
+			node.pkValues = sub.parseValueList()
+			sub.expectEnd(`","`)
+		}
+	}
+	return node
+}
+
+// parseSelect parses a SELECT query. The query follows Cosmos DB's SQL grammar and is passed through to the server
+// mostly as-is; only the extensions of the driver are parsed: "CROSS PARTITION", placeholders, the collection name from
+// the FROM clause and the trailing WITH options. Named placeholders (e.g. @name) are Cosmos DB's own query parameters,
+// they are kept as-is.
+func (p *parser) parseSelect() astStatement {
+	node := &astSelect{placeholders: make(map[int]string)}
+	var tokens []token
+	for tok := p.tok; ; {
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			break
+		}
+		var err error
+		if p.lex.skipSpace(); tok.kind == tokIdent && strings.EqualFold(tok.text, "FROM") && p.lex.hasPrefix("[") {
+			// the collection name may be a quoted identifier, e.g. FROM [my coll]
+			tok, err = p.lex.next()
+		} else {
+			tok, err = p.lex.nextSelectToken()
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// locate the end of the query, i.e. the first WITH outside parentheses, and the FROM clause; a keyword following a
+	// "." is a property name, e.g. c.with
+	end, fromIndex, depth := len(tokens)-1, -1, 0
+	for i, tok := range tokens {
+		isKeyword := tok.kind == tokIdent && depth == 0 && (i == 0 || tokens[i-1].kind != tokOther || tokens[i-1].text != ".")
+		switch {
+		case tok.kind == tokOther && strings.Contains("([{", tok.text):
+			depth++
+		case tok.kind == tokOther && strings.Contains(")]}", tok.text):
+			depth--
+		case isKeyword && fromIndex < 0 && strings.EqualFold(tok.text, "FROM"):
+			fromIndex = i
+		case isKeyword && strings.EqualFold(tok.text, "WITH"):
+			end = i
+		}
+		if end < len(tokens)-1 {
+			break
+		}
+	}
+	if fromIndex < 0 || fromIndex > end {
+		p.tok = tokens[end]
+		p.unexpected("FROM")
+	}
+	next := tokens[fromIndex+1]
+	isSubquery := next.kind == tokOther && next.text == "(" && fromIndex+1 < end
+	if !isSubquery && ((next.kind != tokIdent && next.kind != tokQuotedIdent && next.kind != tokString) || fromIndex+1 >= end) {
+		p.tok = next
+		p.unexpected("collection name")
+	} else if !isSubquery {
+		node.collName = unquoteIdent(next.text)
+	}
+
+	skip := 0 // number of tokens following SELECT to be removed from the query
+	if end > 2 && strings.EqualFold(tokens[1].text, "CROSS") && strings.EqualFold(tokens[2].text, "PARTITION") {
+		node.isCrossPartition, skip = true, 2
+	}
+	var query strings.Builder
+	query.WriteString(tokens[0].text)
+	for i := 1 + skip; i < end; i++ {
+		tok, prev := tokens[i], tokens[i-1]
+		if i == 1+skip {
+			// keep the whitespaces following SELECT, drop the ones following "CROSS PARTITION"
+			query.WriteString(p.lex.input[tokens[0].end:tokens[1].start])
+		} else {
+			query.WriteString(p.lex.input[prev.end:tok.start])
+		}
+		switch {
+		case tok.kind == tokComment:
+			// stripped
+		case i == fromIndex+1 && tok.kind != tokIdent && !isSubquery:
+			// the server ignores the collection name of the FROM clause, a quoted one is not valid Cosmos DB SQL though
+			query.WriteString("root")
+		case isQueryPlaceholder(tok, prev):
+			p.tok = tok
+			switch v := p.placeholder().(type) {
+			case placeholder:
+				node.placeholders[v.index] = "@_" + tok.text[1:]
+				node.numInputs = g18.Max(node.numInputs, v.index)
+				query.WriteString(node.placeholders[v.index])
+			case namedPlaceholder:
+				if g18.FindInSlice(v.name, node.namedParams) < 0 {
+					node.namedParams = append(node.namedParams, v.name)
+					node.numInputs = len(node.namedParams)
+				}
+				query.WriteString(tok.text)
+			}
+		default:
+			query.WriteString(tok.text)
+		}
+	}
+	node.selectQuery = strings.TrimSpace(query.String())
+
+	p.lex.pos = tokens[end].start
+	p.advance()
+	node.withOpts = p.parseWithOpts()
+	p.expectEnd("WITH")
+	if isSubquery {
+		// the collection of a subquery, e.g. FROM (SELECT ... FROM c) AS x, must be supplied with WITH collection=...
+		hasCollection := false
+		for _, opt := range node.withOpts {
+			hasCollection = hasCollection || opt.name == "COLLECTION" || opt.name == "TABLE"
+		}
+		if !hasCollection {
+			p.tok = next
+			p.unexpected("collection name")
+		}
+	}
+	return node
+}
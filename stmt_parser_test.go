@@ -0,0 +1,497 @@
+package gocosmos
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery_syntaxError(t *testing.T) {
+	testName := "TestParseQuery_syntaxError"
+	testData := []struct {
+		name     string
+		sql      string
+		expected SyntaxError
+	}{
+		{name: "unknown_statement", sql: "SELEC * FROM c", expected: SyntaxError{Line: 1, Column: 1, Msg: `expected CREATE, ALTER, DROP, LIST, INSERT, UPSERT, SELECT, UPDATE, DELETE or EXEC but found "SELEC"`}},
+		{name: "missing_name", sql: "CREATE DATABASE ", expected: SyntaxError{Line: 1, Column: 17, Msg: "expected database name but found end of query"}},
+		{name: "if_not_exist", sql: "CREATE TABLE IF NOT EXIST db.table WITH pk=/id", expected: SyntaxError{Line: 1, Column: 21, Msg: `expected EXISTS but found "EXIST"`}},
+		{name: "missing_comma", sql: "INSERT INTO db.table (a, b)\n  VALUES (1 2)", expected: SyntaxError{Line: 2, Column: 13, Msg: `expected ")" but found "2"`}},
+		{name: "single_quoted_value", sql: "INSERT INTO db.table (a) VALUES ('a')", expected: SyntaxError{Line: 1, Column: 34, Msg: `expected value but found "'a'"`}},
+		{name: "invalid_number", sql: "INSERT INTO db.table (a) VALUES (0x1qa)", expected: SyntaxError{Line: 1, Column: 34, Msg: `invalid number "0x1qa"`}},
+		{name: "trailing_token", sql: "DELETE FROM db.table\nWHERE id=1 pk=2", expected: SyntaxError{Line: 2, Column: 12, Msg: `expected operator but found "pk"`}},
+		{name: "predicate_missing_value", sql: "DELETE FROM db.table WHERE IS_DEFINED(c.a) AND c.b >", expected: SyntaxError{Line: 1, Column: 53, Msg: "expected value but found end of query"}},
+		{name: "missing_option_value", sql: "CREATE TABLE db.table WITH pk=", expected: SyntaxError{Line: 1, Column: 31, Msg: "expected value of option PK but found end of query"}},
+		{name: "invalid_pk_value", sql: "EXEC db.table.sproc() WITH pk=a,0x1", expected: SyntaxError{Line: 1, Column: 33, Msg: `invalid number "0x1"`}},
+		{name: "unterminated_string", sql: `UPDATE db.table SET a="\"abc WHERE id=1`, expected: SyntaxError{Line: 1, Column: 23, Msg: "unterminated string"}},
+		{name: "unterminated_comment", sql: "DROP DATABASE db /* comment", expected: SyntaxError{Line: 1, Column: 18, Msg: "unterminated comment"}},
+		{name: "unescaped_quote", sql: "CREATE FUNCTION db.table.udf AS 'function () { return 'a'; }'", expected: SyntaxError{Line: 1, Column: 56, Msg: `expected end of query but found "a"`}},
+		{name: "select_no_from", sql: "SELECT * WITH db=mydb", expected: SyntaxError{Line: 1, Column: 10, Msg: `expected FROM but found "WITH"`}},
+		{name: "unterminated_identifier", sql: "DROP TABLE [db.table", expected: SyntaxError{Line: 1, Column: 12, Msg: "unterminated identifier"}},
+		{name: "empty_identifier", sql: "CREATE DATABASE ``", expected: SyntaxError{Line: 1, Column: 17, Msg: "database name must not be empty"}},
+		{name: "select_no_collection", sql: "SELECT * FROM WITH db=mydb", expected: SyntaxError{Line: 1, Column: 15, Msg: `expected collection name but found "WITH"`}},
+		{name: "multi_rows_missing_parenthesis", sql: "INSERT INTO db.table (a) VALUES (1), 2", expected: SyntaxError{Line: 1, Column: 38, Msg: `expected "(" but found "2"`}},
+		{name: "multi_rows_trailing_token", sql: "INSERT INTO db.table (a) VALUES (1) (2)", expected: SyntaxError{Line: 1, Column: 37, Msg: `expected ",", RETURNING, WITH or end of query but found "("`}},
+		{name: "insert_no_values", sql: "INSERT INTO db.table VALUES (1)", expected: SyntaxError{Line: 1, Column: 22, Msg: `expected "(" or SELECT but found "VALUES"`}},
+		{name: "mixed_placeholders", sql: "UPDATE db.table SET a=@a WHERE id=$1", expected: SyntaxError{Line: 1, Column: 35, Msg: `positional and named placeholders must not be mixed, found "$1"`}},
+		{name: "select_mixed_placeholders", sql: "SELECT * FROM c WHERE c.a=:1 AND c.b=@b WITH db=mydb", expected: SyntaxError{Line: 1, Column: 38, Msg: `positional and named placeholders must not be mixed, found "@b"`}},
+		{name: "invalid_placeholder", sql: "SELECT * FROM c WHERE c.a=$0 WITH db=mydb", expected: SyntaxError{Line: 1, Column: 27, Msg: `invalid placeholder "$0"`}},
+		{name: "returning_no_field", sql: "DELETE FROM db.table WHERE id=1 RETURNING", expected: SyntaxError{Line: 1, Column: 42, Msg: "expected field name but found end of query"}},
+		{name: "returning_twice", sql: "DELETE FROM db.table WHERE id=1 RETURNING id WITH db=mydb RETURNING *", expected: SyntaxError{Line: 1, Column: 59, Msg: `expected WITH or end of query but found "RETURNING"`}},
+		{name: "select_subquery_no_collection", sql: "SELECT VALUE COUNT(1) FROM (SELECT * FROM c) AS x WITH db=mydb", expected: SyntaxError{Line: 1, Column: 28, Msg: `expected collection name but found "("`}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("%s failed: expected SyntaxError but received %#v", testName+"/"+testCase.name, err)
+			}
+			if *syntaxErr != testCase.expected {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, *syntaxErr)
+			}
+		})
+	}
+}
+
+func TestParseQuery_commentsAndStrings(t *testing.T) {
+	testName := "TestParseQuery_commentsAndStrings"
+	testData := []struct {
+		name     string
+		sql      string
+		expected interface{}
+	}{
+		{
+			name:     "parentheses_in_string",
+			sql:      `INSERT INTO db.table (id, note) VALUES ("\"1\"", "\"a (note), with parentheses\"")`,
+			expected: &StmtInsert{StmtCRUD: &StmtCRUD{dbName: "db", collName: "table"}, fields: []string{"id", "note"}, values: []interface{}{"1", "a (note), with parentheses"}},
+		},
+		{
+			name:     "comments",
+			sql:      "-- delete a document\nDELETE FROM db.table /* the id */ WHERE id=1 -- and the pk\nAND pk=\"\\\"a\\\"\"",
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{dbName: "db", collName: "table", pkPaths: []string{"/pk"}, numPkPaths: 1}, id: 1.0, pkValues: []interface{}{"a"}},
+		},
+		{
+			name:     "where_keyword_in_string",
+			sql:      `UPDATE db.table SET note="\"a WHERE clause\"" WHERE id=1`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{dbName: "db", collName: "table", pkPaths: []string{}}, fields: []string{"note"}, values: []interface{}{"a WHERE clause"}, id: 1.0, pkValues: []interface{}{}},
+		},
+		{
+			name: "select_placeholders_outside_strings",
+			sql:  `SELECT * FROM c WHERE c.a="@1" AND c.b=@1 AND c.o={"k":2} AND c.c = :2 /* WITH db=other */ WITH db=mydb`,
+			expected: &StmtSelect{dbName: "mydb", collName: "c", placeholders: map[int]string{1: "@_1", 2: "@_2"},
+				selectQuery: `SELECT * FROM c WHERE c.a="@1" AND c.b=@_1 AND c.o={"k":2} AND c.c = @_2`},
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			switch stmt := s.(type) {
+			case *StmtInsert:
+				stmt.Stmt, stmt.fieldsStr, stmt.valuesStr = nil, "", ""
+			case *StmtDelete:
+				stmt.Stmt, stmt.whereStr = nil, ""
+			case *StmtUpdate:
+				stmt.Stmt, stmt.whereStr, stmt.updateStr = nil, "", ""
+			case *StmtSelect:
+				stmt.Stmt = nil
+			}
+			if !reflect.DeepEqual(s, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, s)
+			}
+		})
+	}
+}
+
+func TestParseQuery_quotedIdentifiers(t *testing.T) {
+	testName := "TestParseQuery_quotedIdentifiers"
+	testData := []struct {
+		name     string
+		sql      string
+		expected interface{}
+	}{
+		{
+			name:     "create_database",
+			sql:      "CREATE DATABASE `my db`",
+			expected: &StmtCreateDatabase{dbName: "my db"},
+		},
+		{
+			name:     "drop_collection",
+			sql:      `DROP COLLECTION IF EXISTS [my.db].[my]]coll]`,
+			expected: &StmtDropCollection{dbName: "my.db", collName: "my]coll", ifExists: true},
+		},
+		{
+			name: "insert",
+			sql:  "INSERT INTO `my db`.\"my coll\" (id, `first name`, [a/b]) VALUES (1, 2, 3) WITH pk=/`first name`",
+			expected: &StmtInsert{StmtCRUD: &StmtCRUD{dbName: "my db", collName: "my coll", withPk: "/`first name`", pkPaths: []string{"/first name"}, numPkPaths: 1},
+				fields: []string{"id", "first name", "a/b"}, values: []interface{}{1.0, 2.0, 3.0}},
+		},
+		{
+			name: "delete",
+			sql:  "DELETE FROM [my db].[my coll] WHERE id=1 AND `p k`=2",
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{dbName: "my db", collName: "my coll", pkPaths: []string{"/p k"}, numPkPaths: 1},
+				id: 1.0, pkValues: []interface{}{2.0}},
+		},
+		{
+			name: "select",
+			sql:  "SELECT c.id, c.tags[0] FROM [my coll] c WITH db=`my db`",
+			expected: &StmtSelect{dbName: "my db", collName: "my coll", placeholders: map[int]string{},
+				selectQuery: "SELECT c.id, c.tags[0] FROM root c"},
+		},
+		{
+			name:     "select_collection_option",
+			sql:      "SELECT * FROM c WITH database=[my db] WITH collection=`my coll`",
+			expected: &StmtSelect{dbName: "my db", collName: "my coll", placeholders: map[int]string{}, selectQuery: "SELECT * FROM c"},
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			switch stmt := s.(type) {
+			case *StmtCreateDatabase:
+				stmt.Stmt = nil
+			case *StmtDropCollection:
+				stmt.Stmt = nil
+			case *StmtInsert:
+				stmt.Stmt, stmt.fieldsStr, stmt.valuesStr = nil, "", ""
+			case *StmtDelete:
+				stmt.Stmt, stmt.whereStr = nil, ""
+			case *StmtSelect:
+				stmt.Stmt = nil
+			}
+			if !reflect.DeepEqual(s, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, s)
+			}
+		})
+	}
+}
+
+func TestPatchOps_escapeFieldNames(t *testing.T) {
+	testName := "TestPatchOps_escapeFieldNames"
+	s, err := ParseQueryWithDefaultDb(nil, "", "UPDATE db.table SET `a/b`=1, [c~d]=2 WHERE id=1")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	ops := s.(*StmtUpdate).patchOps(nil)
+	if ops[0].Path != "/a~1b" || ops[1].Path != "/c~0d" {
+		t.Fatalf("%s failed: unexpected patch operations %#v", testName, ops)
+	}
+}
+
+func TestParseQuery_namedPlaceholders(t *testing.T) {
+	testName := "TestParseQuery_namedPlaceholders"
+	testData := []struct {
+		name      string
+		sql       string
+		expected  interface{}
+		numInputs int
+	}{
+		{
+			name:      "insert",
+			sql:       "INSERT INTO db.table (id, a, b) VALUES (@id, @a, @id)",
+			expected:  &StmtInsert{StmtCRUD: &StmtCRUD{dbName: "db", collName: "table"}, fields: []string{"id", "a", "b"}, values: []interface{}{namedPlaceholder{"id"}, namedPlaceholder{"a"}, namedPlaceholder{"id"}}},
+			numInputs: 2,
+		},
+		{
+			name: "update",
+			sql:  "UPDATE db.table SET a=@value WHERE id=@id AND pk=@pk",
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{dbName: "db", collName: "table", pkPaths: []string{"/pk"}, numPkPaths: 1},
+				fields: []string{"a"}, values: []interface{}{namedPlaceholder{"value"}}, id: namedPlaceholder{"id"}, pkValues: []interface{}{namedPlaceholder{"pk"}}},
+			numInputs: 3,
+		},
+		{
+			name: "delete",
+			sql:  "DELETE FROM db.table WHERE id=@id",
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{dbName: "db", collName: "table", pkPaths: []string{}},
+				id: namedPlaceholder{"id"}, pkValues: []interface{}{}},
+			numInputs: 1,
+		},
+		{
+			name: "insert_multi_rows",
+			sql:  "INSERT INTO db.table (id, a) VALUES (@id1, @a), (@id2, @a)",
+			expected: &StmtInsert{StmtCRUD: &StmtCRUD{dbName: "db", collName: "table"}, fields: []string{"id", "a"}, values: []interface{}{namedPlaceholder{"id1"}, namedPlaceholder{"a"}},
+				extraRows: [][]interface{}{{namedPlaceholder{"id2"}, namedPlaceholder{"a"}}}},
+			numInputs: 3,
+		},
+		{
+			name: "select",
+			sql:  `SELECT * FROM c WHERE c.a=@a AND c.b="@b" AND c.c>@a_2 OR c.a=@a WITH db=mydb`,
+			expected: &StmtSelect{dbName: "mydb", collName: "c", placeholders: map[int]string{},
+				selectQuery: `SELECT * FROM c WHERE c.a=@a AND c.b="@b" AND c.c>@a_2 OR c.a=@a`},
+			numInputs: 2,
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if numInputs := s.NumInput(); numInputs != testCase.numInputs {
+				t.Fatalf("%s failed: expected %d inputs but received %d", testName+"/"+testCase.name, testCase.numInputs, numInputs)
+			}
+			switch stmt := s.(type) {
+			case *StmtInsert:
+				stmt.Stmt, stmt.fieldsStr, stmt.valuesStr = nil, "", ""
+			case *StmtDelete:
+				stmt.Stmt, stmt.whereStr = nil, ""
+			case *StmtUpdate:
+				stmt.Stmt, stmt.whereStr, stmt.updateStr = nil, "", ""
+			case *StmtSelect:
+				stmt.Stmt = nil
+			}
+			if !reflect.DeepEqual(s, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, s)
+			}
+		})
+	}
+}
+
+func TestParseQuery_insertSelect(t *testing.T) {
+	testName := "TestParseQuery_insertSelect"
+	testData := []struct {
+		name      string
+		sql       string
+		mustError string
+		expected  *StmtInsertSelect
+		numInputs int
+	}{
+		{
+			name: "default_source_db",
+			sql:  "INSERT INTO db.target SELECT c.id, c.name FROM c WHERE c.grade > :1 WITH cross_partition=true WITH pk=/name",
+			expected: &StmtInsertSelect{StmtCRUD: &StmtCRUD{dbName: "db", collName: "target", withPk: "/name", pkPaths: []string{"/name"}, numPkPaths: 1},
+				query: &StmtSelect{dbName: "db", collName: "c", isCrossPartition: true, selectQuery: "SELECT c.id, c.name FROM c WHERE c.grade > @_1", placeholders: map[int]string{1: "@_1"}}},
+			numInputs: 1,
+		},
+		{
+			name: "upsert_source_db",
+			sql:  "UPSERT INTO target SELECT * FROM c WHERE c.a=@a AND c.b=@b WITH db=other WITH table=source",
+			expected: &StmtInsertSelect{StmtCRUD: &StmtCRUD{dbName: "mydb", collName: "target"}, isUpsert: true,
+				query: &StmtSelect{dbName: "other", collName: "source", selectQuery: "SELECT * FROM c WHERE c.a=@a AND c.b=@b", placeholders: map[int]string{}}},
+			numInputs: 2,
+		},
+		{
+			name:      "invalid_option",
+			sql:       "INSERT INTO db.target SELECT * FROM c WITH singlePK",
+			mustError: "invalid query, parsing error at WITH SINGLEPK",
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "mydb", testCase.sql)
+			if testCase.mustError != "" {
+				if err == nil || err.Error() != testCase.mustError {
+					t.Fatalf("%s failed: expected error %q but received %v", testName+"/"+testCase.name, testCase.mustError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if numInputs := s.NumInput(); numInputs != testCase.numInputs {
+				t.Fatalf("%s failed: expected %d inputs but received %d", testName+"/"+testCase.name, testCase.numInputs, numInputs)
+			}
+			stmt := s.(*StmtInsertSelect)
+			stmt.Stmt, stmt.query.Stmt = nil, nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestParseQuery_returning(t *testing.T) {
+	testName := "TestParseQuery_returning"
+	testData := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{name: "insert_after_with", sql: "INSERT INTO db.table (id, a) VALUES (1, 2) WITH pk=/id RETURNING *", expected: []string{"*"}},
+		{name: "upsert_before_with", sql: "UPSERT INTO db.table (id, a) VALUES (1, 2) RETURNING id, _etag WITH pk=/id", expected: []string{"id", "_etag"}},
+		{name: "update", sql: "UPDATE db.table SET a=1 WHERE id=1 AND pk=2 RETURNING a, [b c]", expected: []string{"a", "b c"}},
+		{name: "delete", sql: "DELETE FROM db.table WHERE id=1 AND pk=2 RETURNING *", expected: []string{"*"}},
+		{name: "no_returning", sql: "DELETE FROM db.table WHERE id=1 AND pk=2"},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			var returning []string
+			switch stmt := s.(type) {
+			case *StmtInsert:
+				returning = stmt.returning
+			case *StmtUpdate:
+				returning = stmt.returning
+			case *StmtDelete:
+				returning = stmt.returning
+			}
+			if !reflect.DeepEqual(returning, testCase.expected) {
+				t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, testCase.expected, returning)
+			}
+		})
+	}
+}
+
+func TestParseQuery_setBased(t *testing.T) {
+	testName := "TestParseQuery_setBased"
+	testData := []struct {
+		name      string
+		sql       string
+		mustError string
+		expected  setBased
+		numInputs int
+	}{
+		{
+			name:      "update_positional",
+			sql:       "UPDATE db.table SET a=:1 WHERE c.grade > :2 AND c.name != :2 /* comment */ WITH max_rows=10",
+			expected:  setBased{predicate: &astPredicate{text: "c.grade > @_2 AND c.name != @_2", params: []interface{}{placeholder{index: 2}}}, maxRows: 10},
+			numInputs: 2,
+		},
+		{
+			name:      "delete_named",
+			sql:       "DELETE FROM db.table WHERE IS_DEFINED(c.email) AND c.a=@a WITH dry_run RETURNING id",
+			expected:  setBased{predicate: &astPredicate{text: "IS_DEFINED(c.email) AND c.a=@a", params: []interface{}{namedPlaceholder{name: "a"}}}, dryRun: true},
+			numInputs: 1,
+		},
+		{
+			name:      "delete_dry_run_false",
+			sql:       "DELETE FROM db.table WHERE c.grade < 1 WITH dryRun=false",
+			expected:  setBased{predicate: &astPredicate{text: "c.grade < 1"}},
+			numInputs: 0,
+		},
+		{
+			name:      "point_delete",
+			sql:       "DELETE FROM db.table WHERE id=:1",
+			numInputs: -1,
+		},
+		{
+			name:      "id_or_predicate",
+			sql:       "DELETE FROM db.table WHERE id=:1 OR c.x>1",
+			expected:  setBased{predicate: &astPredicate{text: "id=@_1 OR c.x>1", params: []interface{}{placeholder{index: 1}}}},
+			numInputs: 1,
+		},
+		{
+			name:      "non_id_field_predicate",
+			sql:       `UPDATE db.table SET a=1 WHERE status="\"a\""`,
+			expected:  setBased{predicate: &astPredicate{text: `status="\"a\""`}},
+			numInputs: 0,
+		},
+		{
+			name:      "invalid_max_rows",
+			sql:       "DELETE FROM db.table WHERE c.grade < 1 WITH max_rows=0",
+			mustError: "invalid value at WITH MAX_ROWS: 0",
+		},
+		{
+			name:      "max_rows_with_point_update",
+			sql:       "UPDATE db.table SET a=1 WHERE id=1 WITH max_rows=10",
+			mustError: "invalid query, parsing error at WITH MAX_ROWS",
+		},
+		{
+			name:      "single_pk_with_predicate",
+			sql:       "DELETE FROM db.table WHERE c.grade < 1 WITH single_pk",
+			mustError: "invalid query, parsing error at WITH SINGLE_PK",
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if testCase.mustError != "" {
+				if err == nil || err.Error() != testCase.mustError {
+					t.Fatalf("%s failed: expected error %q but received %v", testName+"/"+testCase.name, testCase.mustError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if numInputs := s.NumInput(); numInputs != testCase.numInputs {
+				t.Fatalf("%s failed: expected %d inputs but received %d", testName+"/"+testCase.name, testCase.numInputs, numInputs)
+			}
+			var sb setBased
+			switch stmt := s.(type) {
+			case *StmtUpdate:
+				sb = stmt.setBased
+			case *StmtDelete:
+				sb = stmt.setBased
+			}
+			if !reflect.DeepEqual(sb, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, sb)
+			}
+		})
+	}
+}
+
+func TestParseQuery_select(t *testing.T) {
+	testName := "TestParseQuery_select"
+	testData := []struct {
+		name     string
+		sql      string
+		expected *StmtSelect
+	}{
+		{
+			name:     "subquery",
+			sql:      "SELECT VALUE COUNT(1) FROM (SELECT DISTINCT VALUE c.category FROM c) AS x WITH db=d WITH collection=c",
+			expected: &StmtSelect{dbName: "d", collName: "c", selectQuery: "SELECT VALUE COUNT(1) FROM (SELECT DISTINCT VALUE c.category FROM c) AS x", placeholders: map[int]string{}},
+		},
+		{
+			name:     "keywords_as_properties",
+			sql:      "SELECT c.with, c.from FROM c WHERE c.with = :1 WITH db=d WITH collection=tbl",
+			expected: &StmtSelect{dbName: "d", collName: "tbl", selectQuery: "SELECT c.with, c.from FROM c WHERE c.with = @_1", placeholders: map[int]string{1: "@_1"}},
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt := s.(*StmtSelect)
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %s\nreceived %s", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestParseQuery_literalText(t *testing.T) {
+	testName := "TestParseQuery_literalText"
+	testData := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{name: "insert_exponent", sql: "INSERT INTO db.table (id, a) VALUES (1, 2.5e3)", expected: "1, 2.5e3"},
+		{name: "update_decimal", sql: "UPDATE db.table SET a=1 WHERE id=1.5", expected: "id=1.5"},
+		{name: "delete_negative", sql: "DELETE FROM db.table WHERE id=-12 AND pk=true", expected: "id=-12 AND pk=true"},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			var text string
+			switch stmt := s.(type) {
+			case *StmtInsert:
+				text = stmt.valuesStr
+			case *StmtUpdate:
+				text = stmt.whereStr
+			case *StmtDelete:
+				text = stmt.whereStr
+			}
+			if text != testCase.expected {
+				t.Fatalf("%s failed: expected %q but received %q", testName+"/"+testCase.name, testCase.expected, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package gocosmos
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (info StoredProcedureInfo) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"id":    info.Id,
+		"body":  info.Body,
+		"_rid":  info.Rid,
+		"_ts":   info.Ts,
+		"_self": info.Self,
+		"_etag": info.Etag,
+	}
+}
+
+// StmtCreateProcedure implements "CREATE PROCEDURE" statement.
+//
+// Syntax:
+//
+//	CREATE [OR REPLACE] PROCEDURE [<db-name>.]<collection-name>.<procedure-name> AS '<javascript-function>'
+//
+// - The body of the stored procedure is enclosed in single quotes; single quotes inside the body are escaped by doubling them.
+//
+// - If "OR REPLACE" is specified, an existing stored procedure with the same name is replaced. Otherwise, Exec returns ErrConflict.
+//
+// @Available since v1.2.0
+type StmtCreateProcedure struct {
+	*Stmt
+	dbName    string
+	collName  string
+	procName  string
+	orReplace bool
+	body      string
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtCreateProcedure) String() string {
+	return fmt.Sprintf(`StmtCreateProcedure{Stmt: %s, db: %q, collection: %q, procedure: %q, or_replace: %t}`,
+		s.Stmt, s.dbName, s.collName, s.procName, s.orReplace)
+}
+
+func (s *StmtCreateProcedure) validate() error {
+	if s.dbName == "" || s.collName == "" || s.procName == "" {
+		return errors.New("database/collection/procedure is missing")
+	}
+	if strings.TrimSpace(s.body) == "" {
+		return errors.New("procedure body is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtCreateProcedure) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtCreateProcedure) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtCreateProcedure) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	spec := StoredProcedureSpec{DbName: s.dbName, CollName: s.collName, Id: s.procName, Body: s.body}
+	restResult := s.conn.restClient.CreateStoredProcedureContext(ctx, spec)
+	if restResult.StatusCode == 409 && s.orReplace {
+		replaceResult := s.conn.restClient.ReplaceStoredProcedureContext(ctx, spec)
+		result := buildResultNoResultSet(&replaceResult.RestResponse, true, replaceResult.Rid, 0)
+		return result, result.err
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtDropProcedure implements "DROP PROCEDURE" statement.
+//
+// Syntax:
+//
+//	DROP PROCEDURE [IF EXISTS] [<db-name>.]<collection-name>.<procedure-name>
+//
+// If "IF EXISTS" is specified, Exec will silently swallow the error "404 Not Found".
+//
+// @Available since v1.2.0
+type StmtDropProcedure struct {
+	*Stmt
+	dbName   string
+	collName string
+	procName string
+	ifExists bool
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtDropProcedure) String() string {
+	return fmt.Sprintf(`StmtDropProcedure{Stmt: %s, db: %q, collection: %q, procedure: %q, if_exists: %t}`,
+		s.Stmt, s.dbName, s.collName, s.procName, s.ifExists)
+}
+
+func (s *StmtDropProcedure) validate() error {
+	if s.dbName == "" || s.collName == "" || s.procName == "" {
+		return errors.New("database/collection/procedure is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtDropProcedure) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtDropProcedure) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtDropProcedure) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	restResult := s.conn.restClient.DeleteStoredProcedureContext(ctx, s.dbName, s.collName, s.procName)
+	ignoreErrorCode := 0
+	if s.ifExists {
+		ignoreErrorCode = 404
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, false, "", ignoreErrorCode)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtListProcedures implements "LIST PROCEDURES" statement.
+//
+// Syntax:
+//
+//	LIST PROCEDURES|PROCEDURE FROM [<db-name>.]<collection-name>
+//
+// @Available since v1.2.0
+type StmtListProcedures struct {
+	*Stmt
+	dbName   string
+	collName string
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtListProcedures) String() string {
+	return fmt.Sprintf(`StmtListProcedures{Stmt: %s, db: %q, collection: %q}`, s.Stmt, s.dbName, s.collName)
+}
+
+func (s *StmtListProcedures) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtListProcedures) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtListProcedures) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+func (s *StmtListProcedures) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	restResult := s.conn.restClient.ListStoredProceduresContext(ctx, s.dbName, s.collName)
+	result := &ResultResultSet{
+		err:        restResult.Error(),
+		columnList: []string{"id", "body", "_rid", "_ts", "_self", "_etag"},
+	}
+	if result.err == nil {
+		result.count = len(restResult.StoredProcedures)
+		result.rows = make([]DocInfo, result.count)
+		for i, sproc := range restResult.StoredProcedures {
+			result.rows[i] = sproc.toMap()
+		}
+	}
+	result.err = normalizeError(restResult.StatusCode, 0, result.err)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtExecProcedure implements "EXEC" statement, which executes a stored procedure.
+//
+// Syntax:
+//
+//	EXEC|EXECUTE [<db-name>.]<collection-name>.<procedure-name>([<value1>[,<valueN>]*])
+//	WITH PK=<pk-value>[,<pk-value2>...]
+//
+//	- Values are the parameters of the stored procedure; see StmtInsert for details on values.
+//	- WITH PK specifies the value(s) of the partition key of the logical partition the stored procedure is executed on
+//	  (placeholders are accepted). If the collection's PK has more than one path, values are comma separated, in the same
+//	  order as in the collection.
+//	- Query returns the response of the stored procedure as rows: an array of objects becomes one row per object, an
+//	  object becomes one row, and any other value becomes one row with a single column "$1".
+//	- Exec executes the stored procedure and discards its response.
+//
+// @Available since v1.2.0
+type StmtExecProcedure struct {
+	*Stmt
+	dbName   string
+	collName string
+	procName string
+	argsStr  string
+	args     []interface{}
+	pkValues []interface{}
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtExecProcedure) String() string {
+	return fmt.Sprintf(`StmtExecProcedure{Stmt: %s, db: %q, collection: %q, procedure: %q, args_str: %q, args: %v, pk_values: %v}`,
+		s.Stmt, s.dbName, s.collName, s.procName, s.argsStr, s.args, s.pkValues)
+}
+
+func (s *StmtExecProcedure) parse(withOpts []withOption) error {
+	if err := s.parseWithOpts(withOpts); err != nil {
+		return err
+	}
+	for k := range s.withOpts {
+		if k != "PK" {
+			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
+		}
+	}
+
+	for _, value := range append(append([]interface{}{}, s.args...), s.pkValues...) {
+		s.addInput(value)
+	}
+	return nil
+}
+
+func (s *StmtExecProcedure) validate() error {
+	if s.dbName == "" || s.collName == "" || s.procName == "" {
+		return errors.New("database/collection/procedure is missing")
+	}
+	if len(s.pkValues) == 0 {
+		return errors.New("partition key value is missing, specify it with WITH PK=<value>")
+	}
+	return nil
+}
+
+// resolve replaces placeholders in values with the supplied arguments.
+func (s *StmtExecProcedure) resolve(values []interface{}, args []driver.NamedValue) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = placeholderValue(value, args)
+	}
+	return result
+}
+
+func (s *StmtExecProcedure) execute(ctx context.Context, args []driver.NamedValue) (*RespExecuteStoredProcedure, error) {
+	if len(args) != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, len(args))
+	}
+	if err := s.checkNamedArgs(args); err != nil {
+		return nil, err
+	}
+	restResult := s.conn.restClient.ExecuteStoredProcedureContext(ctx, s.dbName, s.collName, s.procName,
+		s.resolve(s.pkValues, args), s.resolve(s.args, args)...)
+	return restResult, normalizeError(restResult.StatusCode, 0, restResult.Error())
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtExecProcedure) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtExecProcedure) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	restResult, err := s.execute(ctx, args)
+	if restResult == nil {
+		return nil, err
+	}
+	result := &ResultNoResultSet{err: err}
+	result.setResponseInfo(&restResult.RestResponse)
+	if err == nil {
+		result.affectedRows = 1
+	}
+	return result, err
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtExecProcedure) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+func (s *StmtExecProcedure) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	restResult, err := s.execute(ctx, args)
+	if restResult == nil {
+		return nil, err
+	}
+	result := &ResultResultSet{err: err, columnList: make([]string, 0)}
+	if err == nil {
+		switch v := restResult.Result.(type) {
+		case nil:
+			result.rows = make([]DocInfo, 0)
+		case []interface{}:
+			result.documents = v
+		default:
+			result.documents = QueriedDocs{v}
+		}
+		result.init()
+	}
+	return result, result.err
+}
@@ -0,0 +1,187 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStmtCreateProcedure_parse(t *testing.T) {
+	testName := "TestStmtCreateProcedure_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtCreateProcedure
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: "CREATE PROCEDURE sproc AS 'function () {}'", mustError: true},
+		{name: "error_no_default_db", sql: "CREATE PROCEDURE table.sproc AS 'function () {}'", mustError: true},
+		{name: "error_no_body", sql: "CREATE PROCEDURE db.table.sproc AS ''", mustError: true},
+		{name: "error_unquoted_body", sql: "CREATE PROCEDURE db.table.sproc AS function () {}", mustError: true},
+		{name: "error_unescaped_quote", sql: "CREATE PROCEDURE db.table.sproc AS 'function () { return 'a'; }'", mustError: true},
+
+		{name: "basic", sql: "CREATE PROCEDURE db1.table1.sproc1 AS 'function () {}'", expected: &StmtCreateProcedure{dbName: "db1", collName: "table1", procName: "sproc1", body: "function () {}"}},
+		{name: "or_replace", sql: "create\nor\treplace procedure db-2.table_2.sproc-2\r\nas\n'function () {\n  return ''a'';\n}'", expected: &StmtCreateProcedure{dbName: "db-2", collName: "table_2", procName: "sproc-2", orReplace: true, body: "function () {\n  return 'a';\n}"}},
+		{name: "default_db", db: "mydb", sql: "CREATE PROCEDURE table.sproc AS 'function () {}'", expected: &StmtCreateProcedure{dbName: "mydb", collName: "table", procName: "sproc", body: "function () {}"}},
+		{name: "db_in_query", db: "mydb", sql: "CREATE PROCEDURE db.table.sproc AS 'function () {}'", expected: &StmtCreateProcedure{dbName: "db", collName: "table", procName: "sproc", body: "function () {}"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtCreateProcedure)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtCreateProcedure but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtDropProcedure_parse(t *testing.T) {
+	testName := "TestStmtDropProcedure_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtDropProcedure
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: "DROP PROCEDURE sproc", mustError: true},
+		{name: "error_no_default_db", sql: "DROP PROCEDURE table.sproc", mustError: true},
+		{name: "error_if_exist", sql: "DROP PROCEDURE IF EXIST db.table.sproc", mustError: true},
+
+		{name: "basic", sql: "DROP PROCEDURE db1.table1.sproc1", expected: &StmtDropProcedure{dbName: "db1", collName: "table1", procName: "sproc1"}},
+		{name: "if_exists", sql: "drop\nprocedure\tif\r\nexists db-2.table_2.sproc-2", expected: &StmtDropProcedure{dbName: "db-2", collName: "table_2", procName: "sproc-2", ifExists: true}},
+		{name: "default_db", db: "mydb", sql: "DROP PROCEDURE IF EXISTS table.sproc", expected: &StmtDropProcedure{dbName: "mydb", collName: "table", procName: "sproc", ifExists: true}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtDropProcedure)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtDropProcedure but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtListProcedures_parse(t *testing.T) {
+	testName := "TestStmtListProcedures_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtListProcedures
+		mustError bool
+	}{
+		{name: "error_no_collection", db: "mydb", sql: "LIST PROCEDURES", mustError: true},
+		{name: "error_no_default_db", sql: "LIST PROCEDURES FROM table", mustError: true},
+
+		{name: "basic", sql: "LIST PROCEDURES FROM db1.table1", expected: &StmtListProcedures{dbName: "db1", collName: "table1"}},
+		{name: "procedure", sql: "list\nprocedure\r\nfrom\tdb-2.table_2", expected: &StmtListProcedures{dbName: "db-2", collName: "table_2"}},
+		{name: "default_db", db: "mydb", sql: "LIST PROCEDURES FROM table", expected: &StmtListProcedures{dbName: "mydb", collName: "table"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtListProcedures)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtListProcedures but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtExecProcedure_parse(t *testing.T) {
+	testName := "TestStmtExecProcedure_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtExecProcedure
+		numInputs int
+		mustError bool
+	}{
+		{name: "error_no_pk", sql: "EXEC db.table.sproc()", mustError: true},
+		{name: "error_no_default_db", sql: "EXEC table.sproc() WITH PK=a", mustError: true},
+		{name: "error_invalid_with", sql: "EXEC db.table.sproc() WITH PK=a WITH ru=400", mustError: true},
+		{name: "error_invalid_value", sql: "EXEC db.table.sproc(0x1qa) WITH PK=a", mustError: true},
+
+		{name: "basic", sql: "EXEC db1.table1.sproc1() WITH PK=user1", expected: &StmtExecProcedure{dbName: "db1", collName: "table1", procName: "sproc1", args: []interface{}{}, pkValues: []interface{}{"user1"}}},
+		{
+			name:     "values",
+			sql:      "execute\tdb-2.table_2.sproc-2 (null, 1.0,\ntrue, \"\\\"a string\\\"\", \"{\\\"key\\\":\\\"value\\\"}\")\nWITH\tpk=user1,2",
+			expected: &StmtExecProcedure{dbName: "db-2", collName: "table_2", procName: "sproc-2", argsStr: "null, 1.0,\ntrue, \"\\\"a string\\\"\", \"{\\\"key\\\":\\\"value\\\"}\"", args: []interface{}{nil, 1.0, true, "a string", map[string]interface{}{"key": "value"}}, pkValues: []interface{}{"user1", 2.0}},
+		},
+		{
+			name:      "placeholders",
+			db:        "mydb",
+			sql:       "EXEC table.sproc(:1, $3) WITH PK=:2",
+			expected:  &StmtExecProcedure{dbName: "mydb", collName: "table", procName: "sproc", argsStr: ":1, $3", args: []interface{}{placeholder{1}, placeholder{3}}, pkValues: []interface{}{placeholder{2}}},
+			numInputs: 3,
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtExecProcedure)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtExecProcedure but received %T", testName+"/"+testCase.name, s)
+			}
+			if stmt.numInputs != testCase.numInputs {
+				t.Fatalf("%s failed: expected %d input values but received %d", testName+"/"+testCase.name, testCase.numInputs, stmt.numInputs)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package gocosmos
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StmtCreateTrigger implements "CREATE TRIGGER" statement.
+//
+// Syntax:
+//
+//	CREATE [OR REPLACE] TRIGGER [<db-name>.]<collection-name>.<trigger-name> PRE|POST [ON ALL|CREATE|REPLACE|DELETE]
+//	AS '<javascript-function>'
+//
+// - PRE creates a pre-trigger, POST creates a post-trigger.
+//
+// - ON specifies the operation the trigger is fired on, default value is ALL.
+//
+// - The body of the trigger is enclosed in single quotes; single quotes inside the body are escaped by doubling them.
+//
+// - If "OR REPLACE" is specified, an existing trigger with the same name is replaced. Otherwise, Exec returns ErrConflict.
+//
+// @Available since v1.2.0
+type StmtCreateTrigger struct {
+	*Stmt
+	dbName      string
+	collName    string
+	triggerName string
+	orReplace   bool
+	triggerType string
+	operation   string
+	body        string
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtCreateTrigger) String() string {
+	return fmt.Sprintf(`StmtCreateTrigger{Stmt: %s, db: %q, collection: %q, trigger: %q, or_replace: %t, type: %q, operation: %q}`,
+		s.Stmt, s.dbName, s.collName, s.triggerName, s.orReplace, s.triggerType, s.operation)
+}
+
+func (s *StmtCreateTrigger) parse(triggerTypeStr, operationStr string) {
+	switch strings.ToUpper(triggerTypeStr) {
+	case "PRE":
+		s.triggerType = TriggerTypePre
+	case "POST":
+		s.triggerType = TriggerTypePost
+	}
+	switch strings.ToUpper(operationStr) {
+	case "", "ALL":
+		s.operation = TriggerOpAll
+	case "CREATE":
+		s.operation = TriggerOpCreate
+	case "REPLACE":
+		s.operation = TriggerOpReplace
+	case "DELETE":
+		s.operation = TriggerOpDelete
+	}
+}
+
+func (s *StmtCreateTrigger) validate() error {
+	if s.dbName == "" || s.collName == "" || s.triggerName == "" {
+		return errors.New("database/collection/trigger is missing")
+	}
+	if strings.TrimSpace(s.body) == "" {
+		return errors.New("trigger body is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtCreateTrigger) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtCreateTrigger) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtCreateTrigger) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	spec := TriggerSpec{DbName: s.dbName, CollName: s.collName, Id: s.triggerName, Body: s.body, Type: s.triggerType, Operation: s.operation}
+	restResult := s.conn.restClient.CreateTriggerContext(ctx, spec)
+	if restResult.StatusCode == 409 && s.orReplace {
+		replaceResult := s.conn.restClient.ReplaceTriggerContext(ctx, spec)
+		result := buildResultNoResultSet(&replaceResult.RestResponse, true, replaceResult.Rid, 0)
+		return result, result.err
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtDropTrigger implements "DROP TRIGGER" statement.
+//
+// Syntax:
+//
+//	DROP TRIGGER [IF EXISTS] [<db-name>.]<collection-name>.<trigger-name>
+//
+// If "IF EXISTS" is specified, Exec will silently swallow the error "404 Not Found".
+//
+// @Available since v1.2.0
+type StmtDropTrigger struct {
+	*Stmt
+	dbName      string
+	collName    string
+	triggerName string
+	ifExists    bool
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtDropTrigger) String() string {
+	return fmt.Sprintf(`StmtDropTrigger{Stmt: %s, db: %q, collection: %q, trigger: %q, if_exists: %t}`,
+		s.Stmt, s.dbName, s.collName, s.triggerName, s.ifExists)
+}
+
+func (s *StmtDropTrigger) validate() error {
+	if s.dbName == "" || s.collName == "" || s.triggerName == "" {
+		return errors.New("database/collection/trigger is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtDropTrigger) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtDropTrigger) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtDropTrigger) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	restResult := s.conn.restClient.DeleteTriggerContext(ctx, s.dbName, s.collName, s.triggerName)
+	ignoreErrorCode := 0
+	if s.ifExists {
+		ignoreErrorCode = 404
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, false, "", ignoreErrorCode)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtListTriggers implements "LIST TRIGGERS" statement.
+//
+// Syntax:
+//
+//	LIST TRIGGERS|TRIGGER FROM [<db-name>.]<collection-name>
+//
+// @Available since v1.2.0
+type StmtListTriggers struct {
+	*Stmt
+	dbName   string
+	collName string
+}
+
+// String implements fmt.Stringer/String.
+func (s *StmtListTriggers) String() string {
+	return fmt.Sprintf(`StmtListTriggers{Stmt: %s, db: %q, collection: %q}`, s.Stmt, s.dbName, s.collName)
+}
+
+func (s *StmtListTriggers) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtListTriggers) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtListTriggers) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+func (s *StmtListTriggers) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("expected 0 input value, got %d", len(args))
+	}
+
+	restResult := s.conn.restClient.ListTriggersContext(ctx, s.dbName, s.collName)
+	result := &ResultResultSet{
+		err:        restResult.Error(),
+		columnList: []string{"id", "body", "triggerType", "triggerOperation", "_rid", "_ts", "_self", "_etag"},
+	}
+	if result.err == nil {
+		result.count = len(restResult.Triggers)
+		result.rows = make([]DocInfo, result.count)
+		for i, trigger := range restResult.Triggers {
+			result.rows[i] = trigger.toMap()
+		}
+	}
+	result.err = normalizeError(restResult.StatusCode, 0, result.err)
+	return result, result.err
+}
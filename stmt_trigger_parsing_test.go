@@ -0,0 +1,134 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStmtCreateTrigger_parse(t *testing.T) {
+	testName := "TestStmtCreateTrigger_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtCreateTrigger
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: "CREATE TRIGGER trg PRE AS 'function () {}'", mustError: true},
+		{name: "error_no_default_db", sql: "CREATE TRIGGER table.trg PRE AS 'function () {}'", mustError: true},
+		{name: "error_no_type", sql: "CREATE TRIGGER db.table.trg AS 'function () {}'", mustError: true},
+		{name: "error_invalid_type", sql: "CREATE TRIGGER db.table.trg BEFORE AS 'function () {}'", mustError: true},
+		{name: "error_invalid_operation", sql: "CREATE TRIGGER db.table.trg PRE ON UPDATE AS 'function () {}'", mustError: true},
+		{name: "error_no_body", sql: "CREATE TRIGGER db.table.trg POST AS ''", mustError: true},
+		{name: "error_unescaped_quote", sql: "CREATE TRIGGER db.table.trg POST AS 'function () { return 'a'; }'", mustError: true},
+
+		{name: "basic", sql: "CREATE TRIGGER db1.table1.trg1 PRE AS 'function () {}'", expected: &StmtCreateTrigger{dbName: "db1", collName: "table1", triggerName: "trg1", triggerType: TriggerTypePre, operation: TriggerOpAll, body: "function () {}"}},
+		{name: "or_replace", sql: "create\nor\treplace trigger db-2.table_2.trg-2\r\npost on\tcreate\nas\n'function () {\n  return ''a'';\n}'", expected: &StmtCreateTrigger{dbName: "db-2", collName: "table_2", triggerName: "trg-2", orReplace: true, triggerType: TriggerTypePost, operation: TriggerOpCreate, body: "function () {\n  return 'a';\n}"}},
+		{name: "on_replace", sql: "CREATE TRIGGER db.table.trg PRE ON REPLACE AS 'function () {}'", expected: &StmtCreateTrigger{dbName: "db", collName: "table", triggerName: "trg", triggerType: TriggerTypePre, operation: TriggerOpReplace, body: "function () {}"}},
+		{name: "default_db", db: "mydb", sql: "CREATE TRIGGER table.trg POST ON DELETE AS 'function () {}'", expected: &StmtCreateTrigger{dbName: "mydb", collName: "table", triggerName: "trg", triggerType: TriggerTypePost, operation: TriggerOpDelete, body: "function () {}"}},
+		{name: "db_in_query", db: "mydb", sql: "CREATE TRIGGER db.table.trg PRE ON ALL AS 'function () {}'", expected: &StmtCreateTrigger{dbName: "db", collName: "table", triggerName: "trg", triggerType: TriggerTypePre, operation: TriggerOpAll, body: "function () {}"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtCreateTrigger)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtCreateTrigger but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtDropTrigger_parse(t *testing.T) {
+	testName := "TestStmtDropTrigger_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtDropTrigger
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: "DROP TRIGGER trg", mustError: true},
+		{name: "error_no_default_db", sql: "DROP TRIGGER table.trg", mustError: true},
+		{name: "error_if_exist", sql: "DROP TRIGGER IF EXIST db.table.trg", mustError: true},
+
+		{name: "basic", sql: "DROP TRIGGER db1.table1.trg1", expected: &StmtDropTrigger{dbName: "db1", collName: "table1", triggerName: "trg1"}},
+		{name: "if_exists", sql: "drop\ntrigger\tif\r\nexists db-2.table_2.trg-2", expected: &StmtDropTrigger{dbName: "db-2", collName: "table_2", triggerName: "trg-2", ifExists: true}},
+		{name: "default_db", db: "mydb", sql: "DROP TRIGGER IF EXISTS table.trg", expected: &StmtDropTrigger{dbName: "mydb", collName: "table", triggerName: "trg", ifExists: true}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtDropTrigger)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtDropTrigger but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtListTriggers_parse(t *testing.T) {
+	testName := "TestStmtListTriggers_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtListTriggers
+		mustError bool
+	}{
+		{name: "error_no_collection", db: "mydb", sql: "LIST TRIGGERS", mustError: true},
+		{name: "error_no_default_db", sql: "LIST TRIGGERS FROM table", mustError: true},
+
+		{name: "basic", sql: "LIST TRIGGERS FROM db1.table1", expected: &StmtListTriggers{dbName: "db1", collName: "table1"}},
+		{name: "trigger", sql: "list\ntrigger\r\nfrom\tdb-2.table_2", expected: &StmtListTriggers{dbName: "db-2", collName: "table_2"}},
+		{name: "default_db", db: "mydb", sql: "LIST TRIGGERS FROM table", expected: &StmtListTriggers{dbName: "mydb", collName: "table"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtListTriggers)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtListTriggers but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package gocosmos
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Tx is Azure Cosmos DB implementation of driver.Tx.
+//
+// INSERT, UPSERT, UPDATE and DELETE statements executed inside a transaction are not sent to server immediately.
+// Instead, they are buffered and committed as a single transactional batch (see RestClient.ExecuteBatch) when Commit is
+// called. Hence:
+//
+//   - All buffered statements must operate on documents of the same collection and the same logical partition (i.e.
+//     same partition key value), otherwise ErrTxCrossPartition is returned.
+//   - At most MaxBatchOperations statements can be buffered in a transaction.
+//   - The driver.Result returned by a buffered statement is only available after the transaction has been committed.
+//     Before that (or if the transaction is rolled back) its methods return ErrTxNotCommitted.
+//   - Other statements (e.g. SELECT, CREATE COLLECTION) are executed immediately, outside of the transaction.
+//
+// @Available since v1.2.0
+type Tx struct {
+	conn               *Conn
+	ctx                context.Context
+	dbName, collName   string
+	partitionKeyValues []interface{}
+	jsPkValues         string // JSON-encoded partition key values, used to compare partition keys of buffered statements
+	ops                []BatchOp
+	results            []*ResultNoResultSet // one result per buffered operation (shared by the operations of the same statement), populated on commit
+}
+
+// String implements fmt.Stringer/String.
+func (tx *Tx) String() string {
+	return fmt.Sprintf(`Tx{db: %q, collection: %q, pk: %s, num_ops: %d}`, tx.dbName, tx.collName, tx.jsPkValues, len(tx.ops))
+}
+
+// addOp buffers an operation to be committed as part of the transaction.
+func (tx *Tx) addOp(dbName, collName string, pkValues []interface{}, op BatchOp, supportLastInsertId bool) (driver.Result, error) {
+	return tx.addOps(dbName, collName, pkValues, []BatchOp{op}, supportLastInsertId)
+}
+
+// addOps buffers the operations of a statement (e.g. a multi-row INSERT) to be committed as part of the transaction.
+// The returned result reports the number of operations as affected rows once committed.
+//
+// @Available since v1.2.0
+func (tx *Tx) addOps(dbName, collName string, pkValues []interface{}, ops []BatchOp, supportLastInsertId bool) (driver.Result, error) {
+	jsPkValues, err := json.Marshal(pkValues)
+	if err != nil {
+		return nil, err
+	}
+	if len(tx.ops) == 0 {
+		tx.dbName, tx.collName, tx.partitionKeyValues, tx.jsPkValues = dbName, collName, pkValues, string(jsPkValues)
+	} else if tx.dbName != dbName || tx.collName != collName || tx.jsPkValues != string(jsPkValues) {
+		return nil, ErrTxCrossPartition
+	}
+	if len(tx.ops)+len(ops) > MaxBatchOperations {
+		return nil, fmt.Errorf("a transaction can contain at most %d operations", MaxBatchOperations)
+	}
+	result := &ResultNoResultSet{err: ErrTxNotCommitted, supportLastInsertId: supportLastInsertId}
+	for _, op := range ops {
+		tx.ops = append(tx.ops, op)
+		tx.results = append(tx.results, result)
+		if op.Id != "" {
+			result.docId = op.Id
+		}
+	}
+	return result, nil
+}
+
+// finish detaches the transaction from its connection.
+func (tx *Tx) finish() {
+	if tx.conn.tx == tx {
+		tx.conn.tx = nil
+	}
+}
+
+// Commit implements driver.Tx/Commit.
+//
+// Buffered statements are sent to server as a single transactional batch. If any of them fails, none is applied and
+// the error of the failed statement (e.g. ErrConflict, ErrNotFound or ErrPreconditionFailure) is returned.
+func (tx *Tx) Commit() error {
+	defer tx.finish()
+	if len(tx.ops) == 0 {
+		return nil
+	}
+	batchReq := BatchReq{
+		DbName:             tx.dbName,
+		CollName:           tx.collName,
+		PartitionKeyValues: tx.partitionKeyValues,
+		Operations:         tx.ops,
+	}
+	resp := tx.conn.restClient.ExecuteBatchContext(tx.ctx, batchReq)
+	if err := resp.Error(); err != nil {
+		statusCode := resp.StatusCode
+		if i := resp.FailedOperation(); i >= 0 {
+			statusCode = resp.Results[i].StatusCode
+		}
+		err = normalizeError(statusCode, 0, err)
+		for _, result := range tx.results {
+			result.err = err
+		}
+		return err
+	}
+	for _, result := range tx.results {
+		result.err, result.affectedRows, result.requestCharge = nil, 0, 0
+	}
+	for i, result := range tx.results {
+		result.affectedRows++
+		result.sessionToken, result.activityId = resp.SessionToken, resp.RespHeader[respHeaderActivityId]
+		if i < len(resp.Results) {
+			result.requestCharge += resp.Results[i].RequestCharge
+			if len(resp.Results[i].DocInfo) > 0 {
+				result.documents = append(result.documents, resp.Results[i].DocInfo)
+			}
+			if result.supportLastInsertId {
+				result.lastInsertId = resp.Results[i].DocInfo.Rid()
+			}
+		}
+	}
+	return nil
+}
+
+// Rollback implements driver.Tx/Rollback.
+//
+// Since buffered statements have not been sent to server, rolling back simply discards them.
+func (tx *Tx) Rollback() error {
+	defer tx.finish()
+	tx.ops, tx.results = nil, nil
+	return nil
+}
+
+// txFromConn returns the transaction in progress on the connection, nil if none.
+func txFromConn(c *Conn) *Tx {
+	if c == nil {
+		return nil
+	}
+	return c.tx
+}
@@ -3,11 +3,12 @@ package gocosmos
 import "reflect"
 
 const (
-	httpHeaderContentType   = "Content-Type"
-	httpHeaderAccept        = "Accept"
-	httpHeaderAuthorization = "Authorization"
-	httpHeaderIfMatch       = "If-Match"
-	httpHeaderIfNoneMatch   = "If-None-Match"
+	httpHeaderContentType     = "Content-Type"
+	httpHeaderAccept          = "Accept"
+	httpHeaderAuthorization   = "Authorization"
+	httpHeaderIfMatch         = "If-Match"
+	httpHeaderIfNoneMatch     = "If-None-Match"
+	httpHeaderIfModifiedSince = "If-Modified-Since"
 
 	restApiHeaderVersion                        = "x-ms-version"
 	restApiHeaderDate                           = "x-ms-date"
@@ -30,6 +31,14 @@ const (
 	restApiHeaderSupportedQueryFeatures         = "x-ms-cosmos-supported-query-features"
 	restApiHeaderPopulateMetrics                = "x-ms-documentdb-populatequerymetrics"
 	restApiHeaderIncremental                    = "A-IM"
+	restApiHeaderIsBatchRequest                 = "x-ms-cosmos-is-batch-request"
+	restApiHeaderBatchAtomic                    = "x-ms-cosmos-batch-atomic"
+	restApiHeaderBatchContinueOnError           = "x-ms-cosmos-batch-continue-on-error"
+	restApiHeaderChangeFeedWireFormatVersion    = "x-ms-cosmos-changefeed-wire-format-version"
+	restApiHeaderTokenExpiry                    = "x-ms-documentdb-expiry-seconds"
+	restApiHeaderScriptEnableLogging            = "x-ms-documentdb-script-enable-logging"
+	restApiHeaderPreTriggerInclude              = "x-ms-documentdb-pre-trigger-include"
+	restApiHeaderPostTriggerInclude             = "x-ms-documentdb-post-trigger-include"
 
 	restApiParamIndexingPolicy  = "indexingPolicy"
 	restApiParamUniqueKeyPolicy = "uniqueKeyPolicy"
@@ -42,6 +51,9 @@ const (
 	respHeaderSessionToken  = "X-MS-SESSION-TOKEN"
 	respHeaderContinuation  = "X-MS-CONTINUATION"
 	respHeaderEtag          = "ETAG"
+	respHeaderRetryAfterMs  = "X-MS-RETRY-AFTER-MS"
+	respHeaderScriptLog     = "X-MS-DOCUMENTDB-SCRIPT-LOG-RESULTS"
+	respHeaderActivityId    = "X-MS-ACTIVITY-ID"
 
 	docFieldId = "id"
 )